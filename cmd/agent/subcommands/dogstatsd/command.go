@@ -42,6 +42,7 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		Short: "Inspect dogstatsd pipeline status",
 	}
 
+	overflowFlags := topFlags{}
 	topFlags := topFlags{}
 
 	topCmd := &cobra.Command{
@@ -63,6 +64,25 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 
 	c.AddCommand(topCmd)
 
+	overflowCmd := &cobra.Command{
+		Use:   "overflow",
+		Short: "Display metrics with contexts folded into an overflow context by the contexts limits",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return fxutil.OneShot(overflowContexts,
+				fx.Supply(&overflowFlags),
+				fx.Supply(core.BundleParams{
+					ConfigParams: cconfig.NewAgentParams(globalParams.ConfFilePath, cconfig.WithExtraConfFiles(globalParams.ExtraConfFilePath), cconfig.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+			)
+		},
+	}
+	overflowCmd.Flags().StringVarP(&overflowFlags.path, "path", "p", "", "use specified file for input instead of getting contexts from the agent")
+	overflowCmd.Flags().IntVarP(&overflowFlags.nmetrics, "num-metrics", "m", 10, "number of metrics to show")
+	overflowCmd.Flags().IntVarP(&overflowFlags.ntags, "num-tags", "t", 5, "number of tags to show per metric")
+
+	c.AddCommand(overflowCmd)
+
 	c.AddCommand(&cobra.Command{
 		Use:   "dump-contexts",
 		Short: "Write currently tracked contexts as JSON",
@@ -119,25 +139,27 @@ func dumpContexts(config cconfig.Component, _ log.Component) error {
 }
 
 type metric struct {
-	count uint
-	tags  map[string]struct{}
+	count    uint
+	overflow uint
+	tags     map[string]struct{}
 }
 
-func topContexts(config cconfig.Component, flags *topFlags, _ log.Component) error {
+// readContexts reads the contexts dumped by the agent, or by a previous dump when a path
+// is given, and returns them by metric name.
+func readContexts(config cconfig.Component, path string) (map[string]*metric, error) {
 	var err error
 
-	path := flags.path
 	if path == "" {
 		path, err = triggerDump(config)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		fmt.Printf("Wrote %s\n", path)
 	}
 
 	f, err := os.Open(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer f.Close()
 
@@ -151,18 +173,17 @@ func topContexts(config cconfig.Component, flags *topFlags, _ log.Component) err
 
 	dec := json.NewDecoder(r)
 
-	repr := aggregator.ContextDebugRepr{}
-
 	metrics := make(map[string]*metric)
 
 	for {
+		repr := aggregator.ContextDebugRepr{}
 		err := dec.Decode(&repr)
 		if err == io.EOF {
 			break
 		}
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		m := metrics[repr.Name]
@@ -175,11 +196,26 @@ func topContexts(config cconfig.Component, flags *topFlags, _ log.Component) err
 
 		m.count++
 
+		if repr.Overflow {
+			// the overflow contexts only have the overflow tag
+			m.overflow++
+			continue
+		}
+
 		for _, tag := range repr.MetricTags {
 			m.tags[tag] = struct{}{}
 		}
 	}
 
+	return metrics, nil
+}
+
+func topContexts(config cconfig.Component, flags *topFlags, _ log.Component) error {
+	metrics, err := readContexts(config, flags.path)
+	if err != nil {
+		return err
+	}
+
 	fmt.Printf(" % 10s\t%s\t(%s)\n", "Contexts", "Metric name", "number of unique values for each tag")
 
 	ks := make([]string, 0, len(metrics))
@@ -224,6 +260,59 @@ func topContexts(config cconfig.Component, flags *topFlags, _ log.Component) err
 	return nil
 }
 
+func overflowContexts(config cconfig.Component, flags *topFlags, _ log.Component) error {
+	metrics, err := readContexts(config, flags.path)
+	if err != nil {
+		return err
+	}
+
+	ks := make([]string, 0, len(metrics))
+	for k, m := range metrics {
+		if m.overflow > 0 {
+			ks = append(ks, k)
+		}
+	}
+
+	if len(ks) == 0 {
+		fmt.Println("No metric reached the contexts limits")
+		return nil
+	}
+
+	fmt.Printf(" % 10s\t% 10s\t%s\t(%s)\n", "Contexts", "Overflow", "Metric name", "number of unique values for each tag")
+
+	sort.Slice(ks, func(i, j int) bool {
+		n := metrics[ks[i]].count
+		m := metrics[ks[j]].count
+		if n == m {
+			return ks[i] < ks[j]
+		}
+		return n > m
+	})
+
+	top := ks
+	rest := []string{}
+	limit := flags.nmetrics
+	// +1 to avoid showing "1 more", just show it.
+	if len(ks) > limit+1 {
+		top = ks[:limit]
+		rest = ks[limit:]
+	}
+
+	for _, k := range top {
+		m := metrics[k]
+
+		fmt.Printf(" % 10d\t% 10d\t%s\t(", m.count, m.overflow, k)
+		printTopTags(m, flags.ntags)
+		fmt.Println(")")
+	}
+
+	if len(rest) > 0 {
+		fmt.Printf(" (other %d metrics)\n", len(rest))
+	}
+
+	return nil
+}
+
 func printTopTags(m *metric, limit int) {
 	ts := make(map[string]uint)
 	for tag := range m.tags {
@@ -6,9 +6,12 @@
 package dogstatsd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
@@ -31,4 +34,31 @@ func TestCommand(t *testing.T) {
 			assert.Equal(t, 1, f.nmetrics)
 			assert.Equal(t, 2, f.ntags)
 		})
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"dogstatsd", "overflow", "-p", "foo", "-m", "1"},
+		overflowContexts,
+		func(f *topFlags) {
+			assert.Equal(t, "foo", f.path)
+			assert.Equal(t, 1, f.nmetrics)
+			assert.Equal(t, 5, f.ntags)
+		})
+}
+
+func TestReadContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contexts.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"Name":"foo","MetricTags":["a:1","b:1"]}
+{"Name":"foo","MetricTags":["a:2","b:1"]}
+{"Name":"foo","MetricTags":["overflow:true"],"Overflow":true}
+{"Name":"bar","MetricTags":["a:1"]}
+`), 0600))
+
+	metrics, err := readContexts(nil, path)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, uint(3), metrics["foo"].count)
+	assert.Equal(t, uint(1), metrics["foo"].overflow)
+	assert.Len(t, metrics["foo"].tags, 3)
+	assert.Equal(t, uint(1), metrics["bar"].count)
+	assert.Equal(t, uint(0), metrics["bar"].overflow)
 }
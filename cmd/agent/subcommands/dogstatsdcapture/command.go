@@ -11,6 +11,8 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"os"
+	"text/tabwriter"
 	"time"
 
 	"go.uber.org/fx"
@@ -19,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core"
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	replay "github.com/DataDog/datadog-agent/comp/dogstatsd/replay/impl"
 	"github.com/DataDog/datadog-agent/pkg/api/security"
 	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
@@ -34,6 +37,7 @@ import (
 
 const (
 	defaultCaptureDuration = time.Duration(1) * time.Minute
+	defaultInspectTop      = 10
 )
 
 // cliParams are the command-line arguments for this subcommand
@@ -45,6 +49,16 @@ type cliParams struct {
 	dsdCaptureCompressed bool
 }
 
+// inspectCliParams are the command-line arguments of the inspect subcommand
+type inspectCliParams struct {
+	*command.GlobalParams
+
+	dsdCaptureFilePath string
+	dsdInspectTop      int
+	dsdInspectMetrics  []string
+	dsdInspectTags     []string
+}
+
 // Commands returns a slice of subcommands for the 'agent' command.
 func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	cliParams := &cliParams{
@@ -68,6 +82,27 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	dogstatsdCaptureCmd.Flags().StringVarP(&cliParams.dsdCaptureFilePath, "path", "p", "", "Directory path to write the capture to.")
 	dogstatsdCaptureCmd.Flags().BoolVarP(&cliParams.dsdCaptureCompressed, "compressed", "z", true, "Should capture be zstd compressed.")
 
+	inspectCliParams := &inspectCliParams{
+		GlobalParams: globalParams,
+	}
+	inspectCmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Summarise a dogstatsd traffic capture without replaying it",
+		Long:  ``,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return fxutil.OneShot(dogstatsdCaptureInspect,
+				fx.Supply(inspectCliParams),
+				fx.Supply(command.GetDefaultCoreBundleParams(inspectCliParams.GlobalParams)),
+				core.Bundle(),
+			)
+		},
+	}
+	inspectCmd.Flags().StringVarP(&inspectCliParams.dsdCaptureFilePath, "file", "f", "", "Input file with traffic captured with dogstatsd-capture.")
+	inspectCmd.Flags().IntVarP(&inspectCliParams.dsdInspectTop, "top", "t", defaultInspectTop, "Number of metrics and origins to list.")
+	inspectCmd.Flags().StringSliceVar(&inspectCliParams.dsdInspectMetrics, "metric", nil, "Only account for the metrics with these names, a trailing '*' matching any suffix.")
+	inspectCmd.Flags().StringSliceVar(&inspectCliParams.dsdInspectTags, "tag", nil, "Only account for the metrics with one of these tags, a trailing '*' matching any suffix.")
+	dogstatsdCaptureCmd.AddCommand(inspectCmd)
+
 	// shut up grpc client!
 	grpclog.SetLoggerV2(grpclog.NewLoggerV2(io.Discard, io.Discard, io.Discard))
 
@@ -124,3 +159,51 @@ func dogstatsdCapture(_ log.Component, config config.Component, cliParams *cliPa
 
 	return nil
 }
+
+func dogstatsdCaptureInspect(_ log.Component, _ config.Component, cliParams *inspectCliParams) error {
+	reader, err := replay.NewTrafficCaptureReader(cliParams.dsdCaptureFilePath, 0, false)
+	if err != nil {
+		return fmt.Errorf("could not open %s: %w", cliParams.dsdCaptureFilePath, err)
+	}
+	defer reader.Close()
+
+	filter := replay.NewTrafficFilter(cliParams.dsdInspectMetrics, cliParams.dsdInspectTags)
+	summary, err := replay.InspectCapture(reader, filter)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", cliParams.dsdCaptureFilePath, err)
+	}
+
+	printSummary(os.Stdout, summary, cliParams.dsdInspectTop)
+	return nil
+}
+
+// printSummary prints the summary of a capture, listing at most top metrics and origins.
+func printSummary(w io.Writer, summary *replay.CaptureSummary, top int) {
+	fmt.Fprintf(w, "Packets:  %d\n", summary.Packets)
+	fmt.Fprintf(w, "Messages: %d\n", summary.Messages)
+	fmt.Fprintf(w, "Bytes:    %d\n", summary.Bytes)
+	fmt.Fprintf(w, "Duration: %s\n", summary.Duration)
+	fmt.Fprintf(w, "Contexts: %d\n", summary.Contexts)
+	sizes := summary.PacketSizes
+	fmt.Fprintf(w, "Packet sizes (bytes): min %d, median %d, p99 %d, max %d\n", sizes.Min, sizes.Median, sizes.P99, sizes.Max)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nTop metrics (%d in total):\n", len(summary.Metrics))
+	fmt.Fprintln(tw, "NAME\tMESSAGES\tCONTEXTS")
+	for i, m := range summary.Metrics {
+		if i == top {
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", m.Name, m.Messages, m.Contexts)
+	}
+
+	fmt.Fprintf(tw, "\nTop origins (%d in total):\n", len(summary.Origins))
+	fmt.Fprintln(tw, "ORIGIN\tPACKETS\tMESSAGES")
+	for i, o := range summary.Origins {
+		if i == top {
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", o.Origin, o.Packets, o.Messages)
+	}
+	tw.Flush()
+}
@@ -6,14 +6,17 @@
 package dogstatsdcapture
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
 	"github.com/DataDog/datadog-agent/comp/core"
 	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	replay "github.com/DataDog/datadog-agent/comp/dogstatsd/replay/impl"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
 
@@ -28,3 +31,50 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, false, secretParams.Enabled)
 		})
 }
+
+func TestInspectCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"dogstatsd-capture", "inspect", "-f", "capture.dog", "--top", "5", "--metric", "app.*"},
+		dogstatsdCaptureInspect,
+		func(cliParams *inspectCliParams, _ core.BundleParams, secretParams secrets.Params) {
+			require.Equal(t, "capture.dog", cliParams.dsdCaptureFilePath)
+			require.Equal(t, 5, cliParams.dsdInspectTop)
+			require.Equal(t, []string{"app.*"}, cliParams.dsdInspectMetrics)
+			require.Empty(t, cliParams.dsdInspectTags)
+			require.Equal(t, false, secretParams.Enabled)
+		})
+}
+
+func TestPrintSummary(t *testing.T) {
+	var b bytes.Buffer
+	printSummary(&b, &replay.CaptureSummary{
+		Packets:     3,
+		Messages:    4,
+		Bytes:       120,
+		Duration:    2 * time.Second,
+		Contexts:    3,
+		PacketSizes: replay.PacketSizes{Min: 30, Median: 40, P99: 50, Max: 50},
+		Metrics: []replay.MetricSummary{
+			{Name: "app.requests", Messages: 3, Contexts: 2},
+			{Name: "app.latency", Messages: 1, Contexts: 1},
+		},
+		Origins: []replay.OriginSummary{{Origin: "pid:42", Packets: 3, Messages: 4}},
+	}, 1)
+
+	assert.Equal(t, `Packets:  3
+Messages: 4
+Bytes:    120
+Duration: 2s
+Contexts: 3
+Packet sizes (bytes): min 30, median 40, p99 50, max 50
+
+Top metrics (2 in total):
+NAME          MESSAGES  CONTEXTS
+app.requests  3         2
+
+Top origins (1 in total):
+ORIGIN  PACKETS  MESSAGES
+pid:42  3        4
+`, b.String())
+}
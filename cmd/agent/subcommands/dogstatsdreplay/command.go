@@ -35,6 +35,7 @@ import (
 
 const (
 	defaultIterations = 1
+	defaultSpeed      = 1.0
 )
 
 // cliParams are the command-line arguments for this subcommand
@@ -47,6 +48,9 @@ type cliParams struct {
 	dsdVerboseReplay    bool
 	dsdMmapReplay       bool
 	dsdReplayIterations int
+	dsdReplaySpeed      float64
+	dsdReplayMetrics    []string
+	dsdReplayTags       []string
 }
 
 // Commands returns a slice of subcommands for the 'agent' command.
@@ -70,13 +74,21 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	dogstatsdReplayCmd.Flags().StringVarP(&cliParams.dsdReplayFilePath, "file", "f", "", "Input file with traffic captured with dogstatsd-capture.")
 	dogstatsdReplayCmd.Flags().BoolVarP(&cliParams.dsdVerboseReplay, "verbose", "v", false, "Verbose replay.")
 	dogstatsdReplayCmd.Flags().BoolVarP(&cliParams.dsdMmapReplay, "mmap", "m", true, "Mmap file for replay. Set to false to load the entire file into memory instead")
-	dogstatsdReplayCmd.Flags().IntVarP(&cliParams.dsdReplayIterations, "loops", "l", defaultIterations, "Number of iterations to replay.")
+	dogstatsdReplayCmd.Flags().IntVarP(&cliParams.dsdReplayIterations, "loops", "l", defaultIterations, "Number of iterations to replay. Set to 0 to loop until interrupted.")
+	dogstatsdReplayCmd.Flags().Float64Var(&cliParams.dsdReplaySpeed, "speed", defaultSpeed, "Speed multiplier of the replay, for instance 2 to replay twice as fast as recorded.")
+	dogstatsdReplayCmd.Flags().StringSliceVar(&cliParams.dsdReplayMetrics, "metric", nil, "Only replay the metrics with these names, a trailing '*' matching any suffix.")
+	dogstatsdReplayCmd.Flags().StringSliceVar(&cliParams.dsdReplayTags, "tag", nil, "Only replay the metrics with one of these tags, a trailing '*' matching any suffix.")
 
 	return []*cobra.Command{dogstatsdReplayCmd}
 }
 
 //nolint:revive // TODO(AML) Fix revive linter
 func dogstatsdReplay(_ log.Component, config config.Component, cliParams *cliParams) error {
+	if cliParams.dsdReplaySpeed <= 0 {
+		return fmt.Errorf("the replay speed must be positive, got %v", cliParams.dsdReplaySpeed)
+	}
+	filter := replay.NewTrafficFilter(cliParams.dsdReplayMetrics, cliParams.dsdReplayTags)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -132,6 +144,7 @@ func dogstatsdReplay(_ log.Component, config config.Component, cliParams *cliPar
 		fmt.Printf("could not open: %s\n", cliParams.dsdReplayFilePath)
 		return err
 	}
+	reader.SetSpeed(cliParams.dsdReplaySpeed)
 
 	s := pkgconfig.Datadog().GetString("dogstatsd_socket")
 	if s == "" {
@@ -191,8 +204,12 @@ func dogstatsdReplay(_ log.Component, config config.Component, cliParams *cliPar
 			case msg := <-reader.Traffic:
 				// The cadence is enforced by the reader. The reader will only write to
 				// the traffic channel when it estimates the payload should be submitted.
+				payload := filter.Filter(msg.Payload[:msg.PayloadSize])
+				if len(payload) == 0 {
+					continue
+				}
 				n, oobn, err := conn.(*net.UnixConn).WriteMsgUnix(
-					msg.Payload[:msg.PayloadSize], replay.GetUcredsForPid(msg.Pid), addr)
+					payload, replay.GetUcredsForPid(msg.Pid), addr)
 				if err != nil {
 					return err
 				}
@@ -19,10 +19,13 @@ import (
 func TestCommand(t *testing.T) {
 	fxutil.TestOneShotSubcommand(t,
 		Commands(&command.GlobalParams{}),
-		[]string{"dogstatsd-replay", "-v"},
+		[]string{"dogstatsd-replay", "-v", "--speed", "2.5", "--metric", "app.*,system.load", "--tag", "env:prod"},
 		dogstatsdReplay,
 		func(cliParams *cliParams, _ core.BundleParams, secretParams secrets.Params) {
 			require.True(t, cliParams.dsdVerboseReplay)
+			require.Equal(t, 2.5, cliParams.dsdReplaySpeed)
+			require.Equal(t, []string{"app.*", "system.load"}, cliParams.dsdReplayMetrics)
+			require.Equal(t, []string{"env:prod"}, cliParams.dsdReplayTags)
 			require.Equal(t, false, secretParams.Enabled)
 		})
 }
@@ -0,0 +1,549 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package forwarder implements 'agent forwarder'.
+package forwarder
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	"github.com/DataDog/datadog-agent/comp/serializer/compression"
+	"github.com/DataDog/datadog-agent/comp/serializer/compression/compressionimpl/strategy"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// agentCheckTimeout is the time given to the running Agent to answer on its IPC endpoint.
+const agentCheckTimeout = 2 * time.Second
+
+// cliParams are the command-line arguments for the subcommands
+type cliParams struct {
+	*command.GlobalParams
+
+	// args are the files to select, by path or name
+	args []string
+
+	all       bool
+	olderThan time.Duration
+	endpoints []string
+	raw       bool
+	since     time.Duration
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+	oneShot := func(fct interface{}) func(*cobra.Command, []string) error {
+		return func(_ *cobra.Command, args []string) error {
+			cliParams.args = args
+			return fxutil.OneShot(fct,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+			)
+		}
+	}
+
+	forwarderCmd := &cobra.Command{
+		Use:   "forwarder",
+		Short: "Inspect and manage the transactions stored on disk by the forwarder",
+		Long: `Inspect and manage the transactions stored on disk by the forwarder when 'forwarder_storage_max_size_in_bytes' is set.
+
+The Agent must be stopped before replaying or purging files, as it doesn't expect the files of its retry queue to be modified by another process.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list [file...]",
+		Short: "List the stored transactions by file and endpoint",
+		RunE:  oneShot(listStoredTransactions),
+	}
+	addSelectionFlags(listCmd, cliParams)
+
+	dumpCmd := &cobra.Command{
+		Use:   "dump <file>",
+		Short: "Print the decoded transactions of a stored file",
+		Args:  cobra.ExactArgs(1),
+		RunE:  oneShot(dumpStoredFile),
+	}
+	dumpCmd.Flags().StringSliceVar(&cliParams.endpoints, "endpoint", nil, "Only print the transactions of these endpoints.")
+	dumpCmd.Flags().BoolVar(&cliParams.raw, "raw", false, "Print the payloads without decompressing them.")
+
+	replayCmd := &cobra.Command{
+		Use:   "replay [file...]",
+		Short: "Send the transactions of the selected files to the intake",
+		Long:  `Send the transactions of the selected files to the intake. The transactions sent or rejected by the intake are removed from the files, the other ones are kept.`,
+		RunE:  oneShot(replayStoredFiles),
+	}
+	addSelectionFlags(replayCmd, cliParams)
+
+	purgeCmd := &cobra.Command{
+		Use:   "purge [file...]",
+		Short: "Remove the selected files without sending their transactions",
+		RunE:  oneShot(purgeStoredFiles),
+	}
+	addSelectionFlags(purgeCmd, cliParams)
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show which stored transactions were retried, replayed or dropped",
+		Args:  cobra.NoArgs,
+		RunE:  oneShot(showAuditLog),
+	}
+	auditCmd.Flags().DurationVar(&cliParams.since, "since", 0, "Only show the events of this last duration, for instance 24h.")
+
+	forwarderCmd.AddCommand(listCmd, dumpCmd, replayCmd, purgeCmd, auditCmd)
+
+	return []*cobra.Command{forwarderCmd}
+}
+
+func addSelectionFlags(cmd *cobra.Command, cliParams *cliParams) {
+	cmd.Flags().BoolVar(&cliParams.all, "all", false, "Select all the stored files.")
+	cmd.Flags().DurationVar(&cliParams.olderThan, "older-than", 0, "Select the files older than this duration, for instance 2h.")
+	cmd.Flags().StringSliceVar(&cliParams.endpoints, "endpoint", nil, "Select the files holding transactions of these endpoints.")
+}
+
+func listStoredTransactions(log log.Component, config config.Component, cliParams *cliParams) error {
+	storage := defaultforwarder.NewRetryStorage(config, log)
+	files, err := listFiles(storage)
+	if err != nil {
+		return err
+	}
+	// listing doesn't require an explicit selection
+	if len(cliParams.args) == 0 && cliParams.olderThan == 0 && len(cliParams.endpoints) == 0 {
+		cliParams.all = true
+	}
+	files, err = selectFiles(storage, files, cliParams, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var rows []listRow
+	for _, file := range files {
+		transactions, err := storage.ReadTransactions(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot read %s: %v\n", file.Path, err)
+			continue
+		}
+		rows = append(rows, groupByEndpoint(file, storage.Domain(file), transactions, cliParams.endpoints)...)
+	}
+	printList(os.Stdout, storage.RootPath(), rows, time.Now())
+	return nil
+}
+
+func dumpStoredFile(log log.Component, config config.Component, cliParams *cliParams) error {
+	storage := defaultforwarder.NewRetryStorage(config, log)
+	files, err := listFiles(storage)
+	if err != nil {
+		return err
+	}
+	cliParams.all, cliParams.olderThan = false, 0
+	// the endpoints filter the transactions, not the file
+	endpoints := cliParams.endpoints
+	cliParams.endpoints = nil
+	files, err = selectFiles(storage, files, cliParams, time.Now())
+	if err != nil {
+		return err
+	}
+
+	transactions, err := storage.ReadTransactions(files[0])
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", files[0].Path, err)
+	}
+	for i, tr := range transactions {
+		if len(endpoints) > 0 && !contains(endpoints, tr.Endpoint) {
+			continue
+		}
+		printTransaction(os.Stdout, i, tr, cliParams.raw)
+	}
+	return nil
+}
+
+func replayStoredFiles(log log.Component, config config.Component, cliParams *cliParams) error {
+	if err := checkAgentStopped(config); err != nil {
+		return err
+	}
+	storage := defaultforwarder.NewRetryStorage(config, log)
+	files, err := listFiles(storage)
+	if err != nil {
+		return err
+	}
+	files, err = selectFiles(storage, files, cliParams, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var total defaultforwarder.ReplayResult
+	var errs []error
+	for _, file := range files {
+		result, err := storage.Replay(context.Background(), file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot replay %s: %w", file.Path, err))
+			continue
+		}
+		fmt.Printf("%s: %d sent, %d rejected, %d kept\n", filepath.Base(file.Path), result.Sent, result.Rejected, result.Kept)
+		total.Sent += result.Sent
+		total.Rejected += result.Rejected
+		total.Kept += result.Kept
+	}
+	fmt.Printf("Replayed %d files: %d transactions sent, %d rejected, %d kept\n", len(files)-len(errs), total.Sent, total.Rejected, total.Kept)
+	return errors.Join(errs...)
+}
+
+func purgeStoredFiles(log log.Component, config config.Component, cliParams *cliParams) error {
+	if err := checkAgentStopped(config); err != nil {
+		return err
+	}
+	storage := defaultforwarder.NewRetryStorage(config, log)
+	files, err := listFiles(storage)
+	if err != nil {
+		return err
+	}
+	files, err = selectFiles(storage, files, cliParams, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, file := range files {
+		if err := storage.Purge(file); err != nil {
+			errs = append(errs, fmt.Errorf("cannot purge %s: %w", file.Path, err))
+		}
+	}
+	fmt.Printf("Purged %d files\n", len(files)-len(errs))
+	return errors.Join(errs...)
+}
+
+func showAuditLog(log log.Component, config config.Component, cliParams *cliParams) error {
+	storage := defaultforwarder.NewRetryStorage(config, log)
+	events, err := storage.ReadAuditLog()
+	if err != nil {
+		return err
+	}
+	if cliParams.since > 0 {
+		events = eventsSince(events, time.Now().Add(-cliParams.since))
+	}
+	printAuditLog(os.Stdout, events)
+	return nil
+}
+
+// checkAgentStopped returns an error if the Agent answers on its IPC endpoint, as the files of its
+// retry queue must not be modified while it runs.
+func checkAgentStopped(config config.Component) error {
+	ipcAddress, err := pkgconfig.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	if agentAnswers(fmt.Sprintf("https://%v:%v/agent/version", ipcAddress, config.GetInt("cmd_port"))) {
+		return errors.New("the Agent is running, stop it before replaying or purging the stored transactions")
+	}
+	return nil
+}
+
+// agentAnswers returns true if an HTTP server answers at url, whatever its response.
+func agentAnswers(url string) bool {
+	c := util.GetClient(false) // the request is only sent to check whether the Agent is listening
+	c.Timeout = agentCheckTimeout
+	resp, err := c.Get(url)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
+
+// listFiles returns the stored files, or an explicit error when there isn't any retry queue.
+func listFiles(storage *defaultforwarder.RetryStorage) ([]defaultforwarder.StoredFile, error) {
+	files, err := storage.ListFiles()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no transaction stored in %s, check that 'forwarder_storage_max_size_in_bytes' is set", storage.RootPath())
+	}
+	return files, err
+}
+
+// selectFiles returns the files selected by the command-line arguments.
+func selectFiles(storage *defaultforwarder.RetryStorage, files []defaultforwarder.StoredFile, cliParams *cliParams, now time.Time) ([]defaultforwarder.StoredFile, error) {
+	if len(cliParams.args) == 0 && !cliParams.all && cliParams.olderThan == 0 && len(cliParams.endpoints) == 0 {
+		return nil, errors.New("no file selected, use file arguments, --all, --older-than or --endpoint")
+	}
+
+	var selected []defaultforwarder.StoredFile
+	for _, file := range files {
+		if len(cliParams.args) > 0 && !matchFile(file, cliParams.args) {
+			continue
+		}
+		if cliParams.olderThan > 0 && now.Sub(file.ModTime) < cliParams.olderThan {
+			continue
+		}
+		if len(cliParams.endpoints) > 0 {
+			transactions, err := storage.ReadTransactions(file)
+			if err != nil || !hasEndpoint(transactions, cliParams.endpoints) {
+				continue
+			}
+		}
+		selected = append(selected, file)
+	}
+
+	for _, arg := range cliParams.args {
+		if !matchAnyFile(arg, files) {
+			return nil, fmt.Errorf("%s is not a file of the retry queue in %s", arg, storage.RootPath())
+		}
+	}
+	return selected, nil
+}
+
+func matchFile(file defaultforwarder.StoredFile, args []string) bool {
+	for _, arg := range args {
+		if matchAnyFile(arg, []defaultforwarder.StoredFile{file}) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyFile(arg string, files []defaultforwarder.StoredFile) bool {
+	for _, file := range files {
+		if arg == file.Path || arg == filepath.Base(file.Path) {
+			return true
+		}
+		if abs, err := filepath.Abs(arg); err == nil && abs == file.Path {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEndpoint(transactions []defaultforwarder.StoredTransaction, endpoints []string) bool {
+	for _, tr := range transactions {
+		if contains(endpoints, tr.Endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// listRow summarises the transactions of an endpoint in a stored file.
+type listRow struct {
+	file         string
+	domain       string
+	endpoint     string
+	priority     transaction.Priority
+	transactions int
+	points       int
+	bytes        int
+	oldest       time.Time
+}
+
+// groupByEndpoint summarises the transactions of a file by endpoint and priority, only keeping
+// the given endpoints if any.
+func groupByEndpoint(file defaultforwarder.StoredFile, domain string, transactions []defaultforwarder.StoredTransaction, endpoints []string) []listRow {
+	type key struct {
+		endpoint string
+		priority transaction.Priority
+	}
+	rows := make(map[key]*listRow)
+	for _, tr := range transactions {
+		if len(endpoints) > 0 && !contains(endpoints, tr.Endpoint) {
+			continue
+		}
+		k := key{tr.Endpoint, tr.Priority}
+		row, ok := rows[k]
+		if !ok {
+			row = &listRow{file: filepath.Base(file.Path), domain: domain, endpoint: tr.Endpoint, priority: tr.Priority, oldest: tr.CreatedAt}
+			rows[k] = row
+		}
+		row.transactions++
+		row.points += tr.PointCount
+		row.bytes += len(tr.Payload)
+		if tr.CreatedAt.Before(row.oldest) {
+			row.oldest = tr.CreatedAt
+		}
+	}
+
+	result := make([]listRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].endpoint != result[j].endpoint {
+			return result[i].endpoint < result[j].endpoint
+		}
+		return result[i].priority > result[j].priority
+	})
+	return result
+}
+
+func printList(w io.Writer, rootPath string, rows []listRow, now time.Time) {
+	fmt.Fprintf(w, "Retry queue: %s\n\n", rootPath)
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No stored transaction")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tDOMAIN\tENDPOINT\tPRIORITY\tTRANSACTIONS\tPOINTS\tBYTES\tAGE")
+	transactions, points := 0, 0
+	for _, row := range rows {
+		domain := row.domain
+		if domain == "" {
+			domain = "(unknown)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n", row.file, domain, row.endpoint, priorityName(row.priority),
+			row.transactions, row.points, row.bytes, now.Sub(row.oldest).Truncate(time.Second))
+		transactions += row.transactions
+		points += row.points
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nTotal: %d transactions, %d points\n", transactions, points)
+}
+
+func priorityName(priority transaction.Priority) string {
+	if priority == transaction.TransactionPriorityHigh {
+		return "high"
+	}
+	return "normal"
+}
+
+// printTransaction prints a stored transaction and its payload, decompressed according to its
+// Content-Encoding header unless raw is set.
+func printTransaction(w io.Writer, index int, tr defaultforwarder.StoredTransaction, raw bool) {
+	fmt.Fprintf(w, "--- Transaction %d\n", index)
+	fmt.Fprintf(w, "Endpoint:   %s\n", tr.Endpoint)
+	fmt.Fprintf(w, "Route:      %s\n", tr.Route)
+	fmt.Fprintf(w, "Created:    %s\n", tr.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "Priority:   %s\n", priorityName(tr.Priority))
+	fmt.Fprintf(w, "Errors:     %d\n", tr.ErrorCount)
+	fmt.Fprintf(w, "Points:     %d\n", tr.PointCount)
+	fmt.Fprintf(w, "Retryable:  %t\n", tr.Retryable)
+
+	keys := make([]string, 0, len(tr.Headers))
+	for key := range tr.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Fprintln(w, "Headers:")
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", key, tr.Headers.Get(key))
+	}
+
+	payload := tr.Payload
+	if !raw {
+		decoded, err := decompress(payload, tr.Headers.Get("Content-Encoding"))
+		if err != nil {
+			fmt.Fprintf(w, "Cannot decompress the payload: %v\n", err)
+		} else {
+			payload = decoded
+		}
+	}
+	fmt.Fprintf(w, "Payload (%d bytes):\n", len(payload))
+	if utf8.Valid(payload) {
+		fmt.Fprintf(w, "%s\n", payload)
+	} else {
+		fmt.Fprint(w, hex.Dump(payload))
+	}
+}
+
+func decompress(payload []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case compression.ZlibEncoding:
+		return strategy.NewZlibStrategy().Decompress(payload)
+	case compression.ZstdEncoding:
+		return strategy.NewZstdStrategy(0).Decompress(payload)
+	default:
+		return payload, nil
+	}
+}
+
+func eventsSince(events []defaultforwarder.AuditEvent, since time.Time) []defaultforwarder.AuditEvent {
+	var result []defaultforwarder.AuditEvent
+	for _, event := range events {
+		if !event.Time.Before(since) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// printAuditLog prints the events of the audit log, followed by the number of transactions and
+// points of each kind of event.
+func printAuditLog(w io.Writer, events []defaultforwarder.AuditEvent) {
+	if len(events) == 0 {
+		fmt.Fprintln(w, "No event recorded")
+		return
+	}
+
+	type total struct{ files, transactions, points int }
+	totals := make(map[string]*total)
+	var names []string
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tEVENT\tREASON\tDOMAIN\tFILE\tTRANSACTIONS\tPOINTS\tBYTES")
+	for _, event := range events {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\n", event.Time.UTC().Format(time.RFC3339), event.Event, event.Reason,
+			event.Domain, event.File, event.Transactions, event.Points, event.Bytes)
+
+		name := event.Event
+		if event.Reason != "" {
+			name += " (" + event.Reason + ")"
+		}
+		t, ok := totals[name]
+		if !ok {
+			t = &total{}
+			totals[name] = t
+			names = append(names, name)
+		}
+		if event.File != "" {
+			// the transactions dropped from memory don't belong to a file
+			t.files++
+		}
+		t.transactions += event.Transactions
+		t.points += event.Points
+	}
+	tw.Flush()
+
+	sort.Strings(names)
+	fmt.Fprintln(w, "\nSummary:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "EVENT\tFILES\tTRANSACTIONS\tPOINTS")
+	for _, name := range names {
+		t := totals[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", name, t.files, t.transactions, t.points)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/comp/serializer/compression"
+	"github.com/DataDog/datadog-agent/comp/serializer/compression/compressionimpl/strategy"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestListCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"forwarder", "list", "--older-than", "1h", "--endpoint", "series_v2"},
+		listStoredTransactions,
+		func(cliParams *cliParams, _ core.BundleParams, secretParams secrets.Params) {
+			require.Equal(t, time.Hour, cliParams.olderThan)
+			require.Equal(t, []string{"series_v2"}, cliParams.endpoints)
+			require.Empty(t, cliParams.args)
+			require.True(t, secretParams.Enabled)
+		})
+}
+
+func TestReplayCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"forwarder", "replay", "1.retry", "2.retry"},
+		replayStoredFiles,
+		func(cliParams *cliParams, _ core.BundleParams, _ secrets.Params) {
+			require.Equal(t, []string{"1.retry", "2.retry"}, cliParams.args)
+			require.False(t, cliParams.all)
+		})
+}
+
+func TestPurgeCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"forwarder", "purge", "--all"},
+		purgeStoredFiles,
+		func(cliParams *cliParams, _ core.BundleParams, _ secrets.Params) {
+			require.True(t, cliParams.all)
+		})
+}
+
+func TestDumpCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"forwarder", "dump", "1.retry", "--raw"},
+		dumpStoredFile,
+		func(cliParams *cliParams, _ core.BundleParams, _ secrets.Params) {
+			require.Equal(t, []string{"1.retry"}, cliParams.args)
+			require.True(t, cliParams.raw)
+		})
+}
+
+func TestAuditCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"forwarder", "audit", "--since", "24h"},
+		showAuditLog,
+		func(cliParams *cliParams, _ core.BundleParams, _ secrets.Params) {
+			require.Equal(t, 24*time.Hour, cliParams.since)
+		})
+}
+
+func TestSelectFiles(t *testing.T) {
+	now := time.Now()
+	files := []defaultforwarder.StoredFile{
+		{Path: "/queue/a/1.retry", ModTime: now.Add(-3 * time.Hour)},
+		{Path: "/queue/a/2.retry", ModTime: now.Add(-2 * time.Hour)},
+		{Path: "/queue/b/3.retry", ModTime: now.Add(-time.Minute)},
+	}
+	names := func(files []defaultforwarder.StoredFile) []string {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Path)
+		}
+		return names
+	}
+
+	_, err := selectFiles(nil, files, &cliParams{}, now)
+	assert.Error(t, err)
+
+	selected, err := selectFiles(nil, files, &cliParams{all: true}, now)
+	require.NoError(t, err)
+	assert.Len(t, selected, 3)
+
+	selected, err = selectFiles(nil, files, &cliParams{olderThan: time.Hour}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/queue/a/1.retry", "/queue/a/2.retry"}, names(selected))
+
+	selected, err = selectFiles(nil, files, &cliParams{args: []string{"3.retry", "/queue/a/1.retry"}}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/queue/a/1.retry", "/queue/b/3.retry"}, names(selected))
+
+	// the arguments and the other selectors are combined
+	selected, err = selectFiles(nil, files, &cliParams{args: []string{"2.retry", "3.retry"}, olderThan: time.Hour}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/queue/a/2.retry"}, names(selected))
+}
+
+func TestGroupByEndpoint(t *testing.T) {
+	created := time.Unix(1000, 0)
+	transactions := []defaultforwarder.StoredTransaction{
+		{Endpoint: "series_v2", PointCount: 2, Payload: []byte("ab"), CreatedAt: created.Add(time.Second)},
+		{Endpoint: "sketches_v2", PointCount: 1, Payload: []byte("a"), CreatedAt: created},
+		{Endpoint: "series_v2", PointCount: 3, Payload: []byte("abc"), CreatedAt: created},
+	}
+	rows := groupByEndpoint(defaultforwarder.StoredFile{Path: "/queue/a/1.retry"}, "https://app.datadoghq.com", transactions, nil)
+	require.Len(t, rows, 2)
+	assert.Equal(t, listRow{file: "1.retry", domain: "https://app.datadoghq.com", endpoint: "series_v2", transactions: 2, points: 5, bytes: 5, oldest: created}, rows[0])
+	assert.Equal(t, "sketches_v2", rows[1].endpoint)
+
+	rows = groupByEndpoint(defaultforwarder.StoredFile{Path: "/queue/a/1.retry"}, "", transactions, []string{"sketches_v2"})
+	require.Len(t, rows, 1)
+	assert.Equal(t, "sketches_v2", rows[0].endpoint)
+}
+
+func TestDecompress(t *testing.T) {
+	payload := []byte(`{"series":[]}`)
+	for _, s := range []compression.Component{strategy.NewZlibStrategy(), strategy.NewZstdStrategy(1)} {
+		compressed, err := s.Compress(payload)
+		require.NoError(t, err)
+		decompressed, err := decompress(compressed, s.ContentEncoding())
+		require.NoError(t, err)
+		assert.Equal(t, payload, decompressed)
+	}
+	decompressed, err := decompress(payload, "")
+	require.NoError(t, err)
+	assert.Equal(t, payload, decompressed)
+}
+
+func TestPrintAuditLog(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var b bytes.Buffer
+	printAuditLog(&b, []defaultforwarder.AuditEvent{
+		{Time: ts, Event: defaultforwarder.AuditEventStored, Domain: "d", File: "1.retry", Transactions: 2, Points: 4, Bytes: 10},
+		{Time: ts, Event: defaultforwarder.AuditEventDropped, Reason: "disk_full", Domain: "d", File: "1.retry", Transactions: 2, Points: 4, Bytes: 10},
+		{Time: ts, Event: defaultforwarder.AuditEventDropped, Reason: "memory_full", Domain: "d", Transactions: 1, Points: 3, Bytes: 5},
+	})
+	assert.Equal(t, `TIME                  EVENT    REASON       DOMAIN  FILE     TRANSACTIONS  POINTS  BYTES
+2024-01-02T03:04:05Z  stored                d       1.retry  2             4       10
+2024-01-02T03:04:05Z  dropped  disk_full    d       1.retry  2             4       10
+2024-01-02T03:04:05Z  dropped  memory_full  d                1             3       5
+
+Summary:
+EVENT                  FILES  TRANSACTIONS  POINTS
+dropped (disk_full)    1      2             4
+dropped (memory_full)  0      1             3
+stored                 1      2             4
+`, b.String())
+
+	b.Reset()
+	printAuditLog(&b, nil)
+	assert.Equal(t, "No event recorded\n", b.String())
+}
+
+func TestAgentAnswers(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	url := server.URL
+	// the Agent is running even if the request is not authorized
+	assert.True(t, agentAnswers(url))
+
+	server.Close()
+	assert.False(t, agentAnswers(url))
+}
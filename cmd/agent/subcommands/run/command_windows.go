@@ -33,6 +33,7 @@ import (
 
 	// checks implemented as components
 	"github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer"
+	remotewrite "github.com/DataDog/datadog-agent/comp/aggregator/remotewrite/def"
 	"github.com/DataDog/datadog-agent/comp/checks/agentcrashdetect"
 	"github.com/DataDog/datadog-agent/comp/checks/agentcrashdetect/agentcrashdetectimpl"
 	"github.com/DataDog/datadog-agent/comp/checks/windowseventlog"
@@ -131,6 +132,7 @@ func StartAgentWithDefaults(ctxChan <-chan context.Context) (<-chan error, error
 			settings settings.Component,
 			_ optional.Option[gui.Component],
 			_ agenttelemetry.Component,
+			_ remotewrite.Component,
 		) error {
 			defer StopAgentWithDefaults()
 
@@ -18,6 +18,7 @@ import (
 	cmddogstatsdreplay "github.com/DataDog/datadog-agent/cmd/agent/subcommands/dogstatsdreplay"
 	cmddogstatsdstats "github.com/DataDog/datadog-agent/cmd/agent/subcommands/dogstatsdstats"
 	cmdflare "github.com/DataDog/datadog-agent/cmd/agent/subcommands/flare"
+	cmdforwarder "github.com/DataDog/datadog-agent/cmd/agent/subcommands/forwarder"
 	cmdhealth "github.com/DataDog/datadog-agent/cmd/agent/subcommands/health"
 	cmdhostname "github.com/DataDog/datadog-agent/cmd/agent/subcommands/hostname"
 	cmdimport "github.com/DataDog/datadog-agent/cmd/agent/subcommands/import"
@@ -52,6 +53,7 @@ func AgentSubcommands() []command.SubcommandFactory {
 		cmddogstatsdreplay.Commands,
 		cmddogstatsdstats.Commands,
 		cmdflare.Commands,
+		cmdforwarder.Commands,
 		cmdhealth.Commands,
 		cmdhostname.Commands,
 		cmdimport.Commands,
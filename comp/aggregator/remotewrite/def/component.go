@@ -0,0 +1,13 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package remotewrite provides the component receiving the metrics pushed with the Prometheus remote-write protocol.
+package remotewrite
+
+// team: agent-metrics-logs
+
+// Component is the component type.
+type Component interface {
+}
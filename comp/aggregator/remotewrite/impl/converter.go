@@ -0,0 +1,440 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package remotewriteimpl
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+)
+
+const (
+	nameLabel     = "__name__"
+	upperBoundLbl = "le"
+
+	bucketSuffix = "_bucket"
+	sumSuffix    = "_sum"
+	countSuffix  = "_count"
+	totalSuffix  = "_total"
+
+	// stateExpiration is the duration after which the state of a metric family, counter
+	// or histogram which hasn't been received anymore is dropped
+	stateExpiration = 10 * time.Minute
+)
+
+// familyState is the metric type of a metric family, from the metadata of the requests.
+type familyState struct {
+	metricType uint64
+	lastSeen   time.Time
+}
+
+// counterState is the last sample of a counter, from which the increase of the next one is computed.
+type counterState struct {
+	value     float64
+	timestamp int64
+	lastSeen  time.Time
+}
+
+// bucketBounds are the bounds of a bucket of a histogram, the lower bound is excluded.
+type bucketBounds struct {
+	lower float64
+	upper float64
+}
+
+// histogramState is the state of a classic or native histogram, from which the counts of
+// observations of each bucket since the previous commit are computed.
+type histogramState struct {
+	name  string
+	tags  []string
+	gauge bool
+
+	// cumulative holds the cumulative counts of the buckets of a classic histogram by upper bound
+	cumulative map[float64]float64
+	// received holds the upper bounds of the buckets of a classic histogram received since the previous commit
+	received map[float64]struct{}
+	// deferred is set when the buckets of a classic histogram were not all received before a commit
+	deferred bool
+	// buckets holds the counts of the buckets of a native histogram
+	buckets map[bucketBounds]float64
+
+	// committed holds the counts of the buckets at the previous commit, nil before the first one
+	committed map[bucketBounds]float64
+	updated   bool
+	lastSeen  time.Time
+}
+
+// converter maps the series received with the remote-write protocol onto metric samples and
+// histogram buckets sent to the aggregator.
+//
+// The gauges and the increases of the counters are sent with the timestamps of their samples
+// as soon as they are received. The buckets of a classic histogram may be received in different
+// requests, so the histograms are only sent when the sender is committed.
+type converter struct {
+	sender sender.Sender
+	tags   []string
+
+	m sync.Mutex
+	// types are the metric types of the metric families, from the metadata of the requests
+	types      map[string]*familyState
+	counters   map[string]*counterState
+	histograms map[string]*histogramState
+}
+
+func newConverter(s sender.Sender, tags []string) *converter {
+	return &converter{
+		sender:     s,
+		tags:       tags,
+		types:      make(map[string]*familyState),
+		counters:   make(map[string]*counterState),
+		histograms: make(map[string]*histogramState),
+	}
+}
+
+// submit converts the series of a request, originTags are added to all of them.
+func (c *converter) submit(req writeRequest, originTags []string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	now := time.Now()
+	for _, md := range req.metadata {
+		c.types[md.familyName] = &familyState{metricType: md.metricType, lastSeen: now}
+	}
+
+	for _, ts := range req.timeseries {
+		name, upperBound, tags := c.seriesTags(ts.labels, originTags)
+		if name == "" {
+			continue
+		}
+		for _, h := range ts.histograms {
+			c.submitNativeHistogram(name, tags, h, now)
+		}
+		for _, s := range ts.samples {
+			// NaN values are used as staleness markers
+			if math.IsNaN(s.value) {
+				continue
+			}
+			c.submitSample(name, upperBound, tags, s.value, s.timestamp, now)
+		}
+	}
+}
+
+// seriesTags returns the name of a series, its upper bound label and its tags.
+func (c *converter) seriesTags(labels []label, originTags []string) (string, string, []string) {
+	var name, upperBound string
+	tags := make([]string, 0, len(labels)+len(c.tags)+len(originTags))
+	for _, l := range labels {
+		if l.name == nameLabel {
+			name = l.value
+			continue
+		}
+		if l.name == upperBoundLbl {
+			upperBound = l.value
+		}
+		tags = append(tags, l.name+":"+l.value)
+	}
+	tags = append(tags, c.tags...)
+	tags = append(tags, originTags...)
+	return name, upperBound, tags
+}
+
+// metricType returns the type of the metric family of a series and the name of the family.
+// The family is marked as seen at the given time.
+func (c *converter) metricType(name string, now time.Time) (uint64, string) {
+	if f, ok := c.types[name]; ok {
+		f.lastSeen = now
+		return f.metricType, name
+	}
+	for _, suffix := range []string{bucketSuffix, sumSuffix, countSuffix, totalSuffix} {
+		family := strings.TrimSuffix(name, suffix)
+		if family == name {
+			continue
+		}
+		if f, ok := c.types[family]; ok {
+			f.lastSeen = now
+			return f.metricType, family
+		}
+	}
+	return metadataTypeUnknown, name
+}
+
+// submitSample sends a sample, timestamp is in milliseconds since the epoch.
+func (c *converter) submitSample(name string, upperBound string, tags []string, value float64, timestamp int64, now time.Time) {
+	metricType, family := c.metricType(name, now)
+
+	switch {
+	case upperBound != "" && strings.HasSuffix(name, bucketSuffix) &&
+		(metricType == metadataTypeHistogram || metricType == metadataTypeGaugeHistogram || metricType == metadataTypeUnknown):
+		c.addClassicBucket(strings.TrimSuffix(name, bucketSuffix), upperBound, tags, value, metricType == metadataTypeGaugeHistogram, now)
+	case metricType == metadataTypeCounter,
+		metricType == metadataTypeUnknown && strings.HasSuffix(name, totalSuffix),
+		(metricType == metadataTypeHistogram || metricType == metadataTypeSummary) && name != family:
+		// the _sum and _count series of the histograms and the summaries are counters too
+		c.submitCounter(name, tags, value, timestamp, now)
+	default:
+		c.submitGauge(name, tags, value, timestamp, now)
+	}
+}
+
+func (c *converter) submitGauge(name string, tags []string, value float64, timestamp int64, now time.Time) {
+	_ = c.sender.GaugeWithTimestamp(name, value, "", tags, sampleTime(timestamp, now))
+}
+
+// submitCounter sends the increase of a counter since its previous sample. Nothing is sent
+// for the first sample of a counter, when it has been reset or when the samples are out of order.
+func (c *converter) submitCounter(name string, tags []string, value float64, timestamp int64, now time.Time) {
+	if timestamp <= 0 {
+		timestamp = now.UnixMilli()
+	}
+	key := name + "|" + strings.Join(tags, ",")
+	cs, ok := c.counters[key]
+	if !ok {
+		c.counters[key] = &counterState{value: value, timestamp: timestamp, lastSeen: now}
+		return
+	}
+	if timestamp <= cs.timestamp {
+		return
+	}
+	previous := cs.value
+	cs.value, cs.timestamp, cs.lastSeen = value, timestamp, now
+	if value < previous {
+		return
+	}
+	_ = c.sender.CountWithTimestamp(name, value-previous, "", tags, sampleTime(timestamp, now))
+}
+
+// sampleTime returns the time of a sample in seconds, from its timestamp in milliseconds, or
+// the given time if the sample has no timestamp.
+func sampleTime(timestamp int64, now time.Time) float64 {
+	if timestamp <= 0 {
+		return float64(now.UnixNano()) / float64(time.Second)
+	}
+	return float64(timestamp) / 1000
+}
+
+// addClassicBucket records the cumulative count of a bucket of a classic histogram.
+func (c *converter) addClassicBucket(name string, upperBound string, tags []string, value float64, gauge bool, now time.Time) {
+	le, err := strconv.ParseFloat(upperBound, 64)
+	if err != nil {
+		return
+	}
+	tags = removeTag(tags, upperBoundLbl+":"+upperBound)
+
+	h := c.histogram(name, tags, gauge, now)
+	if h.cumulative == nil {
+		h.cumulative = make(map[float64]float64)
+		h.received = make(map[float64]struct{})
+	}
+	h.cumulative[le] = value
+	h.received[le] = struct{}{}
+}
+
+func (c *converter) submitNativeHistogram(name string, tags []string, nh histogram, now time.Time) {
+	gauge := nh.resetHint == resetHintGauge
+	if gauge {
+		c.submitGauge(name+sumSuffix, tags, nh.sum, nh.timestamp, now)
+		c.submitGauge(name+countSuffix, tags, nh.count, nh.timestamp, now)
+	} else {
+		c.submitCounter(name+sumSuffix, tags, nh.sum, nh.timestamp, now)
+		c.submitCounter(name+countSuffix, tags, nh.count, nh.timestamp, now)
+	}
+
+	h := c.histogram(name, tags, gauge, now)
+	h.buckets = nativeBuckets(nh)
+}
+
+// histogram returns the state of a histogram, updated at the given time.
+func (c *converter) histogram(name string, tags []string, gauge bool, now time.Time) *histogramState {
+	key := name + "|" + strings.Join(tags, ",")
+	h, ok := c.histograms[key]
+	if !ok {
+		h = &histogramState{name: name, tags: tags}
+		c.histograms[key] = h
+	}
+	h.gauge = gauge
+	h.updated = true
+	h.lastSeen = now
+	return h
+}
+
+// commit sends the buckets of the histograms updated since the previous commit and commits the sender.
+// The state of the metric families, counters and histograms which aren't received anymore is dropped.
+func (c *converter) commit(now time.Time) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for name, f := range c.types {
+		if now.Sub(f.lastSeen) > stateExpiration {
+			delete(c.types, name)
+		}
+	}
+	for key, cs := range c.counters {
+		if now.Sub(cs.lastSeen) > stateExpiration {
+			delete(c.counters, key)
+		}
+	}
+	for key, h := range c.histograms {
+		if now.Sub(h.lastSeen) > stateExpiration {
+			delete(c.histograms, key)
+			continue
+		}
+		if !h.updated {
+			continue
+		}
+
+		buckets := h.buckets
+		if h.cumulative != nil {
+			if !h.classicComplete() {
+				continue
+			}
+			buckets = classicBuckets(h.cumulative)
+		}
+		h.updated = false
+		c.submitBuckets(h, buckets)
+	}
+
+	c.sender.Commit()
+}
+
+// classicComplete returns whether all the buckets of a classic histogram have been received
+// since the previous commit. The histogram is held back for one commit when some are missing,
+// the buckets which are still missing after that are considered gone.
+func (h *histogramState) classicComplete() bool {
+	if len(h.received) < len(h.cumulative) {
+		if !h.deferred {
+			h.deferred = true
+			return false
+		}
+		for le := range h.cumulative {
+			if _, ok := h.received[le]; !ok {
+				delete(h.cumulative, le)
+			}
+		}
+	}
+	h.deferred = false
+	h.received = make(map[float64]struct{}, len(h.cumulative))
+	return true
+}
+
+// submitBuckets sends the counts of observations of the buckets since the previous commit.
+// Nothing is sent on the first commit of a histogram or when its counts have been reset.
+// The buckets of a native histogram which weren't present at the previous commit were empty.
+func (c *converter) submitBuckets(h *histogramState, buckets map[bucketBounds]float64) {
+	if h.gauge {
+		for bounds, count := range buckets {
+			c.submitBucket(h, bounds, count)
+		}
+		return
+	}
+
+	committed := h.committed
+	h.committed = buckets
+	if committed == nil {
+		return
+	}
+	for bounds, count := range buckets {
+		previous, ok := committed[bounds]
+		if count < previous {
+			// the histogram has been reset
+			return
+		}
+		if !ok && h.cumulative != nil {
+			// the bounds of the buckets of a classic histogram have changed
+			return
+		}
+	}
+	for bounds, count := range buckets {
+		c.submitBucket(h, bounds, count-committed[bounds])
+	}
+}
+
+func (c *converter) submitBucket(h *histogramState, bounds bucketBounds, count float64) {
+	value := int64(math.Round(count))
+	if value <= 0 {
+		return
+	}
+	c.sender.HistogramBucket(h.name, value, bounds.lower, bounds.upper, false, "", h.tags, false)
+}
+
+// classicBuckets returns the buckets of a classic histogram from the cumulative counts of
+// its upper bounds. The lower bound of the first bucket is 0, or its upper bound if it is negative.
+func classicBuckets(cumulative map[float64]float64) map[bucketBounds]float64 {
+	upperBounds := make([]float64, 0, len(cumulative))
+	for le := range cumulative {
+		upperBounds = append(upperBounds, le)
+	}
+	sort.Float64s(upperBounds)
+
+	buckets := make(map[bucketBounds]float64, len(upperBounds))
+	lower := math.Min(0, upperBounds[0])
+	previous := 0.0
+	for _, le := range upperBounds {
+		buckets[bucketBounds{lower: lower, upper: le}] = cumulative[le] - previous
+		lower = le
+		previous = cumulative[le]
+	}
+	return buckets
+}
+
+// nativeBuckets returns the buckets of a native histogram. With the schema s, the upper
+// bound of the bucket of index i is 2^(i*2^-s), the zero bucket holds the observations
+// within the zero threshold.
+func nativeBuckets(h histogram) map[bucketBounds]float64 {
+	buckets := make(map[bucketBounds]float64)
+	if h.zeroCount > 0 {
+		buckets[bucketBounds{lower: -h.zeroThreshold, upper: h.zeroThreshold}] = h.zeroCount
+	}
+
+	bound := func(index int32) float64 {
+		return math.Exp2(float64(index) * math.Exp2(-float64(h.schema)))
+	}
+	forEachNativeBucket(h.positiveSpans, h.positiveDeltas, h.positiveCounts, func(index int32, count float64) {
+		buckets[bucketBounds{lower: bound(index - 1), upper: bound(index)}] += count
+	})
+	forEachNativeBucket(h.negativeSpans, h.negativeDeltas, h.negativeCounts, func(index int32, count float64) {
+		buckets[bucketBounds{lower: -bound(index), upper: -bound(index - 1)}] += count
+	})
+	return buckets
+}
+
+// forEachNativeBucket calls fn with the index and the count of each bucket described by spans,
+// the counts being either integers encoded as deltas from the previous bucket or floats.
+func forEachNativeBucket(spans []bucketSpan, deltas []int64, counts []float64, fn func(int32, float64)) {
+	var index int32
+	var count int64
+	i := 0
+	for _, span := range spans {
+		index += span.offset
+		for j := uint32(0); j < span.length; j++ {
+			switch {
+			case i < len(deltas):
+				count += deltas[i]
+				fn(index, float64(count))
+			case i < len(counts):
+				fn(index, counts[i])
+			default:
+				return
+			}
+			index++
+			i++
+		}
+	}
+}
+
+// removeTag returns a copy of tags without tag, the tags are shared by the samples of a series.
+func removeTag(tags []string, tag string) []string {
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t != tag {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package remotewriteimpl
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+func newTestConverter(tags []string) (*converter, *mocksender.MockSender) {
+	s := mocksender.NewMockSender(senderID)
+	s.SetupAcceptAll()
+	return newConverter(s, tags), s
+}
+
+func series(name string, value float64, labels ...label) timeSeries {
+	return timeSeries{
+		labels:  append([]label{{nameLabel, name}}, labels...),
+		samples: []sample{{value: value, timestamp: 1700000000000}},
+	}
+}
+
+func TestConverterSamples(t *testing.T) {
+	c, s := newTestConverter([]string{"env:prod"})
+	origin := []string{"remote_write_origin:prometheus"}
+
+	req := writeRequest{
+		timeseries: []timeSeries{
+			series("up", 1, label{"job", "api"}),
+			series("http_requests_total", 42, label{"job", "api"}),
+			series("processed_items", 7),
+			series("rpc_duration_seconds_sum", 12.5),
+			series("rpc_duration_seconds_count", 5),
+			series("rpc_duration_seconds", 0.2, label{"quantile", "0.5"}),
+			series("stale", math.NaN()),
+			{labels: []label{{"job", "api"}}, samples: []sample{{value: 1}}},
+		},
+		metadata: []metricMetadata{
+			{metricType: metadataTypeCounter, familyName: "processed_items"},
+			{metricType: metadataTypeSummary, familyName: "rpc_duration_seconds"},
+		},
+	}
+	c.submit(req, origin)
+
+	// the gauges are sent with the timestamps of their samples, the counters from their second sample
+	s.AssertMetricWithTimestamp(t, "GaugeWithTimestamp", "up", 1, "", []string{"job:api", "env:prod", "remote_write_origin:prometheus"}, 1700000000)
+	s.AssertMetricWithTimestamp(t, "GaugeWithTimestamp", "rpc_duration_seconds", 0.2, "", []string{"quantile:0.5", "env:prod", "remote_write_origin:prometheus"}, 1700000000)
+	s.AssertNumberOfCalls(t, "GaugeWithTimestamp", 2)
+	s.AssertNumberOfCalls(t, "CountWithTimestamp", 0)
+
+	for i := range req.timeseries {
+		for j := range req.timeseries[i].samples {
+			req.timeseries[i].samples[j].value *= 2
+			req.timeseries[i].samples[j].timestamp += 15000
+		}
+	}
+	req.metadata = nil
+	c.submit(req, origin)
+	s.AssertMetricWithTimestamp(t, "CountWithTimestamp", "http_requests_total", 42, "", []string{"job:api", "env:prod", "remote_write_origin:prometheus"}, 1700000015)
+	s.AssertMetricWithTimestamp(t, "CountWithTimestamp", "processed_items", 7, "", []string{"env:prod", "remote_write_origin:prometheus"}, 1700000015)
+	s.AssertMetricWithTimestamp(t, "CountWithTimestamp", "rpc_duration_seconds_sum", 12.5, "", []string{"env:prod", "remote_write_origin:prometheus"}, 1700000015)
+	s.AssertMetricWithTimestamp(t, "CountWithTimestamp", "rpc_duration_seconds_count", 5, "", []string{"env:prod", "remote_write_origin:prometheus"}, 1700000015)
+	s.AssertNumberOfCalls(t, "GaugeWithTimestamp", 4)
+	s.AssertNumberOfCalls(t, "CountWithTimestamp", 4)
+
+	// nothing is sent when a counter is reset or for the samples received out of order
+	counter := func(value float64, timestamp int64) {
+		c.submit(writeRequest{timeseries: []timeSeries{{
+			labels:  []label{{nameLabel, "processed_items"}},
+			samples: []sample{{value: value, timestamp: timestamp}},
+		}}}, origin)
+	}
+	counter(3, 1700000030000)
+	counter(10, 1700000020000)
+	s.AssertNumberOfCalls(t, "CountWithTimestamp", 4)
+	counter(5, 1700000045000)
+	s.AssertMetricWithTimestamp(t, "CountWithTimestamp", "processed_items", 2, "", []string{"env:prod", "remote_write_origin:prometheus"}, 1700000045)
+	s.AssertNumberOfCalls(t, "CountWithTimestamp", 5)
+
+	// the state of the families and the counters is dropped once they aren't received anymore
+	c.commit(time.Now().Add(stateExpiration + time.Minute))
+	if len(c.types) != 0 || len(c.counters) != 0 {
+		t.Errorf("expected the state to be expired, got %d families and %d counters", len(c.types), len(c.counters))
+	}
+}
+
+func TestConverterClassicHistogram(t *testing.T) {
+	c, s := newTestConverter(nil)
+	now := time.Now()
+
+	submit := func(le string, value float64) {
+		c.submit(writeRequest{
+			timeseries: []timeSeries{series("latency_bucket", value, label{"job", "api"}, label{upperBoundLbl, le})},
+		}, nil)
+	}
+
+	// the first commit only records the counts of the buckets
+	submit("0.1", 2)
+	submit("1", 5)
+	submit("+Inf", 6)
+	c.commit(now)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 0)
+	s.AssertNumberOfCalls(t, "Commit", 1)
+
+	// the buckets may be received in different requests, the histogram is held back until
+	// they are all received
+	submit("0.1", 3)
+	submit("1", 8)
+	c.commit(now)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 0)
+	submit("+Inf", 10)
+	c.commit(now)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 1, 0, 0.1, false, "", []string{"job:api"}, false)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 2, 0.1, 1, false, "", []string{"job:api"}, false)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 1, 1, math.Inf(1), false, "", []string{"job:api"}, false)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 3)
+
+	// nothing is sent when the histogram is reset
+	submit("0.1", 0)
+	submit("1", 1)
+	submit("+Inf", 1)
+	c.commit(now)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 3)
+
+	// the buckets which are still not received at the next commit are dropped
+	submit("0.1", 1)
+	submit("1", 2)
+	c.commit(now)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 3)
+	submit("0.1", 2)
+	submit("1", 4)
+	c.commit(now)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 2, 0, 0.1, false, "", []string{"job:api"}, false)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 1, 0.1, 1, false, "", []string{"job:api"}, false)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 5)
+
+	// the histogram is dropped once it isn't received anymore
+	c.commit(now.Add(stateExpiration + time.Minute))
+	if len(c.histograms) != 0 {
+		t.Errorf("expected the histogram to be expired, got %d histograms", len(c.histograms))
+	}
+}
+
+func TestConverterClassicHistogramSamples(t *testing.T) {
+	c, s := newTestConverter([]string{"env:prod"})
+	now := time.Now()
+
+	// the buckets series hold several samples, they update the same histogram
+	bucket := func(le string, values ...float64) timeSeries {
+		ts := timeSeries{labels: []label{{nameLabel, "latency_bucket"}, {"job", "api"}, {upperBoundLbl, le}}}
+		for i, v := range values {
+			ts.samples = append(ts.samples, sample{value: v, timestamp: 1700000000000 + int64(i)*15000})
+		}
+		return ts
+	}
+	c.submit(writeRequest{timeseries: []timeSeries{bucket("1", 1, 2), bucket("+Inf", 2, 3)}}, nil)
+	c.commit(now)
+	c.submit(writeRequest{timeseries: []timeSeries{bucket("1", 4, 5), bucket("+Inf", 6, 8)}}, nil)
+	c.commit(now)
+
+	if len(c.histograms) != 1 {
+		t.Fatalf("expected a single histogram, got %d", len(c.histograms))
+	}
+	for _, h := range c.histograms {
+		if strings.Join(h.tags, ",") != "job:api,env:prod" {
+			t.Errorf("unexpected tags %v", h.tags)
+		}
+	}
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 3, 0, 1, false, "", []string{"job:api", "env:prod"}, false)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 2, 1, math.Inf(1), false, "", []string{"job:api", "env:prod"}, false)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 2)
+}
+
+func TestConverterNativeHistogram(t *testing.T) {
+	c, s := newTestConverter(nil)
+	now := time.Now()
+
+	timestamp := int64(1700000000000)
+	submit := func(zeroCount float64, deltas []int64) {
+		timestamp += 15000
+		var count float64
+		var bucket int64
+		for _, d := range deltas {
+			bucket += d
+			count += float64(bucket)
+		}
+		c.submit(writeRequest{
+			timeseries: []timeSeries{{
+				labels: []label{{nameLabel, "latency"}},
+				histograms: []histogram{{
+					count:          count + zeroCount,
+					sum:            10,
+					schema:         0,
+					zeroThreshold:  0.001,
+					zeroCount:      zeroCount,
+					positiveSpans:  []bucketSpan{{offset: 0, length: 2}},
+					positiveDeltas: deltas,
+					timestamp:      timestamp,
+				}},
+			}},
+		}, nil)
+	}
+
+	submit(1, []int64{2, 1})
+	c.commit(now)
+	s.AssertNumberOfCalls(t, "CountWithTimestamp", 0)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 0)
+
+	// with the schema 0, the bucket of index i holds the observations in (2^(i-1), 2^i]
+	submit(2, []int64{4, 0})
+	c.commit(now)
+	s.AssertMetricWithTimestamp(t, "CountWithTimestamp", "latency_sum", 0, "", []string{}, 1700000030)
+	s.AssertMetricWithTimestamp(t, "CountWithTimestamp", "latency_count", 4, "", []string{}, 1700000030)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 1, -0.001, 0.001, false, "", []string{}, false)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 2, 0.5, 1, false, "", []string{}, false)
+	s.AssertHistogramBucket(t, "HistogramBucket", "latency", 1, 1, 2, false, "", []string{}, false)
+	s.AssertNumberOfCalls(t, "HistogramBucket", 3)
+}
+
+func TestNativeBuckets(t *testing.T) {
+	buckets := nativeBuckets(histogram{
+		schema:         1,
+		negativeSpans:  []bucketSpan{{offset: 1, length: 1}},
+		negativeCounts: []float64{3},
+		positiveSpans:  []bucketSpan{{offset: -1, length: 1}, {offset: 2, length: 1}},
+		positiveCounts: []float64{1, 2},
+	})
+
+	expected := map[bucketBounds]float64{
+		{lower: -math.Exp2(0.5), upper: -1}:  3,
+		{lower: 0.5, upper: math.Exp2(-0.5)}: 1,
+		{lower: math.Exp2(0.5), upper: 2}:    2,
+	}
+	if len(buckets) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, buckets)
+	}
+	for bounds, count := range expected {
+		if buckets[bounds] != count {
+			t.Errorf("expected %v for %v, got %v", count, bounds, buckets)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package remotewriteimpl
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The remote-write requests are decoded directly from the protobuf wire format, the field
+// numbers are the ones of prometheus/prompb/remote.proto and prometheus/prompb/types.proto.
+const (
+	// WriteRequest
+	fieldRequestTimeseries protowire.Number = 1
+	fieldRequestMetadata   protowire.Number = 3
+	// TimeSeries
+	fieldTimeSeriesLabels     protowire.Number = 1
+	fieldTimeSeriesSamples    protowire.Number = 2
+	fieldTimeSeriesHistograms protowire.Number = 4
+	// Label
+	fieldLabelName  protowire.Number = 1
+	fieldLabelValue protowire.Number = 2
+	// Sample
+	fieldSampleValue     protowire.Number = 1
+	fieldSampleTimestamp protowire.Number = 2
+	// MetricMetadata
+	fieldMetadataType       protowire.Number = 1
+	fieldMetadataFamilyName protowire.Number = 2
+	// Histogram
+	fieldHistogramCountInt       protowire.Number = 1
+	fieldHistogramCountFloat     protowire.Number = 2
+	fieldHistogramSum            protowire.Number = 3
+	fieldHistogramSchema         protowire.Number = 4
+	fieldHistogramZeroThreshold  protowire.Number = 5
+	fieldHistogramZeroCountInt   protowire.Number = 6
+	fieldHistogramZeroCountFloat protowire.Number = 7
+	fieldHistogramNegativeSpans  protowire.Number = 8
+	fieldHistogramNegativeDeltas protowire.Number = 9
+	fieldHistogramNegativeCounts protowire.Number = 10
+	fieldHistogramPositiveSpans  protowire.Number = 11
+	fieldHistogramPositiveDeltas protowire.Number = 12
+	fieldHistogramPositiveCounts protowire.Number = 13
+	fieldHistogramResetHint      protowire.Number = 14
+	fieldHistogramTimestamp      protowire.Number = 15
+	// BucketSpan
+	fieldBucketSpanOffset protowire.Number = 1
+	fieldBucketSpanLength protowire.Number = 2
+)
+
+// The types of the MetricMetadata messages.
+const (
+	metadataTypeUnknown        = 0
+	metadataTypeCounter        = 1
+	metadataTypeGauge          = 2
+	metadataTypeHistogram      = 3
+	metadataTypeGaugeHistogram = 4
+	metadataTypeSummary        = 5
+)
+
+// resetHintGauge is the reset hint of the gauge histograms.
+const resetHintGauge = 3
+
+type writeRequest struct {
+	timeseries []timeSeries
+	metadata   []metricMetadata
+}
+
+type timeSeries struct {
+	labels     []label
+	samples    []sample
+	histograms []histogram
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+type metricMetadata struct {
+	metricType uint64
+	familyName string
+}
+
+// histogram is a native histogram, the counts of its buckets are either integers
+// encoded as deltas from the previous bucket or floats.
+type histogram struct {
+	count          float64
+	sum            float64
+	schema         int32
+	zeroThreshold  float64
+	zeroCount      float64
+	negativeSpans  []bucketSpan
+	negativeDeltas []int64
+	negativeCounts []float64
+	positiveSpans  []bucketSpan
+	positiveDeltas []int64
+	positiveCounts []float64
+	resetHint      uint64
+	timestamp      int64
+}
+
+type bucketSpan struct {
+	offset int32
+	length uint32
+}
+
+// decodeWriteRequest decodes a WriteRequest message.
+func decodeWriteRequest(b []byte) (writeRequest, error) {
+	var req writeRequest
+	err := forEachField(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch num {
+		case fieldRequestTimeseries:
+			m, err := consumeMessage(typ, v)
+			if err != nil {
+				return err
+			}
+			ts, err := decodeTimeSeries(m)
+			if err != nil {
+				return err
+			}
+			req.timeseries = append(req.timeseries, ts)
+		case fieldRequestMetadata:
+			m, err := consumeMessage(typ, v)
+			if err != nil {
+				return err
+			}
+			md, err := decodeMetricMetadata(m)
+			if err != nil {
+				return err
+			}
+			req.metadata = append(req.metadata, md)
+		}
+		return nil
+	})
+	return req, err
+}
+
+func decodeTimeSeries(b []byte) (timeSeries, error) {
+	var ts timeSeries
+	err := forEachField(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch num {
+		case fieldTimeSeriesLabels:
+			m, err := consumeMessage(typ, v)
+			if err != nil {
+				return err
+			}
+			l, err := decodeLabel(m)
+			if err != nil {
+				return err
+			}
+			ts.labels = append(ts.labels, l)
+		case fieldTimeSeriesSamples:
+			m, err := consumeMessage(typ, v)
+			if err != nil {
+				return err
+			}
+			s, err := decodeSample(m)
+			if err != nil {
+				return err
+			}
+			ts.samples = append(ts.samples, s)
+		case fieldTimeSeriesHistograms:
+			m, err := consumeMessage(typ, v)
+			if err != nil {
+				return err
+			}
+			h, err := decodeHistogram(m)
+			if err != nil {
+				return err
+			}
+			ts.histograms = append(ts.histograms, h)
+		}
+		return nil
+	})
+	return ts, err
+}
+
+func decodeLabel(b []byte) (label, error) {
+	var l label
+	err := forEachField(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch num {
+		case fieldLabelName:
+			l.name, err = consumeString(typ, v)
+		case fieldLabelValue:
+			l.value, err = consumeString(typ, v)
+		}
+		return err
+	})
+	return l, err
+}
+
+func decodeSample(b []byte) (sample, error) {
+	var s sample
+	err := forEachField(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch num {
+		case fieldSampleValue:
+			s.value, err = consumeDouble(typ, v)
+		case fieldSampleTimestamp:
+			var ts uint64
+			ts, err = consumeVarint(typ, v)
+			s.timestamp = int64(ts)
+		}
+		return err
+	})
+	return s, err
+}
+
+func decodeMetricMetadata(b []byte) (metricMetadata, error) {
+	var md metricMetadata
+	err := forEachField(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch num {
+		case fieldMetadataType:
+			md.metricType, err = consumeVarint(typ, v)
+		case fieldMetadataFamilyName:
+			md.familyName, err = consumeString(typ, v)
+		}
+		return err
+	})
+	return md, err
+}
+
+func decodeHistogram(b []byte) (histogram, error) {
+	var h histogram
+	err := forEachField(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		var u uint64
+		switch num {
+		case fieldHistogramCountInt:
+			u, err = consumeVarint(typ, v)
+			h.count = float64(u)
+		case fieldHistogramCountFloat:
+			h.count, err = consumeDouble(typ, v)
+		case fieldHistogramSum:
+			h.sum, err = consumeDouble(typ, v)
+		case fieldHistogramSchema:
+			u, err = consumeVarint(typ, v)
+			h.schema = int32(protowire.DecodeZigZag(u))
+		case fieldHistogramZeroThreshold:
+			h.zeroThreshold, err = consumeDouble(typ, v)
+		case fieldHistogramZeroCountInt:
+			u, err = consumeVarint(typ, v)
+			h.zeroCount = float64(u)
+		case fieldHistogramZeroCountFloat:
+			h.zeroCount, err = consumeDouble(typ, v)
+		case fieldHistogramNegativeSpans:
+			var span bucketSpan
+			span, err = decodeBucketSpan(typ, v)
+			h.negativeSpans = append(h.negativeSpans, span)
+		case fieldHistogramNegativeDeltas:
+			h.negativeDeltas, err = appendPackedSint64(h.negativeDeltas, typ, v)
+		case fieldHistogramNegativeCounts:
+			h.negativeCounts, err = appendPackedDouble(h.negativeCounts, typ, v)
+		case fieldHistogramPositiveSpans:
+			var span bucketSpan
+			span, err = decodeBucketSpan(typ, v)
+			h.positiveSpans = append(h.positiveSpans, span)
+		case fieldHistogramPositiveDeltas:
+			h.positiveDeltas, err = appendPackedSint64(h.positiveDeltas, typ, v)
+		case fieldHistogramPositiveCounts:
+			h.positiveCounts, err = appendPackedDouble(h.positiveCounts, typ, v)
+		case fieldHistogramResetHint:
+			h.resetHint, err = consumeVarint(typ, v)
+		case fieldHistogramTimestamp:
+			u, err = consumeVarint(typ, v)
+			h.timestamp = int64(u)
+		}
+		return err
+	})
+	return h, err
+}
+
+func decodeBucketSpan(typ protowire.Type, v []byte) (bucketSpan, error) {
+	var span bucketSpan
+	m, err := consumeMessage(typ, v)
+	if err != nil {
+		return span, err
+	}
+	err = forEachField(m, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		var u uint64
+		switch num {
+		case fieldBucketSpanOffset:
+			u, err = consumeVarint(typ, v)
+			span.offset = int32(protowire.DecodeZigZag(u))
+		case fieldBucketSpanLength:
+			u, err = consumeVarint(typ, v)
+			span.length = uint32(u)
+		}
+		return err
+	})
+	return span, err
+}
+
+// forEachField calls fn with the number, the type and the encoded value of each field of a message.
+func forEachField(b []byte, fn func(protowire.Number, protowire.Type, []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			return protowire.ParseError(m)
+		}
+		if err := fn(num, typ, b[:m]); err != nil {
+			return err
+		}
+		b = b[m:]
+	}
+	return nil
+}
+
+func checkType(typ protowire.Type, expected protowire.Type) error {
+	if typ != expected {
+		return fmt.Errorf("unexpected wire type %d, expected %d", typ, expected)
+	}
+	return nil
+}
+
+func consumeMessage(typ protowire.Type, v []byte) ([]byte, error) {
+	if err := checkType(typ, protowire.BytesType); err != nil {
+		return nil, err
+	}
+	m, _ := protowire.ConsumeBytes(v)
+	return m, nil
+}
+
+func consumeString(typ protowire.Type, v []byte) (string, error) {
+	m, err := consumeMessage(typ, v)
+	return string(m), err
+}
+
+func consumeVarint(typ protowire.Type, v []byte) (uint64, error) {
+	if err := checkType(typ, protowire.VarintType); err != nil {
+		return 0, err
+	}
+	u, _ := protowire.ConsumeVarint(v)
+	return u, nil
+}
+
+func consumeDouble(typ protowire.Type, v []byte) (float64, error) {
+	if err := checkType(typ, protowire.Fixed64Type); err != nil {
+		return 0, err
+	}
+	u, _ := protowire.ConsumeFixed64(v)
+	return math.Float64frombits(u), nil
+}
+
+// appendPackedSint64 appends the values of a repeated sint64 field, packed or not.
+func appendPackedSint64(dst []int64, typ protowire.Type, v []byte) ([]int64, error) {
+	if typ == protowire.VarintType {
+		u, _ := protowire.ConsumeVarint(v)
+		return append(dst, protowire.DecodeZigZag(u)), nil
+	}
+	m, err := consumeMessage(typ, v)
+	if err != nil {
+		return dst, err
+	}
+	for len(m) > 0 {
+		u, n := protowire.ConsumeVarint(m)
+		if n < 0 {
+			return dst, protowire.ParseError(n)
+		}
+		dst = append(dst, protowire.DecodeZigZag(u))
+		m = m[n:]
+	}
+	return dst, nil
+}
+
+// appendPackedDouble appends the values of a repeated double field, packed or not.
+func appendPackedDouble(dst []float64, typ protowire.Type, v []byte) ([]float64, error) {
+	if typ == protowire.Fixed64Type {
+		u, _ := protowire.ConsumeFixed64(v)
+		return append(dst, math.Float64frombits(u)), nil
+	}
+	m, err := consumeMessage(typ, v)
+	if err != nil {
+		return dst, err
+	}
+	for len(m) > 0 {
+		u, n := protowire.ConsumeFixed64(m)
+		if n < 0 {
+			return dst, protowire.ParseError(n)
+		}
+		dst = append(dst, math.Float64frombits(u))
+		m = m[n:]
+	}
+	return dst, nil
+}
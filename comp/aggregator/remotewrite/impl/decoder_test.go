@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package remotewriteimpl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendMessage(b []byte, num protowire.Number, m []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
+
+func appendDouble(b []byte, num protowire.Number, f float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(f))
+}
+
+func appendVarint(b []byte, num protowire.Number, u uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, u)
+}
+
+// encodeWriteRequest encodes a WriteRequest message, like the remote-write clients do.
+func encodeWriteRequest(req writeRequest) []byte {
+	var b []byte
+	for _, ts := range req.timeseries {
+		b = appendMessage(b, fieldRequestTimeseries, encodeTimeSeries(ts))
+	}
+	for _, md := range req.metadata {
+		var m []byte
+		m = appendVarint(m, fieldMetadataType, md.metricType)
+		m = appendMessage(m, fieldMetadataFamilyName, []byte(md.familyName))
+		b = appendMessage(b, fieldRequestMetadata, m)
+	}
+	return b
+}
+
+func encodeTimeSeries(ts timeSeries) []byte {
+	var b []byte
+	for _, l := range ts.labels {
+		var m []byte
+		m = appendMessage(m, fieldLabelName, []byte(l.name))
+		m = appendMessage(m, fieldLabelValue, []byte(l.value))
+		b = appendMessage(b, fieldTimeSeriesLabels, m)
+	}
+	for _, s := range ts.samples {
+		var m []byte
+		m = appendDouble(m, fieldSampleValue, s.value)
+		m = appendVarint(m, fieldSampleTimestamp, uint64(s.timestamp))
+		b = appendMessage(b, fieldTimeSeriesSamples, m)
+	}
+	for _, h := range ts.histograms {
+		b = appendMessage(b, fieldTimeSeriesHistograms, encodeHistogram(h))
+	}
+	return b
+}
+
+// encodeHistogram encodes a native histogram with integer counts, or float counts
+// when it has no deltas.
+func encodeHistogram(h histogram) []byte {
+	var b []byte
+	integer := len(h.positiveDeltas) > 0 || len(h.negativeDeltas) > 0
+	if integer {
+		b = appendVarint(b, fieldHistogramCountInt, uint64(h.count))
+	} else {
+		b = appendDouble(b, fieldHistogramCountFloat, h.count)
+	}
+	b = appendDouble(b, fieldHistogramSum, h.sum)
+	b = appendVarint(b, fieldHistogramSchema, protowire.EncodeZigZag(int64(h.schema)))
+	b = appendDouble(b, fieldHistogramZeroThreshold, h.zeroThreshold)
+	if integer {
+		b = appendVarint(b, fieldHistogramZeroCountInt, uint64(h.zeroCount))
+	} else {
+		b = appendDouble(b, fieldHistogramZeroCountFloat, h.zeroCount)
+	}
+	appendSpans := func(b []byte, num protowire.Number, spans []bucketSpan) []byte {
+		for _, span := range spans {
+			var m []byte
+			m = appendVarint(m, fieldBucketSpanOffset, protowire.EncodeZigZag(int64(span.offset)))
+			m = appendVarint(m, fieldBucketSpanLength, uint64(span.length))
+			b = appendMessage(b, num, m)
+		}
+		return b
+	}
+	packedDeltas := func(deltas []int64) []byte {
+		var m []byte
+		for _, d := range deltas {
+			m = protowire.AppendVarint(m, protowire.EncodeZigZag(d))
+		}
+		return m
+	}
+	packedCounts := func(counts []float64) []byte {
+		var m []byte
+		for _, c := range counts {
+			m = protowire.AppendFixed64(m, math.Float64bits(c))
+		}
+		return m
+	}
+	b = appendSpans(b, fieldHistogramNegativeSpans, h.negativeSpans)
+	b = appendMessage(b, fieldHistogramNegativeDeltas, packedDeltas(h.negativeDeltas))
+	b = appendMessage(b, fieldHistogramNegativeCounts, packedCounts(h.negativeCounts))
+	b = appendSpans(b, fieldHistogramPositiveSpans, h.positiveSpans)
+	b = appendMessage(b, fieldHistogramPositiveDeltas, packedDeltas(h.positiveDeltas))
+	b = appendMessage(b, fieldHistogramPositiveCounts, packedCounts(h.positiveCounts))
+	b = appendVarint(b, fieldHistogramResetHint, h.resetHint)
+	b = appendVarint(b, fieldHistogramTimestamp, uint64(h.timestamp))
+	return b
+}
+
+func TestDecodeWriteRequest(t *testing.T) {
+	expected := writeRequest{
+		timeseries: []timeSeries{
+			{
+				labels:  []label{{"__name__", "http_requests_total"}, {"job", "api"}},
+				samples: []sample{{value: 42, timestamp: 1700000000000}, {value: 43, timestamp: 1700000010000}},
+			},
+			{
+				labels: []label{{"__name__", "request_duration_seconds"}},
+				histograms: []histogram{{
+					count:          6,
+					sum:            12.5,
+					schema:         -1,
+					zeroThreshold:  0.001,
+					zeroCount:      1,
+					negativeSpans:  []bucketSpan{{offset: 0, length: 1}},
+					negativeDeltas: []int64{1},
+					positiveSpans:  []bucketSpan{{offset: -1, length: 2}, {offset: 3, length: 1}},
+					positiveDeltas: []int64{2, -1, 0},
+					resetHint:      2,
+					timestamp:      1700000010000,
+				}},
+			},
+		},
+		metadata: []metricMetadata{{metricType: metadataTypeCounter, familyName: "http_requests"}},
+	}
+
+	req, err := decodeWriteRequest(encodeWriteRequest(expected))
+	require.NoError(t, err)
+	assert.Equal(t, expected, req)
+}
+
+func TestDecodeUnpackedRepeatedFields(t *testing.T) {
+	var b []byte
+	b = appendVarint(b, fieldHistogramPositiveDeltas, protowire.EncodeZigZag(3))
+	b = appendVarint(b, fieldHistogramPositiveDeltas, protowire.EncodeZigZag(-2))
+	b = appendDouble(b, fieldHistogramNegativeCounts, 1.5)
+
+	h, err := decodeHistogram(b)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, -2}, h.positiveDeltas)
+	assert.Equal(t, []float64{1.5}, h.negativeCounts)
+}
+
+func TestDecodeInvalidWriteRequest(t *testing.T) {
+	// truncated message
+	b := encodeWriteRequest(writeRequest{timeseries: []timeSeries{{labels: []label{{"__name__", "up"}}}}})
+	_, err := decodeWriteRequest(b[:len(b)-1])
+	assert.Error(t, err)
+
+	// unexpected wire type of the label name
+	var m []byte
+	m = appendVarint(m, fieldLabelName, 1)
+	b = appendMessage(nil, fieldTimeSeriesLabels, m)
+	b = appendMessage(nil, fieldRequestTimeseries, b)
+	_, err = decodeWriteRequest(b)
+	assert.Error(t, err)
+}
@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package remotewriteimpl implements the remotewrite component interface
+package remotewriteimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer"
+	remotewrite "github.com/DataDog/datadog-agent/comp/aggregator/remotewrite/def"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	compdef "github.com/DataDog/datadog-agent/comp/def"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+)
+
+const (
+	// writePath is the path of the remote-write endpoint of Prometheus
+	writePath = "/api/v1/write"
+
+	// senderID is the ID of the sender of the received metrics
+	senderID checkid.ID = "prometheus_remote_write"
+
+	// commitInterval is the interval at which the received metrics are committed to the aggregator
+	commitInterval = 15 * time.Second
+
+	// maxDecodedSize is the maximum size of a decompressed request
+	maxDecodedSize = 32 * 1024 * 1024
+
+	originTagPrefix = "remote_write_origin:"
+)
+
+// Requires defines the dependencies for the remotewrite component
+type Requires struct {
+	Lifecycle     compdef.Lifecycle
+	Config        config.Component
+	Log           log.Component
+	Telemetry     telemetry.Component
+	Demultiplexer demultiplexer.Component
+}
+
+// Provides defines the output of the remotewrite component
+type Provides struct {
+	Comp remotewrite.Component
+}
+
+type receiver struct {
+	log    log.Component
+	demux  demultiplexer.Component
+	addr   string
+	tags   []string
+	server *http.Server
+
+	converter *converter
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+
+	tlmRequests telemetry.Counter
+	tlmSeries   telemetry.Counter
+}
+
+// NewComponent creates a new remotewrite component
+func NewComponent(reqs Requires) Provides {
+	r := &receiver{
+		log:   reqs.Log,
+		demux: reqs.Demultiplexer,
+		tags:  reqs.Config.GetStringSlice("prometheus_remote_write.tags"),
+		tlmRequests: reqs.Telemetry.NewCounter("prometheus_remote_write", "requests",
+			[]string{"state"}, "Count of the remote-write requests received"),
+		tlmSeries: reqs.Telemetry.NewCounter("prometheus_remote_write", "series",
+			[]string{}, "Count of the series received with the remote-write protocol"),
+	}
+
+	if reqs.Config.GetBool("prometheus_remote_write.enabled") {
+		port := reqs.Config.GetString("prometheus_remote_write.port")
+		r.addr = net.JoinHostPort(pkgconfigsetup.GetBindHost(reqs.Config), port)
+		if reqs.Config.GetBool("prometheus_remote_write.non_local_traffic") {
+			// Listen to all network interfaces
+			r.addr = ":" + port
+		}
+		reqs.Lifecycle.Append(compdef.Hook{
+			OnStart: r.start,
+			OnStop:  r.stop,
+		})
+	}
+
+	return Provides{
+		Comp: r,
+	}
+}
+
+func (r *receiver) start(_ context.Context) error {
+	s, err := r.demux.GetSender(senderID)
+	if err != nil {
+		return fmt.Errorf("can't get the sender of the remote-write metrics: %s", err)
+	}
+
+	listener, err := net.Listen("tcp", r.addr)
+	if err != nil {
+		return fmt.Errorf("can't listen on %s: %s", r.addr, err)
+	}
+
+	r.converter = newConverter(s, r.tags)
+	r.stopChan = make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(writePath, r.handleWrite)
+	r.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	r.wg.Add(2)
+	go func() {
+		defer r.wg.Done()
+		if err := r.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			r.log.Errorf("Error while serving the remote-write endpoint: %s", err)
+		}
+	}()
+	go func() {
+		defer r.wg.Done()
+		r.commitLoop()
+	}()
+
+	r.log.Infof("Receiving the metrics pushed with the Prometheus remote-write protocol on http://%s%s", listener.Addr(), writePath)
+	return nil
+}
+
+func (r *receiver) stop(_ context.Context) error {
+	_ = r.server.Close()
+	close(r.stopChan)
+	r.wg.Wait()
+	r.demux.DestroySender(senderID)
+	return nil
+}
+
+// commitLoop commits the received metrics to the aggregator until the receiver is stopped.
+func (r *receiver) commitLoop() {
+	ticker := time.NewTicker(commitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stopChan:
+			r.converter.commit(time.Now())
+			return
+		case now := <-ticker.C:
+			r.converter.commit(now)
+		}
+	}
+}
+
+// handleWrite decodes a snappy compressed WriteRequest and submits its series.
+func (r *receiver) handleWrite(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		r.tlmRequests.Inc("error")
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	compressed, err := io.ReadAll(io.LimitReader(req.Body, maxDecodedSize))
+	if err != nil {
+		r.tlmRequests.Inc("error")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if n, err := snappy.DecodedLen(compressed); err != nil || n > maxDecodedSize {
+		r.tlmRequests.Inc("error")
+		http.Error(w, "invalid or too large snappy payload", http.StatusBadRequest)
+		return
+	}
+	payload, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		r.tlmRequests.Inc("error")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeReq, err := decodeWriteRequest(payload)
+	if err != nil {
+		r.tlmRequests.Inc("error")
+		r.log.Debugf("Invalid remote-write request from %s: %s", req.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.converter.submit(writeReq, []string{originTagPrefix + requestOrigin(req)})
+	r.tlmRequests.Inc("ok")
+	r.tlmSeries.Add(float64(len(writeReq.timeseries)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requestOrigin returns the origin of a request: the value of its origin query parameter,
+// or the address of the client.
+func requestOrigin(req *http.Request) string {
+	if origin := req.URL.Query().Get("origin"); origin != "" {
+		return origin
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
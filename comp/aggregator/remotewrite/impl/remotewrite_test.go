@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package remotewriteimpl
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry/telemetryimpl"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func newTestReceiver(t *testing.T) (*receiver, *mocksender.MockSender) {
+	tel := fxutil.Test[telemetry.Component](t, telemetryimpl.MockModule())
+	c, s := newTestConverter([]string{"env:prod"})
+	return &receiver{
+		log:         logmock.New(t),
+		converter:   c,
+		tlmRequests: tel.NewCounter("prometheus_remote_write", "requests", []string{"state"}, ""),
+		tlmSeries:   tel.NewCounter("prometheus_remote_write", "series", []string{}, ""),
+	}, s
+}
+
+func TestHandleWrite(t *testing.T) {
+	r, s := newTestReceiver(t)
+
+	payload := encodeWriteRequest(writeRequest{timeseries: []timeSeries{series("up", 1, label{"job", "api"})}})
+	req := httptest.NewRequest(http.MethodPost, writePath+"?origin=prometheus-eu", bytes.NewReader(snappy.Encode(nil, payload)))
+	w := httptest.NewRecorder()
+	r.handleWrite(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	s.AssertMetricWithTimestamp(t, "GaugeWithTimestamp", "up", 1, "", []string{"job:api", "env:prod", "remote_write_origin:prometheus-eu"}, 1700000000)
+
+	// the origin defaults to the address of the client
+	req = httptest.NewRequest(http.MethodPost, writePath, bytes.NewReader(snappy.Encode(nil, payload)))
+	req.RemoteAddr = "10.0.0.1:43210"
+	w = httptest.NewRecorder()
+	r.handleWrite(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	s.AssertMetricWithTimestamp(t, "GaugeWithTimestamp", "up", 1, "", []string{"job:api", "env:prod", "remote_write_origin:10.0.0.1"}, 1700000000)
+}
+
+func TestHandleWriteErrors(t *testing.T) {
+	r, s := newTestReceiver(t)
+
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+		code   int
+	}{
+		{
+			name:   "method not allowed",
+			method: http.MethodGet,
+			code:   http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "not snappy compressed",
+			method: http.MethodPost,
+			body:   []byte("not snappy"),
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "invalid write request",
+			method: http.MethodPost,
+			body:   snappy.Encode(nil, []byte{0xff, 0xff}),
+			code:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.handleWrite(w, httptest.NewRequest(tt.method, writePath, bytes.NewReader(tt.body)))
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+	s.AssertNotCalled(t, "GaugeWithTimestamp")
+}
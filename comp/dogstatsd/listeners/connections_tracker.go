@@ -7,6 +7,7 @@
 package listeners
 
 import (
+	"crypto/tls"
 	"net"
 	"time"
 
@@ -25,13 +26,15 @@ type ConnectionTracker struct {
 	closeDelay        time.Duration
 	activeConnections *atomic.Int32
 	name              string
+	telemetryStore    *TelemetryStore
 }
 
 // NewConnectionTracker creates a new ConnectionTracker.
 // closeDelay is the time to wait before closing a connection. First it will be shutdown for write, which
 // will notify the client that we are disconnecting, then it will be closed. This gives some time to
 // consume the remaining packets.
-func NewConnectionTracker(name string, closeDelay time.Duration) *ConnectionTracker {
+// The opened, closed and active connections are reported in the telemetry with the name as transport.
+func NewConnectionTracker(name string, closeDelay time.Duration, telemetryStore *TelemetryStore) *ConnectionTracker {
 	return &ConnectionTracker{
 		connections:       make(map[net.Conn]struct{}),
 		connToTrack:       make(chan net.Conn),
@@ -41,6 +44,7 @@ func NewConnectionTracker(name string, closeDelay time.Duration) *ConnectionTrac
 		closeDelay:        closeDelay,
 		activeConnections: atomic.NewInt32(0),
 		name:              name,
+		telemetryStore:    telemetryStore,
 	}
 }
 
@@ -66,13 +70,16 @@ func (t *ConnectionTracker) HandleConnections() {
 		select {
 		case conn := <-t.connToTrack:
 			log.Debugf("dogstatsd-%s: tracking new connection %s", t.name, conn.RemoteAddr().String())
+			t.telemetryStore.tlmConnectionsOpened.Inc(t.name)
 
 			if requestStop {
 				//Close it immediately if we are shutting down.
 				conn.Close()
+				t.telemetryStore.tlmConnectionsClosed.Inc(t.name)
 			} else {
 				t.connections[conn] = struct{}{}
 				t.activeConnections.Inc()
+				t.telemetryStore.tlmConnections.Inc(t.name)
 			}
 		case conn := <-t.connToClose:
 			err := conn.Close()
@@ -83,6 +90,8 @@ func (t *ConnectionTracker) HandleConnections() {
 			} else {
 				delete(t.connections, conn)
 				t.activeConnections.Dec()
+				t.telemetryStore.tlmConnections.Dec(t.name)
+				t.telemetryStore.tlmConnectionsClosed.Inc(t.name)
 			}
 		case <-t.stopChan:
 			log.Infof("dogstatsd-%s: stopping connections", t.name)
@@ -97,6 +106,8 @@ func (t *ConnectionTracker) HandleConnections() {
 					err = c.CloseWrite()
 				case *net.UnixConn:
 					err = c.CloseWrite()
+				case *tls.Conn:
+					err = c.CloseWrite()
 				}
 				log.Debugf("dogstatsd-%s: failed to shutdown connection: %v", t.name, err)
 			}
@@ -10,10 +10,22 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry/telemetryimpl"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
 
-func TestConnectionTrackerBasic(_ *testing.T) {
-	tracker := NewConnectionTracker("test", 1*time.Second)
+func newTestConnectionTracker(t *testing.T) (*ConnectionTracker, telemetry.Mock) {
+	telemetryComp := fxutil.Test[telemetry.Mock](t, telemetryimpl.MockModule())
+	return NewConnectionTracker("test", 1*time.Second, NewTelemetryStore(nil, telemetryComp)), telemetryComp
+}
+
+func TestConnectionTrackerBasic(t *testing.T) {
+	tracker, telemetryComp := newTestConnectionTracker(t)
 	tracker.Start()
 	a, b := net.Pipe()
 	tracker.Track(a)
@@ -33,11 +45,23 @@ func TestConnectionTrackerBasic(_ *testing.T) {
 
 	wg.Wait() // Wait for stop to complete.
 
+	opened, err := telemetryComp.GetCountMetric("dogstatsd", "stream_connections_opened")
+	require.NoError(t, err)
+	require.Len(t, opened, 1)
+	assert.Equal(t, float64(2), opened[0].Value())
+	closed, err := telemetryComp.GetCountMetric("dogstatsd", "stream_connections_closed")
+	require.NoError(t, err)
+	require.Len(t, closed, 1)
+	assert.Equal(t, float64(2), closed[0].Value())
+	active, err := telemetryComp.GetGaugeMetric("dogstatsd", "stream_connections")
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, float64(0), active[0].Value())
 }
 
-func TestConnectionTrackerRaceToStop(_ *testing.T) {
+func TestConnectionTrackerRaceToStop(t *testing.T) {
 
-	tracker := NewConnectionTracker("test", 1*time.Second)
+	tracker, _ := newTestConnectionTracker(t)
 	tracker.Start()
 
 	var stopped, started sync.WaitGroup
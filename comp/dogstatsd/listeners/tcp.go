@@ -0,0 +1,255 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listeners
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/packets"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	tcpExpvars             = expvar.NewMap("dogstatsd-tcp")
+	tcpPacketReadingErrors = expvar.Int{}
+	tcpPackets             = expvar.Int{}
+	tcpBytes               = expvar.Int{}
+)
+
+const (
+	// TCPFramingNewline frames the messages sent over TCP by terminating them with a newline
+	TCPFramingNewline = "newline"
+	// TCPFramingLengthPrefixed frames the payloads sent over TCP by prefixing them with their
+	// length as a 4-byte little-endian integer, like the UDS stream listener
+	TCPFramingLengthPrefixed = "length_prefixed"
+)
+
+func init() {
+	tcpExpvars.Set("PacketReadingErrors", &tcpPacketReadingErrors)
+	tcpExpvars.Set("Packets", &tcpPackets)
+	tcpExpvars.Set("Bytes", &tcpBytes)
+}
+
+// TCPListener implements the StatsdListener interface for TCP protocol.
+// It accepts connections on a given TCP address, optionally with TLS, and
+// sends back packets ready to be processed.
+// Origin detection is not implemented for TCP.
+type TCPListener struct {
+	listener                net.Listener
+	framing                 string
+	bufferSize              int
+	packetsBuffer           *packets.Buffer
+	packetAssembler         *packets.Assembler
+	sharedPacketPoolManager *packets.PoolManager[packets.Packet]
+	connTracker             *ConnectionTracker
+	listenWg                sync.WaitGroup
+	telemetryStore          *TelemetryStore
+}
+
+// NewTCPListener returns an idle TCP Statsd listener
+func NewTCPListener(packetOut chan packets.Packets, sharedPacketPoolManager *packets.PoolManager[packets.Packet], cfg config.Reader, telemetryStore *TelemetryStore, packetsTelemetryStore *packets.TelemetryStore) (*TCPListener, error) {
+	var url string
+
+	framing := cfg.GetString("dogstatsd_tcp_framing")
+	if framing != TCPFramingNewline && framing != TCPFramingLengthPrefixed {
+		return nil, fmt.Errorf("invalid dogstatsd_tcp_framing %q, expected %q or %q", framing, TCPFramingNewline, TCPFramingLengthPrefixed)
+	}
+
+	port := cfg.GetString("dogstatsd_tcp_port")
+	if port == RandomPortName {
+		port = "0"
+	}
+
+	if cfg.GetBool("dogstatsd_non_local_traffic") {
+		// Listen to all network interfaces
+		url = fmt.Sprintf(":%s", port)
+	} else {
+		url = net.JoinHostPort(config.GetBindHostFromConfig(cfg), port)
+	}
+
+	var tlsConfig *tls.Config
+	certPath := cfg.GetString("dogstatsd_tcp_tls_cert_path")
+	keyPath := cfg.GetString("dogstatsd_tcp_tls_key_path")
+	if certPath != "" || keyPath != "" {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load tls certificate: %s", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	listener, err := net.Listen("tcp", url)
+	if err != nil {
+		return nil, fmt.Errorf("can't listen: %s", err)
+	}
+	transport := "tcp"
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+		transport = "tls"
+	}
+
+	bufferSize := cfg.GetInt("dogstatsd_buffer_size")
+	packetsBufferSize := cfg.GetInt("dogstatsd_packet_buffer_size")
+	flushTimeout := cfg.GetDuration("dogstatsd_packet_buffer_flush_timeout")
+
+	// The packets of all the connections share the same buffer, the newline framing
+	// guarantees that the assembler only receives complete messages.
+	packetsBuffer := packets.NewBuffer(uint(packetsBufferSize), flushTimeout, packetOut, "tcp", packetsTelemetryStore)
+	packetAssembler := packets.NewAssembler(flushTimeout, packetsBuffer, sharedPacketPoolManager, packets.TCP)
+
+	l := &TCPListener{
+		listener:                listener,
+		framing:                 framing,
+		bufferSize:              bufferSize,
+		packetsBuffer:           packetsBuffer,
+		packetAssembler:         packetAssembler,
+		sharedPacketPoolManager: sharedPacketPoolManager,
+		connTracker:             NewConnectionTracker(transport, 1*time.Second, telemetryStore),
+		telemetryStore:          telemetryStore,
+	}
+	log.Debugf("dogstatsd-tcp: %s successfully initialized", listener.Addr())
+	return l, nil
+}
+
+// LocalAddr returns the local network address of the listener.
+func (l *TCPListener) LocalAddr() string {
+	return l.listener.Addr().String()
+}
+
+// Listen runs the intake loop. Should be called in its own goroutine
+func (l *TCPListener) Listen() {
+	l.listenWg.Add(1)
+
+	go func() {
+		defer l.listenWg.Done()
+		l.listen()
+	}()
+}
+
+func (l *TCPListener) listen() {
+	l.connTracker.Start()
+	log.Infof("dogstatsd-tcp: starting to listen on %s with %s framing", l.listener.Addr(), l.framing)
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), " use of closed network connection") {
+				log.Errorf("dogstatsd-tcp: error accepting connection: %v", err)
+			}
+			return
+		}
+		go func() {
+			l.connTracker.Track(conn)
+			err := l.handleConnection(conn)
+			l.connTracker.Close(conn)
+			if err != nil {
+				log.Errorf("dogstatsd-tcp: error handling connection from %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// handleConnection reads the messages of a connection until it is closed.
+func (l *TCPListener) handleConnection(conn net.Conn) error {
+	log.Debugf("dogstatsd-tcp: starting to handle %s", conn.RemoteAddr())
+	if l.framing == TCPFramingLengthPrefixed {
+		return l.readLengthPrefixed(conn)
+	}
+	return l.readNewlines(conn)
+}
+
+// readNewlines reads newline terminated messages and merges them into packets
+// with the assembler. A message bigger than the buffer size drops the connection.
+func (l *TCPListener) readNewlines(conn net.Conn) error {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, l.bufferSize), l.bufferSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		l.onReadSuccess(len(line))
+		// packetAssembler merges multiple messages together and sends them when its buffer is full
+		l.packetAssembler.AddMessage(line)
+	}
+	return l.readError(scanner.Err())
+}
+
+// readLengthPrefixed reads payloads prefixed by their length, each payload is forwarded
+// as a packet. A payload bigger than the buffer size drops the connection.
+func (l *TCPListener) readLengthPrefixed(conn net.Conn) error {
+	b := []byte{0, 0, 0, 0}
+	for {
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return l.readError(err)
+		}
+		length := binary.LittleEndian.Uint32(b)
+
+		// retrieve an available packet from the packet pool,
+		// which will be pushed back by the server when processed.
+		packet := l.sharedPacketPoolManager.Get()
+		if length == 0 {
+			l.sharedPacketPoolManager.Put(packet)
+			continue
+		}
+		if length > uint32(len(packet.Buffer)) {
+			l.sharedPacketPoolManager.Put(packet)
+			return l.readError(fmt.Errorf("packet length %d too large", length))
+		}
+		n, err := io.ReadFull(conn, packet.Buffer[:length])
+		if err != nil {
+			l.sharedPacketPoolManager.Put(packet)
+			return l.readError(err)
+		}
+		l.onReadSuccess(n)
+
+		packet.Contents = packet.Buffer[:n]
+		packet.Source = packets.TCP
+		// packetsBuffer handles the forwarding of the packets to the dogstatsd server intake channel
+		l.packetsBuffer.Append(packet)
+	}
+}
+
+func (l *TCPListener) onReadSuccess(n int) {
+	tcpPackets.Add(1)
+	tcpBytes.Add(int64(n))
+	l.telemetryStore.tlmTCPPackets.Inc("ok")
+	l.telemetryStore.tlmTCPPacketsBytes.Add(float64(n))
+}
+
+// readError returns the error which ended the reads of a connection, nil if the
+// connection has been closed by the client or the listener.
+func (l *TCPListener) readError(err error) error {
+	if err == nil || err == io.EOF || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	tcpPackets.Add(1)
+	tcpPacketReadingErrors.Add(1)
+	l.telemetryStore.tlmTCPPackets.Inc("error")
+	return err
+}
+
+// Stop closes the TCP listener and its connections and stops listening
+func (l *TCPListener) Stop() {
+	_ = l.listener.Close()
+	l.listenWg.Wait()
+	l.connTracker.Stop()
+	l.packetAssembler.Close()
+	l.packetsBuffer.Close()
+}
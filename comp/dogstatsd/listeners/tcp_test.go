@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+//go:build !windows
+
+package listeners
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/packets"
+)
+
+func newTestTCPListener(t *testing.T, cfg map[string]interface{}, packetChannel chan packets.Packets) (*TCPListener, listenerDeps) {
+	cfg["dogstatsd_tcp_port"] = RandomPortName
+	deps := fulfillDepsWithConfig(t, cfg)
+	telemetryStore := NewTelemetryStore(nil, deps.Telemetry)
+	packetsTelemetryStore := packets.NewTelemetryStore(nil, deps.Telemetry)
+	s, err := NewTCPListener(packetChannel, newPacketPoolManagerUDP(deps.Config, packetsTelemetryStore), deps.Config, telemetryStore, packetsTelemetryStore)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	return s, deps
+}
+
+func receivePackets(t *testing.T, packetChannel chan packets.Packets) packets.Packets {
+	select {
+	case pkts := <-packetChannel:
+		return pkts
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Timeout on receive channel")
+	}
+	return nil
+}
+
+func TestStartStopTCPListener(t *testing.T) {
+	s, _ := newTestTCPListener(t, map[string]interface{}{}, nil)
+	s.Listen()
+	addr := s.LocalAddr()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s.Stop()
+
+	// the connections are closed by the listener
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+
+	// the port can be bound again
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	l.Close()
+}
+
+func TestTCPReceiveNewlineFraming(t *testing.T) {
+	packetChannel := make(chan packets.Packets)
+	s, deps := newTestTCPListener(t, map[string]interface{}{}, packetChannel)
+	s.Listen()
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.LocalAddr())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// a message split across writes is only forwarded once it is complete
+	_, err = conn.Write([]byte("daemon:666|g|#sometag1:somevalue1\ndaemon:999|"))
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	_, err = conn.Write([]byte("g|#sometag1:somevalue1\n"))
+	require.NoError(t, err)
+
+	pkts := receivePackets(t, packetChannel)
+	require.Len(t, pkts, 1)
+	assert.Equal(t, "daemon:666|g|#sometag1:somevalue1\ndaemon:999|g|#sometag1:somevalue1", string(pkts[0].Contents))
+	assert.Equal(t, packets.TCP, pkts[0].Source)
+
+	telemetryMock, ok := deps.Telemetry.(telemetry.Mock)
+	require.True(t, ok)
+	packetsMetrics, err := telemetryMock.GetCountMetric("dogstatsd", "tcp_packets")
+	require.NoError(t, err)
+	require.Len(t, packetsMetrics, 1)
+	assert.Equal(t, float64(2), packetsMetrics[0].Value())
+	connectionsMetrics, err := telemetryMock.GetGaugeMetric("dogstatsd", "stream_connections")
+	require.NoError(t, err)
+	require.Len(t, connectionsMetrics, 1)
+	assert.Equal(t, float64(1), connectionsMetrics[0].Value())
+	assert.Equal(t, "tcp", connectionsMetrics[0].Tags()["transport"])
+}
+
+func TestTCPReceiveLengthPrefixedFraming(t *testing.T) {
+	var contents0 = []byte("daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2")
+	var contents1 = []byte("daemon:999|g|#sometag1:somevalue1")
+
+	packetChannel := make(chan packets.Packets)
+	s, _ := newTestTCPListener(t, map[string]interface{}{"dogstatsd_tcp_framing": TCPFramingLengthPrefixed}, packetChannel)
+	s.Listen()
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.LocalAddr())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	binary.Write(conn, binary.LittleEndian, int32(len(contents0)))
+	conn.Write(contents0)
+	binary.Write(conn, binary.LittleEndian, int32(len(contents1)))
+	conn.Write(contents1)
+
+	pkts := receivePackets(t, packetChannel)
+	require.Len(t, pkts, 2)
+	assert.Equal(t, contents0, pkts[0].Contents)
+	assert.Equal(t, packets.TCP, pkts[0].Source)
+	assert.Equal(t, contents1, pkts[1].Contents)
+	assert.Equal(t, packets.TCP, pkts[1].Source)
+}
+
+func TestTCPDropsConnectionOnTooLargeMessage(t *testing.T) {
+	s, _ := newTestTCPListener(t, map[string]interface{}{"dogstatsd_tcp_framing": TCPFramingLengthPrefixed}, nil)
+	s.Listen()
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.LocalAddr())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	binary.Write(conn, binary.LittleEndian, int32(1024*1024))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+	assert.False(t, os.IsTimeout(err))
+}
+
+func TestTCPInvalidFraming(t *testing.T) {
+	deps := fulfillDepsWithConfig(t, map[string]interface{}{"dogstatsd_tcp_port": RandomPortName, "dogstatsd_tcp_framing": "json"})
+	telemetryStore := NewTelemetryStore(nil, deps.Telemetry)
+	packetsTelemetryStore := packets.NewTelemetryStore(nil, deps.Telemetry)
+	s, err := NewTCPListener(nil, newPacketPoolManagerUDP(deps.Config, packetsTelemetryStore), deps.Config, telemetryStore, packetsTelemetryStore)
+	assert.Nil(t, s)
+	assert.Error(t, err)
+}
+
+func TestTCPReceiveTLS(t *testing.T) {
+	certPath, keyPath, pool := generateTestCertificate(t)
+
+	packetChannel := make(chan packets.Packets)
+	s, _ := newTestTCPListener(t, map[string]interface{}{
+		"dogstatsd_tcp_tls_cert_path": certPath,
+		"dogstatsd_tcp_tls_key_path":  keyPath,
+	}, packetChannel)
+	s.Listen()
+	defer s.Stop()
+
+	conn, err := tls.Dial("tcp", s.LocalAddr(), &tls.Config{RootCAs: pool, ServerName: "localhost"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("daemon:666|g\n"))
+	require.NoError(t, err)
+
+	pkts := receivePackets(t, packetChannel)
+	require.Len(t, pkts, 1)
+	assert.Equal(t, "daemon:666|g", string(pkts[0].Contents))
+}
+
+// generateTestCertificate writes a self-signed certificate for localhost and returns
+// the paths of the certificate and of its key, and a pool trusting it.
+func generateTestCertificate(t *testing.T) (string, string, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certPath, keyPath, pool
+}
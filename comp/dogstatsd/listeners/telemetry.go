@@ -23,6 +23,13 @@ type TelemetryStore struct {
 	tlmUDSOriginDetectionError telemetry.Counter
 	tlmUDSPacketsBytes         telemetry.Counter
 	tlmUDSConnections          telemetry.Gauge
+	// TCP
+	tlmTCPPackets      telemetry.Counter
+	tlmTCPPacketsBytes telemetry.Counter
+	// Stream connections
+	tlmConnections       telemetry.Gauge
+	tlmConnectionsOpened telemetry.Counter
+	tlmConnectionsClosed telemetry.Counter
 
 	tlmListener telemetry.Histogram
 }
@@ -46,6 +53,16 @@ func NewTelemetryStore(buckets []float64, telemetrycomp telemetry.Component) *Te
 			[]string{"listener_id", "transport"}, "Dogstatsd UDS packets bytes"),
 		tlmUDSConnections: telemetrycomp.NewGauge("dogstatsd", "uds_connections",
 			[]string{"listener_id", "transport"}, "Dogstatsd UDS connections count"),
+		tlmTCPPackets: telemetrycomp.NewCounter("dogstatsd", "tcp_packets",
+			[]string{"state"}, "Dogstatsd TCP packets count"),
+		tlmTCPPacketsBytes: telemetrycomp.NewCounter("dogstatsd", "tcp_packets_bytes",
+			nil, "Dogstatsd TCP packets bytes count"),
+		tlmConnections: telemetrycomp.NewGauge("dogstatsd", "stream_connections",
+			[]string{"transport"}, "Dogstatsd active stream connections count"),
+		tlmConnectionsOpened: telemetrycomp.NewCounter("dogstatsd", "stream_connections_opened",
+			[]string{"transport"}, "Dogstatsd stream connections opened count"),
+		tlmConnectionsClosed: telemetrycomp.NewCounter("dogstatsd", "stream_connections_closed",
+			[]string{"transport"}, "Dogstatsd stream connections closed count"),
 		tlmListener: telemetrycomp.NewHistogram(
 			"dogstatsd",
 			"listener_read_latency",
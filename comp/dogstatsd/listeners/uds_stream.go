@@ -55,7 +55,7 @@ func NewUDSStreamListener(packetOut chan packets.Packets, sharedPacketPoolManage
 
 	listener := &UDSStreamListener{
 		UDSListener: *l,
-		connTracker: NewConnectionTracker(transport, 1*time.Second),
+		connTracker: NewConnectionTracker(transport, 1*time.Second, telemetryStore),
 		conn:        conn,
 	}
 
@@ -21,6 +21,8 @@ const (
 	UDS
 	// NamedPipe Windows named pipe listner
 	NamedPipe
+	// TCP listener
+	TCP
 )
 
 // Packet represents a statsd packet ready to process,
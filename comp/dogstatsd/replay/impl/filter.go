@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package replayimpl
+
+import (
+	"bytes"
+)
+
+// TrafficFilter selects the DogStatsD metrics of a capture by name and tags.
+//
+// The patterns match exactly, or by prefix when they end with a `*`. A metric is selected if its
+// name matches one of the name patterns and one of its tags matches one of the tag patterns, an
+// empty list of patterns matching everything. The events and service checks are never selected.
+type TrafficFilter struct {
+	names []pattern
+	tags  []pattern
+}
+
+type pattern struct {
+	value  []byte
+	prefix bool
+}
+
+// NewTrafficFilter returns a filter selecting the metrics matching the patterns, or nil if there
+// isn't any pattern.
+func NewTrafficFilter(names, tags []string) *TrafficFilter {
+	if len(names) == 0 && len(tags) == 0 {
+		return nil
+	}
+	return &TrafficFilter{names: newPatterns(names), tags: newPatterns(tags)}
+}
+
+func newPatterns(values []string) []pattern {
+	patterns := make([]pattern, 0, len(values))
+	for _, v := range values {
+		p := pattern{value: []byte(v)}
+		if last := len(p.value) - 1; last >= 0 && p.value[last] == '*' {
+			p.value, p.prefix = p.value[:last], true
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// Filter returns the payload with only the selected messages, or an empty payload if none of
+// them is selected. A nil filter selects all the messages.
+func (f *TrafficFilter) Filter(payload []byte) []byte {
+	if f == nil {
+		return payload
+	}
+
+	filtered := make([]byte, 0, len(payload))
+	for _, message := range bytes.Split(payload, []byte{'\n'}) {
+		if !f.Matches(message) {
+			continue
+		}
+		if len(filtered) > 0 {
+			filtered = append(filtered, '\n')
+		}
+		filtered = append(filtered, message...)
+	}
+	return filtered
+}
+
+// Matches returns whether a DogStatsD message is selected by the filter.
+func (f *TrafficFilter) Matches(message []byte) bool {
+	if f == nil {
+		return true
+	}
+	name, tags, ok := parseMetric(message)
+	if !ok || !matchAny(f.names, name) {
+		return false
+	}
+	if len(f.tags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if matchAny(f.tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []pattern, value []byte) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if p.prefix && bytes.HasPrefix(value, p.value) || bytes.Equal(value, p.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMetric returns the name and the tags of a DogStatsD metric message, and false if the
+// message is not a metric.
+func parseMetric(message []byte) ([]byte, [][]byte, bool) {
+	message = bytes.TrimSpace(message)
+	if bytes.HasPrefix(message, []byte("_e{")) || bytes.HasPrefix(message, []byte("_sc|")) {
+		return nil, nil, false
+	}
+	nameEnd := bytes.IndexByte(message, ':')
+	if nameEnd <= 0 {
+		return nil, nil, false
+	}
+
+	var tags [][]byte
+	for _, field := range bytes.Split(message[nameEnd+1:], []byte{'|'})[1:] {
+		if len(field) > 0 && field[0] == '#' {
+			tags = bytes.Split(field[1:], []byte{','})
+			break
+		}
+	}
+	return message[:nameEnd], tags, true
+}
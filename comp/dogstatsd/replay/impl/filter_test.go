@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package replayimpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrafficFilter(t *testing.T) {
+	payload := []byte("app.requests:1|c|#env:prod,service:web\n" +
+		"app.latency:3|h|@0.5|#service:api\n" +
+		"system.load:1|g\n" +
+		"_e{5,4}:title|text|#service:web\n" +
+		"_sc|check|0|#service:web")
+
+	assert.Nil(t, NewTrafficFilter(nil, nil))
+	var filter *TrafficFilter
+	assert.Equal(t, payload, filter.Filter(payload))
+
+	filter = NewTrafficFilter([]string{"app.*"}, nil)
+	assert.Equal(t, "app.requests:1|c|#env:prod,service:web\napp.latency:3|h|@0.5|#service:api", string(filter.Filter(payload)))
+
+	filter = NewTrafficFilter(nil, []string{"service:web"})
+	assert.Equal(t, "app.requests:1|c|#env:prod,service:web", string(filter.Filter(payload)))
+
+	filter = NewTrafficFilter([]string{"system.load", "app.latency"}, []string{"env:*", "service:api"})
+	assert.Equal(t, "app.latency:3|h|@0.5|#service:api", string(filter.Filter(payload)))
+
+	filter = NewTrafficFilter([]string{"unknown"}, nil)
+	assert.Empty(t, filter.Filter(payload))
+}
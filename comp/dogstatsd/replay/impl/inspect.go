@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package replayimpl
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CaptureSummary summarises the traffic of a capture.
+type CaptureSummary struct {
+	Packets  int
+	Messages int
+	Bytes    int
+	Duration time.Duration
+	// Contexts is the number of distinct metric contexts, from the names and tags of the metrics
+	Contexts int
+
+	// PacketSizes holds the minimum, median, 99th percentile and maximum packet sizes in bytes
+	PacketSizes PacketSizes
+	// Metrics holds the metrics sorted by decreasing number of messages
+	Metrics []MetricSummary
+	// Origins holds the origins of the packets sorted by decreasing number of packets
+	Origins []OriginSummary
+}
+
+// PacketSizes summarises the sizes of the packets of a capture.
+type PacketSizes struct {
+	Min    int
+	Median int
+	P99    int
+	Max    int
+}
+
+// MetricSummary summarises the messages of a metric.
+type MetricSummary struct {
+	Name     string
+	Messages int
+	Contexts int
+}
+
+// OriginSummary summarises the packets of an origin, which is the container of the sender when
+// the capture holds it, or its PID otherwise.
+type OriginSummary struct {
+	Origin   string
+	Packets  int
+	Messages int
+}
+
+// InspectCapture reads all the packets of a capture and summarises them. Only the metrics
+// selected by the filter are accounted for, a nil filter selecting them all.
+func InspectCapture(tc *TrafficCaptureReader, filter *TrafficFilter) (*CaptureSummary, error) {
+	// the state is optional, the PIDs are used as origins without it
+	pidMap, _, _ := tc.ReadState()
+
+	tc.Lock()
+	tsResolution := time.Nanosecond
+	if tc.Version < minNanoVersion {
+		tsResolution = time.Second
+	}
+	tc.Unlock()
+
+	summary := &CaptureSummary{}
+	metrics := make(map[string]*MetricSummary)
+	origins := make(map[string]*OriginSummary)
+	contexts := make(map[string]struct{})
+	var sizes []int
+	var first, last int64
+
+	tc.Seek(0)
+	for {
+		msg, err := tc.ReadNext()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		payload := msg.Payload[:msg.PayloadSize]
+		messages := 0
+		for _, message := range bytes.Split(payload, []byte{'\n'}) {
+			if len(bytes.TrimSpace(message)) == 0 || !filter.Matches(message) {
+				continue
+			}
+			messages++
+
+			name, tags, ok := parseMetric(message)
+			if !ok {
+				continue
+			}
+			m, ok := metrics[string(name)]
+			if !ok {
+				m = &MetricSummary{Name: string(name)}
+				metrics[m.Name] = m
+			}
+			m.Messages++
+			if key := contextKey(name, tags); !hasContext(contexts, key) {
+				contexts[key] = struct{}{}
+				m.Contexts++
+			}
+		}
+		if messages == 0 {
+			continue
+		}
+
+		if first == 0 {
+			first = msg.Timestamp
+		}
+		last = msg.Timestamp
+		summary.Packets++
+		summary.Messages += messages
+		summary.Bytes += len(payload)
+		sizes = append(sizes, len(payload))
+
+		origin, ok := pidMap[msg.Pid]
+		if !ok {
+			origin = "pid:" + strconv.Itoa(int(msg.Pid))
+		}
+		o, ok := origins[origin]
+		if !ok {
+			o = &OriginSummary{Origin: origin}
+			origins[origin] = o
+		}
+		o.Packets++
+		o.Messages += messages
+	}
+
+	summary.Duration = time.Duration(last-first) * tsResolution
+	summary.Contexts = len(contexts)
+	if len(sizes) > 0 {
+		sort.Ints(sizes)
+		summary.PacketSizes = PacketSizes{
+			Min:    sizes[0],
+			Median: sizes[len(sizes)/2],
+			P99:    sizes[len(sizes)*99/100],
+			Max:    sizes[len(sizes)-1],
+		}
+	}
+
+	for _, m := range metrics {
+		summary.Metrics = append(summary.Metrics, *m)
+	}
+	sort.Slice(summary.Metrics, func(i, j int) bool {
+		a, b := summary.Metrics[i], summary.Metrics[j]
+		return a.Messages > b.Messages || a.Messages == b.Messages && a.Name < b.Name
+	})
+	for _, o := range origins {
+		summary.Origins = append(summary.Origins, *o)
+	}
+	sort.Slice(summary.Origins, func(i, j int) bool {
+		a, b := summary.Origins[i], summary.Origins[j]
+		return a.Packets > b.Packets || a.Packets == b.Packets && a.Origin < b.Origin
+	})
+	return summary, nil
+}
+
+// contextKey returns a key identifying the context of a metric, regardless of the order of its tags.
+func contextKey(name []byte, tags [][]byte) string {
+	sorted := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		sorted = append(sorted, string(tag))
+	}
+	sort.Strings(sorted)
+
+	var b bytes.Buffer
+	b.Write(name)
+	for _, tag := range sorted {
+		b.WriteByte('|')
+		b.WriteString(tag)
+	}
+	return b.String()
+}
+
+func hasContext(contexts map[string]struct{}, key string) bool {
+	_, ok := contexts[key]
+	return ok
+}
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package replayimpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectCapture(t *testing.T) {
+	tc, err := NewTrafficCaptureReader("resources/test/datadog-capture.dog.zstd", 1, false)
+	require.NoError(t, err)
+	defer tc.Close()
+
+	summary, err := InspectCapture(tc, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 21, summary.Packets)
+	assert.Equal(t, 21, summary.Messages)
+	assert.Equal(t, 21*30, summary.Bytes)
+	assert.Equal(t, 13*time.Second, summary.Duration)
+	assert.Equal(t, 1, summary.Contexts)
+	assert.Equal(t, PacketSizes{Min: 30, Median: 30, P99: 30, Max: 30}, summary.PacketSizes)
+	assert.Equal(t, []MetricSummary{{Name: "jaime.uds.test", Messages: 21, Contexts: 1}}, summary.Metrics)
+
+	// the senders of the packets are resolved to their containers thanks to the state of the capture
+	require.Len(t, summary.Origins, 15)
+	assert.Equal(t, OriginSummary{
+		Origin:   "container_id://c1371eaf97a11f43ac700fd8524b4ea316d83a7259282a9e9eeac8d071406b22",
+		Packets:  7,
+		Messages: 7,
+	}, summary.Origins[0])
+	assert.Equal(t, OriginSummary{Origin: "pid:2809", Packets: 1, Messages: 1}, summary.Origins[1])
+
+	// the capture can be inspected again, and filtered
+	summary, err = InspectCapture(tc, NewTrafficFilter(nil, []string{"shell:prod"}))
+	require.NoError(t, err)
+	assert.Zero(t, summary.Packets)
+	assert.Empty(t, summary.Metrics)
+}
@@ -28,6 +28,8 @@ type TrafficCaptureReader struct {
 	fuse        chan struct{}
 	offset      uint32
 	mmap        bool
+	// speed multiplies the pace of the replay, 0 means the recorded pace
+	speed float64
 
 	sync.Mutex
 }
@@ -50,6 +52,7 @@ func (tc *TrafficCaptureReader) Read(ready chan struct{}) {
 	} else {
 		tsResolution = time.Nanosecond
 	}
+	speed := tc.speed
 	tc.Unlock()
 
 	first := int64(0)
@@ -76,6 +79,9 @@ func (tc *TrafficCaptureReader) Read(ready chan struct{}) {
 		}
 
 		t := time.Duration(msg.Timestamp-first) * tsResolution
+		if speed > 0 {
+			t = time.Duration(float64(t) / speed)
+		}
 		time.Sleep(t - time.Since(start))
 
 		tc.Traffic <- msg
@@ -89,6 +95,14 @@ func (tc *TrafficCaptureReader) Read(ready chan struct{}) {
 	}
 }
 
+// SetSpeed sets the multiplier of the pace at which Read writes the packets, 1 being the
+// recorded pace. It must be called before Read.
+func (tc *TrafficCaptureReader) SetSpeed(speed float64) {
+	tc.Lock()
+	defer tc.Unlock()
+	tc.speed = speed
+}
+
 // Close cleans up any resources used by the TrafficCaptureReader, should not normally
 // be called directly.
 func (tc *TrafficCaptureReader) Close() error {
@@ -182,7 +196,6 @@ func (tc *TrafficCaptureReader) ReadState() (map[int32]string, map[string]*pb.En
 	pbState := &pb.TaggerState{}
 	err := proto.Unmarshal(tc.Contents[length-int(sz)-4:length-4], pbState)
 	if err != nil {
-		tc.Unlock()
 		return nil, nil, err
 	}
 
@@ -30,6 +30,7 @@ type enrichConfig struct {
 	metricPrefix              string
 	metricPrefixBlacklist     []string
 	metricBlocklist           blocklist
+	tagFilter                 tagFilter
 	defaultHostname           string
 	entityIDPrecedenceEnabled bool
 	serverlessMode            bool
@@ -121,6 +122,8 @@ func enrichMetricSample(dest []metrics.MetricSample, ddSample dogstatsdMetricSam
 		return []metrics.MetricSample{}
 	}
 
+	tags = conf.tagFilter.apply(metricName, tags)
+
 	if conf.serverlessMode { // we don't want to set the host while running in serverless mode
 		hostnameFromTags = ""
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
@@ -1009,6 +1010,32 @@ func TestMetricBlocklistShouldBlock(t *testing.T) {
 	assert.Equal(t, 0, len(samples))
 }
 
+func TestTagFilterShouldRemoveTags(t *testing.T) {
+	tagFilter, err := newTagFilter([]config.TagFilterRule{
+		{Match: "custom.checkout.*", Deny: []string{"user_id", "session"}},
+	})
+	require.NoError(t, err)
+	conf := enrichConfig{
+		metricPrefix:    "custom.",
+		tagFilter:       tagFilter,
+		defaultHostname: "default",
+	}
+
+	message := []byte("checkout.duration:21|ms|#env:prod,user_id:42,session:abc,host:foo")
+	deps := newServerDeps(t)
+	stringInternerTelemetry := newSiTelemetry(false, deps.Telemetry)
+	parser := newParser(deps.Config, newFloat64ListPool(deps.Telemetry), 1, deps.WMeta, stringInternerTelemetry)
+	parsed, err := parser.parseMetricSample(message)
+	assert.NoError(t, err)
+	samples := []metrics.MetricSample{}
+	samples = enrichMetricSample(samples, parsed, "", "", conf)
+
+	require.Len(t, samples, 1)
+	assert.Equal(t, "custom.checkout.duration", samples[0].Name)
+	assert.Equal(t, []string{"env:prod"}, samples[0].Tags)
+	assert.Equal(t, "foo", samples[0].Host)
+}
+
 func TestServerlessModeShouldSetEmptyHostname(t *testing.T) {
 	conf := enrichConfig{
 		serverlessMode:  true,
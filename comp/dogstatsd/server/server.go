@@ -223,6 +223,15 @@ func newServerCompat(cfg config.Reader, log log.Component, capture replay.Compon
 		cfg.GetBool("statsd_metric_blocklist_match_prefix"),
 	)
 
+	tagFilterRules, err := config.GetDogstatsdTagFilters()
+	if err != nil {
+		log.Errorf("Dogstatsd: unable to read the tag filters: %s", err.Error())
+	}
+	tagFilter, err := newTagFilter(tagFilterRules)
+	if err != nil {
+		log.Errorf("Dogstatsd: ignoring the tag filters: %s", err.Error())
+	}
+
 	defaultHostname, err := hostname.Get(context.TODO())
 	if err != nil {
 		log.Errorf("Dogstatsd: unable to determine default hostname: %s", err.Error())
@@ -296,6 +305,7 @@ func newServerCompat(cfg config.Reader, log log.Component, capture replay.Compon
 			metricPrefix:              metricPrefix,
 			metricPrefixBlacklist:     metricPrefixBlacklist,
 			metricBlocklist:           metricBlocklist,
+			tagFilter:                 tagFilter,
 			entityIDPrecedenceEnabled: entityIDPrecedenceEnabled,
 			defaultHostname:           defaultHostname,
 			serverlessMode:            serverless,
@@ -400,6 +410,15 @@ func (s *server) start(context.Context) error {
 		}
 	}
 
+	if s.config.GetString("dogstatsd_tcp_port") == listeners.RandomPortName || s.config.GetInt("dogstatsd_tcp_port") > 0 {
+		tcpListener, err := listeners.NewTCPListener(packetsChannel, sharedPacketPoolManager, s.config, s.listernersTelemetry, s.packetsTelemetry)
+		if err != nil {
+			s.log.Errorf("Can't init TCP listener: %s", err.Error())
+		} else {
+			tmpListeners = append(tmpListeners, tcpListener)
+		}
+	}
+
 	pipeName := s.config.GetString("dogstatsd_pipe_name")
 	if len(pipeName) > 0 {
 		namedPipeListener, err := listeners.NewNamedPipeListener(pipeName, packetsChannel, sharedPacketPoolManager, s.config, s.tCapture, s.listernersTelemetry, s.packetsTelemetry, s.telemetry)
@@ -620,6 +639,9 @@ func (s *server) eolEnabled(sourceType packets.SourceType) bool {
 		return s.eolTerminationUDP
 	case packets.NamedPipe:
 		return s.eolTerminationNamedPipe
+	case packets.TCP:
+		// the TCP listener only forwards complete messages
+		return false
 	}
 	return false
 }
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// tagFilter removes tags from the metrics according to the keys allowed or denied
+// by the rules matching their name.
+type tagFilter struct {
+	rules []tagFilterRule
+}
+
+type tagFilterRule struct {
+	// prefix is the literal part of the pattern before its first wildcard, it is
+	// checked before the regexp to skip most of the names not matching the rule.
+	prefix  string
+	pattern *regexp.Regexp
+	allow   map[string]struct{}
+	deny    map[string]struct{}
+}
+
+func newTagFilter(rules []config.TagFilterRule) (tagFilter, error) {
+	f := tagFilter{}
+	for _, r := range rules {
+		if r.Match == "" {
+			return tagFilter{}, fmt.Errorf("tag filter rule with no match pattern")
+		}
+		if len(r.Allow) == 0 && len(r.Deny) == 0 {
+			return tagFilter{}, fmt.Errorf("tag filter rule %q with neither allow nor deny list", r.Match)
+		}
+
+		parts := strings.Split(r.Match, "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		pattern, err := regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+		if err != nil {
+			return tagFilter{}, fmt.Errorf("invalid tag filter pattern %q: %s", r.Match, err)
+		}
+
+		rule := tagFilterRule{
+			prefix:  strings.SplitN(r.Match, "*", 2)[0],
+			pattern: pattern,
+		}
+		if len(r.Allow) > 0 {
+			rule.allow = toKeySet(r.Allow)
+		}
+		if len(r.Deny) > 0 {
+			rule.deny = toKeySet(r.Deny)
+		}
+		f.rules = append(f.rules, rule)
+	}
+	return f, nil
+}
+
+func toKeySet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	return set
+}
+
+func (r *tagFilterRule) matches(name string) bool {
+	return strings.HasPrefix(name, r.prefix) && r.pattern.MatchString(name)
+}
+
+// keep returns whether a tag is kept by the rule.
+func (r *tagFilterRule) keep(tag string) bool {
+	key := tag
+	if i := strings.IndexByte(tag, ':'); i >= 0 {
+		key = tag[:i]
+	}
+	if r.allow != nil {
+		if _, ok := r.allow[key]; !ok {
+			return false
+		}
+	}
+	_, denied := r.deny[key]
+	return !denied
+}
+
+// apply removes in place the tags filtered out by the rules matching the metric
+// name and returns the remaining tags.
+func (f *tagFilter) apply(name string, tags []string) []string {
+	for i := range f.rules {
+		rule := &f.rules[i]
+		if !rule.matches(name) {
+			continue
+		}
+		n := 0
+		for _, tag := range tags {
+			if rule.keep(tag) {
+				tags[n] = tag
+				n++
+			}
+		}
+		tags = tags[:n]
+	}
+	return tags
+}
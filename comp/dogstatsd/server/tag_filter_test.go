@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestTagFilter(t *testing.T) {
+	f, err := newTagFilter([]config.TagFilterRule{
+		{Match: "checkout.*", Deny: []string{"user_id", "session"}},
+		{Match: "payment.latency", Allow: []string{"env", "service", "region"}},
+		{Match: "*.region.*", Deny: []string{"region"}},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{"checkout.cart.size", []string{"env:prod", "user_id:42", "session:abc", "session"}, []string{"env:prod"}},
+		{"checkout", []string{"env:prod", "user_id:42"}, []string{"env:prod", "user_id:42"}},
+		{"payment.latency", []string{"env:prod", "user_id:42", "service:api", "region:us"}, []string{"env:prod", "service:api", "region:us"}},
+		{"payment.latency.p99", []string{"env:prod", "user_id:42"}, []string{"env:prod", "user_id:42"}},
+		// every matching rule is applied
+		{"checkout.region.eu", []string{"region:eu", "user_id:42", "env:prod"}, []string{"env:prod"}},
+		{"other.metric", []string{"user_id:42"}, []string{"user_id:42"}},
+		{"checkout.empty", []string{}, []string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, f.apply(tc.name, tc.tags))
+		})
+	}
+}
+
+func TestTagFilterSpecialCharacters(t *testing.T) {
+	f, err := newTagFilter([]config.TagFilterRule{{Match: "app.(v1).*", Deny: []string{"user_id"}}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{}, f.apply("app.(v1).requests", []string{"user_id:42"}))
+	assert.Equal(t, []string{"user_id:42"}, f.apply("app.v1.requests", []string{"user_id:42"}))
+}
+
+func TestTagFilterInvalidRules(t *testing.T) {
+	_, err := newTagFilter([]config.TagFilterRule{{Deny: []string{"user_id"}}})
+	assert.Error(t, err)
+	_, err = newTagFilter([]config.TagFilterRule{{Match: "checkout.*"}})
+	assert.Error(t, err)
+}
+
+func TestEmptyTagFilter(t *testing.T) {
+	f, err := newTagFilter(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_id:42"}, f.apply("checkout.cart.size", []string{"user_id:42"}))
+}
@@ -105,6 +105,7 @@ type Options struct {
 	DomainResolvers                map[string]resolver.DomainResolver
 	ConnectionResetInterval        time.Duration
 	CompletionHandler              transaction.HTTPCompletionHandler
+	MetricRoutes                   *MetricRoutes
 }
 
 // SetFeature sets forwarder features in a feature set
@@ -121,6 +122,7 @@ func HasFeature(features, flag Features) bool { return features&flag != 0 }
 
 // NewOptions creates new Options with default values
 func NewOptions(config config.Component, log log.Component, keysPerDomain map[string][]string) *Options {
+	metricRoutes, keysPerDomain := newMetricRoutes(config, log, keysPerDomain)
 	resolvers := resolver.NewSingleDomainResolvers(keysPerDomain)
 	vectorMetricsURL, err := pkgconfigsetup.GetObsPipelineURL(pkgconfigsetup.Metrics, config)
 	if err != nil {
@@ -134,10 +136,13 @@ func NewOptions(config config.Component, log log.Component, keysPerDomain map[st
 			vectorMetricsURL,
 		)
 	}
-	return NewOptionsWithResolvers(config, log, resolvers)
+	options := NewOptionsWithResolvers(config, log, resolvers)
+	options.MetricRoutes = metricRoutes
+	return options
 }
 
-// NewOptionsWithResolvers creates new Options with default values
+// NewOptionsWithResolvers creates new Options with default values. The metric routes are not
+// set, the forwarders sending series and sketches must be created with NewOptions or NewParamsWithResolvers.
 func NewOptionsWithResolvers(config config.Component, log log.Component, domainResolvers map[string]resolver.DomainResolver) *Options {
 	validationInterval := config.GetInt("forwarder_apikey_validation_interval")
 	if validationInterval <= 0 {
@@ -206,6 +211,7 @@ type DefaultForwarder struct {
 	m                sync.Mutex // To control Start/Stop races
 
 	completionHandler transaction.HTTPCompletionHandler
+	metricRoutes      *MetricRoutes
 
 	agentName                       string
 	queueDurationCapacity           *retry.QueueDurationCapacity
@@ -231,33 +237,33 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 			validationInterval:    options.APIKeyValidationInterval,
 		},
 		completionHandler: options.CompletionHandler,
+		metricRoutes:      options.MetricRoutes,
 		agentName:         agentName,
 	}
 	var optionalRemovalPolicy *retry.FileRemovalPolicy
 	storageMaxSize := config.GetInt64("forwarder_storage_max_size_in_bytes")
 	var diskUsageLimit *retry.DiskUsageLimit
+	var auditLog *retry.AuditLog
 
 	// Disk Persistence is a core-only feature for now.
 	if storageMaxSize == 0 {
 		log.Infof("Retry queue storage on disk is disabled")
 	} else if agentName != "" {
-		storagePath := config.GetString("forwarder_storage_path")
-		if storagePath == "" {
-			storagePath = path.Join(config.GetString("run_path"), "transactions_to_retry")
-		}
+		storagePath := getRetryStoragePath(config, agentName)
 		outdatedFileInDays := config.GetInt("forwarder_outdated_file_in_days")
 		var err error
 
-		storagePath = path.Join(storagePath, agentName)
 		optionalRemovalPolicy, err = retry.NewFileRemovalPolicy(storagePath, outdatedFileInDays, retry.FileRemovalPolicyTelemetry{})
 		if err != nil {
 			log.Errorf("Error when initializing the removal policy: %v", err)
 		} else {
+			auditLog = retry.NewAuditLog(storagePath)
 			filesRemoved, err := optionalRemovalPolicy.RemoveOutdatedFiles()
 			if err != nil {
 				log.Errorf("Error when removing outdated files: %v", err)
 			}
 			log.Debugf("Outdated files removed: %v", strings.Join(filesRemoved, ", "))
+			recordDroppedFiles(log, auditLog, filesRemoved, retry.AuditReasonOutdated)
 		}
 
 		diskRatio := config.GetFloat64("forwarder_storage_max_disk_ratio")
@@ -308,7 +314,8 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 				diskUsageLimit,
 				transactionContainerSort,
 				resolver,
-				pointCountTelemetry)
+				pointCountTelemetry,
+				auditLog)
 			f.domainResolvers[domain] = resolver
 			fwd := newDomainForwarder(
 				config,
@@ -356,11 +363,35 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 			log.Errorf("Error when removing outdated files: %v", err)
 		}
 		log.Debugf("Outdated files removed: %v", strings.Join(filesRemoved, ", "))
+		recordDroppedFiles(log, auditLog, filesRemoved, retry.AuditReasonUnknownDomain)
 	}
 
 	return f
 }
 
+// getRetryStoragePath returns the root folder of the on-disk retry queue of an agent.
+func getRetryStoragePath(config config.Component, agentName string) string {
+	storagePath := config.GetString("forwarder_storage_path")
+	if storagePath == "" {
+		storagePath = path.Join(config.GetString("run_path"), "transactions_to_retry")
+	}
+	return path.Join(storagePath, agentName)
+}
+
+// recordDroppedFiles records in the audit log the files removed by the removal policy.
+func recordDroppedFiles(log log.Component, auditLog *retry.AuditLog, files []string, reason string) {
+	for _, file := range files {
+		err := auditLog.Record(retry.AuditEvent{
+			Event:  retry.AuditEventDropped,
+			Reason: reason,
+			File:   path.Base(file),
+		})
+		if err != nil {
+			log.Warnf("Cannot record the removal of the file %v in the audit log: %v", file, err)
+		}
+	}
+}
+
 func getAgentName(options *Options) string {
 	if HasFeature(options.EnabledFeatures, CoreFeatures) {
 		return "core"
@@ -467,7 +498,7 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 
 	for _, payload := range payloads {
 		for domain, dr := range f.domainResolvers {
-			for _, apiKey := range dr.GetAPIKeys() {
+			for _, apiKey := range f.metricRoutes.apiKeys(domain, dr.GetAPIKeys(), payload.Route) {
 				t := transaction.NewHTTPTransaction()
 				t.Domain, _ = dr.Resolve(endpoint)
 				t.Endpoint = endpoint
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package retry
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	auditLogFilename       = "audit.log"
+	auditLogMaxSizeInBytes = 10 * 1024 * 1024
+)
+
+// The events recorded in the audit log
+const (
+	// AuditEventStored is recorded when transactions are stored in a file
+	AuditEventStored = "stored"
+	// AuditEventRetried is recorded when the transactions of a file are loaded back in memory to be retried
+	AuditEventRetried = "retried"
+	// AuditEventDropped is recorded when the transactions of a file are dropped
+	AuditEventDropped = "dropped"
+	// AuditEventReplayed is recorded when the transactions of a file are sent manually
+	AuditEventReplayed = "replayed"
+	// AuditEventPurged is recorded when a file is removed manually
+	AuditEventPurged = "purged"
+)
+
+// The reasons of the dropped events
+const (
+	// AuditReasonDiskFull means that the file was removed to make room for newer transactions
+	AuditReasonDiskFull = "disk_full"
+	// AuditReasonOutdated means that the file was older than `forwarder_outdated_file_in_days`
+	AuditReasonOutdated = "outdated"
+	// AuditReasonUnknownDomain means that the domain of the file is not configured anymore
+	AuditReasonUnknownDomain = "unknown_domain"
+	// AuditReasonUnreadable means that the file couldn't be read or deserialized
+	AuditReasonUnreadable = "unreadable"
+	// AuditReasonRejected means that the intake rejected the transactions
+	AuditReasonRejected = "rejected"
+	// AuditReasonMemoryFull means that the transactions were removed from the in-memory retry
+	// queue to make room for newer transactions
+	AuditReasonMemoryFull = "memory_full"
+	// AuditReasonStoreFailed means that the transactions couldn't be stored on disk
+	AuditReasonStoreFailed = "store_failed"
+)
+
+// AuditEvent is an entry of the audit log.
+type AuditEvent struct {
+	Time         time.Time `json:"time"`
+	Event        string    `json:"event"`
+	Reason       string    `json:"reason,omitempty"`
+	Domain       string    `json:"domain,omitempty"`
+	File         string    `json:"file,omitempty"`
+	Transactions int       `json:"transactions,omitempty"`
+	Points       int       `json:"points,omitempty"`
+	Bytes        int64     `json:"bytes,omitempty"`
+}
+
+// AuditLog records the lifecycle of the files of the on-disk retry queue, so that it is possible to
+// know which transactions were retried and which ones were dropped after an outage.
+//
+// The events are appended to a file as JSON lines. When the file exceeds its maximum size, it is
+// rotated to a single backup file.
+type AuditLog struct {
+	path           string
+	maxSizeInBytes int64
+	m              sync.Mutex
+}
+
+// NewAuditLog creates a new instance of AuditLog writing in the root folder of the retry queue.
+func NewAuditLog(rootPath string) *AuditLog {
+	return &AuditLog{
+		path:           path.Join(rootPath, auditLogFilename),
+		maxSizeInBytes: auditLogMaxSizeInBytes,
+	}
+}
+
+// Record appends an event to the audit log, setting its time if it is not set.
+// A nil AuditLog doesn't record anything.
+func (a *AuditLog) Record(event AuditEvent) error {
+	if a == nil {
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if info, err := os.Stat(a.path); err == nil && info.Size()+int64(len(line)) > a.maxSizeInBytes {
+		if err := os.Rename(a.path, a.path+".1"); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadAuditLog returns the events of the audit log in the root folder of the retry queue, oldest
+// first. The lines which can't be parsed are ignored.
+func ReadAuditLog(rootPath string) ([]AuditEvent, error) {
+	filename := path.Join(rootPath, auditLogFilename)
+	var events []AuditEvent
+	for _, p := range []string{filename + ".1", filename} {
+		file, err := os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var event AuditEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+				events = append(events, event)
+			}
+		}
+		err = scanner.Err()
+		_ = file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
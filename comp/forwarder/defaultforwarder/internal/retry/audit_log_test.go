@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package retry
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogRotation(t *testing.T) {
+	root := t.TempDir()
+	auditLog := NewAuditLog(root)
+	auditLog.maxSizeInBytes = 300
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, auditLog.Record(AuditEvent{Event: AuditEventStored, File: "file", Transactions: i}))
+	}
+	info, err := os.Stat(path.Join(root, auditLogFilename))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(300))
+	_, err = os.Stat(path.Join(root, auditLogFilename+".1"))
+	require.NoError(t, err)
+
+	// the events of the backup file are returned first
+	events, err := ReadAuditLog(root)
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	assert.Equal(t, 4, events[len(events)-1].Transactions)
+	for i := 1; i < len(events); i++ {
+		assert.Less(t, events[i-1].Transactions, events[i].Transactions)
+		assert.False(t, events[i].Time.IsZero())
+	}
+}
+
+func TestAuditLogNil(t *testing.T) {
+	var auditLog *AuditLog
+	assert.NoError(t, auditLog.Record(AuditEvent{Event: AuditEventStored}))
+
+	events, err := ReadAuditLog(t.TempDir())
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
@@ -6,9 +6,6 @@
 package retry
 
 import (
-	"crypto/md5"
-	"fmt"
-	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -108,13 +105,10 @@ func (p *FileRemovalPolicy) forEachDomainPath(callback func(folderPath string) (
 }
 
 func (p *FileRemovalPolicy) getFolderPathForDomain(domainName string) (string, error) {
-	// Use md5 for the folder name as the domainName is an url which can contain invalid charaters for a file path.
-	h := md5.New()
-	if _, err := io.WriteString(h, domainName); err != nil {
+	folder, err := DomainFolderName(domainName)
+	if err != nil {
 		return "", err
 	}
-	folder := fmt.Sprintf("%x", h.Sum(nil))
-
 	return path.Join(p.rootPath, folder), nil
 }
 
@@ -109,6 +109,15 @@ func (s *HTTPTransactionsSerializer) GetBytesAndReset() ([]byte, error) {
 	return out, err
 }
 
+// count returns the number of transactions added since the last reset, and their number of points.
+func (s *HTTPTransactionsSerializer) count() (int, int) {
+	points := 0
+	for _, tr := range s.collection.Values {
+		points += int(tr.PointCount)
+	}
+	return len(s.collection.Values), points
+}
+
 // Deserialize deserializes from bytes.
 func (s *HTTPTransactionsSerializer) Deserialize(bytes []byte) ([]transaction.Transaction, int, error) {
 	collection := HttpTransactionProtoCollection{}
@@ -30,6 +30,8 @@ type onDiskRetryQueue struct {
 	currentSizeInBytes  int64
 	telemetry           onDiskRetryQueueTelemetry
 	pointCountTelemetry *PointCountTelemetry
+	domain              string
+	auditLog            *AuditLog
 }
 
 func newOnDiskRetryQueue(
@@ -38,7 +40,9 @@ func newOnDiskRetryQueue(
 	storagePath string,
 	diskUsageLimit *DiskUsageLimit,
 	telemetry onDiskRetryQueueTelemetry,
-	pointCountTelemetry *PointCountTelemetry) (*onDiskRetryQueue, error) {
+	pointCountTelemetry *PointCountTelemetry,
+	domain string,
+	optionalAuditLog *AuditLog) (*onDiskRetryQueue, error) {
 
 	if err := os.MkdirAll(storagePath, 0700); err != nil {
 		return nil, err
@@ -51,6 +55,8 @@ func newOnDiskRetryQueue(
 		diskUsageLimit:      diskUsageLimit,
 		telemetry:           telemetry,
 		pointCountTelemetry: pointCountTelemetry,
+		domain:              domain,
+		auditLog:            optionalAuditLog,
 	}
 
 	if err := storage.reloadExistingRetryFiles(); err != nil {
@@ -78,6 +84,7 @@ func (s *onDiskRetryQueue) Store(transactions []transaction.Transaction) error {
 		}
 	}
 
+	transactionCount, pointCount := s.serializer.count()
 	bytes, err := s.serializer.GetBytesAndReset()
 	if err != nil {
 		return err
@@ -105,6 +112,13 @@ func (s *onDiskRetryQueue) Store(transactions []transaction.Transaction) error {
 	}
 	s.currentSizeInBytes += bufferSize
 	s.filenames = append(s.filenames, file.Name())
+	s.recordAuditEvent(AuditEvent{
+		Event:        AuditEventStored,
+		File:         file.Name(),
+		Transactions: transactionCount,
+		Points:       pointCount,
+		Bytes:        bufferSize,
+	})
 	s.telemetry.setFileSize(bufferSize)
 	s.telemetry.setCurrentSizeInBytes(s.GetDiskSpaceUsed())
 	s.telemetry.setFilesCount(s.getFilesCount())
@@ -127,13 +141,22 @@ func (s *onDiskRetryQueue) ExtractLast() ([]transaction.Transaction, error) {
 	}
 
 	if err != nil {
+		s.recordAuditEvent(AuditEvent{Event: AuditEventDropped, Reason: AuditReasonUnreadable, File: path})
 		return nil, err
 	}
 
 	transactions, errorsCount, err := s.serializer.Deserialize(bytes)
 	if err != nil {
+		s.recordAuditEvent(AuditEvent{Event: AuditEventDropped, Reason: AuditReasonUnreadable, File: path, Bytes: int64(len(bytes))})
 		return nil, err
 	}
+	s.recordAuditEvent(AuditEvent{
+		Event:        AuditEventRetried,
+		File:         path,
+		Transactions: len(transactions),
+		Points:       getPointCount(transactions),
+		Bytes:        int64(len(bytes)),
+	})
 	s.telemetry.addDeserializeErrorsCount(errorsCount)
 	s.telemetry.addDeserializeTransactionsCount(len(transactions))
 	s.telemetry.setCurrentSizeInBytes(s.GetDiskSpaceUsed())
@@ -166,18 +189,18 @@ func (s *onDiskRetryQueue) makeRoomFor(bufferSize int64) error {
 		filename := s.filenames[index]
 		s.log.Errorf("Maximum disk space for retry transactions is reached. Removing %s", filename)
 
+		event := AuditEvent{Event: AuditEventDropped, Reason: AuditReasonDiskFull, File: filename}
 		bytes, err := os.ReadFile(filename)
 		if err != nil {
 			s.log.Errorf("Cannot read the file %v: %v", filename, err)
 		} else if transactions, _, errDeserialize := s.serializer.Deserialize(bytes); errDeserialize == nil {
-			pointDroppedCount := 0
-			for _, tr := range transactions {
-				pointDroppedCount += tr.GetPointCount()
-			}
+			pointDroppedCount := getPointCount(transactions)
 			s.onPointDropped(pointDroppedCount)
+			event.Transactions, event.Points, event.Bytes = len(transactions), pointDroppedCount, int64(len(bytes))
 		} else {
 			s.log.Errorf("Cannot deserialize the content of file %v: %v", filename, errDeserialize)
 		}
+		s.recordAuditEvent(event)
 
 		if err := s.removeFileAt(index); err != nil {
 			return err
@@ -193,6 +216,22 @@ func (s *onDiskRetryQueue) onPointDropped(count int) {
 	s.pointCountTelemetry.OnPointDropped(count)
 }
 
+func (s *onDiskRetryQueue) recordAuditEvent(event AuditEvent) {
+	event.Domain = s.domain
+	event.File = filepath.Base(event.File)
+	if err := s.auditLog.Record(event); err != nil {
+		s.log.Warnf("Cannot record the event %q of the file %v in the audit log: %v", event.Event, event.File, err)
+	}
+}
+
+func getPointCount(transactions []transaction.Transaction) int {
+	count := 0
+	for _, tr := range transactions {
+		count += tr.GetPointCount()
+	}
+	return count
+}
+
 func (s *onDiskRetryQueue) removeFileAt(index int) error {
 	filename := s.filenames[index]
 
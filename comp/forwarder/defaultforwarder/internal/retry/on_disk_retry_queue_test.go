@@ -8,6 +8,7 @@
 package retry
 
 import (
+	"path"
 	"strconv"
 	"testing"
 
@@ -134,7 +135,43 @@ func newTestOnDiskRetryQueue(t *testing.T, a *assert.Assertions, path string, ma
 		}}
 	diskUsageLimit := NewDiskUsageLimit("", disk, maxSizeInBytes, 1)
 	log := logmock.New(t)
-	storage, err := newOnDiskRetryQueue(log, NewHTTPTransactionsSerializer(log, resolver.NewSingleDomainResolver(domainName, nil)), path, diskUsageLimit, telemetry, NewPointCountTelemetryMock())
+	storage, err := newOnDiskRetryQueue(log, NewHTTPTransactionsSerializer(log, resolver.NewSingleDomainResolver(domainName, nil)), path, diskUsageLimit, telemetry, NewPointCountTelemetryMock(), domainName, nil)
 	a.NoError(err)
 	return storage
 }
+
+func TestOnDiskRetryQueueAuditLog(t *testing.T) {
+	a := assert.New(t)
+	root := t.TempDir()
+	folder, err := DomainFolderName(domainName)
+	a.NoError(err)
+
+	q := newTestOnDiskRetryQueue(t, a, path.Join(root, folder), 1000)
+	q.auditLog = NewAuditLog(root)
+	a.NoError(q.Store(createHTTPTransactionCollectionTests("endpoint1", "endpoint2")))
+
+	files, err := ListStoredFiles(root)
+	a.NoError(err)
+	a.Len(files, 1)
+	a.Equal(folder, files[0].DomainFolder)
+	stored, err := ReadStoredTransactions(files[0].Path)
+	a.NoError(err)
+	a.Len(stored, 2)
+	a.Equal("endpoint1", stored[0].Endpoint)
+	a.Equal(1, stored[0].PointCount)
+
+	_, err = q.ExtractLast()
+	a.NoError(err)
+
+	events, err := ReadAuditLog(root)
+	a.NoError(err)
+	a.Len(events, 2)
+	a.Equal(AuditEventStored, events[0].Event)
+	a.Equal(AuditEventRetried, events[1].Event)
+	for _, event := range events {
+		a.Equal(domainName, event.Domain)
+		a.Equal(path.Base(files[0].Path), event.File)
+		a.Equal(2, event.Transactions)
+		a.Equal(2, event.Points)
+	}
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package retry
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+)
+
+// StoredFile is a file of the on-disk retry queue.
+type StoredFile struct {
+	Path string
+	// DomainFolder is the name of the folder of the domain of the transactions
+	DomainFolder string
+	ModTime      time.Time
+	Size         int64
+}
+
+// StoredTransaction is a transaction of a file of the on-disk retry queue, as it is stored: the
+// API keys of its route and headers are replaced with placeholders.
+type StoredTransaction struct {
+	Endpoint   string
+	Route      string
+	Headers    http.Header
+	Payload    []byte
+	CreatedAt  time.Time
+	ErrorCount int
+	PointCount int
+	Priority   transaction.Priority
+	Retryable  bool
+}
+
+// DomainFolderName returns the name of the folder of the on-disk retry queue of a domain.
+func DomainFolderName(domain string) (string, error) {
+	// Use md5 for the folder name as the domainName is an url which can contain invalid charaters for a file path.
+	h := md5.New()
+	if _, err := io.WriteString(h, domain); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// ListStoredFiles returns the files of the on-disk retry queue in its root folder, oldest first.
+func ListStoredFiles(rootPath string) ([]StoredFile, error) {
+	domains, err := os.ReadDir(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []StoredFile
+	for _, domain := range domains {
+		if !domain.IsDir() {
+			continue
+		}
+		folderPath := path.Join(rootPath, domain.Name())
+		entries, err := os.ReadDir(folderPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || filepath.Ext(entry.Name()) != retryTransactionsExtension {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			files = append(files, StoredFile{
+				Path:         path.Join(folderPath, entry.Name()),
+				DomainFolder: domain.Name(),
+				ModTime:      info.ModTime(),
+				Size:         info.Size(),
+			})
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime.Before(files[j].ModTime)
+	})
+	return files, nil
+}
+
+// ReadStoredTransactions returns the transactions of a file of the on-disk retry queue.
+func ReadStoredTransactions(filename string) ([]StoredTransaction, error) {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	collection := HttpTransactionProtoCollection{}
+	if err := proto.Unmarshal(bytes, &collection); err != nil {
+		return nil, err
+	}
+
+	transactions := make([]StoredTransaction, 0, len(collection.Values))
+	for _, tr := range collection.Values {
+		priority, err := fromTransactionPriorityProto(tr.Priority)
+		if err != nil {
+			return nil, err
+		}
+		headers := make(http.Header, len(tr.Headers))
+		for key, values := range tr.Headers {
+			headers[key] = values.Values
+		}
+		transactions = append(transactions, StoredTransaction{
+			Endpoint:   tr.Endpoint.GetName(),
+			Route:      tr.Endpoint.GetRoute(),
+			Headers:    headers,
+			Payload:    tr.Payload,
+			CreatedAt:  time.Unix(tr.CreatedAt, 0),
+			ErrorCount: int(tr.ErrorCount),
+			PointCount: int(tr.PointCount),
+			Priority:   priority,
+			Retryable:  tr.Retryable,
+		})
+	}
+	return transactions, nil
+}
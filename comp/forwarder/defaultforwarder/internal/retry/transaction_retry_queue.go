@@ -40,6 +40,11 @@ type TransactionRetryQueue struct {
 	telemetry             TransactionRetryQueueTelemetry
 	pointCountTelemetry   *PointCountTelemetry
 	mutex                 sync.RWMutex
+
+	// optionalAuditLog records the transactions dropped from memory
+	optionalAuditLog *AuditLog
+	domain           string
+	log              log.Component
 }
 
 // BuildTransactionRetryQueue builds a new instance of TransactionRetryQueue
@@ -51,14 +56,15 @@ func BuildTransactionRetryQueue(
 	optionalDiskUsageLimit *DiskUsageLimit,
 	dropPrioritySorter TransactionPrioritySorter,
 	resolver resolver.DomainResolver,
-	pointCountTelemetry *PointCountTelemetry) *TransactionRetryQueue {
+	pointCountTelemetry *PointCountTelemetry,
+	optionalAuditLog *AuditLog) *TransactionRetryQueue {
 	var storage TransactionDiskStorage
 	var err error
 	domain := resolver.GetBaseDomain()
 
 	if optionalDomainFolderPath != "" && optionalDiskUsageLimit != nil {
 		serializer := NewHTTPTransactionsSerializer(log, resolver)
-		storage, err = newOnDiskRetryQueue(log, serializer, optionalDomainFolderPath, optionalDiskUsageLimit, newOnDiskRetryQueueTelemetry(resolver.GetBaseDomain()), pointCountTelemetry)
+		storage, err = newOnDiskRetryQueue(log, serializer, optionalDomainFolderPath, optionalDiskUsageLimit, newOnDiskRetryQueueTelemetry(domain), pointCountTelemetry, domain, optionalAuditLog)
 
 		// If the storage on disk cannot be used, log the error and continue.
 		// Returning `nil, err` would mean not using `TransactionRetryQueue` and so not using `forwarder_retry_queue_payloads_max_size` config.
@@ -67,13 +73,17 @@ func BuildTransactionRetryQueue(
 		}
 	}
 
-	return NewTransactionRetryQueue(
+	queue := NewTransactionRetryQueue(
 		dropPrioritySorter,
 		storage,
 		maxMemSizeInBytes,
 		flushToStorageRatio,
 		NewTransactionRetryQueueTelemetry(domain),
 		pointCountTelemetry)
+	queue.optionalAuditLog = optionalAuditLog
+	queue.domain = domain
+	queue.log = log
+	return queue
 }
 
 // NewTransactionRetryQueue creates a new instance of NewTransactionRetryQueue
@@ -116,11 +126,7 @@ func (tc *TransactionRetryQueue) Add(t transaction.Transaction) (int, error) {
 			if err := tc.optionalStorage.Store(payloads); err != nil {
 				diskErr = multierror.Append(diskErr, err)
 				// Assuming all payloads failed during serialization
-				pointCountDroppped := 0
-				for _, payload := range payloads {
-					pointCountDroppped += payload.GetPointCount()
-				}
-				tc.onDropPoints(pointCountDroppped)
+				tc.onDropTransactions(payloads, AuditReasonStoreFailed)
 			}
 		}
 		if diskErr != nil {
@@ -134,11 +140,7 @@ func (tc *TransactionRetryQueue) Add(t transaction.Transaction) (int, error) {
 	inMemTransactionDroppedCount := 0
 	if payloadSizeInBytesToDrop > 0 {
 		transactions := tc.extractTransactionsFromMemory(payloadSizeInBytesToDrop)
-		pointCountDroppped := 0
-		for _, tr := range transactions {
-			pointCountDroppped += tr.GetPointCount()
-		}
-		tc.onDropPoints(pointCountDroppped)
+		tc.onDropTransactions(transactions, AuditReasonMemoryFull)
 		inMemTransactionDroppedCount = len(transactions)
 		tc.telemetry.addTransactionsDroppedCount(inMemTransactionDroppedCount)
 	}
@@ -151,9 +153,23 @@ func (tc *TransactionRetryQueue) Add(t transaction.Transaction) (int, error) {
 	return inMemTransactionDroppedCount, diskErr
 }
 
-func (tc *TransactionRetryQueue) onDropPoints(count int) {
-	tc.telemetry.addPointDroppedCount(count)
-	tc.pointCountTelemetry.OnPointDropped(count)
+// onDropTransactions counts the points of the dropped transactions and records them in the
+// audit log.
+func (tc *TransactionRetryQueue) onDropTransactions(transactions []transaction.Transaction, reason string) {
+	if len(transactions) == 0 {
+		return
+	}
+	event := AuditEvent{Event: AuditEventDropped, Reason: reason, Domain: tc.domain, Transactions: len(transactions)}
+	for _, tr := range transactions {
+		event.Points += tr.GetPointCount()
+		event.Bytes += int64(tr.GetPayloadSize())
+	}
+	tc.telemetry.addPointDroppedCount(event.Points)
+	tc.pointCountTelemetry.OnPointDropped(event.Points)
+
+	if err := tc.optionalAuditLog.Record(event); err != nil && tc.log != nil {
+		tc.log.Warnf("Cannot record the %d transactions dropped from memory in the audit log: %v", event.Transactions, err)
+	}
 }
 
 // ExtractTransactions extracts transactions from the container.
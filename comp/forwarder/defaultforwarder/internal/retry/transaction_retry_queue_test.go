@@ -124,6 +124,31 @@ func TestTransactionRetryQueueNoTransactionStorage(t *testing.T) {
 	assertPayloadSizeFromExtractTransactions(a, container, []int{11, 30})
 }
 
+func TestTransactionRetryQueueAuditsDrops(t *testing.T) {
+	a := assert.New(t)
+	root := t.TempDir()
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), nil, 50, 0.1, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+	container.optionalAuditLog = NewAuditLog(root)
+	container.domain = "domain"
+
+	for _, payloadSize := range []int{9, 10, 11, 30} {
+		_, err := container.Add(createTransactionWithPayloadSize(payloadSize))
+		a.NoError(err)
+	}
+
+	events, err := ReadAuditLog(root)
+	a.NoError(err)
+	a.Equal([]AuditEvent{{
+		Time:         events[0].Time,
+		Event:        AuditEventDropped,
+		Reason:       AuditReasonMemoryFull,
+		Domain:       "domain",
+		Transactions: 2,
+		Points:       2,
+		Bytes:        9 + 10,
+	}}, events)
+}
+
 func TestTransactionRetryQueueZeroMaxMemSizeInBytes(t *testing.T) {
 	a := assert.New(t)
 	q := newOnDiskRetryQueueTest(t, a)
@@ -186,7 +211,9 @@ func newOnDiskRetryQueueTest(t *testing.T, a *assert.Assertions) *onDiskRetryQue
 		path,
 		diskUsageLimit,
 		newOnDiskRetryQueueTelemetry("domain"),
-		NewPointCountTelemetryMock())
+		NewPointCountTelemetryMock(),
+		"domain",
+		nil)
 	a.NoError(err)
 	return q
 }
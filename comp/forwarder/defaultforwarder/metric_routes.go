@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/config/utils"
+)
+
+// MetricRoutes holds the endpoints of the metric routes.
+//
+// The series and sketches payloads of a route are only sent to the endpoints of the route, and
+// the API keys which are only used by routes don't receive any other payload.
+type MetricRoutes struct {
+	// endpoints holds the API keys per domain of each route, by route name
+	endpoints map[string]map[string][]string
+	// routeOnlyKeys holds the API keys per domain which are only used by routes
+	routeOnlyKeys map[string]map[string]struct{}
+}
+
+// newMetricRoutes reads the metric routes from the configuration. It returns the routes, and the
+// API keys per domain of both the default endpoints and the endpoints of the routes.
+func newMetricRoutes(config config.Component, log log.Component, keysPerDomain map[string][]string) (*MetricRoutes, map[string][]string) {
+	routes, err := pkgconfigsetup.GetMetricRoutes(config)
+	if err != nil || len(routes) == 0 {
+		return nil, keysPerDomain
+	}
+
+	defaultKeys := make(map[string]map[string]struct{}, len(keysPerDomain))
+	allKeys := make(map[string][]string, len(keysPerDomain))
+	for domain, apiKeys := range keysPerDomain {
+		defaultKeys[normalizeDomain(domain)] = toSet(apiKeys)
+		allKeys[domain] = append([]string(nil), apiKeys...)
+	}
+
+	r := &MetricRoutes{
+		endpoints:     make(map[string]map[string][]string, len(routes)),
+		routeOnlyKeys: make(map[string]map[string]struct{}),
+	}
+	for _, route := range routes {
+		if route.Name == "" {
+			log.Errorf("Ignoring a metric route without name")
+			continue
+		}
+		if _, ok := r.endpoints[route.Name]; ok {
+			log.Errorf("Ignoring the duplicated metric route %q", route.Name)
+			continue
+		}
+
+		endpoints := make(map[string][]string, len(route.Endpoints))
+		for domain, apiKeys := range route.Endpoints {
+			for _, apiKey := range apiKeys {
+				apiKey = strings.TrimSpace(apiKey)
+				if apiKey == "" {
+					continue
+				}
+				normalized := normalizeDomain(domain)
+				endpoints[normalized] = append(endpoints[normalized], apiKey)
+				allKeys[domain] = append(allKeys[domain], apiKey)
+				if _, ok := defaultKeys[normalized][apiKey]; !ok {
+					if r.routeOnlyKeys[normalized] == nil {
+						r.routeOnlyKeys[normalized] = make(map[string]struct{})
+					}
+					r.routeOnlyKeys[normalized][apiKey] = struct{}{}
+				}
+			}
+		}
+		if len(endpoints) == 0 {
+			log.Warnf("The metric route %q has no endpoint, its series and sketches will be dropped", route.Name)
+		}
+		r.endpoints[route.Name] = endpoints
+	}
+
+	// dedupe the API keys
+	for domain, apiKeys := range allKeys {
+		deduped := make([]string, 0, len(apiKeys))
+		seen := make(map[string]struct{}, len(apiKeys))
+		for _, apiKey := range apiKeys {
+			if _, ok := seen[apiKey]; !ok {
+				seen[apiKey] = struct{}{}
+				deduped = append(deduped, apiKey)
+			}
+		}
+		allKeys[domain] = deduped
+	}
+
+	return r, allKeys
+}
+
+// apiKeys returns the API keys of a domain to which a payload of the given route is sent,
+// among the API keys of the domain.
+func (r *MetricRoutes) apiKeys(domain string, domainKeys []string, route string) []string {
+	if r == nil {
+		if route != "" {
+			return nil
+		}
+		return domainKeys
+	}
+	if route != "" {
+		return r.endpoints[route][domain]
+	}
+
+	routeOnlyKeys := r.routeOnlyKeys[domain]
+	if len(routeOnlyKeys) == 0 {
+		return domainKeys
+	}
+	apiKeys := make([]string, 0, len(domainKeys))
+	for _, apiKey := range domainKeys {
+		if _, ok := routeOnlyKeys[apiKey]; !ok {
+			apiKeys = append(apiKeys, apiKey)
+		}
+	}
+	return apiKeys
+}
+
+// normalizeDomain returns a domain as it is used by the domain forwarders.
+func normalizeDomain(domain string) string {
+	normalized, _ := utils.AddAgentVersionToDomain(domain, "app")
+	return normalized
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	configUtils "github.com/DataDog/datadog-agent/pkg/config/utils"
+)
+
+const metricRoutesConfig = `
+metric_routes:
+  - name: payments
+    match_tags: ["team:payments"]
+    endpoints:
+      "https://app.datadoghq.com": ["payments-key", "main-key"]
+  - name: search
+    match_tags: ["team:search"]
+    endpoints:
+      "https://app.datadoghq.eu": ["search-key"]
+`
+
+func TestNewMetricRoutes(t *testing.T) {
+	mockConfig := pkgconfigsetup.ConfFromYAML(metricRoutesConfig)
+	log := logmock.New(t)
+
+	routes, keysPerDomain := newMetricRoutes(mockConfig, log, map[string][]string{
+		"https://app.datadoghq.com": {"main-key"},
+		"https://other.example.com": {"other-key"},
+	})
+
+	assert.Equal(t, map[string][]string{
+		"https://app.datadoghq.com": {"main-key", "payments-key"},
+		"https://app.datadoghq.eu":  {"search-key"},
+		"https://other.example.com": {"other-key"},
+	}, keysPerDomain)
+
+	mainDomain, _ := configUtils.AddAgentVersionToDomain("https://app.datadoghq.com", "app")
+	euDomain, _ := configUtils.AddAgentVersionToDomain("https://app.datadoghq.eu", "app")
+	mainKeys := []string{"main-key", "payments-key"}
+
+	// the default payloads are not sent to the API keys which are only used by routes
+	assert.Equal(t, []string{"main-key"}, routes.apiKeys(mainDomain, mainKeys, ""))
+	assert.Empty(t, routes.apiKeys(euDomain, []string{"search-key"}, ""))
+
+	// the payloads of a route are only sent to its endpoints
+	assert.Equal(t, []string{"payments-key", "main-key"}, routes.apiKeys(mainDomain, mainKeys, "payments"))
+	assert.Empty(t, routes.apiKeys(euDomain, []string{"search-key"}, "payments"))
+	assert.Equal(t, []string{"search-key"}, routes.apiKeys(euDomain, []string{"search-key"}, "search"))
+	assert.Empty(t, routes.apiKeys(mainDomain, mainKeys, "unknown"))
+}
+
+func TestNewMetricRoutesNotConfigured(t *testing.T) {
+	mockConfig := pkgconfigsetup.Conf()
+	log := logmock.New(t)
+
+	routes, keysPerDomain := newMetricRoutes(mockConfig, log, keysPerDomains)
+	assert.Nil(t, routes)
+	assert.Equal(t, keysPerDomains, keysPerDomain)
+
+	assert.Equal(t, []string{"api-key-1"}, routes.apiKeys(testVersionDomain, []string{"api-key-1"}, ""))
+	assert.Empty(t, routes.apiKeys(testVersionDomain, []string{"api-key-1"}, "payments"))
+}
+
+func TestCreateHTTPTransactionsWithMetricRoutes(t *testing.T) {
+	mockConfig := pkgconfigsetup.ConfFromYAML(metricRoutesConfig)
+	log := logmock.New(t)
+	forwarder := NewDefaultForwarder(mockConfig, log, NewOptions(mockConfig, log, map[string][]string{
+		"https://app.datadoghq.com": {"main-key"},
+	}))
+	endpoint := transaction.Endpoint{Route: "/api/foo", Name: "foo"}
+
+	apiKeys := func(route string) []string {
+		payload := transaction.NewBytesPayload([]byte("A payload"), 1)
+		payload.Route = route
+		transactions := forwarder.createHTTPTransactions(endpoint, transaction.BytesPayloads{payload}, transaction.Series, make(http.Header))
+		keys := make([]string, 0, len(transactions))
+		for _, t := range transactions {
+			keys = append(keys, t.Headers.Get(apiHTTPHeaderKey))
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	require.Len(t, forwarder.domainResolvers, 2)
+	assert.Equal(t, []string{"main-key"}, apiKeys(""))
+	assert.Equal(t, []string{"main-key", "payments-key"}, apiKeys("payments"))
+	assert.Equal(t, []string{"search-key"}, apiKeys("search"))
+}
+
+func TestNewParamsWithResolversMetricRoutes(t *testing.T) {
+	mockConfig := pkgconfigsetup.ConfFromYAML("api_key: main-key\n" + metricRoutesConfig)
+	log := logmock.New(t)
+
+	options := NewParamsWithResolvers(mockConfig, log).Options
+	require.NotNil(t, options.MetricRoutes)
+	require.Len(t, options.DomainResolvers, 2)
+	keys := options.DomainResolvers["https://app.datadoghq.com"].GetAPIKeys()
+	sort.Strings(keys)
+	assert.Equal(t, []string{"main-key", "payments-key"}, keys)
+	assert.Equal(t, []string{"search-key"}, options.DomainResolvers["https://app.datadoghq.eu"].GetAPIKeys())
+}
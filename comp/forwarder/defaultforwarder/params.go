@@ -27,8 +27,10 @@ func NewParams(config config.Component, log log.Component) Params {
 
 // NewParamsWithResolvers initializes a new Params struct with resolvers
 func NewParamsWithResolvers(config config.Component, log log.Component) Params {
-	keysPerDomain := getMultipleEndpoints(config, log)
-	return Params{Options: NewOptionsWithResolvers(config, log, resolver.NewSingleDomainResolvers(keysPerDomain))}
+	metricRoutes, keysPerDomain := newMetricRoutes(config, log, getMultipleEndpoints(config, log))
+	options := NewOptionsWithResolvers(config, log, resolver.NewSingleDomainResolvers(keysPerDomain))
+	options.MetricRoutes = metricRoutes
+	return Params{Options: options}
 }
 
 func getMultipleEndpoints(config config.Component, log log.Component) map[string][]string {
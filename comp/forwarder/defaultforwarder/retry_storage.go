@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/internal/retry"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/resolver"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/config/utils"
+)
+
+// StoredFile is a file of the on-disk retry queue.
+type StoredFile = retry.StoredFile
+
+// StoredTransaction is a transaction of a file of the on-disk retry queue.
+type StoredTransaction = retry.StoredTransaction
+
+// AuditEvent is an entry of the audit log of the on-disk retry queue.
+type AuditEvent = retry.AuditEvent
+
+// The events recorded in the audit log of the on-disk retry queue
+const (
+	AuditEventStored   = retry.AuditEventStored
+	AuditEventRetried  = retry.AuditEventRetried
+	AuditEventDropped  = retry.AuditEventDropped
+	AuditEventReplayed = retry.AuditEventReplayed
+	AuditEventPurged   = retry.AuditEventPurged
+)
+
+// ReplayResult is the outcome of the replay of a file of the on-disk retry queue.
+type ReplayResult struct {
+	// Sent is the number of transactions accepted by the intake
+	Sent int
+	// Rejected is the number of transactions rejected by the intake, they are dropped
+	Rejected int
+	// Kept is the number of transactions which couldn't be sent, they are kept in the file
+	Kept int
+}
+
+// RetryStorage gives access to the on-disk retry queue of the core agent, to inspect, replay
+// or purge the stored transactions.
+//
+// The Agent must be stopped while the files are replayed or purged, as it doesn't expect the
+// files of its retry queue to be modified by another process.
+type RetryStorage struct {
+	config   config.Component
+	log      log.Component
+	rootPath string
+	auditLog *retry.AuditLog
+	// domains and resolvers indexed by domain folder name
+	domains   map[string]string
+	resolvers map[string]resolver.DomainResolver
+}
+
+// NewRetryStorage creates a new instance of RetryStorage for the domains configured for the core agent.
+func NewRetryStorage(config config.Component, log log.Component) *RetryStorage {
+	rootPath := getRetryStoragePath(config, getAgentName(&Options{EnabledFeatures: CoreFeatures}))
+	s := &RetryStorage{
+		config:    config,
+		log:       log,
+		rootPath:  rootPath,
+		auditLog:  retry.NewAuditLog(rootPath),
+		domains:   make(map[string]string),
+		resolvers: make(map[string]resolver.DomainResolver),
+	}
+
+	options := NewOptions(config, log, getMultipleEndpoints(config, log))
+	for domain, r := range options.DomainResolvers {
+		domain, _ := utils.AddAgentVersionToDomain(domain, "app")
+		r.SetBaseDomain(domain)
+		folder, err := retry.DomainFolderName(domain)
+		if err != nil {
+			log.Errorf("Cannot compute the folder of the domain '%v': %v", domain, err)
+			continue
+		}
+		s.domains[folder] = domain
+		s.resolvers[folder] = r
+	}
+	return s
+}
+
+// RootPath returns the root folder of the on-disk retry queue.
+func (s *RetryStorage) RootPath() string {
+	return s.rootPath
+}
+
+// ListFiles returns the files of the on-disk retry queue, oldest first.
+func (s *RetryStorage) ListFiles() ([]StoredFile, error) {
+	return retry.ListStoredFiles(s.rootPath)
+}
+
+// Domain returns the domain of a stored file, or an empty string if the domain is not configured anymore.
+func (s *RetryStorage) Domain(file StoredFile) string {
+	return s.domains[file.DomainFolder]
+}
+
+// ReadTransactions returns the transactions of a stored file. The API keys are not restored.
+func (s *RetryStorage) ReadTransactions(file StoredFile) ([]StoredTransaction, error) {
+	return retry.ReadStoredTransactions(file.Path)
+}
+
+// ReadAuditLog returns the events of the audit log, oldest first.
+func (s *RetryStorage) ReadAuditLog() ([]AuditEvent, error) {
+	return retry.ReadAuditLog(s.rootPath)
+}
+
+// Replay sends the transactions of a stored file to the intake. The transactions which are sent
+// or rejected by the intake are removed from the file, the file being removed when it is empty.
+func (s *RetryStorage) Replay(ctx context.Context, file StoredFile) (ReplayResult, error) {
+	var result ReplayResult
+	domain := s.Domain(file)
+	r, ok := s.resolvers[file.DomainFolder]
+	if !ok {
+		return result, fmt.Errorf("the domain of the file %v is not configured", file.Path)
+	}
+
+	bytes, err := os.ReadFile(file.Path)
+	if err != nil {
+		return result, err
+	}
+	serializer := retry.NewHTTPTransactionsSerializer(s.log, r)
+	transactions, errorCount, err := serializer.Deserialize(bytes)
+	if err != nil {
+		return result, err
+	}
+	if errorCount > 0 {
+		// Replaying the file would drop the transactions which can't be deserialized.
+		return result, fmt.Errorf("%d transactions of the file %v cannot be deserialized, check that the API keys used to store them are still configured", errorCount, file.Path)
+	}
+
+	client := NewHTTPClient(s.config)
+	sentPoints, rejectedPoints := 0, 0
+	for _, t := range transactions {
+		tr, ok := t.(*transaction.HTTPTransaction)
+		if !ok {
+			continue
+		}
+		var statusCode int
+		var completionErr error
+		tr.CompletionHandler = func(_ *transaction.HTTPTransaction, code int, _ []byte, err error) {
+			statusCode, completionErr = code, err
+		}
+
+		err := tr.Process(ctx, s.config, s.log, client)
+		switch {
+		case err != nil || statusCode == 0:
+			result.Kept++
+			if err := serializer.Add(tr); err != nil {
+				return result, err
+			}
+		case completionErr != nil || statusCode >= 400:
+			result.Rejected++
+			rejectedPoints += tr.GetPointCount()
+		default:
+			result.Sent++
+			sentPoints += tr.GetPointCount()
+		}
+	}
+
+	if result.Kept > 0 {
+		bytes, err := serializer.GetBytesAndReset()
+		if err != nil {
+			return result, err
+		}
+		if err := os.WriteFile(file.Path, bytes, 0600); err != nil {
+			return result, err
+		}
+	} else if err := os.Remove(file.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return result, err
+	}
+
+	if result.Sent > 0 {
+		s.record(retry.AuditEvent{
+			Event:        retry.AuditEventReplayed,
+			Domain:       domain,
+			File:         path.Base(file.Path),
+			Transactions: result.Sent,
+			Points:       sentPoints,
+		})
+	}
+	if result.Rejected > 0 {
+		s.record(retry.AuditEvent{
+			Event:        retry.AuditEventDropped,
+			Reason:       retry.AuditReasonRejected,
+			Domain:       domain,
+			File:         path.Base(file.Path),
+			Transactions: result.Rejected,
+			Points:       rejectedPoints,
+		})
+	}
+	return result, nil
+}
+
+// Purge removes a stored file without sending its transactions.
+func (s *RetryStorage) Purge(file StoredFile) error {
+	if err := os.Remove(file.Path); err != nil {
+		return err
+	}
+	s.record(retry.AuditEvent{
+		Event:  retry.AuditEventPurged,
+		Domain: s.Domain(file),
+		File:   path.Base(file.Path),
+		Bytes:  file.Size,
+	})
+	return nil
+}
+
+func (s *RetryStorage) record(event retry.AuditEvent) {
+	if err := s.auditLog.Record(event); err != nil {
+		s.log.Warnf("Cannot record the event %v of the file %v in the audit log: %v", event.Event, event.File, err)
+	}
+}
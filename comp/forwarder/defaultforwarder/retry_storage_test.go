@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/internal/retry"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestRetryStorageReplay(t *testing.T) {
+	var routes []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routes = append(routes, r.URL.Path)
+		assert.Equal(t, "api_key1", r.Header.Get("DD-Api-Key"))
+		switch r.URL.Path {
+		case "/rejected":
+			w.WriteHeader(http.StatusBadRequest)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer ts.Close()
+
+	mockConfig := fxutil.Test[config.Component](t, fx.Options(
+		config.MockModule(),
+	))
+	mockConfig.SetWithoutSource("dd_url", ts.URL)
+	mockConfig.SetWithoutSource("api_key", "api_key1")
+	mockConfig.SetWithoutSource("forwarder_storage_path", t.TempDir())
+	log := logmock.New(t)
+
+	storage := NewRetryStorage(mockConfig, log)
+	folder, err := retry.DomainFolderName(ts.URL)
+	require.NoError(t, err)
+	require.Equal(t, ts.URL, storage.domains[folder])
+	require.NoError(t, os.MkdirAll(path.Join(storage.RootPath(), folder), 0700))
+
+	serializer := retry.NewHTTPTransactionsSerializer(log, storage.resolvers[folder])
+	for _, route := range []string{"/sent", "/rejected", "/unavailable"} {
+		tr := transaction.NewHTTPTransaction()
+		tr.Domain = ts.URL
+		tr.Endpoint = transaction.Endpoint{Route: route, Name: route[1:]}
+		tr.Headers.Set("DD-Api-Key", "api_key1")
+		tr.Payload = transaction.NewBytesPayload([]byte("payload"), 2)
+		require.NoError(t, serializer.Add(tr))
+	}
+	bytes, err := serializer.GetBytesAndReset()
+	require.NoError(t, err)
+	filename := path.Join(storage.RootPath(), folder, "1.retry")
+	require.NoError(t, os.WriteFile(filename, bytes, 0600))
+
+	files, err := storage.ListFiles()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ts.URL, storage.Domain(files[0]))
+
+	// the API keys are not written in clear
+	stored, err := storage.ReadTransactions(files[0])
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+	assert.NotEqual(t, "api_key1", stored[0].Headers.Get("DD-Api-Key"))
+
+	result, err := storage.Replay(context.Background(), files[0])
+	require.NoError(t, err)
+	assert.Equal(t, ReplayResult{Sent: 1, Rejected: 1, Kept: 1}, result)
+	assert.Equal(t, []string{"/sent", "/rejected", "/unavailable"}, routes)
+
+	// only the transaction which couldn't be sent is kept
+	stored, err = storage.ReadTransactions(files[0])
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "unavailable", stored[0].Endpoint)
+
+	require.NoError(t, storage.Purge(files[0]))
+	files, err = storage.ListFiles()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	events, err := storage.ReadAuditLog()
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, AuditEventReplayed, events[0].Event)
+	assert.Equal(t, 2, events[0].Points)
+	assert.Equal(t, AuditEventDropped, events[1].Event)
+	assert.Equal(t, retry.AuditReasonRejected, events[1].Reason)
+	assert.Equal(t, AuditEventPurged, events[2].Event)
+	for _, event := range events {
+		assert.Equal(t, ts.URL, event.Domain)
+		assert.Equal(t, "1.retry", event.File)
+	}
+}
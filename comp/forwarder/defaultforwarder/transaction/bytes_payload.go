@@ -11,6 +11,8 @@ type BytesPayload struct {
 	content     []byte
 	pointCount  int
 	Destination Destination
+	// Route is the name of the metric route of the payload, empty when it is sent to the default endpoints
+	Route string
 }
 
 // NewBytesPayload creates a new instance of BytesPayload.
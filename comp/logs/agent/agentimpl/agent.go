@@ -16,6 +16,7 @@ import (
 	"go.uber.org/atomic"
 	"go.uber.org/fx"
 
+	"github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer"
 	configComponent "github.com/DataDog/datadog-agent/comp/core/config"
 	flaretypes "github.com/DataDog/datadog-agent/comp/core/flare/types"
 	"github.com/DataDog/datadog-agent/comp/core/hostname"
@@ -28,6 +29,8 @@ import (
 	"github.com/DataDog/datadog-agent/comp/logs/integrations/def"
 	"github.com/DataDog/datadog-agent/comp/metadata/inventoryagent"
 	rctypes "github.com/DataDog/datadog-agent/comp/remote-config/rcclient/types"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
 	pkgConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
@@ -35,6 +38,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/launchers"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/processor"
 	"github.com/DataDog/datadog-agent/pkg/logs/schedulers"
 	"github.com/DataDog/datadog-agent/pkg/logs/sds"
 	"github.com/DataDog/datadog-agent/pkg/logs/service"
@@ -60,6 +64,9 @@ const (
 
 	// inventory setting name
 	logsTransport = "logs_transport"
+
+	// ID of the sender of the metrics generated from logs
+	logsMetricsSenderID checkid.ID = "logs-agent-generated-metrics"
 )
 
 // Module defines the fx options for this component.
@@ -79,6 +86,9 @@ type dependencies struct {
 	WMeta              optional.Option[workloadmeta.Component]
 	SchedulerProviders []schedulers.Scheduler `group:"log-agent-scheduler"`
 	IntegrationsLogs   integrations.Component
+	// Demultiplexer is used to submit the metrics generated from logs,
+	// it is not available in every binary running the logs agent.
+	Demultiplexer demultiplexer.Component `optional:"true"`
 }
 
 type provides struct {
@@ -114,6 +124,8 @@ type logAgent struct {
 	wmeta                     optional.Option[workloadmeta.Component]
 	schedulerProviders        []schedulers.Scheduler
 	integrationsLogs          integrations.Component
+	senderManager             sender.SenderManager
+	metricsCommitter          *processor.MetricsCommitter
 
 	// started is true if the logs agent is running
 	started *atomic.Bool
@@ -139,6 +151,7 @@ func newLogsAgent(deps dependencies) provides {
 			wmeta:              deps.WMeta,
 			schedulerProviders: deps.SchedulerProviders,
 			integrationsLogs:   deps.IntegrationsLogs,
+			senderManager:      deps.Demultiplexer,
 		}
 		deps.Lc.Append(fx.Hook{
 			OnStart: logsAgent.start,
@@ -225,6 +238,17 @@ func (a *logAgent) setupAgent() error {
 		status.AddGlobalWarning(invalidProcessingRules, multiLineWarning)
 	}
 
+	if a.senderManager != nil {
+		// the metrics generated from logs are committed on their own sender, not to commit
+		// the samples of the other components using the default sender
+		metricSender, err := a.senderManager.GetSender(logsMetricsSenderID)
+		if err != nil {
+			a.log.Warnf("Metrics generated from logs won't be submitted: %v", err)
+		} else {
+			a.metricsCommitter = processor.NewMetricsCommitter(metricSender)
+		}
+	}
+
 	a.SetupPipeline(processingRules, a.wmeta, a.integrationsLogs)
 	return nil
 }
@@ -245,6 +269,9 @@ func (a *logAgent) startPipeline() {
 		a.launchers,
 		a.schedulers,
 	)
+	if a.metricsCommitter != nil {
+		starter.Add(a.metricsCommitter)
+	}
 	starter.Start()
 }
 
@@ -261,6 +288,10 @@ func (a *logAgent) stop(context.Context) error {
 		a.destinationsCtx,
 		a.diagnosticMessageReceiver,
 	)
+	if a.metricsCommitter != nil {
+		// the processors are stopped, commit the last metrics they generated
+		stopper.Add(a.metricsCommitter)
+	}
 
 	// This will try to stop everything in order, including the potentially blocking
 	// parts like the sender. After StopTimeout it will just stop the last part of the
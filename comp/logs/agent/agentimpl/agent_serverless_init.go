@@ -57,6 +57,7 @@ func (a *logAgent) SetupPipeline(
 		filelauncher.DefaultSleepDuration,
 		a.config.GetBool("logs_config.validate_pod_container_id"),
 		time.Duration(a.config.GetFloat64("logs_config.file_scan_period")*float64(time.Second)),
+		a.config.GetBool("logs_config.file_watcher_enabled"),
 		a.config.GetString("logs_config.file_wildcard_selection_mode"), a.flarecontroller))
 
 	a.schedulers = schedulers.NewSchedulers(a.sources, a.services)
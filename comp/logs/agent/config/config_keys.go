@@ -92,6 +92,24 @@ func (l *LogsConfigKeys) isForceHTTPUse() bool {
 		l.getConfig().GetBool(l.getConfigKey("force_use_http"))
 }
 
+func (l *LogsConfigKeys) useOTLP() bool {
+	return l.getConfig().GetBool(l.getConfigKey("use_otlp"))
+}
+
+// hasOTLPEndpoints returns true if the main endpoint or one of the additional endpoints is an OTLP endpoint,
+// which can only be used with HTTP.
+func (l *LogsConfigKeys) hasOTLPEndpoints() bool {
+	if l.useOTLP() {
+		return true
+	}
+	for _, e := range l.getAdditionalEndpoints() {
+		if e.UseOTLP {
+			return true
+		}
+	}
+	return false
+}
+
 func (l *LogsConfigKeys) logsNoSSL() bool {
 	return l.getConfig().GetBool(l.getConfigKey("logs_no_ssl"))
 }
@@ -57,6 +57,10 @@ type Endpoint struct {
 	TrackType IntakeTrackType
 	Protocol  IntakeProtocol
 	Origin    IntakeOrigin
+
+	// UseOTLP indicates that the logs are sent to an OpenTelemetry collector with the OTLP/HTTP protocol
+	// instead of the Datadog intake.
+	UseOTLP bool `mapstructure:"use_otlp" json:"use_otlp"`
 }
 
 // unmarshalEndpoint is used to load additional endpoints from the configuration which stored as JSON/mapstructure.
@@ -168,6 +172,7 @@ func loadHTTPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys, intakeTrackTy
 		newE.TrackType = e.TrackType
 		newE.Protocol = e.Protocol
 		newE.Origin = e.Origin
+		newE.UseOTLP = e.UseOTLP
 
 		if e.UseSSL != nil {
 			newE.useSSL = *e.UseSSL
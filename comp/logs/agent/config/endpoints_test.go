@@ -627,6 +627,39 @@ func (suite *EndpointsTestSuite) TestAdditionalEndpointsUseSSLHTTPMainEndpointFa
 	suite.False(endpoints.Endpoints[3].UseSSL())
 }
 
+func (suite *EndpointsTestSuite) TestAdditionalOTLPEndpointForcesHTTP() {
+	suite.config.SetWithoutSource("logs_config.additional_endpoints", []map[string]interface{}{
+		{
+			"host":     "otel-collector",
+			"port":     4318,
+			"use_ssl":  false,
+			"use_otlp": true,
+		},
+	})
+
+	endpoints, err := BuildEndpoints(suite.config, HTTPConnectivityFailure, "test-track", "test-proto", "test-source")
+	suite.Nil(err)
+	suite.True(endpoints.UseHTTP)
+	suite.Len(endpoints.Endpoints, 2)
+	suite.False(endpoints.Main.UseOTLP)
+	suite.True(endpoints.Endpoints[1].UseOTLP)
+	suite.Equal("otel-collector", endpoints.Endpoints[1].Host)
+	suite.Equal(4318, endpoints.Endpoints[1].Port)
+}
+
+func (suite *EndpointsTestSuite) TestMainOTLPEndpoint() {
+	suite.config.SetWithoutSource("logs_config.use_otlp", true)
+	suite.config.SetWithoutSource("logs_config.logs_dd_url", "http://otel-collector:4318")
+
+	endpoints, err := BuildEndpoints(suite.config, HTTPConnectivityFailure, "test-track", "test-proto", "test-source")
+	suite.Nil(err)
+	suite.True(endpoints.UseHTTP)
+	suite.True(endpoints.Main.UseOTLP)
+	suite.Equal("otel-collector", endpoints.Main.Host)
+	suite.Equal(4318, endpoints.Main.Port)
+	suite.False(endpoints.Main.UseSSL())
+}
+
 func (suite *EndpointsTestSuite) TestMainApiKeyRotation() {
 	suite.config.SetWithoutSource("api_key", "1234")
 	logsConfig := defaultLogsConfigKeys(suite.config)
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// grokPatterns contains the built-in patterns usable in a parse_grok
+// processing rule with the %{PATTERN} or %{PATTERN:field} syntax.
+var grokPatterns = map[string]string{
+	"WORD":              `\b\w+\b`,
+	"NOTSPACE":          `\S+`,
+	"SPACE":             `\s*`,
+	"DATA":              `.*?`,
+	"GREEDYDATA":        `.*`,
+	"INT":               `[+-]?\d+`,
+	"NUMBER":            `[+-]?(?:\d+(?:\.\d+)?|\.\d+)`,
+	"BASE16NUM":         `(?:0[xX])?[0-9A-Fa-f]+`,
+	"UUID":              `[A-Fa-f0-9]{8}-(?:[A-Fa-f0-9]{4}-){3}[A-Fa-f0-9]{12}`,
+	"IPV4":              `(?:\d{1,3}\.){3}\d{1,3}`,
+	"IPV6":              `[0-9A-Fa-f:]*:[0-9A-Fa-f:.]+`,
+	"IP":                `(?:(?:\d{1,3}\.){3}\d{1,3}|[0-9A-Fa-f:]*:[0-9A-Fa-f:.]+)`,
+	"HOSTNAME":          `\b[0-9A-Za-z][0-9A-Za-z-]{0,62}(?:\.[0-9A-Za-z][0-9A-Za-z-]{0,62})*\.?\b`,
+	"USER":              `[a-zA-Z0-9._-]+`,
+	"PATH":              `(?:/[^\s/]*)+`,
+	"URIPATHPARAM":      `/[^\s?#]*(?:\?[^\s#]*)?`,
+	"QUOTEDSTRING":      `"(?:[^"\\]|\\.)*"`,
+	"LOGLEVEL":          `(?i:trace|debug|info|notice|warn(?:ing)?|err(?:or)?|crit(?:ical)?|fatal|severe|emerg(?:ency)?|alert)`,
+	"TIMESTAMP_ISO8601": `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?`,
+	"HTTPDATE":          `\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`,
+	"SYSLOGTIMESTAMP":   `\w{3} +\d{1,2} \d{2}:\d{2}:\d{2}`,
+}
+
+// grokExpression matches %{PATTERN} and %{PATTERN:field} in a grok pattern.
+var grokExpression = regexp.MustCompile(`%\{(\w+)(?::([\w.]+))?\}`)
+
+// ExpandGrokPattern converts a grok pattern into a regular expression where
+// every %{PATTERN:field} expression is turned into a named capture group.
+func ExpandGrokPattern(pattern string) (string, error) {
+	var err error
+	expanded := grokExpression.ReplaceAllStringFunc(pattern, func(expr string) string {
+		if err != nil {
+			return expr
+		}
+		groups := grokExpression.FindStringSubmatch(expr)
+		re, exists := grokPatterns[groups[1]]
+		if !exists {
+			err = fmt.Errorf("unknown grok pattern %s", groups[1])
+			return expr
+		}
+		if groups[2] == "" {
+			return "(?:" + re + ")"
+		}
+		// regexp group names can't contain dots, they are
+		// translated back when extracting the fields.
+		return "(?P<" + strings.ReplaceAll(groups[2], ".", "__") + ">" + re + ")"
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
+
+// GrokFieldName returns the field name of a regexp group generated by ExpandGrokPattern.
+func GrokFieldName(groupName string) string {
+	return strings.ReplaceAll(groupName, "__", ".")
+}
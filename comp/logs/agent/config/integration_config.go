@@ -25,6 +25,8 @@ const (
 	IntegrationType   = "integration"
 	WindowsEventType  = "windows_event"
 	StringChannelType = "string_channel"
+	UnixType          = "unix"
+	FIFOType          = "fifo"
 
 	// UTF16BE for UTF-16 Big endian encoding
 	UTF16BE string = "utf-16-be"
@@ -32,6 +34,14 @@ const (
 	UTF16LE string = "utf-16-le"
 	// SHIFTJIS for Shift JIS (Japanese) encoding
 	SHIFTJIS string = "shift-jis"
+
+	// SyslogFormat for network sources receiving syslog messages (RFC 3164 or RFC 5424)
+	SyslogFormat string = "syslog"
+
+	// StreamSocketType for unix sources accepting connections, the default
+	StreamSocketType string = "stream"
+	// DatagramSocketType for unix sources receiving datagrams, e.g. /dev/log
+	DatagramSocketType string = "datagram"
 )
 
 // LogsConfig represents a log source config, which can be for instance
@@ -42,12 +52,19 @@ type LogsConfig struct {
 	IntegrationName string
 
 	Port        int    // Network
-	IdleTimeout string `mapstructure:"idle_timeout" json:"idle_timeout"` // Network
-	Path        string // File, Journald
+	IdleTimeout string `mapstructure:"idle_timeout" json:"idle_timeout"`   // Network
+	Format      string `mapstructure:"format" json:"format"`               // Network
+	TLSCertPath string `mapstructure:"tls_cert_path" json:"tls_cert_path"` // TCP
+	TLSKeyPath  string `mapstructure:"tls_key_path" json:"tls_key_path"`   // TCP
+	Path        string // File, Journald, Unix, FIFO
+	SocketType  string `mapstructure:"socket_type" json:"socket_type"` // Unix
 
 	Encoding     string   `mapstructure:"encoding" json:"encoding"`             // File
 	ExcludePaths []string `mapstructure:"exclude_paths" json:"exclude_paths"`   // File
 	TailingMode  string   `mapstructure:"start_position" json:"start_position"` // File
+	// BackfillRotated enables the ingestion of the compressed rotated files (.gz, .zst)
+	// which have not been read because they were rotated while the agent was stopped.
+	BackfillRotated bool `mapstructure:"backfill_rotated" json:"backfill_rotated"` // File
 
 	//nolint:revive // TODO(AML) Fix revive linter
 	ConfigId           string   `mapstructure:"config_id" json:"config_id"`                   // Journald
@@ -114,15 +131,28 @@ func (c *LogsConfig) Dump(multiline bool) string {
 	case TCPType:
 		fmt.Fprintf(&b, ws("Port: %d,"), c.Port)
 		fmt.Fprintf(&b, ws("IdleTimeout: %#v,"), c.IdleTimeout)
+		fmt.Fprintf(&b, ws("Format: %#v,"), c.Format)
+		fmt.Fprintf(&b, ws("TLSCertPath: %#v,"), c.TLSCertPath)
+		fmt.Fprintf(&b, ws("TLSKeyPath: %#v,"), c.TLSKeyPath)
 	case UDPType:
 		fmt.Fprintf(&b, ws("Port: %d,"), c.Port)
 		fmt.Fprintf(&b, ws("IdleTimeout: %#v,"), c.IdleTimeout)
+		fmt.Fprintf(&b, ws("Format: %#v,"), c.Format)
+	case UnixType:
+		fmt.Fprintf(&b, ws("Path: %#v,"), c.Path)
+		fmt.Fprintf(&b, ws("SocketType: %#v,"), c.SocketType)
+		fmt.Fprintf(&b, ws("IdleTimeout: %#v,"), c.IdleTimeout)
+		fmt.Fprintf(&b, ws("Format: %#v,"), c.Format)
+	case FIFOType:
+		fmt.Fprintf(&b, ws("Path: %#v,"), c.Path)
+		fmt.Fprintf(&b, ws("Format: %#v,"), c.Format)
 	case FileType:
 		fmt.Fprintf(&b, ws("Path: %#v,"), c.Path)
 		fmt.Fprintf(&b, ws("Encoding: %#v,"), c.Encoding)
 		fmt.Fprintf(&b, ws("Identifier: %#v,"), c.Identifier)
 		fmt.Fprintf(&b, ws("ExcludePaths: %#v,"), c.ExcludePaths)
 		fmt.Fprintf(&b, ws("TailingMode: %#v,"), c.TailingMode)
+		fmt.Fprintf(&b, ws("BackfillRotated: %t,"), c.BackfillRotated)
 	case DockerType, ContainerdType:
 		fmt.Fprintf(&b, ws("Image: %#v,"), c.Image)
 		fmt.Fprintf(&b, ws("Label: %#v,"), c.Label)
@@ -173,7 +203,9 @@ func (c *LogsConfig) PublicJSON() ([]byte, error) {
 	return json.Marshal(&struct {
 		Type            string            `json:"type,omitempty"`
 		Port            int               `json:"port,omitempty"`           // Network
-		Path            string            `json:"path,omitempty"`           // File, Journald
+		Format          string            `json:"format,omitempty"`         // Network
+		Path            string            `json:"path,omitempty"`           // File, Journald, Unix, FIFO
+		SocketType      string            `json:"socket_type,omitempty"`    // Unix
 		Encoding        string            `json:"encoding,omitempty"`       // File
 		ExcludePaths    []string          `json:"exclude_paths,omitempty"`  // File
 		TailingMode     string            `json:"start_position,omitempty"` // File
@@ -186,7 +218,9 @@ func (c *LogsConfig) PublicJSON() ([]byte, error) {
 	}{
 		Type:            c.Type,
 		Port:            c.Port,
+		Format:          c.Format,
 		Path:            c.Path,
+		SocketType:      c.SocketType,
 		Encoding:        c.Encoding,
 		ExcludePaths:    c.ExcludePaths,
 		TailingMode:     c.TailingMode,
@@ -256,18 +290,46 @@ func (c *LogsConfig) Validate() error {
 		if err != nil {
 			return err
 		}
+	case c.BackfillRotated:
+		return fmt.Errorf("backfill_rotated is only supported by file sources")
 	case c.Type == TCPType && c.Port == 0:
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == UnixType && c.Path == "":
+		return fmt.Errorf("unix source must have a path")
+	case c.Type == FIFOType && c.Path == "":
+		return fmt.Errorf("fifo source must have a path")
 	}
-	err := ValidateProcessingRules(c.ProcessingRules)
+	err := c.validateNetworkOptions()
+	if err != nil {
+		return err
+	}
+	err = ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
 		return err
 	}
 	return CompileProcessingRules(c.ProcessingRules)
 }
 
+func (c *LogsConfig) validateNetworkOptions() error {
+	switch {
+	case c.Format != "" && c.Format != SyslogFormat:
+		return fmt.Errorf("invalid format '%v', the only supported format is '%v'", c.Format, SyslogFormat)
+	case c.Format != "" && c.Type != TCPType && c.Type != UDPType && c.Type != UnixType && c.Type != FIFOType:
+		return fmt.Errorf("format is only supported by tcp, udp, unix and fifo sources")
+	case c.SocketType != "" && c.Type != UnixType:
+		return fmt.Errorf("socket_type is only supported by unix sources")
+	case c.SocketType != "" && c.SocketType != StreamSocketType && c.SocketType != DatagramSocketType:
+		return fmt.Errorf("invalid socket_type '%v', supported values are '%v' and '%v'", c.SocketType, StreamSocketType, DatagramSocketType)
+	case (c.TLSCertPath != "" || c.TLSKeyPath != "") && c.Type != TCPType:
+		return fmt.Errorf("tls is only supported by tcp sources")
+	case (c.TLSCertPath == "") != (c.TLSKeyPath == ""):
+		return fmt.Errorf("tcp source must have both a tls_cert_path and a tls_key_path to use tls")
+	}
+	return nil
+}
+
 func (c *LogsConfig) validateTailingMode() error {
 	mode, found := TailingModeFromString(c.TailingMode)
 	if !found && c.TailingMode != "" {
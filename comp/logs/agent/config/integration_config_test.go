@@ -21,8 +21,14 @@ import (
 func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 	validConfigs := []*LogsConfig{
 		{Type: FileType, Path: "/var/log/foo.log"},
+		{Type: FileType, Path: "/var/log/*.log", BackfillRotated: true},
 		{Type: TCPType, Port: 1234},
 		{Type: UDPType, Port: 5678},
+		{Type: TCPType, Port: 1234, Format: SyslogFormat, TLSCertPath: "/etc/certs/cert.pem", TLSKeyPath: "/etc/certs/key.pem"},
+		{Type: UDPType, Port: 5678, Format: SyslogFormat},
+		{Type: UnixType, Path: "/var/run/app.sock"},
+		{Type: UnixType, Path: "/dev/log", SocketType: DatagramSocketType, Format: SyslogFormat},
+		{Type: FIFOType, Path: "/var/run/app.pipe"},
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
 	}
@@ -39,6 +45,15 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: FileType},
 		{Type: TCPType},
 		{Type: UDPType},
+		{Type: TCPType, Port: 1234, Format: "gelf"},
+		{Type: FileType, Path: "/var/log/foo.log", Format: SyslogFormat},
+		{Type: UDPType, Port: 5678, TLSCertPath: "/etc/certs/cert.pem", TLSKeyPath: "/etc/certs/key.pem"},
+		{Type: TCPType, Port: 1234, TLSCertPath: "/etc/certs/cert.pem"},
+		{Type: UnixType},
+		{Type: UnixType, Path: "/var/run/app.sock", SocketType: "seqpacket"},
+		{Type: FIFOType},
+		{Type: FIFOType, Path: "/var/run/app.pipe", SocketType: StreamSocketType},
+		{Type: DockerType, BackfillRotated: true},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: "bar"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch}}},
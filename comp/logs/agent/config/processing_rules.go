@@ -8,6 +8,8 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"strconv"
+	"time"
 )
 
 // Processing rule types
@@ -16,6 +18,22 @@ const (
 	IncludeAtMatch = "include_at_match"
 	MaskSequences  = "mask_sequences"
 	MultiLine      = "multi_line"
+	JSONParsing    = "parse_json"
+	LogfmtParsing  = "parse_logfmt"
+	GrokParsing    = "parse_grok"
+	GenerateMetric = "generate_metric"
+	Deduplicate    = "deduplicate"
+	RateLimit      = "rate_limit"
+)
+
+// defaultDeduplicationWindow is the window of the deduplicate rules not setting one.
+const defaultDeduplicationWindow = 10 * time.Second
+
+// Metric types supported by the generate_metric processing rule
+const (
+	CountMetricType        = "count"
+	GaugeMetricType        = "gauge"
+	DistributionMetricType = "distribution"
 )
 
 // ProcessingRule defines an exclusion or a masking rule to
@@ -25,16 +43,37 @@ type ProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
 	Pattern            string
+	// Fields used by the parsing rules (parse_json, parse_logfmt and parse_grok)
+	// to lift parsed attributes into the message.
+	MessageField    string   `mapstructure:"message_field" json:"message_field"`
+	StatusField     string   `mapstructure:"status_field" json:"status_field"`
+	TimestampField  string   `mapstructure:"timestamp_field" json:"timestamp_field"`
+	TimestampFormat string   `mapstructure:"timestamp_format" json:"timestamp_format"`
+	TagFields       []string `mapstructure:"tag_fields" json:"tag_fields"`
+	// Fields used by the generate_metric rule to submit a metric for each
+	// matching log. The value is either 1 or the numeric value captured
+	// by the ValueGroup group (name or index) of the pattern.
+	MetricName string `mapstructure:"metric_name" json:"metric_name"`
+	MetricType string `mapstructure:"metric_type" json:"metric_type"`
+	ValueGroup string `mapstructure:"value_group" json:"value_group"`
+	// Window is the duration during which the deduplicate rule collapses identical logs.
+	Window string `mapstructure:"window" json:"window"`
+	// Rate and Burst configure the token bucket of the rate_limit rule:
+	// Rate logs per second are allowed, with bursts up to Burst logs.
+	Rate  float64 `mapstructure:"rate" json:"rate"`
+	Burst int     `mapstructure:"burst" json:"burst"`
 	// TODO: should be moved out
-	Regex       *regexp.Regexp
-	Placeholder []byte
+	Regex          *regexp.Regexp
+	Placeholder    []byte
+	WindowDuration time.Duration
 }
 
 // ValidateProcessingRules validates the rules and raises an error if one is misconfigured.
 // Each processing rule must have:
-// - a valid name
-// - a valid type
-// - a valid pattern that compiles
+//   - a valid name
+//   - a valid type
+//   - a valid pattern that compiles, except for parse_json, parse_logfmt, deduplicate and
+//     rate_limit rules for which the pattern is optional and only used to select the logs to process
 func ValidateProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
 		if rule.Name == "" {
@@ -44,6 +83,45 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		switch rule.Type {
 		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
 			break
+		case GenerateMetric:
+			if err := validateGenerateMetricRule(rule); err != nil {
+				return err
+			}
+		case Deduplicate:
+			if rule.Window != "" {
+				if window, err := time.ParseDuration(rule.Window); err != nil || window <= 0 {
+					return fmt.Errorf("invalid window %s for processing rule: %s", rule.Window, rule.Name)
+				}
+			}
+			if rule.Pattern == "" {
+				continue
+			}
+		case RateLimit:
+			if rule.Rate <= 0 {
+				return fmt.Errorf("a positive rate must be set for processing rule: %s", rule.Name)
+			}
+			if rule.Burst < 0 {
+				return fmt.Errorf("invalid burst %d for processing rule: %s", rule.Burst, rule.Name)
+			}
+			if rule.Pattern == "" {
+				continue
+			}
+		case JSONParsing, LogfmtParsing:
+			if rule.Pattern == "" {
+				continue
+			}
+		case GrokParsing:
+			if rule.Pattern == "" {
+				return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
+			}
+			expanded, err := ExpandGrokPattern(rule.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid grok pattern %s for processing rule: %s: %v", rule.Pattern, rule.Name, err)
+			}
+			if _, err := regexp.Compile(expanded); err != nil {
+				return fmt.Errorf("invalid grok pattern %s for processing rule: %s", rule.Pattern, rule.Name)
+			}
+			continue
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
 		default:
@@ -64,12 +142,39 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 // CompileProcessingRules compiles all processing rule regular expressions.
 func CompileProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
+		switch rule.Type {
+		case Deduplicate:
+			rule.WindowDuration = defaultDeduplicationWindow
+			if rule.Window != "" {
+				window, err := time.ParseDuration(rule.Window)
+				if err != nil {
+					return err
+				}
+				rule.WindowDuration = window
+			}
+			if rule.Pattern == "" {
+				continue
+			}
+		case JSONParsing, LogfmtParsing, RateLimit:
+			if rule.Pattern == "" {
+				continue
+			}
+		case GrokParsing:
+			expanded, err := ExpandGrokPattern(rule.Pattern)
+			if err != nil {
+				return err
+			}
+			if rule.Regex, err = regexp.Compile(expanded); err != nil {
+				return err
+			}
+			continue
+		}
 		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return err
 		}
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch:
+		case ExcludeAtMatch, IncludeAtMatch, JSONParsing, LogfmtParsing, GenerateMetric, Deduplicate, RateLimit:
 			rule.Regex = re
 		case MaskSequences:
 			rule.Regex = re
@@ -83,3 +188,46 @@ func CompileProcessingRules(rules []*ProcessingRule) error {
 	}
 	return nil
 }
+
+func validateGenerateMetricRule(rule *ProcessingRule) error {
+	if rule.MetricName == "" {
+		return fmt.Errorf("no metric name provided for processing rule: %s", rule.Name)
+	}
+	switch rule.MetricType {
+	case "", CountMetricType:
+		break
+	case GaugeMetricType, DistributionMetricType:
+		if rule.ValueGroup == "" {
+			return fmt.Errorf("a value group must be set for %s metrics in processing rule: %s", rule.MetricType, rule.Name)
+		}
+	default:
+		return fmt.Errorf("metric type %s is not supported for processing rule: %s", rule.MetricType, rule.Name)
+	}
+	if rule.ValueGroup != "" {
+		// an invalid pattern is reported by the caller
+		if re, err := regexp.Compile(rule.Pattern); err == nil && valueGroupIndex(re, rule.ValueGroup) < 0 {
+			return fmt.Errorf("value group %s not found in pattern %s for processing rule: %s", rule.ValueGroup, rule.Pattern, rule.Name)
+		}
+	}
+	return nil
+}
+
+// MetricValueGroupIndex returns the index of the capture group holding
+// the metric value of a generate_metric rule, or -1 if there is none.
+func (rule *ProcessingRule) MetricValueGroupIndex() int {
+	if rule.ValueGroup == "" || rule.Regex == nil {
+		return -1
+	}
+	return valueGroupIndex(rule.Regex, rule.ValueGroup)
+}
+
+func valueGroupIndex(re *regexp.Regexp, group string) int {
+	if index := re.SubexpIndex(group); index >= 0 {
+		return index
+	}
+	index, err := strconv.Atoi(group)
+	if err != nil || index <= 0 || index > re.NumSubexp() {
+		return -1
+	}
+	return index
+}
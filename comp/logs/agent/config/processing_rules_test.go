@@ -9,6 +9,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -33,3 +34,92 @@ func TestCompileShouldFailWithInvalidRules(t *testing.T) {
 		assert.Nil(t, rule.Regex)
 	}
 }
+
+func TestCompileParsingRules(t *testing.T) {
+	rules := []*ProcessingRule{
+		{Type: JSONParsing},
+		{Type: LogfmtParsing, Pattern: "^level="},
+		{Type: GrokParsing, Pattern: "%{TIMESTAMP_ISO8601:timestamp} %{LOGLEVEL:level} %{GREEDYDATA:message}"},
+	}
+	err := CompileProcessingRules(rules)
+	assert.Nil(t, err)
+	assert.Nil(t, rules[0].Regex)
+	assert.True(t, rules[1].Regex.MatchString("level=info msg=hello"))
+	assert.Equal(t, []string{"", "timestamp", "level", "message"}, rules[2].Regex.SubexpNames())
+	assert.True(t, rules[2].Regex.MatchString("2024-01-01T10:00:00Z WARN something happened"))
+}
+
+func TestValidateParsingRules(t *testing.T) {
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "json", Type: JSONParsing}}))
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "logfmt", Type: LogfmtParsing}}))
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "grok", Type: GrokParsing, Pattern: "%{WORD:http.method} %{PATH}"}}))
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "grok", Type: GrokParsing}}))
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "grok", Type: GrokParsing, Pattern: "%{UNKNOWN:field}"}}))
+}
+
+func TestExpandGrokPattern(t *testing.T) {
+	expanded, err := ExpandGrokPattern("%{WORD:http.method} %{INT}")
+	assert.Nil(t, err)
+	assert.Equal(t, `(?P<http__method>\b\w+\b) (?:[+-]?\d+)`, expanded)
+	assert.Equal(t, "http.method", GrokFieldName("http__method"))
+}
+
+func TestValidateGenerateMetricRules(t *testing.T) {
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "errors", Type: GenerateMetric, Pattern: "ERROR", MetricName: "app.errors"}}))
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "latency", Type: GenerateMetric, Pattern: `took (?P<ms>\d+)ms`, MetricName: "app.latency", MetricType: DistributionMetricType, ValueGroup: "ms"}}))
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "queue", Type: GenerateMetric, Pattern: `queue=(\d+)`, MetricName: "app.queue", MetricType: GaugeMetricType, ValueGroup: "1"}}))
+
+	// missing metric name
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "errors", Type: GenerateMetric, Pattern: "ERROR"}}))
+	// unknown metric type
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "errors", Type: GenerateMetric, Pattern: "ERROR", MetricName: "app.errors", MetricType: "histogram"}}))
+	// gauge without value
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "queue", Type: GenerateMetric, Pattern: `queue=(\d+)`, MetricName: "app.queue", MetricType: GaugeMetricType}}))
+	// unknown group
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "queue", Type: GenerateMetric, Pattern: `queue=(\d+)`, MetricName: "app.queue", MetricType: GaugeMetricType, ValueGroup: "2"}}))
+	// missing pattern
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "errors", Type: GenerateMetric, MetricName: "app.errors"}}))
+}
+
+func TestMetricValueGroupIndex(t *testing.T) {
+	rules := []*ProcessingRule{
+		{Type: GenerateMetric, Pattern: `took (?P<ms>\d+)ms`, ValueGroup: "ms"},
+		{Type: GenerateMetric, Pattern: `queue=(\d+)`, ValueGroup: "1"},
+		{Type: GenerateMetric, Pattern: `ERROR`},
+	}
+	assert.Nil(t, CompileProcessingRules(rules))
+	assert.Equal(t, 1, rules[0].MetricValueGroupIndex())
+	assert.Equal(t, 1, rules[1].MetricValueGroupIndex())
+	assert.Equal(t, -1, rules[2].MetricValueGroupIndex())
+}
+
+func TestValidateThrottlingRules(t *testing.T) {
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "dedup", Type: Deduplicate}}))
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "dedup", Type: Deduplicate, Window: "1m", Pattern: `\d+`}}))
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "limit", Type: RateLimit, Rate: 0.5}}))
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "limit", Type: RateLimit, Rate: 100, Burst: 500, Pattern: `user=(\w+)`}}))
+
+	// invalid window
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "dedup", Type: Deduplicate, Window: "10"}}))
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "dedup", Type: Deduplicate, Window: "-1s"}}))
+	// missing rate
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "limit", Type: RateLimit}}))
+	// invalid burst
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "limit", Type: RateLimit, Rate: 1, Burst: -1}}))
+	// invalid pattern
+	assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "limit", Type: RateLimit, Rate: 1, Pattern: "("}}))
+}
+
+func TestCompileThrottlingRules(t *testing.T) {
+	rules := []*ProcessingRule{
+		{Type: Deduplicate},
+		{Type: Deduplicate, Window: "1m", Pattern: `\d+`},
+		{Type: RateLimit, Rate: 1},
+	}
+	assert.Nil(t, CompileProcessingRules(rules))
+	assert.Equal(t, defaultDeduplicationWindow, rules[0].WindowDuration)
+	assert.Nil(t, rules[0].Regex)
+	assert.Equal(t, time.Minute, rules[1].WindowDuration)
+	assert.NotNil(t, rules[1].Regex)
+	assert.Nil(t, rules[2].Regex)
+}
@@ -731,6 +731,8 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(t, o.Redis.Enabled)
 	assert.True(t, o.Memcached.Enabled)
 	assert.True(t, o.Memcached.KeepCommand)
+	assert.True(t, o.GraphQL.Enabled)
+	assert.True(t, o.GraphQL.RemoveAliases)
 	assert.True(t, o.CreditCards.Enabled)
 	assert.True(t, o.CreditCards.Luhn)
 
@@ -1918,6 +1920,45 @@ func TestLoadEnv(t *testing.T) {
 		assert.True(t, cfg.Obfuscation.Memcached.Enabled)
 	})
 
+	env = "DD_APM_OBFUSCATION_GRAPHQL_ENABLED"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, "false")
+
+		c := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			fx.Replace(corecomp.MockParams{
+				Params:      corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+				SetupConfig: true,
+			}),
+			MockModule(),
+		))
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.False(t, coreconfig.Datadog().GetBool("apm_config.obfuscation.graphql.enabled"))
+		assert.False(t, cfg.Obfuscation.GraphQL.Enabled)
+	})
+
+	env = "DD_APM_OBFUSCATION_GRAPHQL_REMOVE_ALIASES"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, "false")
+
+		c := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			fx.Replace(corecomp.MockParams{
+				Params:      corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+				SetupConfig: true,
+			}),
+			MockModule(),
+		))
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.False(t, coreconfig.Datadog().GetBool("apm_config.obfuscation.graphql.remove_aliases"))
+		assert.False(t, cfg.Obfuscation.GraphQL.RemoveAliases)
+		assert.True(t, cfg.Obfuscation.GraphQL.Enabled)
+	})
+
 	env = "DD_APM_OBFUSCATION_MEMCACHED_ENABLED"
 	t.Run(env, func(t *testing.T) {
 		t.Setenv(env, "true")
@@ -2562,6 +2603,50 @@ func TestPeerTagsAggregation(t *testing.T) {
 	})
 }
 
+func TestStatsCustomTags(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		assert.Nil(t, cfg.StatsCustomTags)
+		assert.Equal(t, 100, cfg.StatsCustomTagsCardinalityLimit)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		overrides := map[string]interface{}{
+			"apm_config.stats_custom_tags":                   []string{"customer.tier", "region"},
+			"apm_config.stats_custom_tags_cardinality_limit": 20,
+		}
+
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			fx.Replace(corecomp.MockParams{Overrides: overrides}),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		assert.Equal(t, []string{"customer.tier", "region"}, cfg.StatsCustomTags)
+		assert.Equal(t, 20, cfg.StatsCustomTagsCardinalityLimit)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_APM_STATS_CUSTOM_TAGS", `["customer.tier","region"]`)
+		t.Setenv("DD_APM_STATS_CUSTOM_TAGS_CARDINALITY_LIMIT", "0")
+
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		assert.Equal(t, []string{"customer.tier", "region"}, cfg.StatsCustomTags)
+		assert.Equal(t, 0, cfg.StatsCustomTagsCardinalityLimit)
+	})
+}
+
 func TestComputeStatsBySpanKind(t *testing.T) {
 	t.Run("disabled", func(t *testing.T) {
 		config := fxutil.Test[Component](t, fx.Options(
@@ -2716,3 +2801,154 @@ func TestDisableReceiverConfig(t *testing.T) {
 
 	assert.False(t, cfg.ReceiverEnabled)
 }
+
+func TestTailSampling(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		assert.False(t, cfg.TailSampling.Enabled)
+		assert.Equal(t, 10*time.Second, cfg.TailSampling.DecisionWait)
+		assert.Empty(t, cfg.TailSampling.Policies)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_APM_TAIL_SAMPLING_ENABLED", "true")
+		t.Setenv("DD_APM_TAIL_SAMPLING_DECISION_WAIT", "30s")
+		t.Setenv("DD_APM_TAIL_SAMPLING_MAX_MEMORY_BYTES", "1000")
+		t.Setenv("DD_APM_TAIL_SAMPLING_POLICIES", `[{"type":"error"}, {"name":"slow","type":"latency","threshold":"500ms"}, {"type":"rate_limit","tps":2.5}]`)
+
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			fx.Replace(corecomp.MockParams{
+				Params:      corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+				SetupConfig: true,
+			}),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		assert.True(t, cfg.TailSampling.Enabled)
+		assert.Equal(t, 30*time.Second, cfg.TailSampling.DecisionWait)
+		assert.EqualValues(t, 1000, cfg.TailSampling.MaxMemoryBytes)
+		assert.Equal(t, []*traceconfig.TailSamplingPolicy{
+			{Name: "error", Type: "error"},
+			{Name: "slow", Type: "latency", Threshold: 500 * time.Millisecond},
+			{Name: "rate_limit", Type: "rate_limit", TPS: 2.5},
+		}, cfg.TailSampling.Policies)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		overrides := map[string]interface{}{
+			"apm_config.tail_sampling.policies": []interface{}{
+				map[interface{}]interface{}{"type": "tag", "key": "customer.tier", "value": "premium"},
+			},
+		}
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			fx.Replace(corecomp.MockParams{Overrides: overrides}),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		assert.Equal(t, []*traceconfig.TailSamplingPolicy{
+			{Name: "tag", Type: "tag", Key: "customer.tier", Value: "premium"},
+		}, cfg.TailSampling.Policies)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, tt := range []struct {
+			policy *traceconfig.TailSamplingPolicy
+			err    string
+		}{
+			{&traceconfig.TailSamplingPolicy{Type: "latency"}, `policy 0: latency policies must have a positive "threshold"`},
+			{&traceconfig.TailSamplingPolicy{Type: "tag", Value: "v"}, `policy 0: tag policies must have a "key"`},
+			{&traceconfig.TailSamplingPolicy{Type: "rate_limit"}, `policy 0: rate_limit policies must have a positive "tps"`},
+			{&traceconfig.TailSamplingPolicy{Type: "unknown"}, `policy 0: unknown type "unknown"`},
+		} {
+			err := validateTailSamplingPolicies([]*traceconfig.TailSamplingPolicy{tt.policy})
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+}
+
+func TestSpanRules(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		assert.Empty(t, cfg.SpanRules)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_APM_SPAN_RULES", `[{"name":"^http","tags":{"http.route":"^/health"},"action":"drop"}, {"service":"web","action":"copy_tag","key":"customer.id","target":"usr.id"}]`)
+
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			fx.Replace(corecomp.MockParams{
+				Params:      corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+				SetupConfig: true,
+			}),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		require.Len(t, cfg.SpanRules, 2)
+		drop := cfg.SpanRules[0]
+		assert.Equal(t, traceconfig.SpanRuleDrop, drop.Action)
+		assert.Equal(t, "^http", drop.NameRe.String())
+		assert.Nil(t, drop.ServiceRe)
+		require.Contains(t, drop.TagsRe, "http.route")
+		assert.Equal(t, "^/health", drop.TagsRe["http.route"].String())
+		copyTag := cfg.SpanRules[1]
+		assert.Equal(t, traceconfig.SpanRuleCopyTag, copyTag.Action)
+		assert.Equal(t, "web", copyTag.ServiceRe.String())
+		assert.Equal(t, "customer.id", copyTag.Key)
+		assert.Equal(t, "usr.id", copyTag.Target)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		overrides := map[string]interface{}{
+			"apm_config.span_rules": []interface{}{
+				map[interface{}]interface{}{
+					"tags":   map[interface{}]interface{}{"http.route": "^/health"},
+					"action": "set_tag",
+					"key":    "health",
+					"value":  "true",
+				},
+			},
+		}
+		config := fxutil.Test[Component](t, fx.Options(
+			corecomp.MockModule(),
+			fx.Replace(corecomp.MockParams{Overrides: overrides}),
+			MockModule(),
+		))
+		cfg := config.Object()
+		require.NotNil(t, cfg)
+		require.Len(t, cfg.SpanRules, 1)
+		assert.Equal(t, "health", cfg.SpanRules[0].Key)
+		assert.Equal(t, "true", cfg.SpanRules[0].Value)
+		assert.Contains(t, cfg.SpanRules[0].TagsRe, "http.route")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, tt := range []struct {
+			rule *traceconfig.SpanRule
+			err  string
+		}{
+			{&traceconfig.SpanRule{Action: "set_tag"}, `rule 0: set_tag rules must have a "key"`},
+			{&traceconfig.SpanRule{Action: "rename_tag", Key: "a"}, `rule 0: rename_tag rules must have a "key" and a "target"`},
+			{&traceconfig.SpanRule{Action: "unknown"}, `rule 0: unknown action "unknown"`},
+			{&traceconfig.SpanRule{Action: "drop", Service: "("}, "rule 0: service: error parsing regexp: missing closing ): `(`"},
+			{&traceconfig.SpanRule{Action: "drop", Tags: map[string]string{"k": "["}}, "rule 0: tag \"k\": error parsing regexp: missing closing ]: `[`"},
+		} {
+			assert.EqualError(t, compileSpanRules([]*traceconfig.SpanRule{tt.rule}), tt.err)
+		}
+	})
+}
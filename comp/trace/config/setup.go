@@ -20,6 +20,7 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes"
+	"github.com/mitchellh/mapstructure"
 
 	corecompcfg "github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/comp/core/tagger"
@@ -218,6 +219,9 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	if core.IsSet("apm_config.receiver_socket") {
 		c.ReceiverSocket = core.GetString("apm_config.receiver_socket")
 	}
+	c.ZipkinReceiverEnabled = core.GetBool("apm_config.zipkin_receiver_enabled")
+	c.JaegerReceiverEnabled = core.GetBool("apm_config.jaeger_receiver_enabled")
+	c.JaegerGRPCPort = core.GetInt("apm_config.jaeger_receiver_grpc_port")
 	if core.IsSet("apm_config.connection_limit") {
 		c.ConnectionLimit = core.GetInt("apm_config.connection_limit")
 	}
@@ -232,6 +236,10 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	if core.IsSet("apm_config.peer_tags") {
 		c.PeerTags = core.GetStringSlice("apm_config.peer_tags")
 	}
+	if core.IsSet("apm_config.stats_custom_tags") {
+		c.StatsCustomTags = core.GetStringSlice("apm_config.stats_custom_tags")
+	}
+	c.StatsCustomTagsCardinalityLimit = core.GetInt("apm_config.stats_custom_tags_cardinality_limit")
 
 	if core.IsSet("apm_config.extra_sample_rate") {
 		c.ExtraSampleRate = core.GetFloat64("apm_config.extra_sample_rate")
@@ -272,6 +280,30 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.ProbabilisticSamplerHashSeed = uint32(core.GetInt("apm_config.probabilistic_sampler.hash_seed"))
 	}
 
+	if core.IsSet("apm_config.tail_sampling.enabled") {
+		c.TailSampling.Enabled = core.GetBool("apm_config.tail_sampling.enabled")
+	}
+	if core.IsSet("apm_config.tail_sampling.decision_wait") {
+		c.TailSampling.DecisionWait = core.GetDuration("apm_config.tail_sampling.decision_wait")
+	}
+	if core.IsSet("apm_config.tail_sampling.max_memory_bytes") {
+		c.TailSampling.MaxMemoryBytes = core.GetInt64("apm_config.tail_sampling.max_memory_bytes")
+	}
+	if k := "apm_config.tail_sampling.policies"; core.IsSet(k) {
+		policies := make([]*config.TailSamplingPolicy, 0)
+		if err := decodeTailSamplingPolicies(core.Get(k), &policies); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"type\": \"latency\", \"threshold\": \"500ms\"}]', error: %v", k, err)
+		} else {
+			if err := validateTailSamplingPolicies(policies); err != nil {
+				return fmt.Errorf("tail_sampling.policies: %s", err)
+			}
+			c.TailSampling.Policies = policies
+		}
+	}
+	if c.TailSampling.Enabled && c.TailSampling.DecisionWait <= 0 {
+		return errors.New("tail_sampling.decision_wait must be positive")
+	}
+
 	if core.IsSet("apm_config.max_remote_traces_per_second") {
 		c.MaxRemoteTPS = core.GetFloat64("apm_config.max_remote_traces_per_second")
 	}
@@ -322,6 +354,18 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		}
 	}
 
+	if k := "apm_config.span_rules"; core.IsSet(k) {
+		rules := make([]*config.SpanRule, 0)
+		if err := coreconfig.Datadog().UnmarshalKey(k, &rules); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"service\": \"pattern\",\"name\": \"pattern\",\"tags\": {\"tag_name\": \"pattern\"},\"action\": \"drop\"}]', error: %v", k, err)
+		} else {
+			if err := compileSpanRules(rules); err != nil {
+				return fmt.Errorf("span_rules: %s", err)
+			}
+			c.SpanRules = rules
+		}
+	}
+
 	if core.IsSet("bind_host") || core.IsSet("apm_config.apm_non_local_traffic") {
 		if core.IsSet("bind_host") {
 			host := core.GetString("bind_host")
@@ -410,6 +454,7 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.Obfuscation.Mongo.Enabled = true
 		c.Obfuscation.Memcached.Enabled = true
 		c.Obfuscation.Redis.Enabled = true
+		c.Obfuscation.GraphQL.Enabled = true
 		c.Obfuscation.CreditCards.Enabled = true
 
 		// TODO(x): There is an issue with coreconfig.Datadog().IsSet("apm_config.obfuscation"), probably coming from Viper,
@@ -445,6 +490,12 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		if coreconfig.Datadog().IsSet("apm_config.obfuscation.http.remove_paths_with_digits") {
 			c.Obfuscation.HTTP.RemovePathDigits = coreconfig.Datadog().GetBool("apm_config.obfuscation.http.remove_paths_with_digits")
 		}
+		if coreconfig.Datadog().IsSet("apm_config.obfuscation.graphql.enabled") {
+			c.Obfuscation.GraphQL.Enabled = coreconfig.Datadog().GetBool("apm_config.obfuscation.graphql.enabled")
+		}
+		if coreconfig.Datadog().IsSet("apm_config.obfuscation.graphql.remove_aliases") {
+			c.Obfuscation.GraphQL.RemoveAliases = coreconfig.Datadog().GetBool("apm_config.obfuscation.graphql.remove_aliases")
+		}
 		if coreconfig.Datadog().IsSet("apm_config.obfuscation.memcached.enabled") {
 			c.Obfuscation.Memcached.Enabled = coreconfig.Datadog().GetBool("apm_config.obfuscation.memcached.enabled")
 		}
@@ -720,6 +771,89 @@ func compileReplaceRules(rules []*config.ReplaceRule) error {
 	return nil
 }
 
+// compileSpanRules validates the actions and compiles the regular expressions of the span rules.
+// If it fails it returns the first error.
+func compileSpanRules(rules []*config.SpanRule) error {
+	for i, r := range rules {
+		switch r.Action {
+		case config.SpanRuleDrop:
+		case config.SpanRuleSetTag, config.SpanRuleDeleteTag:
+			if r.Key == "" {
+				return fmt.Errorf("rule %d: %s rules must have a \"key\"", i, r.Action)
+			}
+		case config.SpanRuleRenameTag, config.SpanRuleCopyTag:
+			if r.Key == "" || r.Target == "" {
+				return fmt.Errorf("rule %d: %s rules must have a \"key\" and a \"target\"", i, r.Action)
+			}
+		default:
+			return fmt.Errorf("rule %d: unknown action %q", i, r.Action)
+		}
+		var err error
+		if r.Service != "" {
+			if r.ServiceRe, err = regexp.Compile(r.Service); err != nil {
+				return fmt.Errorf("rule %d: service: %s", i, err)
+			}
+		}
+		if r.Name != "" {
+			if r.NameRe, err = regexp.Compile(r.Name); err != nil {
+				return fmt.Errorf("rule %d: name: %s", i, err)
+			}
+		}
+		if len(r.Tags) > 0 {
+			r.TagsRe = make(map[string]*regexp.Regexp, len(r.Tags))
+			for k, pattern := range r.Tags {
+				if r.TagsRe[k], err = regexp.Compile(pattern); err != nil {
+					return fmt.Errorf("rule %d: tag %q: %s", i, k, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateTailSamplingPolicies checks the settings of each tail sampling policy and defaults
+// their name to their type. If it fails it returns the first error.
+func validateTailSamplingPolicies(policies []*config.TailSamplingPolicy) error {
+	for i, p := range policies {
+		switch p.Type {
+		case config.TailSamplingPolicyError:
+		case config.TailSamplingPolicyLatency:
+			if p.Threshold <= 0 {
+				return fmt.Errorf("policy %d: latency policies must have a positive \"threshold\"", i)
+			}
+		case config.TailSamplingPolicyTag:
+			if p.Key == "" {
+				return fmt.Errorf("policy %d: tag policies must have a \"key\"", i)
+			}
+		case config.TailSamplingPolicyRateLimit:
+			if p.TPS <= 0 {
+				return fmt.Errorf("policy %d: rate_limit policies must have a positive \"tps\"", i)
+			}
+		default:
+			return fmt.Errorf("policy %d: unknown type %q", i, p.Type)
+		}
+		if p.Name == "" {
+			p.Name = p.Type
+		}
+	}
+	return nil
+}
+
+// decodeTailSamplingPolicies decodes the raw value of the tail sampling policies. UnmarshalKey
+// can't be used as it doesn't find the keys nested under apm_config when they are only set from
+// the environment.
+func decodeTailSamplingPolicies(in interface{}, policies *[]*config.TailSamplingPolicy) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           policies,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(in)
+}
+
 // getDuration returns the duration of the provided value in seconds
 func getDuration(seconds int) time.Duration {
 	return time.Duration(seconds) * time.Second
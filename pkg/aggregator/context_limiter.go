@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// overflowTag is the tag of the contexts into which the contexts beyond the limits are folded
+	overflowTag = "overflow:true"
+
+	overflowReasonMetric = "metric"
+	overflowReasonOrigin = "origin"
+)
+
+// contextLimiter limits the number of contexts tracked for each metric name and for each
+// origin. A limit of 0 means no limit. It is shared by the context resolvers of all the
+// DogStatsD pipelines, so that the limits apply to the contexts of the whole agent.
+type contextLimiter struct {
+	mu sync.Mutex
+
+	metricLimit  int
+	metricLimits map[string]int
+	originLimit  int
+
+	countsByMetric map[string]int
+	countsByOrigin map[ckey.TagsKey]int
+}
+
+// newContextLimiter returns a contextLimiter, or nil when no limit is set.
+func newContextLimiter(metricLimit int, metricLimits map[string]int, originLimit int) *contextLimiter {
+	enabled := metricLimit > 0 || originLimit > 0
+	for _, limit := range metricLimits {
+		enabled = enabled || limit > 0
+	}
+	if !enabled {
+		return nil
+	}
+
+	return &contextLimiter{
+		metricLimit:    metricLimit,
+		metricLimits:   metricLimits,
+		originLimit:    originLimit,
+		countsByMetric: make(map[string]int),
+		countsByOrigin: make(map[ckey.TagsKey]int),
+	}
+}
+
+// newContextLimiterFromConfig returns the contextLimiter of the DogStatsD contexts,
+// or nil when no limit is configured.
+func newContextLimiterFromConfig() *contextLimiter {
+	limits, err := config.GetDogstatsdContextLimits()
+	if err != nil {
+		log.Errorf("Ignoring the limits of contexts by metric: %v", err)
+	}
+	metricLimits := make(map[string]int, len(limits))
+	for _, limit := range limits {
+		metricLimits[limit.MetricName] = limit.Limit
+	}
+
+	return newContextLimiter(
+		config.Datadog().GetInt("dogstatsd_context_limit_per_metric"),
+		metricLimits,
+		config.Datadog().GetInt("dogstatsd_context_limit_per_origin"),
+	)
+}
+
+// tryTrack counts a new context of the metric and origin, or returns the reason why it
+// can't be tracked. The contexts without origin are only limited per metric.
+func (l *contextLimiter) tryTrack(name string, origin ckey.TagsKey, hasOrigin bool) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, ok := l.metricLimits[name]
+	if !ok {
+		limit = l.metricLimit
+	}
+	if limit > 0 && l.countsByMetric[name] >= limit {
+		return overflowReasonMetric
+	}
+	if hasOrigin && l.originLimit > 0 && l.countsByOrigin[origin] >= l.originLimit {
+		return overflowReasonOrigin
+	}
+
+	l.countsByMetric[name]++
+	if hasOrigin {
+		l.countsByOrigin[origin]++
+	}
+	return ""
+}
+
+// remove stops counting a context of the metric and origin.
+func (l *contextLimiter) remove(name string, origin ckey.TagsKey, hasOrigin bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.countsByMetric[name] <= 1 {
+		delete(l.countsByMetric, name)
+	} else {
+		l.countsByMetric[name]--
+	}
+	if !hasOrigin {
+		return
+	}
+	if l.countsByOrigin[origin] <= 1 {
+		delete(l.countsByOrigin, origin)
+	} else {
+		l.countsByOrigin[origin]--
+	}
+}
@@ -27,6 +27,7 @@ type Context struct {
 	metricTags *tags.Entry
 	noIndex    bool
 	source     metrics.MetricSource
+	overflow   bool
 }
 
 type resolverEntry struct {
@@ -75,6 +76,8 @@ type contextResolver struct {
 	keyGenerator     *ckey.KeyGenerator
 	taggerBuffer     *tagset.HashingTagsAccumulator
 	metricBuffer     *tagset.HashingTagsAccumulator
+	// limiter limits the number of contexts per metric and per origin, nil if there is no limit
+	limiter *contextLimiter
 }
 
 // generateContextKey generates the contextKey associated with the context of the metricSample
@@ -105,34 +108,58 @@ func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSample
 
 	contextKey, taggerKey, metricKey := cr.generateContextKey(metricSampleContext) // the generator will remove duplicates (and doesn't mind the order)
 
-	if entry, ok := cr.contextsByKey[contextKey]; !ok {
-		mtype := metricSampleContext.GetMetricType()
-		context := &Context{
-			Name:       metricSampleContext.GetName(),
-			taggerTags: cr.tagsCache.Insert(taggerKey, cr.taggerBuffer),
-			metricTags: cr.tagsCache.Insert(metricKey, cr.metricBuffer),
-			Host:       metricSampleContext.GetHost(),
-			mtype:      mtype,
-			noIndex:    metricSampleContext.IsNoIndex(),
-			source:     metricSampleContext.GetSource(),
-		}
-		cr.contextsByKey[contextKey] = resolverEntry{
-			lastSeen: timestamp,
-			context:  context,
-		}
-
-		cr.seendByMtype[mtype] = true
-		cr.countsByMtype[mtype]++
-		cr.bytesByMtype[mtype] += uint64(context.SizeInBytes())
-		cr.dataBytesByMtype[mtype] += uint64(context.DataSizeInBytes())
-	} else {
+	if entry, ok := cr.contextsByKey[contextKey]; ok {
 		// We can't assign to a field of a struct contained in map
 		cr.contextsByKey[contextKey] = resolverEntry{
 			lastSeen: timestamp,
 			context:  entry.context,
 		}
+		return contextKey
+	}
+
+	overflow := false
+	if cr.limiter != nil {
+		name := metricSampleContext.GetName()
+		hasOrigin := len(cr.taggerBuffer.Get()) > 0
+		if reason := cr.limiter.tryTrack(name, taggerKey, hasOrigin); reason != "" {
+			// Fold the context into the overflow context of the metric and origin, which
+			// only keeps the origin tags.
+			tlmDogstatsdContextsOverflow.Inc(cr.id, reason)
+			cr.metricBuffer.Reset()
+			cr.metricBuffer.Append(overflowTag)
+			contextKey, taggerKey, metricKey = cr.generateContextKey(metricSampleContext)
+			if entry, ok := cr.contextsByKey[contextKey]; ok {
+				cr.contextsByKey[contextKey] = resolverEntry{
+					lastSeen: timestamp,
+					context:  entry.context,
+				}
+				return contextKey
+			}
+			overflow = true
+		}
+	}
+
+	mtype := metricSampleContext.GetMetricType()
+	context := &Context{
+		Name:       metricSampleContext.GetName(),
+		taggerTags: cr.tagsCache.Insert(taggerKey, cr.taggerBuffer),
+		metricTags: cr.tagsCache.Insert(metricKey, cr.metricBuffer),
+		Host:       metricSampleContext.GetHost(),
+		mtype:      mtype,
+		noIndex:    metricSampleContext.IsNoIndex(),
+		source:     metricSampleContext.GetSource(),
+		overflow:   overflow,
+	}
+	cr.contextsByKey[contextKey] = resolverEntry{
+		lastSeen: timestamp,
+		context:  context,
 	}
 
+	cr.seendByMtype[mtype] = true
+	cr.countsByMtype[mtype]++
+	cr.bytesByMtype[mtype] += uint64(context.SizeInBytes())
+	cr.dataBytesByMtype[mtype] += uint64(context.DataSizeInBytes())
+
 	return contextKey
 }
 
@@ -150,6 +177,11 @@ func (cr *contextResolver) remove(expiredContextKey ckey.ContextKey) {
 	delete(cr.contextsByKey, expiredContextKey)
 
 	if context != nil {
+		if cr.limiter != nil && !context.overflow {
+			taggerTags := context.taggerTags.Tags()
+			taggerKey := ckey.TagsKey(tagset.NewHashingTagsAccumulatorWithTags(taggerTags).Hash())
+			cr.limiter.remove(context.Name, taggerKey, len(taggerTags) > 0)
+		}
 		cr.countsByMtype[context.mtype]--
 		cr.bytesByMtype[context.mtype] -= uint64(context.SizeInBytes())
 		cr.dataBytesByMtype[context.mtype] -= uint64(context.DataSizeInBytes())
@@ -217,11 +249,16 @@ type timestampContextResolver struct {
 
 	contextExpireTime int64
 	counterExpireTime int64
+	// extraTTL returns how long a context is kept in addition to its expiration time, nil if the
+	// contexts are not kept longer
+	extraTTL func(*Context) int64
 }
 
-func newTimestampContextResolver(cache *tags.Store, id string, contextExpireTime, counterExpireTime int64) *timestampContextResolver {
+func newTimestampContextResolver(cache *tags.Store, id string, contextExpireTime, counterExpireTime int64, limiter *contextLimiter) *timestampContextResolver {
+	resolver := newContextResolver(cache, id)
+	resolver.limiter = limiter
 	return &timestampContextResolver{
-		resolver: newContextResolver(cache, id),
+		resolver: resolver,
 
 		contextExpireTime: contextExpireTime,
 		counterExpireTime: counterExpireTime,
@@ -253,6 +290,9 @@ func (cr *timestampContextResolver) expireContexts(timestamp int64) {
 		if entry.context.mtype == metrics.CounterType {
 			ttl = cr.counterExpireTime
 		}
+		if cr.extraTTL != nil {
+			ttl += cr.extraTTL(entry.context)
+		}
 		if entry.lastSeen+ttl < timestamp {
 			cr.resolver.remove(ck)
 		}
@@ -21,6 +21,7 @@ type ContextDebugRepr struct {
 	MetricTags []string
 	NoIndex    bool
 	Source     metrics.MetricSource
+	Overflow   bool
 }
 
 func (cr *contextResolver) dumpContexts(dest io.Writer) error {
@@ -36,6 +37,7 @@ func (cr *contextResolver) dumpContexts(dest io.Writer) error {
 			MetricTags: c.metricTags.Tags(),
 			NoIndex:    c.noIndex,
 			Source:     c.source,
+			Overflow:   c.overflow,
 		})
 		if err != nil {
 			return err
@@ -8,6 +8,7 @@
 package aggregator
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -138,7 +139,7 @@ func testExpireContexts(t *testing.T, store *tags.Store) {
 		Tags:       []string{"foo"},
 		SampleRate: 1,
 	}
-	contextResolver := newTimestampContextResolver(store, "test", 2, 4)
+	contextResolver := newTimestampContextResolver(store, "test", 2, 4, nil)
 
 	// Track the 2 contexts
 	contextKey1 := contextResolver.trackContext(&mSample1, 4) // expires after 6
@@ -283,3 +284,79 @@ func TestOriginTelemetry(t *testing.T) {
 		Points: []metrics.Point{{Ts: ts, Value: 1.0}},
 	}})
 }
+
+func testContextLimitPerMetric(t *testing.T, store *tags.Store) {
+	r := newTimestampContextResolver(store, "test", 2, 4, newContextLimiter(2, map[string]int{"unlimited": 0}, 0))
+
+	k1 := r.trackContext(&mockSample{"foo", nil, []string{"a:1"}}, 0)
+	k2 := r.trackContext(&mockSample{"foo", nil, []string{"a:2"}}, 0)
+	// the contexts beyond the limit are folded into a single overflow context
+	k3 := r.trackContext(&mockSample{"foo", nil, []string{"a:3"}}, 0)
+	k4 := r.trackContext(&mockSample{"foo", nil, []string{"a:4"}}, 0)
+	assert.Equal(t, k3, k4)
+	assert.NotEqual(t, k1, k3)
+	assert.NotEqual(t, k2, k3)
+	cx, ok := r.get(k3)
+	require.True(t, ok)
+	assertContext(t, cx, "foo", []string{overflowTag}, "noop")
+	assert.True(t, cx.overflow)
+
+	// the tracked contexts are still resolved
+	assert.Equal(t, k1, r.trackContext(&mockSample{"foo", nil, []string{"a:1"}}, 0))
+
+	// the limits are per metric
+	r.trackContext(&mockSample{"bar", nil, []string{"a:1"}}, 0)
+	k5 := r.trackContext(&mockSample{"bar", nil, []string{"a:2"}}, 0)
+	cx, _ = r.get(k5)
+	assert.False(t, cx.overflow)
+
+	for i := 0; i < 5; i++ {
+		k := r.trackContext(&mockSample{"unlimited", nil, []string{fmt.Sprintf("a:%d", i)}}, 0)
+		cx, _ = r.get(k)
+		assert.False(t, cx.overflow)
+	}
+
+	// the expired contexts make room for new ones
+	r.trackContext(&mockSample{"foo", nil, []string{"a:1"}}, 3)
+	r.expireContexts(3)
+	k6 := r.trackContext(&mockSample{"foo", nil, []string{"a:6"}}, 3)
+	cx, _ = r.get(k6)
+	assert.False(t, cx.overflow)
+	assertContext(t, cx, "foo", []string{"a:6"}, "noop")
+}
+
+func TestContextLimitPerMetric(t *testing.T) {
+	testWithTagsStore(t, testContextLimitPerMetric)
+}
+
+func testContextLimitPerOrigin(t *testing.T, store *tags.Store) {
+	r := newTimestampContextResolver(store, "test", 2, 4, newContextLimiter(0, nil, 2))
+
+	r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"a:1"}}, 0)
+	r.trackContext(&mockSample{"bar", []string{"pod:a"}, []string{"a:1"}}, 0)
+	k := r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"a:2"}}, 0)
+	cx, _ := r.get(k)
+	assert.True(t, cx.overflow)
+	// the overflow context keeps the origin tags
+	assertContext(t, cx, "foo", []string{"pod:a", overflowTag}, "noop")
+
+	k = r.trackContext(&mockSample{"foo", []string{"pod:b"}, []string{"a:2"}}, 0)
+	cx, _ = r.get(k)
+	assert.False(t, cx.overflow)
+
+	// the contexts without origin are not limited
+	for i := 0; i < 5; i++ {
+		k := r.trackContext(&mockSample{"foo", nil, []string{fmt.Sprintf("a:%d", i)}}, 0)
+		cx, _ = r.get(k)
+		assert.False(t, cx.overflow)
+	}
+}
+
+func TestContextLimitPerOrigin(t *testing.T) {
+	testWithTagsStore(t, testContextLimitPerOrigin)
+}
+
+func TestNoContextLimiter(t *testing.T) {
+	assert.Nil(t, newContextLimiter(0, map[string]int{"foo": 0}, 0))
+	assert.NotNil(t, newContextLimiter(0, map[string]int{"foo": 10}, 0))
+}
@@ -65,6 +65,11 @@ type AgentDemultiplexer struct {
 
 	// sharded statsd time samplers
 	statsd
+
+	// openMetrics exposes the flushed metrics over HTTP, nil when it is not enabled
+	openMetrics *openMetricsExposition
+	// derivedMetrics computes metrics from the flushed series, nil when none is configured
+	derivedMetrics *derivedMetrics
 }
 
 // AgentDemultiplexerOptions are the options used to initialize a Demultiplexer.
@@ -165,11 +170,14 @@ func initAgentDemultiplexer(
 
 	statsdWorkers := make([]*timeSamplerWorker, statsdPipelinesCount)
 
+	// the context limits apply to all the pipelines
+	contextLimiter := newContextLimiterFromConfig()
+
 	for i := 0; i < statsdPipelinesCount; i++ {
 		// the sampler
 		tagsStore := tags.NewStore(config.Datadog().GetBool("aggregator_use_tags_store"), fmt.Sprintf("timesampler #%d", i))
 
-		statsdSampler := NewTimeSampler(TimeSamplerID(i), bucketSize, tagsStore, agg.hostname)
+		statsdSampler := newTimeSampler(TimeSamplerID(i), bucketSize, tagsStore, agg.hostname, contextLimiter)
 
 		// its worker (process loop + flush/serialization mechanism)
 
@@ -217,6 +225,9 @@ func initAgentDemultiplexer(
 			metricSamplePool:  metricSamplePool,
 			noAggStreamWorker: noAggWorker,
 		},
+
+		openMetrics:    newOpenMetricsExpositionFromConfig(),
+		derivedMetrics: newDerivedMetricsFromConfig(agg.hostname),
 	}
 
 	return demux
@@ -280,6 +291,12 @@ func (d *AgentDemultiplexer) run() {
 		go d.noAggStreamWorker.run()
 	}
 
+	if d.openMetrics != nil {
+		if err := d.openMetrics.start(); err != nil {
+			d.log.Errorf("error starting the OpenMetrics exposition: %v", err)
+		}
+	}
+
 	d.flushLoop() // this is the blocking call
 }
 
@@ -338,6 +355,10 @@ func (d *AgentDemultiplexer) Stop(flush bool) {
 	// stops the flushloop and makes sure no automatic flushes will happen anymore
 	d.stopChan <- struct{}{}
 
+	if d.openMetrics != nil {
+		d.openMetrics.stop()
+	}
+
 	d.m.Lock()
 	defer d.m.Unlock()
 
@@ -406,6 +427,16 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 		series,
 		sketches,
 		func(seriesSink metrics.SerieSink, sketchesSink metrics.SketchesSink) {
+			if d.openMetrics != nil {
+				seriesSink = d.openMetrics.serieSink(seriesSink)
+				sketchesSink = d.openMetrics.sketchesSink(sketchesSink)
+			}
+			// the derived metrics are computed from the series of the samplers
+			samplersSeriesSink := seriesSink
+			if d.derivedMetrics != nil {
+				samplersSeriesSink = d.derivedMetrics.serieSink(seriesSink)
+			}
+
 			// flush DogStatsD pipelines (statsd/time samplers)
 			// ------------------------------------------------
 
@@ -417,7 +448,7 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 						blockChan: make(chan struct{}),
 					},
 					sketchesSink: sketchesSink,
-					seriesSink:   seriesSink,
+					seriesSink:   samplersSeriesSink,
 				}
 
 				worker.flushChan <- t
@@ -435,12 +466,16 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 						waitForSerializer: waitForSerializer,
 					},
 					sketchesSink: sketchesSink,
-					seriesSink:   seriesSink,
+					seriesSink:   samplersSeriesSink,
 				}
 
 				d.aggregator.flushChan <- t
 				<-t.trigger.blockChan
 			}
+
+			if d.derivedMetrics != nil {
+				d.derivedMetrics.flush(float64(start.Unix()), seriesSink)
+			}
 		}, func(serieSource metrics.SerieSource) {
 			sendIterableSeries(d.sharedSerializer, start, serieSource)
 		},
@@ -455,6 +490,10 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 			}
 		})
 
+	if d.openMetrics != nil {
+		d.openMetrics.publish()
+	}
+
 	addFlushTime("MainFlushTime", int64(time.Since(start)))
 	aggregatorNumberOfFlush.Add(1)
 }
@@ -22,6 +22,8 @@ import (
 	"github.com/DataDog/datadog-agent/comp/forwarder/orchestrator/orchestratorimpl"
 	"github.com/DataDog/datadog-agent/comp/serializer/compression"
 	"github.com/DataDog/datadog-agent/comp/serializer/compression/compressionimpl"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
@@ -116,6 +118,55 @@ func TestDemuxNoAggOptionIsDisabledByDefault(t *testing.T) {
 	demux.Stop(false)
 }
 
+func TestDemuxContextLimitsSharedByPipelines(t *testing.T) {
+	require := require.New(t)
+
+	deps := createDemultiplexerAgentTestDeps(t)
+
+	pc := pkgconfig.Datadog().GetInt("dogstatsd_pipeline_count")
+	aa := pkgconfig.Datadog().GetBool("dogstatsd_pipeline_autoadjust")
+	limit := pkgconfig.Datadog().GetInt("dogstatsd_context_limit_per_metric")
+	defer func() {
+		pkgconfig.Datadog().SetWithoutSource("dogstatsd_pipeline_count", pc)
+		pkgconfig.Datadog().SetWithoutSource("dogstatsd_pipeline_autoadjust", aa)
+		pkgconfig.Datadog().SetWithoutSource("dogstatsd_context_limit_per_metric", limit)
+	}()
+	pkgconfig.Datadog().SetWithoutSource("dogstatsd_pipeline_count", 2)
+	pkgconfig.Datadog().SetWithoutSource("dogstatsd_pipeline_autoadjust", false)
+	pkgconfig.Datadog().SetWithoutSource("dogstatsd_context_limit_per_metric", 2)
+
+	opts := demuxTestOptions()
+	demux := initAgentDemultiplexer(deps.Log, NewForwarderTest(deps.Log), deps.OrchestratorFwd, opts, deps.EventPlatform, deps.Compressor, "")
+	require.Len(demux.statsd.workers, 2)
+
+	first := demux.statsd.workers[0].sampler.contextResolver.resolver
+	second := demux.statsd.workers[1].sampler.contextResolver.resolver
+
+	k1 := first.trackContext(&mockSample{"foo", nil, []string{"a:1"}}, 0)
+	k2 := second.trackContext(&mockSample{"foo", nil, []string{"a:2"}}, 0)
+	for _, k := range []struct {
+		resolver *contextResolver
+		key      ckey.ContextKey
+	}{{first, k1}, {second, k2}} {
+		cx, ok := k.resolver.get(k.key)
+		require.True(ok)
+		require.False(cx.overflow)
+	}
+
+	// the limit applies to the contexts of all the pipelines
+	k3 := first.trackContext(&mockSample{"foo", nil, []string{"a:3"}}, 0)
+	cx, ok := first.get(k3)
+	require.True(ok)
+	require.True(cx.overflow)
+
+	// the contexts expired by a pipeline make room for the other ones
+	second.remove(k2)
+	k4 := first.trackContext(&mockSample{"foo", nil, []string{"a:4"}}, 0)
+	cx, ok = first.get(k4)
+	require.True(ok)
+	require.False(cx.overflow)
+}
+
 func TestMetricSampleTypeConversion(t *testing.T) {
 	require := require.New(t)
 
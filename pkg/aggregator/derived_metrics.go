@@ -0,0 +1,411 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// byClauseRegexp matches the `by {<tag key>, ...}` clause ending an expression
+var byClauseRegexp = regexp.MustCompile(`\s+by\s*\{([^}]*)\}\s*$`)
+
+// derivedMetrics computes metrics from the series flushed by the check and time samplers.
+//
+// The series of the metrics used by the derived metrics are recorded while they are appended
+// to the sink of a flush, and the derived metrics are appended to the sink at the end of the flush.
+type derivedMetrics struct {
+	metrics  []*derivedMetric
+	hostname string
+
+	m sync.Mutex
+	// values holds the sum of the points of each recorded serie, by metric name
+	values map[string][]derivedMetricInput
+}
+
+// derivedMetric is a gauge computed from the sum of the series of other metrics.
+type derivedMetric struct {
+	name string
+	expr derivedExpression
+	// by holds the tag keys grouping the series, the series are all summed if it is empty.
+	// The series missing one of these tag keys are ignored.
+	by []string
+}
+
+type derivedMetricInput struct {
+	tags  tagset.CompositeTags
+	value float64
+}
+
+// newDerivedMetricsFromConfig returns the derived metrics configured with `derived_metrics`,
+// or nil if there isn't any. The invalid definitions are ignored.
+func newDerivedMetricsFromConfig(hostname string) *derivedMetrics {
+	definitions, err := config.GetDerivedMetrics()
+	if err != nil || len(definitions) == 0 {
+		return nil
+	}
+
+	d := &derivedMetrics{hostname: hostname}
+	for _, definition := range definitions {
+		if definition.Name == "" {
+			log.Errorf("Ignoring the derived metric without name: %q", definition.Expression)
+			continue
+		}
+		dm, err := parseDerivedMetric(definition.Name, definition.Expression)
+		if err != nil {
+			log.Errorf("Ignoring the derived metric %q: %s", definition.Name, err)
+			continue
+		}
+		d.metrics = append(d.metrics, dm)
+	}
+	if len(d.metrics) == 0 {
+		return nil
+	}
+
+	d.values = make(map[string][]derivedMetricInput)
+	for _, dm := range d.metrics {
+		for _, name := range dm.expr.metricNames(nil) {
+			d.values[name] = nil
+		}
+	}
+	return d
+}
+
+// parseDerivedMetric parses the expression of a derived metric, for instance
+// `http.errors / http.requests by {service}`.
+func parseDerivedMetric(name, expression string) (*derivedMetric, error) {
+	dm := &derivedMetric{name: name}
+	if match := byClauseRegexp.FindStringSubmatchIndex(expression); match != nil {
+		for _, key := range strings.Split(expression[match[2]:match[3]], ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				return nil, fmt.Errorf("empty tag key in %q", expression[match[0]:])
+			}
+			dm.by = append(dm.by, key)
+		}
+		expression = expression[:match[0]]
+	}
+
+	p := &derivedExpressionParser{input: expression}
+	expr, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	dm.expr = expr
+	return dm, nil
+}
+
+// serieSink returns a sink recording the series of the metrics used by the derived metrics
+// before appending them to the given sink.
+func (d *derivedMetrics) serieSink(sink metrics.SerieSink) metrics.SerieSink {
+	return &derivedMetricsSerieSink{derivedMetrics: d, sink: sink}
+}
+
+// flush appends the derived metrics computed from the recorded series to the sink, and
+// resets the recorded series.
+func (d *derivedMetrics) flush(timestamp float64, sink metrics.SerieSink) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	for _, dm := range d.metrics {
+		for _, serie := range dm.compute(d.values) {
+			serie.Host = d.hostname
+			serie.Points[0].Ts = timestamp
+			sink.Append(serie)
+		}
+	}
+	for name := range d.values {
+		d.values[name] = nil
+	}
+}
+
+// compute returns a serie for each group of series for which the expression can be evaluated.
+func (dm *derivedMetric) compute(inputs map[string][]derivedMetricInput) []*metrics.Serie {
+	type group struct {
+		tags   []string
+		values map[string]float64
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	for _, name := range dm.expr.metricNames(nil) {
+		for _, input := range inputs[name] {
+			tags := dm.groupTags(input.tags)
+			if len(tags) < len(dm.by) {
+				continue
+			}
+			key := strings.Join(tags, ",")
+			g, ok := groups[key]
+			if !ok {
+				g = &group{tags: tags, values: make(map[string]float64)}
+				groups[key] = g
+				keys = append(keys, key)
+			}
+			g.values[name] += input.value
+		}
+	}
+
+	series := make([]*metrics.Serie, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+		value, ok := dm.expr.eval(g.values)
+		if !ok || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		series = append(series, &metrics.Serie{
+			Name:   dm.name,
+			Points: []metrics.Point{{Value: value}},
+			Tags:   tagset.CompositeTagsFromSlice(g.tags),
+			MType:  metrics.APIGaugeType,
+		})
+	}
+	return series
+}
+
+// groupTags returns the first tag of each of the grouping tag keys found in the tags.
+func (dm *derivedMetric) groupTags(tags tagset.CompositeTags) []string {
+	var groupTags []string
+	for _, key := range dm.by {
+		prefix := key + ":"
+		tag, found := "", false
+		tags.Find(func(t string) bool {
+			if strings.HasPrefix(t, prefix) {
+				tag, found = t, true
+			}
+			return found
+		})
+		if found {
+			groupTags = append(groupTags, tag)
+		}
+	}
+	return groupTags
+}
+
+type derivedMetricsSerieSink struct {
+	derivedMetrics *derivedMetrics
+	sink           metrics.SerieSink
+}
+
+func (s *derivedMetricsSerieSink) Append(serie *metrics.Serie) {
+	d := s.derivedMetrics
+	d.m.Lock()
+	if values, ok := d.values[serie.Name]; ok && len(serie.Points) > 0 {
+		d.values[serie.Name] = append(values, derivedMetricInput{tags: serie.Tags, value: serieValue(serie)})
+	}
+	d.m.Unlock()
+	s.sink.Append(serie)
+}
+
+// serieValue returns the value of a serie holding several points, e.g. the buckets of a
+// flush of the time sampler: the sum of the points of the counts and rates, and the
+// latest point of the gauges.
+func serieValue(serie *metrics.Serie) float64 {
+	if serie.MType != metrics.APICountType && serie.MType != metrics.APIRateType {
+		latest := serie.Points[0]
+		for _, p := range serie.Points[1:] {
+			if p.Ts >= latest.Ts {
+				latest = p
+			}
+		}
+		return latest.Value
+	}
+	var sum float64
+	for _, p := range serie.Points {
+		sum += p.Value
+	}
+	return sum
+}
+
+// derivedExpression is an arithmetic expression over the values of metrics.
+type derivedExpression interface {
+	// eval returns the value of the expression, and false if it can't be evaluated
+	eval(values map[string]float64) (float64, bool)
+	// metricNames appends the names of the metrics of the expression to names
+	metricNames(names []string) []string
+}
+
+type derivedMetricName string
+
+func (e derivedMetricName) eval(values map[string]float64) (float64, bool) {
+	value, ok := values[string(e)]
+	return value, ok
+}
+
+func (e derivedMetricName) metricNames(names []string) []string {
+	for _, name := range names {
+		if name == string(e) {
+			return names
+		}
+	}
+	return append(names, string(e))
+}
+
+type derivedNumber float64
+
+func (e derivedNumber) eval(map[string]float64) (float64, bool) {
+	return float64(e), true
+}
+
+func (e derivedNumber) metricNames(names []string) []string {
+	return names
+}
+
+type derivedOperation struct {
+	op          byte
+	left, right derivedExpression
+}
+
+func (e *derivedOperation) eval(values map[string]float64) (float64, bool) {
+	left, ok := e.left.eval(values)
+	if !ok {
+		return 0, false
+	}
+	right, ok := e.right.eval(values)
+	if !ok {
+		return 0, false
+	}
+	switch e.op {
+	case '+':
+		return left + right, true
+	case '-':
+		return left - right, true
+	case '*':
+		return left * right, true
+	default:
+		if right == 0 {
+			return 0, false
+		}
+		return left / right, true
+	}
+}
+
+func (e *derivedOperation) metricNames(names []string) []string {
+	return e.right.metricNames(e.left.metricNames(names))
+}
+
+// derivedExpressionParser is a recursive descent parser of the expressions of derived metrics:
+//
+//	expression = term { ("+" | "-") term }
+//	term       = factor { ("*" | "/") factor }
+//	factor     = number | metric name | "(" expression ")" | "-" factor
+type derivedExpressionParser struct {
+	input string
+	pos   int
+}
+
+func (p *derivedExpressionParser) parse() (derivedExpression, error) {
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != 0 {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	return expr, nil
+}
+
+// peek skips the spaces and returns the next character, or 0 at the end of the input.
+func (p *derivedExpressionParser) peek() byte {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos == len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *derivedExpressionParser) parseExpression() (derivedExpression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &derivedOperation{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *derivedExpressionParser) parseTerm() (derivedExpression, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &derivedOperation{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *derivedExpressionParser) parseFactor() (derivedExpression, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		expr, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos)
+		}
+		p.pos++
+		return expr, nil
+	case c == '-':
+		p.pos++
+		expr, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &derivedOperation{op: '-', left: derivedNumber(0), right: expr}, nil
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return derivedNumber(value), nil
+	case isMetricNameStart(c):
+		start := p.pos
+		for p.pos < len(p.input) && (isMetricNameStart(p.input[p.pos]) || isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		return derivedMetricName(p.input[start:p.pos]), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isMetricNameStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
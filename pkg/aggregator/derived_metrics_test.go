@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package aggregator
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func TestParseDerivedMetric(t *testing.T) {
+	dm, err := parseDerivedMetric("ratio", "a.errors / (a.requests + b_requests) by { service , env }")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service", "env"}, dm.by)
+	assert.Equal(t, []string{"a.errors", "a.requests", "b_requests"}, dm.expr.metricNames(nil))
+
+	value, ok := dm.expr.eval(map[string]float64{"a.errors": 3, "a.requests": 4, "b_requests": 2})
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, value)
+	_, ok = dm.expr.eval(map[string]float64{"a.errors": 3, "a.requests": 4})
+	assert.False(t, ok)
+	_, ok = dm.expr.eval(map[string]float64{"a.errors": 3, "a.requests": 0, "b_requests": 0})
+	assert.False(t, ok)
+
+	// operators precedence and unary minus
+	dm, err = parseDerivedMetric("percent", "100 * -a + b * 2 - 1")
+	require.NoError(t, err)
+	assert.Empty(t, dm.by)
+	value, ok = dm.expr.eval(map[string]float64{"a": 0.5, "b": 3})
+	assert.True(t, ok)
+	assert.Equal(t, -45.0, value)
+
+	for _, expression := range []string{"", "a /", "(a + b", "a b", "a + $", "a by {}", "1.2.3"} {
+		_, err := parseDerivedMetric("invalid", expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestDerivedMetrics(t *testing.T) {
+	pkgconfig.Datadog().SetWithoutSource("derived_metrics", []map[string]interface{}{
+		{"name": "http.error_ratio", "expression": "http.errors / http.requests by {service}"},
+		{"name": "http.total", "expression": "http.requests"},
+		{"name": "invalid", "expression": "http.errors /"},
+	})
+	defer pkgconfig.Datadog().SetWithoutSource("derived_metrics", nil)
+
+	d := newDerivedMetricsFromConfig("my-host")
+	require.NotNil(t, d)
+	require.Len(t, d.metrics, 2)
+
+	var series metrics.Series
+	sink := d.serieSink(&series)
+	serie := func(name string, value float64, tags ...string) *metrics.Serie {
+		return &metrics.Serie{
+			Name:   name,
+			Points: []metrics.Point{{Ts: 90, Value: value / 2}, {Ts: 100, Value: value}},
+			Tags:   tagset.CompositeTagsFromSlice(tags),
+			MType:  metrics.APIRateType,
+		}
+	}
+	sink.Append(serie("http.requests", 10, "service:web", "env:prod"))
+	sink.Append(serie("http.requests", 30, "service:web", "env:staging"))
+	sink.Append(serie("http.errors", 4, "service:web", "env:prod"))
+	sink.Append(serie("http.requests", 10, "service:api"))
+	sink.Append(serie("http.errors", 1, "service:api"))
+	sink.Append(serie("http.errors", 1, "service:db"))
+	sink.Append(serie("other", 1, "service:web"))
+	// the series without service aren't grouped with the others
+	sink.Append(serie("http.requests", 2))
+	sink.Append(serie("http.errors", 2))
+	// the recorded series are appended to the sink
+	require.Len(t, series, 9)
+
+	series = series[:0]
+	d.flush(120, &series)
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Name+series[i].Tags.Join(",") < series[j].Name+series[j].Tags.Join(",")
+	})
+	values := map[string]float64{}
+	for _, s := range series {
+		assert.Equal(t, "my-host", s.Host)
+		assert.Equal(t, metrics.APIGaugeType, s.MType)
+		require.Len(t, s.Points, 1)
+		assert.Equal(t, 120.0, s.Points[0].Ts)
+		values[s.Name+"|"+s.Tags.Join(",")] = s.Points[0].Value
+	}
+	// the service db has no request, its ratio can't be computed, and the points of
+	// each serie are summed
+	assert.Equal(t, map[string]float64{
+		"http.error_ratio|service:api": 0.1,
+		"http.error_ratio|service:web": 0.1,
+		"http.total|":                  78,
+	}, values)
+
+	// the recorded series are reset after each flush
+	series = series[:0]
+	d.flush(130, &series)
+	assert.Empty(t, series)
+
+	// the latest point of the gauges is used
+	sink.Append(&metrics.Serie{
+		Name:   "http.requests",
+		Points: []metrics.Point{{Ts: 100, Value: 7}, {Ts: 90, Value: 5}},
+		MType:  metrics.APIGaugeType,
+	})
+	series = series[:0]
+	d.flush(140, &series)
+	require.Len(t, series, 1)
+	assert.Equal(t, "http.total", series[0].Name)
+	assert.Equal(t, []metrics.Point{{Ts: 140, Value: 7}}, series[0].Points)
+}
+
+func TestDerivedMetricsNotConfigured(t *testing.T) {
+	assert.Nil(t, newDerivedMetricsFromConfig("my-host"))
+}
@@ -0,0 +1,358 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	openMetricsPath        = "/metrics"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// openMetricsQuantiles are the quantiles of the sketches exposed in the summaries.
+var openMetricsQuantiles = []float64{0.5, 0.75, 0.95, 0.99}
+
+// openMetricsExposition keeps the series and sketches sent to the serializer by the
+// last flush of the demultiplexer, and exposes them over HTTP in the OpenMetrics text
+// format so that they can be scraped locally, even when the intake can't be reached.
+type openMetricsExposition struct {
+	m sync.RWMutex
+	// pendingSeries and pendingSketches are filled by the flush in progress
+	pendingSeries   []*metrics.Serie
+	pendingSketches []*metrics.SketchSeries
+	series          []*metrics.Serie
+	sketches        []*metrics.SketchSeries
+
+	addr     string
+	listener net.Listener
+	server   *http.Server
+}
+
+// newOpenMetricsExpositionFromConfig returns the exposition of the flushed metrics,
+// or nil when it is not enabled.
+func newOpenMetricsExpositionFromConfig() *openMetricsExposition {
+	cfg := config.Datadog()
+	if !cfg.GetBool("openmetrics_exposition.enabled") {
+		return nil
+	}
+
+	port := cfg.GetString("openmetrics_exposition.port")
+	addr := net.JoinHostPort(config.GetBindHost(), port)
+	if cfg.GetBool("openmetrics_exposition.non_local_traffic") {
+		// Listen to all network interfaces
+		addr = ":" + port
+	}
+	return newOpenMetricsExposition(addr)
+}
+
+func newOpenMetricsExposition(addr string) *openMetricsExposition {
+	e := &openMetricsExposition{addr: addr}
+	mux := http.NewServeMux()
+	mux.Handle(openMetricsPath, e)
+	e.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return e
+}
+
+// start starts to serve the flushed metrics.
+func (e *openMetricsExposition) start() error {
+	listener, err := net.Listen("tcp", e.addr)
+	if err != nil {
+		return fmt.Errorf("can't listen on %s: %s", e.addr, err)
+	}
+	e.listener = listener
+
+	go func() {
+		if err := e.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Error while serving the OpenMetrics exposition: %s", err)
+		}
+	}()
+	log.Infof("Exposing the flushed metrics in the OpenMetrics format on http://%s%s", listener.Addr(), openMetricsPath)
+	return nil
+}
+
+// stop stops serving the flushed metrics.
+func (e *openMetricsExposition) stop() {
+	if e.listener != nil {
+		_ = e.server.Close()
+	}
+}
+
+// serieSink returns a sink recording the series before appending them to the given sink.
+func (e *openMetricsExposition) serieSink(sink metrics.SerieSink) metrics.SerieSink {
+	return &openMetricsSerieSink{exposition: e, sink: sink}
+}
+
+// sketchesSink returns a sink recording the sketches before appending them to the given sink.
+func (e *openMetricsExposition) sketchesSink(sink metrics.SketchesSink) metrics.SketchesSink {
+	return &openMetricsSketchesSink{exposition: e, sink: sink}
+}
+
+// publish replaces the exposed metrics with the ones recorded since the previous publication.
+func (e *openMetricsExposition) publish() {
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.series, e.pendingSeries = e.pendingSeries, nil
+	e.sketches, e.pendingSketches = e.pendingSketches, nil
+}
+
+// ServeHTTP writes the metrics of the last flush in the OpenMetrics text format.
+func (e *openMetricsExposition) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	e.m.RLock()
+	series, sketches := e.series, e.sketches
+	e.m.RUnlock()
+
+	w.Header().Set("Content-Type", openMetricsContentType)
+	if err := writeOpenMetrics(w, series, sketches); err != nil {
+		log.Debugf("Error while writing the OpenMetrics exposition: %s", err)
+	}
+}
+
+type openMetricsSerieSink struct {
+	exposition *openMetricsExposition
+	sink       metrics.SerieSink
+}
+
+func (s *openMetricsSerieSink) Append(serie *metrics.Serie) {
+	s.exposition.m.Lock()
+	s.exposition.pendingSeries = append(s.exposition.pendingSeries, serie)
+	s.exposition.m.Unlock()
+	s.sink.Append(serie)
+}
+
+type openMetricsSketchesSink struct {
+	exposition *openMetricsExposition
+	sink       metrics.SketchesSink
+}
+
+func (s *openMetricsSketchesSink) Append(sketch *metrics.SketchSeries) {
+	s.exposition.m.Lock()
+	s.exposition.pendingSketches = append(s.exposition.pendingSketches, sketch)
+	s.exposition.m.Unlock()
+	s.sink.Append(sketch)
+}
+
+// openMetricsFamily holds the samples of a metric family, they are written together
+// after its type.
+type openMetricsFamily struct {
+	metricType string
+	samples    []string
+	// labelSets are the label sets of the metrics of the family
+	labelSets map[string]struct{}
+}
+
+// writeOpenMetrics writes the series and the sketches in the OpenMetrics text format.
+// Only the most recent point of each serie and sketch is written.
+//
+// The gauges and the rates are exposed as gauges. The counts are exposed with the
+// unknown type, as they are the deltas of a flush interval and not cumulative counters.
+// The sketches are exposed as summaries of a few quantiles, unless the names of their
+// _sum and _count samples are already used by other metrics.
+// The names and the labels of the metrics are sanitized, only the first of the metrics
+// ending up with the same name and labels is written, e.g. `a.b` and `a_b`.
+func writeOpenMetrics(w io.Writer, series []*metrics.Serie, sketches []*metrics.SketchSeries) error {
+	families := make(map[string]*openMetricsFamily)
+	// addSamples adds the samples of a metric to its family, and returns false if they
+	// clash with another metric of the family
+	addSamples := func(name string, metricType string, labels []string, samples ...string) bool {
+		family, ok := families[name]
+		if !ok {
+			family = &openMetricsFamily{metricType: metricType, labelSets: make(map[string]struct{})}
+			families[name] = family
+		} else if family.metricType != metricType {
+			log.Debugf("Not exposing a %s %q, a %s has the same name", metricType, name, family.metricType)
+			return false
+		}
+		labelSet := strings.Join(labels, ",")
+		if _, exists := family.labelSets[labelSet]; exists {
+			log.Debugf("Not exposing a %s %q, another one has the same labels {%s}", metricType, name, labelSet)
+			return false
+		}
+		family.labelSets[labelSet] = struct{}{}
+		family.samples = append(family.samples, samples...)
+		return true
+	}
+
+	for _, serie := range series {
+		if len(serie.Points) == 0 {
+			continue
+		}
+		point := serie.Points[len(serie.Points)-1]
+		name := openMetricsName(serie.Name)
+		metricType := "gauge"
+		if serie.MType == metrics.APICountType {
+			metricType = "unknown"
+		}
+		labels := openMetricsLabels(serie.Tags.UnsafeToReadOnlySliceString(), serie.Host, serie.Device)
+		addSamples(name, metricType, labels, openMetricsSample(name, labels, point.Value, point.Ts))
+	}
+
+	// summarySuffixed holds the names of the _sum and _count samples of the summaries,
+	// which can't be used by other families
+	summarySuffixed := make(map[string]struct{})
+	summaryClashes := func(name string) bool {
+		if _, ok := summarySuffixed[name]; ok {
+			return true
+		}
+		for _, suffix := range []string{"_sum", "_count"} {
+			if _, ok := families[name+suffix]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, sketch := range sketches {
+		if len(sketch.Points) == 0 || sketch.Points[len(sketch.Points)-1].Sketch == nil {
+			continue
+		}
+		point := sketch.Points[len(sketch.Points)-1]
+		ts := float64(point.Ts)
+		name := openMetricsName(sketch.Name)
+		if summaryClashes(name) {
+			log.Debugf("Not exposing the summary %q, its samples clash with another metric", name)
+			continue
+		}
+		labels := openMetricsLabels(sketch.Tags.UnsafeToReadOnlySliceString(), sketch.Host, "")
+
+		samples := make([]string, 0, len(openMetricsQuantiles)+2)
+		for _, q := range openMetricsQuantiles {
+			quantileLabels := append(labels[:len(labels):len(labels)], `quantile="`+strconv.FormatFloat(q, 'g', -1, 64)+`"`)
+			samples = append(samples, openMetricsSample(name, quantileLabels, point.Sketch.Quantile(quantile.Default(), q), ts))
+		}
+		samples = append(samples,
+			openMetricsSample(name+"_sum", labels, point.Sketch.Basic.Sum, ts),
+			openMetricsSample(name+"_count", labels, float64(point.Sketch.Basic.Cnt), ts))
+		if addSamples(name, "summary", labels, samples...) {
+			summarySuffixed[name+"_sum"] = struct{}{}
+			summarySuffixed[name+"_count"] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		family := families[name]
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, family.metricType)
+		for _, sample := range family.samples {
+			bw.WriteString(sample)
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// openMetricsSample returns the line of a sample.
+func openMetricsSample(name string, labels []string, value float64, ts float64) string {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		b.WriteString(strings.Join(labels, ","))
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	if ts > 0 {
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(ts, 'f', -1, 64))
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// openMetricsLabels converts the tags into labels sorted by name. The values of the
+// tags sharing the same key are joined with commas, a tag without value becomes a
+// label with the value "true".
+func openMetricsLabels(tags []string, host string, device string) []string {
+	values := make(map[string][]string, len(tags)+2)
+	for _, tag := range tags {
+		key, value, found := strings.Cut(tag, ":")
+		if !found {
+			value = "true"
+		}
+		if key == "" {
+			continue
+		}
+		key = openMetricsLabelName(key)
+		values[key] = append(values[key], value)
+	}
+	if host != "" {
+		values["host"] = []string{host}
+	}
+	if device != "" {
+		values["device"] = []string{device}
+	}
+
+	labels := make([]string, 0, len(values))
+	for key, v := range values {
+		labels = append(labels, key+`="`+openMetricsEscape(strings.Join(v, ","))+`"`)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// openMetricsName replaces the characters not allowed in metric names, such as
+// the dots of the Datadog metric names, with underscores.
+func openMetricsName(name string) string {
+	return sanitizeOpenMetricsName(name, true)
+}
+
+// openMetricsLabelName replaces the characters not allowed in label names with underscores.
+func openMetricsLabelName(name string) string {
+	return sanitizeOpenMetricsName(name, false)
+}
+
+func sanitizeOpenMetricsName(name string, allowColons bool) string {
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':' && allowColons:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+var openMetricsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// openMetricsEscape escapes a label value.
+func openMetricsEscape(value string) string {
+	return openMetricsEscaper.Replace(value)
+}
@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package aggregator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func TestWriteOpenMetricsSeries(t *testing.T) {
+	series := []*metrics.Serie{
+		{
+			Name:   "my.gauge",
+			Points: []metrics.Point{{Ts: 1700000000, Value: 1}, {Ts: 1700000010, Value: 2.5}},
+			Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod", "role:db", "role:cache", "canary", "2xx.code:ok"}),
+			Host:   "my-host",
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			Name:   "my.gauge",
+			Points: []metrics.Point{{Ts: 1700000010, Value: 3}},
+			Tags:   tagset.CompositeTagsFromSlice([]string{`path:/a"b\c`}),
+			Device: "sda1",
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			Name:   "http.requests",
+			Points: []metrics.Point{{Ts: 1700000010, Value: 42}},
+			MType:  metrics.APICountType,
+		},
+		{
+			Name:   "http.requests.rate",
+			Points: []metrics.Point{{Ts: 1700000010, Value: 4.2}},
+			MType:  metrics.APIRateType,
+		},
+		{
+			Name:  "no.points",
+			MType: metrics.APIGaugeType,
+		},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, writeOpenMetrics(&b, series, nil))
+	assert.Equal(t, `# TYPE http_requests unknown
+http_requests 42 1700000010
+# TYPE http_requests_rate gauge
+http_requests_rate 4.2 1700000010
+# TYPE my_gauge gauge
+my_gauge{_2xx_code="ok",canary="true",env="prod",host="my-host",role="db,cache"} 2.5 1700000010
+my_gauge{device="sda1",path="/a\"b\\c"} 3 1700000010
+# EOF
+`, b.String())
+}
+
+func TestWriteOpenMetricsSketches(t *testing.T) {
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1, 2, 3, 4, 10)
+	sketches := []*metrics.SketchSeries{
+		{
+			Name:   "request.latency",
+			Tags:   tagset.CompositeTagsFromSlice([]string{"service:api"}),
+			Host:   "my-host",
+			Points: []metrics.SketchPoint{{Ts: 1700000010, Sketch: sketch}},
+		},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, writeOpenMetrics(&b, nil, sketches))
+
+	expected := "# TYPE request_latency summary\n"
+	for _, q := range openMetricsQuantiles {
+		expected += fmt.Sprintf("request_latency{host=\"my-host\",service=\"api\",quantile=\"%s\"} %s 1700000010\n",
+			strconv.FormatFloat(q, 'g', -1, 64), strconv.FormatFloat(sketch.Quantile(quantile.Default(), q), 'g', -1, 64))
+	}
+	expected += `request_latency_sum{host="my-host",service="api"} 20 1700000010
+request_latency_count{host="my-host",service="api"} 5 1700000010
+# EOF
+`
+	assert.Equal(t, expected, b.String())
+}
+
+func TestWriteOpenMetricsNameConflict(t *testing.T) {
+	series := []*metrics.Serie{
+		{Name: "my.metric", Points: []metrics.Point{{Ts: 1700000010, Value: 1}}, MType: metrics.APIGaugeType},
+		{Name: "my_metric", Points: []metrics.Point{{Ts: 1700000010, Value: 2}}, MType: metrics.APICountType},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, writeOpenMetrics(&b, series, nil))
+	assert.Equal(t, "# TYPE my_metric gauge\nmy_metric 1 1700000010\n# EOF\n", b.String())
+}
+
+func TestWriteOpenMetricsLabelsConflict(t *testing.T) {
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1)
+	series := []*metrics.Serie{
+		{Name: "my.metric", Tags: tagset.CompositeTagsFromSlice([]string{"env:prod"}), Points: []metrics.Point{{Ts: 1700000010, Value: 1}}, MType: metrics.APIGaugeType},
+		{Name: "my_metric", Tags: tagset.CompositeTagsFromSlice([]string{"env:prod"}), Points: []metrics.Point{{Ts: 1700000010, Value: 2}}, MType: metrics.APIGaugeType},
+		{Name: "my_metric", Tags: tagset.CompositeTagsFromSlice([]string{"env.name:prod"}), Points: []metrics.Point{{Ts: 1700000010, Value: 3}}, MType: metrics.APIGaugeType},
+		{Name: "my_metric", Tags: tagset.CompositeTagsFromSlice([]string{"env_name:prod"}), Points: []metrics.Point{{Ts: 1700000010, Value: 4}}, MType: metrics.APIGaugeType},
+		{Name: "my_metric", Tags: tagset.CompositeTagsFromSlice([]string{"env:staging"}), Points: []metrics.Point{{Ts: 1700000010, Value: 5}}, MType: metrics.APIGaugeType},
+	}
+	sketches := []*metrics.SketchSeries{
+		{Name: "request.latency", Points: []metrics.SketchPoint{{Ts: 1700000010, Sketch: sketch}}},
+		{Name: "request_latency", Points: []metrics.SketchPoint{{Ts: 1700000010, Sketch: sketch}}},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, writeOpenMetrics(&b, series, sketches))
+	// only the first of the metrics with the same name and labels once sanitized is exposed
+	assert.Equal(t, `# TYPE my_metric gauge
+my_metric{env="prod"} 1 1700000010
+my_metric{env_name="prod"} 3 1700000010
+my_metric{env="staging"} 5 1700000010
+# TYPE request_latency summary
+request_latency{quantile="0.5"} 1 1700000010
+request_latency{quantile="0.75"} 1 1700000010
+request_latency{quantile="0.95"} 1 1700000010
+request_latency{quantile="0.99"} 1 1700000010
+request_latency_sum 1 1700000010
+request_latency_count 1 1700000010
+# EOF
+`, b.String())
+}
+
+func TestWriteOpenMetricsSummaryNameConflict(t *testing.T) {
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1)
+	series := []*metrics.Serie{
+		{Name: "request.latency_count", Points: []metrics.Point{{Ts: 1700000010, Value: 1}}, MType: metrics.APIGaugeType},
+	}
+	sketches := []*metrics.SketchSeries{
+		{Name: "request.latency", Points: []metrics.SketchPoint{{Ts: 1700000010, Sketch: sketch}}},
+		{Name: "other", Points: []metrics.SketchPoint{{Ts: 1700000010, Sketch: sketch}}},
+		{Name: "other_sum", Points: []metrics.SketchPoint{{Ts: 1700000010, Sketch: sketch}}},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, writeOpenMetrics(&b, series, sketches))
+	// the summaries whose samples clash with another metric are not exposed
+	assert.Contains(t, b.String(), "# TYPE request_latency_count gauge\nrequest_latency_count 1 1700000010\n")
+	assert.NotContains(t, b.String(), "# TYPE request_latency summary")
+	assert.Contains(t, b.String(), "# TYPE other summary")
+	assert.Contains(t, b.String(), "other_sum 1 1700000010\n")
+	assert.NotContains(t, b.String(), "# TYPE other_sum summary")
+}
+
+func TestOpenMetricsExpositionPublish(t *testing.T) {
+	e := newOpenMetricsExposition("127.0.0.1:0")
+	seriesSink := metrics.Series{}
+	sketchesSink := metrics.SketchSeriesList{}
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openMetricsPath, nil))
+		assert.Equal(t, openMetricsContentType, w.Header().Get("Content-Type"))
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "# EOF\n", scrape())
+
+	// the recorded series are still appended to the sinks
+	e.serieSink(&seriesSink).Append(&metrics.Serie{Name: "my.gauge", Points: []metrics.Point{{Ts: 1700000010, Value: 1}}, MType: metrics.APIGaugeType})
+	assert.Len(t, seriesSink, 1)
+
+	// the series of a flush are exposed once it is complete
+	assert.Equal(t, "# EOF\n", scrape())
+	e.publish()
+	assert.Equal(t, "# TYPE my_gauge gauge\nmy_gauge 1 1700000010\n# EOF\n", scrape())
+
+	// the next flush replaces the exposed metrics
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1)
+	e.sketchesSink(&sketchesSink).Append(&metrics.SketchSeries{Name: "my.distribution", Points: []metrics.SketchPoint{{Ts: 1700000020, Sketch: sketch}}})
+	assert.Len(t, sketchesSink, 1)
+	e.publish()
+	assert.Contains(t, scrape(), "my_distribution_count 1 1700000020\n")
+	assert.NotContains(t, scrape(), "my_gauge")
+}
+
+func TestOpenMetricsExpositionServer(t *testing.T) {
+	e := newOpenMetricsExposition("127.0.0.1:0")
+	require.NoError(t, e.start())
+	defer e.stop()
+
+	e.serieSink(&metrics.Series{}).Append(&metrics.Serie{Name: "my.gauge", Points: []metrics.Point{{Ts: 1700000010, Value: 1}}, MType: metrics.APIGaugeType})
+	e.publish()
+
+	resp, err := http.Get("http://" + e.listener.Addr().String() + openMetricsPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "# TYPE my_gauge gauge\nmy_gauge 1 1700000010\n# EOF\n", string(body))
+}
@@ -34,6 +34,8 @@ type TimeSampler struct {
 	metricsByTimestamp map[int64]metrics.ContextMetrics
 	lastCutOffTime     int64
 	sketchMap          sketchMap
+	// rollups holds the buckets of the metrics aggregated into coarser intervals
+	rollups []*rollup
 
 	// id is a number to differentiate multiple time samplers
 	// since we start running more than one with the demultiplexer introduction
@@ -45,6 +47,12 @@ type TimeSampler struct {
 
 // NewTimeSampler returns a newly initialized TimeSampler
 func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, hostname string) *TimeSampler {
+	return newTimeSampler(id, interval, cache, hostname, newContextLimiterFromConfig())
+}
+
+// newTimeSampler returns a newly initialized TimeSampler limiting its contexts with limiter,
+// which can be shared with the other TimeSamplers.
+func newTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, hostname string, limiter *contextLimiter) *TimeSampler {
 	if interval == 0 {
 		interval = bucketSize
 	}
@@ -57,12 +65,16 @@ func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, hostnam
 
 	s := &TimeSampler{
 		interval:           interval,
-		contextResolver:    newTimestampContextResolver(cache, idString, contextExpireTime, counterExpireTime),
+		contextResolver:    newTimestampContextResolver(cache, idString, contextExpireTime, counterExpireTime, limiter),
 		metricsByTimestamp: map[int64]metrics.ContextMetrics{},
 		sketchMap:          make(sketchMap),
 		id:                 id,
 		idString:           idString,
 		hostname:           hostname,
+		rollups:            newRollupsFromConfig(interval),
+	}
+	if len(s.rollups) > 0 {
+		s.contextResolver.extraTTL = s.rollupExtraTTL
 	}
 
 	return s
@@ -72,8 +84,8 @@ func (s *TimeSampler) calculateBucketStart(timestamp float64) int64 {
 	return int64(timestamp) - int64(timestamp)%s.interval
 }
 
-func (s *TimeSampler) isBucketStillOpen(bucketStartTimestamp, timestamp int64) bool {
-	return bucketStartTimestamp+s.interval > timestamp
+func (s *TimeSampler) isBucketStillOpen(bucketStartTimestamp, timestamp, interval int64) bool {
+	return bucketStartTimestamp+interval > timestamp
 }
 
 func (s *TimeSampler) sample(metricSample *metrics.MetricSample, timestamp float64) {
@@ -84,26 +96,32 @@ func (s *TimeSampler) sample(metricSample *metrics.MetricSample, timestamp float
 
 	// Keep track of the context
 	contextKey := s.contextResolver.trackContext(metricSample, int64(timestamp))
-	bucketStart := s.calculateBucketStart(timestamp)
+
+	// The rolled up metrics are aggregated in their own buckets
+	interval, metricsByTimestamp, sketches := s.interval, s.metricsByTimestamp, s.sketchMap
+	if r := s.rollupOf(metricSample.Name); r != nil {
+		interval, metricsByTimestamp, sketches = r.interval, r.metricsByTimestamp, r.sketchMap
+	}
+	bucketStart := int64(timestamp) - int64(timestamp)%interval
 
 	switch metricSample.Mtype {
 	case metrics.DistributionType:
-		s.sketchMap.insert(bucketStart, contextKey, metricSample.Value, metricSample.SampleRate)
+		sketches.insert(bucketStart, contextKey, metricSample.Value, metricSample.SampleRate)
 	default:
 		// If it's a new bucket, initialize it
-		bucketMetrics, ok := s.metricsByTimestamp[bucketStart]
+		bucketMetrics, ok := metricsByTimestamp[bucketStart]
 		if !ok {
 			bucketMetrics = metrics.MakeContextMetrics()
-			s.metricsByTimestamp[bucketStart] = bucketMetrics
+			metricsByTimestamp[bucketStart] = bucketMetrics
 		}
 		// Add sample to bucket
-		if err := bucketMetrics.AddSample(contextKey, metricSample, timestamp, s.interval, nil, config.Datadog()); err != nil {
+		if err := bucketMetrics.AddSample(contextKey, metricSample, timestamp, interval, nil, config.Datadog()); err != nil {
 			log.Debugf("TimeSampler #%d Ignoring sample '%s' on host '%s' and tags '%s': %s", s.id, metricSample.Name, metricSample.Host, metricSample.Tags, err)
 		}
 	}
 }
 
-func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.SketchPoint) *metrics.SketchSeries {
+func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.SketchPoint, interval int64) *metrics.SketchSeries {
 	ctx, ok := s.contextResolver.get(ck)
 	if !ok {
 		return nil
@@ -113,7 +131,7 @@ func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.Sketc
 		Name:       ctx.Name,
 		Tags:       ctx.Tags(),
 		Host:       ctx.Host,
-		Interval:   s.interval,
+		Interval:   interval,
 		Points:     points,
 		ContextKey: ck,
 		Source:     ctx.source,
@@ -124,38 +142,51 @@ func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.Sketc
 }
 
 func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
+	s.flushBuckets(s.metricsByTimestamp, s.interval, cutoffTime, s.lastCutOffTime, nil, series)
+
+	for _, r := range s.rollups {
+		// a rolled up bucket is closed once the cutoff time reaches the end of its interval
+		rollupCutOffTime := cutoffTime - cutoffTime%r.interval
+		s.flushBuckets(r.metricsByTimestamp, r.interval, rollupCutOffTime, r.lastCutOffTime, r, series)
+		r.lastCutOffTime = rollupCutOffTime
+	}
+}
+
+// flushBuckets flushes the closed buckets of `interval` seconds of metricsByTimestamp, which hold
+// the metrics of the given rollup, or the metrics which are not rolled up if it is nil.
+func (s *TimeSampler) flushBuckets(metricsByTimestamp map[int64]metrics.ContextMetrics, interval, cutoffTime, lastCutOffTime int64, rollup *rollup, series metrics.SerieSink) {
 	// Map to hold the expired contexts that will need to be deleted after the flush so that we stop sending zeros
 	contextMetricsFlusher := metrics.NewContextMetricsFlusher()
 
-	if len(s.metricsByTimestamp) > 0 {
-		for bucketTimestamp, contextMetrics := range s.metricsByTimestamp {
+	if len(metricsByTimestamp) > 0 {
+		for bucketTimestamp, contextMetrics := range metricsByTimestamp {
 			// disregard when the timestamp is too recent
-			if s.isBucketStillOpen(bucketTimestamp, cutoffTime) {
+			if s.isBucketStillOpen(bucketTimestamp, cutoffTime, interval) {
 				continue
 			}
 
 			// Add a 0 sample to all the counters that are not expired.
 			// It is ok to add 0 samples to a counter that was already sampled for real in the bucket, since it won't change its value
-			s.countersSampleZeroValue(bucketTimestamp, contextMetrics)
+			s.countersSampleZeroValue(bucketTimestamp, contextMetrics, interval, rollup)
 			contextMetricsFlusher.Append(float64(bucketTimestamp), contextMetrics)
 
-			delete(s.metricsByTimestamp, bucketTimestamp)
+			delete(metricsByTimestamp, bucketTimestamp)
 		}
-	} else if s.lastCutOffTime+s.interval <= cutoffTime {
+	} else if lastCutOffTime+interval <= cutoffTime {
 		// Even if there is no metric in this flush, recreate empty counters,
 		// but only if we've passed an interval since the last flush
 
 		contextMetrics := metrics.MakeContextMetrics()
 
-		s.countersSampleZeroValue(cutoffTime-s.interval, contextMetrics)
-		contextMetricsFlusher.Append(float64(cutoffTime-s.interval), contextMetrics)
+		s.countersSampleZeroValue(cutoffTime-interval, contextMetrics, interval, rollup)
+		contextMetricsFlusher.Append(float64(cutoffTime-interval), contextMetrics)
 	}
 
 	// serieBySignature is reused for each call of dedupSerieBySerieSignature to avoid allocations.
 	serieBySignature := make(map[SerieSignature]*metrics.Serie)
 	s.flushContextMetrics(contextMetricsFlusher, func(rawSeries []*metrics.Serie) {
 		// Note: rawSeries is reused at each call
-		s.dedupSerieBySerieSignature(rawSeries, series, serieBySignature)
+		s.dedupSerieBySerieSignature(rawSeries, series, serieBySignature, interval)
 	})
 }
 
@@ -163,6 +194,7 @@ func (s *TimeSampler) dedupSerieBySerieSignature(
 	rawSeries []*metrics.Serie,
 	serieSink metrics.SerieSink,
 	serieBySignature map[SerieSignature]*metrics.Serie,
+	interval int64,
 ) {
 	// clear the map. Reuse serieBySignature
 	for k := range serieBySignature {
@@ -186,7 +218,7 @@ func (s *TimeSampler) dedupSerieBySerieSignature(
 			serie.Tags = context.Tags()
 			serie.Host = context.Host
 			serie.NoIndex = context.noIndex
-			serie.Interval = s.interval
+			serie.Interval = interval
 			serie.Source = context.source
 
 			serieBySignature[serieSignature] = serie
@@ -199,16 +231,25 @@ func (s *TimeSampler) dedupSerieBySerieSignature(
 }
 
 func (s *TimeSampler) flushSketches(cutoffTime int64, sketchesSink metrics.SketchesSink) {
+	s.flushSketchBuckets(s.sketchMap, s.interval, cutoffTime, sketchesSink)
+	for _, r := range s.rollups {
+		s.flushSketchBuckets(r.sketchMap, r.interval, cutoffTime-cutoffTime%r.interval, sketchesSink)
+	}
+}
+
+// flushSketchBuckets flushes the closed buckets of `interval` seconds of a sketch map
+func (s *TimeSampler) flushSketchBuckets(sketches sketchMap, interval, cutoffTime int64, sketchesSink metrics.SketchesSink) {
 	pointsByCtx := make(map[ckey.ContextKey][]metrics.SketchPoint)
 
-	s.sketchMap.flushBefore(cutoffTime, func(ck ckey.ContextKey, p metrics.SketchPoint) {
+	// a bucket is closed if it ends before the cutoff time
+	sketches.flushBefore(cutoffTime-interval+1, func(ck ckey.ContextKey, p metrics.SketchPoint) {
 		if p.Sketch == nil {
 			return
 		}
 		pointsByCtx[ck] = append(pointsByCtx[ck], p)
 	})
 	for ck, points := range pointsByCtx {
-		ss := s.newSketchSeries(ck, points)
+		ss := s.newSketchSeries(ck, points, interval)
 		if ss == nil {
 			log.Errorf("TimeSampler #%d Ignoring all metrics on context key '%v': inconsistent context resolver state: the context is not tracked", s.id, ck)
 			continue
@@ -238,7 +279,11 @@ func (s *TimeSampler) updateMetrics() {
 	totalContexts := s.contextResolver.length()
 	aggregatorDogstatsdContexts.Set(int64(totalContexts))
 	tlmDogstatsdContexts.Set(float64(totalContexts), s.idString)
-	tlmDogstatsdTimeBuckets.Set(float64(len(s.metricsByTimestamp)), s.idString)
+	timeBuckets := len(s.metricsByTimestamp)
+	for _, r := range s.rollups {
+		timeBuckets += len(r.metricsByTimestamp)
+	}
+	tlmDogstatsdTimeBuckets.Set(float64(timeBuckets), s.idString)
 
 	countByMtype := s.contextResolver.countsByMtype()
 	for i := 0; i < int(metrics.NumMetricTypes); i++ {
@@ -263,10 +308,12 @@ func (s *TimeSampler) flushContextMetrics(contextMetricsFlusher *metrics.Context
 	}
 }
 
-func (s *TimeSampler) countersSampleZeroValue(timestamp int64, contextMetrics metrics.ContextMetrics) {
+// countersSampleZeroValue adds a 0 sample to the counters which are not expired and are aggregated
+// in buckets of `interval` seconds, those of the given rollup or those not rolled up if it is nil.
+func (s *TimeSampler) countersSampleZeroValue(timestamp int64, contextMetrics metrics.ContextMetrics, interval int64, rollup *rollup) {
 	expirySeconds := config.Datadog().GetInt64("dogstatsd_expiry_seconds")
 	for counterContext, entry := range s.contextResolver.resolver.contextsByKey {
-		if entry.lastSeen+expirySeconds > timestamp && entry.context.mtype == metrics.CounterType {
+		if entry.lastSeen+expirySeconds > timestamp && entry.context.mtype == metrics.CounterType && (len(s.rollups) == 0 || s.rollupOf(entry.context.Name) == rollup) {
 			sample := &metrics.MetricSample{
 				Name:       "",
 				Value:      0.0,
@@ -279,7 +326,7 @@ func (s *TimeSampler) countersSampleZeroValue(timestamp int64, contextMetrics me
 			}
 			// Add a zero value sample to the counter
 			// It is ok to add a 0 sample to a counter that was already sampled in the bucket, it won't change its value
-			contextMetrics.AddSample(counterContext, sample, float64(timestamp), s.interval, nil, config.Datadog()) //nolint:errcheck
+			contextMetrics.AddSample(counterContext, sample, float64(timestamp), interval, nil, config.Datadog()) //nolint:errcheck
 		}
 	}
 }
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// rollup holds the buckets of the metrics which are aggregated by the time sampler into a
+// coarser interval than its own.
+type rollup struct {
+	interval           int64
+	prefixes           []string
+	metricsByTimestamp map[int64]metrics.ContextMetrics
+	lastCutOffTime     int64
+	sketchMap          sketchMap
+}
+
+// newRollupsFromConfig returns the rollups configured with `dogstatsd_rollups`. The rules whose
+// interval is not a multiple of the interval of the time sampler are ignored.
+func newRollupsFromConfig(interval int64) []*rollup {
+	rules, err := config.GetDogstatsdRollups()
+	if err != nil {
+		return nil
+	}
+
+	rollups := make([]*rollup, 0, len(rules))
+	for _, rule := range rules {
+		rollupInterval := int64(rule.Interval)
+		if rollupInterval <= interval || rollupInterval%interval != 0 {
+			log.Errorf("Ignoring the rollup of the metrics %v: its interval must be a multiple of %d seconds, got %d", rule.Prefixes, interval, rule.Interval)
+			continue
+		}
+		if len(rule.Prefixes) == 0 {
+			log.Errorf("Ignoring the rollup into %d seconds without metric prefix", rule.Interval)
+			continue
+		}
+		rollups = append(rollups, &rollup{
+			interval:           rollupInterval,
+			prefixes:           rule.Prefixes,
+			metricsByTimestamp: map[int64]metrics.ContextMetrics{},
+			sketchMap:          make(sketchMap),
+		})
+	}
+	return rollups
+}
+
+// rollupOf returns the first rollup matching the name of a metric, or nil if the metric is
+// aggregated in the interval of the time sampler.
+func (s *TimeSampler) rollupOf(name string) *rollup {
+	for _, r := range s.rollups {
+		for _, prefix := range r.prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// rollupExtraTTL returns how long a context is kept in addition to the usual expiration time, so
+// that a rolled up context is still tracked when its bucket is flushed.
+func (s *TimeSampler) rollupExtraTTL(context *Context) int64 {
+	if r := s.rollupOf(context.Name); r != nil {
+		return r.interval
+	}
+	return 0
+}
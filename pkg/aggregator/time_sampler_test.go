@@ -8,6 +8,7 @@
 package aggregator
 
 import (
+	"fmt"
 	"math"
 	"sort"
 	"testing"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
@@ -551,6 +553,101 @@ func BenchmarkTimeSampler(b *testing.B) {
 	benchWithTagsStore(b, benchmarkTimeSampler)
 }
 
+func testContextLimitOverflow(t *testing.T, store *tags.Store) {
+	pkgconfig.Datadog().SetWithoutSource("dogstatsd_context_limit_per_metric", 2)
+	defer pkgconfig.Datadog().SetWithoutSource("dogstatsd_context_limit_per_metric", 0)
+	sampler := testTimeSampler(store)
+
+	for i := 0; i < 5; i++ {
+		sampler.sample(&metrics.MetricSample{
+			Name:       "my.counter",
+			Value:      1,
+			Mtype:      metrics.CounterType,
+			Tags:       []string{fmt.Sprintf("request_id:%d", i)},
+			SampleRate: 1,
+		}, 12345.0)
+	}
+
+	series, _ := flushSerie(sampler, 12360.0)
+	require.Len(t, series, 3)
+	values := map[string]float64{}
+	for _, serie := range series {
+		require.Len(t, serie.Points, 1)
+		values[serie.Tags.Join(",")] = serie.Points[0].Value
+	}
+	// the contexts beyond the limit are aggregated into the overflow serie
+	assert.Equal(t, map[string]float64{
+		"request_id:0":  0.1,
+		"request_id:1":  0.1,
+		"overflow:true": 0.3,
+	}, values)
+}
+
+func TestContextLimitOverflow(t *testing.T) {
+	testWithTagsStore(t, testContextLimitOverflow)
+}
+
+func testRollup(t *testing.T, store *tags.Store) {
+	pkgconfig.Datadog().SetWithoutSource("dogstatsd_rollups", []map[string]interface{}{
+		{"interval": 60, "prefixes": []string{"rolled."}},
+		{"interval": 15, "prefixes": []string{"invalid."}},
+	})
+	defer pkgconfig.Datadog().SetWithoutSource("dogstatsd_rollups", nil)
+	sampler := testTimeSampler(store)
+	require.Len(t, sampler.rollups, 1)
+
+	counter := &metrics.MetricSample{Name: "rolled.counter", Value: 6, Mtype: metrics.CounterType, SampleRate: 1}
+	sampler.sample(counter, 1202.0)
+	sampler.sample(counter, 1232.0)
+	sampler.sample(&metrics.MetricSample{Name: "rolled.gauge", Value: 3, Mtype: metrics.GaugeType, SampleRate: 1}, 1205.0)
+	sampler.sample(&metrics.MetricSample{Name: "rolled.distribution", Value: 1, Mtype: metrics.DistributionType, SampleRate: 1}, 1205.0)
+	sampler.sample(&metrics.MetricSample{Name: "my.gauge", Value: 1, Mtype: metrics.GaugeType, SampleRate: 1}, 1205.0)
+
+	// the metrics which are not rolled up are flushed as usual
+	series, sketches := flushSerie(sampler, 1230.0)
+	require.Len(t, series, 1)
+	assert.Equal(t, "my.gauge", series[0].Name)
+	assert.Equal(t, int64(10), series[0].Interval)
+	assert.Empty(t, sketches)
+
+	// the rolled up metrics are kept until the end of their interval, even if the contexts
+	// haven't been seen for longer than their expiration time
+	series, sketches = flushSerie(sampler, 1250.0)
+	assert.Empty(t, series)
+	assert.Empty(t, sketches)
+
+	series, sketches = flushSerie(sampler, 1260.0)
+	require.Len(t, series, 2)
+	sort.Slice(series, func(i, j int) bool { return series[i].Name < series[j].Name })
+	metrics.AssertSerieEqual(t, &metrics.Serie{
+		Name:       "rolled.counter",
+		Points:     []metrics.Point{{Ts: 1200.0, Value: 0.2}},
+		Tags:       tagset.NewCompositeTags([]string{}, []string{}),
+		MType:      metrics.APIRateType,
+		ContextKey: generateContextKey(counter),
+		Interval:   60,
+	}, series[0])
+	assert.Equal(t, "rolled.gauge", series[1].Name)
+	assert.Equal(t, []metrics.Point{{Ts: 1200.0, Value: 3}}, series[1].Points)
+	assert.Equal(t, int64(60), series[1].Interval)
+	require.Len(t, sketches, 1)
+	assert.Equal(t, "rolled.distribution", sketches[0].Name)
+	assert.Equal(t, int64(60), sketches[0].Interval)
+	assert.Equal(t, int64(1200), sketches[0].Points[0].Ts)
+
+	// the counters which are rolled up are only reset once per interval
+	series, _ = flushSerie(sampler, 1310.0)
+	assert.Empty(t, series)
+	series, _ = flushSerie(sampler, 1320.0)
+	require.Len(t, series, 1)
+	assert.Equal(t, "rolled.counter", series[0].Name)
+	assert.Equal(t, []metrics.Point{{Ts: 1260.0, Value: 0}}, series[0].Points)
+}
+
+func TestRollup(t *testing.T) {
+	testWithTagsStore(t, testRollup)
+}
+
 func flushSerie(sampler *TimeSampler, timestamp float64) (metrics.Series, metrics.SketchSeriesList) {
 	var series metrics.Series
 	var sketches metrics.SketchSeriesList
@@ -185,6 +185,14 @@ type (
 	Listeners = pkgconfigsetup.Listeners
 	// MappingProfile Alias
 	MappingProfile = pkgconfigsetup.MappingProfile
+	// ContextLimit Alias
+	ContextLimit = pkgconfigsetup.ContextLimit
+	// TagFilterRule Alias
+	TagFilterRule = pkgconfigsetup.TagFilterRule
+	// RollupRule Alias
+	RollupRule = pkgconfigsetup.RollupRule
+	// DerivedMetric Alias
+	DerivedMetric = pkgconfigsetup.DerivedMetric
 )
 
 // GetObsPipelineURL Alias using Datadog config
@@ -227,6 +235,26 @@ func GetDogstatsdMappingProfiles() ([]MappingProfile, error) {
 	return pkgconfigsetup.GetDogstatsdMappingProfiles(Datadog())
 }
 
+// GetDogstatsdTagFilters Alias using Datadog config
+func GetDogstatsdTagFilters() ([]TagFilterRule, error) {
+	return pkgconfigsetup.GetDogstatsdTagFilters(Datadog())
+}
+
+// GetDogstatsdRollups Alias using Datadog config
+func GetDogstatsdRollups() ([]RollupRule, error) {
+	return pkgconfigsetup.GetDogstatsdRollups(Datadog())
+}
+
+// GetDerivedMetrics Alias using Datadog config
+func GetDerivedMetrics() ([]DerivedMetric, error) {
+	return pkgconfigsetup.GetDerivedMetrics(Datadog())
+}
+
+// GetDogstatsdContextLimits Alias using Datadog config
+func GetDogstatsdContextLimits() ([]ContextLimit, error) {
+	return pkgconfigsetup.GetDogstatsdContextLimits(Datadog())
+}
+
 var (
 	// IsRemoteConfigEnabled Alias
 	IsRemoteConfigEnabled = pkgconfigsetup.IsRemoteConfigEnabled
@@ -41,6 +41,8 @@ func setupAPM(config pkgconfigmodel.Setup) {
 	config.BindEnv("apm_config.obfuscation.remove_stack_traces", "DD_APM_OBFUSCATION_REMOVE_STACK_TRACES")
 	config.BindEnv("apm_config.obfuscation.redis.enabled", "DD_APM_OBFUSCATION_REDIS_ENABLED")
 	config.BindEnv("apm_config.obfuscation.redis.remove_all_args", "DD_APM_OBFUSCATION_REDIS_REMOVE_ALL_ARGS")
+	config.BindEnv("apm_config.obfuscation.graphql.enabled", "DD_APM_OBFUSCATION_GRAPHQL_ENABLED")
+	config.BindEnv("apm_config.obfuscation.graphql.remove_aliases", "DD_APM_OBFUSCATION_GRAPHQL_REMOVE_ALIASES")
 	config.BindEnv("apm_config.obfuscation.memcached.enabled", "DD_APM_OBFUSCATION_MEMCACHED_ENABLED")
 	config.BindEnv("apm_config.obfuscation.memcached.keep_command", "DD_APM_OBFUSCATION_MEMCACHED_KEEP_COMMAND")
 	config.SetKnown("apm_config.filter_tags.require")
@@ -74,12 +76,16 @@ func setupAPM(config pkgconfigmodel.Setup) {
 
 	config.BindEnvAndSetDefault("apm_config.receiver_enabled", true, "DD_APM_RECEIVER_ENABLED")
 	config.BindEnvAndSetDefault("apm_config.receiver_port", 8126, "DD_APM_RECEIVER_PORT", "DD_RECEIVER_PORT")
+	config.BindEnvAndSetDefault("apm_config.zipkin_receiver_enabled", false, "DD_APM_ZIPKIN_RECEIVER_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.jaeger_receiver_enabled", false, "DD_APM_JAEGER_RECEIVER_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.jaeger_receiver_grpc_port", 0, "DD_APM_JAEGER_RECEIVER_GRPC_PORT")
 	config.BindEnvAndSetDefault("apm_config.windows_pipe_buffer_size", 1_000_000, "DD_APM_WINDOWS_PIPE_BUFFER_SIZE")                          //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.windows_pipe_security_descriptor", "D:AI(A;;GA;;;WD)", "DD_APM_WINDOWS_PIPE_SECURITY_DESCRIPTOR") //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.remote_tagger", true, "DD_APM_REMOTE_TAGGER")                                                     //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.peer_service_aggregation", false, "DD_APM_PEER_SERVICE_AGGREGATION")                              //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.peer_tags_aggregation", false, "DD_APM_PEER_TAGS_AGGREGATION")                                    //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.compute_stats_by_span_kind", false, "DD_APM_COMPUTE_STATS_BY_SPAN_KIND")                          //nolint:errcheck
+	config.BindEnvAndSetDefault("apm_config.stats_custom_tags_cardinality_limit", 100, "DD_APM_STATS_CUSTOM_TAGS_CARDINALITY_LIMIT")
 	config.BindEnvAndSetDefault("apm_config.instrumentation.enabled", false, "DD_APM_INSTRUMENTATION_ENABLED")
 	config.BindEnvAndSetDefault("apm_config.instrumentation.enabled_namespaces", []string{}, "DD_APM_INSTRUMENTATION_ENABLED_NAMESPACES")
 	config.BindEnvAndSetDefault("apm_config.instrumentation.disabled_namespaces", []string{}, "DD_APM_INSTRUMENTATION_DISABLED_NAMESPACES")
@@ -103,6 +109,10 @@ func setupAPM(config pkgconfigmodel.Setup) {
 	config.BindEnv("apm_config.probabilistic_sampler.enabled", "DD_APM_PROBABILISTIC_SAMPLER_ENABLED")
 	config.BindEnv("apm_config.probabilistic_sampler.sampling_percentage", "DD_APM_PROBABILISTIC_SAMPLER_SAMPLING_PERCENTAGE")
 	config.BindEnv("apm_config.probabilistic_sampler.hash_seed", "DD_APM_PROBABILISTIC_SAMPLER_HASH_SEED")
+	config.BindEnv("apm_config.tail_sampling.enabled", "DD_APM_TAIL_SAMPLING_ENABLED")
+	config.BindEnv("apm_config.tail_sampling.decision_wait", "DD_APM_TAIL_SAMPLING_DECISION_WAIT")
+	config.BindEnv("apm_config.tail_sampling.max_memory_bytes", "DD_APM_TAIL_SAMPLING_MAX_MEMORY_BYTES")
+	config.BindEnv("apm_config.tail_sampling.policies", "DD_APM_TAIL_SAMPLING_POLICIES")
 
 	config.BindEnv("apm_config.max_memory", "DD_APM_MAX_MEMORY")
 	config.BindEnv("apm_config.max_cpu_percent", "DD_APM_MAX_CPU_PERCENT")
@@ -116,6 +126,7 @@ func setupAPM(config pkgconfigmodel.Setup) {
 	config.BindEnv("apm_config.profiling_additional_endpoints", "DD_APM_PROFILING_ADDITIONAL_ENDPOINTS")
 	config.BindEnv("apm_config.additional_endpoints", "DD_APM_ADDITIONAL_ENDPOINTS")
 	config.BindEnv("apm_config.replace_tags", "DD_APM_REPLACE_TAGS")
+	config.BindEnv("apm_config.span_rules", "DD_APM_SPAN_RULES")
 	config.BindEnv("apm_config.analyzed_spans", "DD_APM_ANALYZED_SPANS")
 	config.BindEnv("apm_config.ignore_resources", "DD_APM_IGNORE_RESOURCES", "DD_IGNORE_RESOURCE")
 	config.BindEnv("apm_config.receiver_socket", "DD_APM_RECEIVER_SOCKET")
@@ -186,6 +197,22 @@ func setupAPM(config pkgconfigmodel.Setup) {
 		return out
 	})
 
+	config.SetEnvKeyTransformer("apm_config.span_rules", func(in string) interface{} {
+		var out []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Warnf(`"apm_config.span_rules" can not be parsed: %v`, err)
+		}
+		return out
+	})
+
+	config.SetEnvKeyTransformer("apm_config.tail_sampling.policies", func(in string) interface{} {
+		var out []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Warnf(`"apm_config.tail_sampling.policies" can not be parsed: %v`, err)
+		}
+		return out
+	})
+
 	config.SetEnvKeyTransformer("apm_config.analyzed_spans", func(in string) interface{} {
 		out, err := parseAnalyzedSpans(in)
 		if err != nil {
@@ -202,6 +229,15 @@ func setupAPM(config pkgconfigmodel.Setup) {
 		}
 		return out
 	})
+
+	config.BindEnv("apm_config.stats_custom_tags", "DD_APM_STATS_CUSTOM_TAGS")
+	config.SetEnvKeyTransformer("apm_config.stats_custom_tags", func(in string) interface{} {
+		var out []string
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Warnf(`"apm_config.stats_custom_tags" can not be parsed: %v`, err)
+		}
+		return out
+	})
 }
 
 func parseKVList(key string) func(string) interface{} {
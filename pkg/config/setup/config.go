@@ -197,6 +197,40 @@ type MetricMapping struct {
 	Tags      map[string]string `mapstructure:"tags" json:"tags" yaml:"tags"`
 }
 
+// TagFilterRule represents the tag keys kept or removed from the DogStatsD metrics matching a name pattern
+type TagFilterRule struct {
+	Match string   `mapstructure:"match" json:"match" yaml:"match"`
+	Allow []string `mapstructure:"allow" json:"allow" yaml:"allow"`
+	Deny  []string `mapstructure:"deny" json:"deny" yaml:"deny"`
+}
+
+// MetricRoute represents the endpoints receiving the series and sketches matching some tags,
+// instead of the main endpoint and the additional endpoints
+type MetricRoute struct {
+	Name      string              `mapstructure:"name" json:"name" yaml:"name"`
+	MatchTags []string            `mapstructure:"match_tags" json:"match_tags" yaml:"match_tags"`
+	Endpoints map[string][]string `mapstructure:"endpoints" json:"endpoints" yaml:"endpoints"`
+}
+
+// ContextLimit represents the maximum number of contexts of a DogStatsD metric
+type ContextLimit struct {
+	MetricName string `mapstructure:"metric_name" json:"metric_name" yaml:"metric_name"`
+	Limit      int    `mapstructure:"limit" json:"limit" yaml:"limit"`
+}
+
+// RollupRule represents the DogStatsD metrics aggregated into a coarser interval than the
+// default 10 seconds one
+type RollupRule struct {
+	Interval int      `mapstructure:"interval" json:"interval" yaml:"interval"`
+	Prefixes []string `mapstructure:"prefixes" json:"prefixes" yaml:"prefixes"`
+}
+
+// DerivedMetric represents a metric computed by the aggregator from other metrics on each flush
+type DerivedMetric struct {
+	Name       string `mapstructure:"name" json:"name" yaml:"name"`
+	Expression string `mapstructure:"expression" json:"expression" yaml:"expression"`
+}
+
 // DataType represent the generic data type (e.g. metrics, logs) that can be sent by the Agent
 type DataType string
 
@@ -1216,6 +1250,24 @@ func aggregator(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("basic_telemetry_add_container_tags", false) // configure adding the agent container tags to the basic agent telemetry metrics (e.g. `datadog.agent.running`)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_chan_size", 200)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_buffer_size", 4000)
+	// Metrics computed from the flushed series
+	config.BindEnv("derived_metrics")
+	config.SetEnvKeyTransformer("derived_metrics", func(in string) interface{} {
+		var derivedMetrics []DerivedMetric
+		if err := json.Unmarshal([]byte(in), &derivedMetrics); err != nil {
+			log.Errorf(`"derived_metrics" can not be parsed: %v`, err)
+		}
+		return derivedMetrics
+	})
+	// Exposition of the flushed metrics in the OpenMetrics format
+	config.BindEnvAndSetDefault("openmetrics_exposition.enabled", false)
+	config.BindEnvAndSetDefault("openmetrics_exposition.port", 5004)
+	config.BindEnvAndSetDefault("openmetrics_exposition.non_local_traffic", false)
+	// Receiver of the metrics pushed with the Prometheus remote-write protocol
+	config.BindEnvAndSetDefault("prometheus_remote_write.enabled", false)
+	config.BindEnvAndSetDefault("prometheus_remote_write.port", 9201)
+	config.BindEnvAndSetDefault("prometheus_remote_write.non_local_traffic", false)
+	config.BindEnvAndSetDefault("prometheus_remote_write.tags", []string{})
 }
 
 func serverless(config pkgconfigmodel.Setup) {
@@ -1233,6 +1285,14 @@ func serverless(config pkgconfigmodel.Setup) {
 func forwarder(config pkgconfigmodel.Setup) {
 	// Forwarder
 	config.BindEnvAndSetDefault("additional_endpoints", map[string][]string{})
+	config.BindEnv("metric_routes")
+	config.SetEnvKeyTransformer("metric_routes", func(in string) interface{} {
+		var routes []MetricRoute
+		if err := json.Unmarshal([]byte(in), &routes); err != nil {
+			log.Errorf(`"metric_routes" can not be parsed: %v`, err)
+		}
+		return routes
+	})
 	config.BindEnvAndSetDefault("forwarder_timeout", 20)
 	config.BindEnv("forwarder_retry_queue_max_size")                                                     // Deprecated in favor of `forwarder_retry_queue_payloads_max_size`
 	config.BindEnv("forwarder_retry_queue_payloads_max_size")                                            // Default value is defined inside `NewOptions` in pkg/forwarder/forwarder.go
@@ -1266,6 +1326,12 @@ func dogstatsd(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("use_dogstatsd", true)
 	config.BindEnvAndSetDefault("dogstatsd_port", 8125)    // Notice: 0 means UDP port closed
 	config.BindEnvAndSetDefault("dogstatsd_pipe_name", "") // experimental and not officially supported for now.
+	// TCP listener, the messages are either terminated by a newline or prefixed by their
+	// length on 4 bytes. Options for the framing are: newline, length_prefixed
+	config.BindEnvAndSetDefault("dogstatsd_tcp_port", 0) // Notice: 0 means TCP port closed
+	config.BindEnvAndSetDefault("dogstatsd_tcp_framing", "newline")
+	config.BindEnvAndSetDefault("dogstatsd_tcp_tls_cert_path", "") // Notice: empty means TLS disabled
+	config.BindEnvAndSetDefault("dogstatsd_tcp_tls_key_path", "")
 	// Experimental and not officially supported for now.
 	// Options are: udp, uds, named_pipe
 	config.BindEnvAndSetDefault("dogstatsd_eol_required", []string{})
@@ -1299,6 +1365,27 @@ func dogstatsd(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("dogstatsd_expiry_seconds", 300)
 	// Control how long we keep dogstatsd contexts in memory.
 	config.BindEnvAndSetDefault("dogstatsd_context_expiry_seconds", 20)
+	// Limit the number of contexts of each metric name and of each origin, the contexts beyond
+	// the limits are folded into a single `overflow:true` context. Notice: 0 means no limit.
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_per_metric", 0)
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_per_origin", 0)
+	config.BindEnv("dogstatsd_context_limits")
+	config.SetEnvKeyTransformer("dogstatsd_context_limits", func(in string) interface{} {
+		var limits []ContextLimit
+		if err := json.Unmarshal([]byte(in), &limits); err != nil {
+			log.Errorf(`"dogstatsd_context_limits" can not be parsed: %v`, err)
+		}
+		return limits
+	})
+	// Aggregate the metrics matching some prefixes into coarser intervals than the default one.
+	config.BindEnv("dogstatsd_rollups")
+	config.SetEnvKeyTransformer("dogstatsd_rollups", func(in string) interface{} {
+		var rules []RollupRule
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"dogstatsd_rollups" can not be parsed: %v`, err)
+		}
+		return rules
+	})
 	config.BindEnvAndSetDefault("dogstatsd_origin_detection", false) // Only supported for socket traffic
 	config.BindEnvAndSetDefault("dogstatsd_origin_detection_client", false)
 	config.BindEnvAndSetDefault("dogstatsd_origin_optout_enabled", true)
@@ -1345,6 +1432,15 @@ func dogstatsd(config pkgconfigmodel.Setup) {
 		return mappings
 	})
 
+	config.BindEnv("dogstatsd_tag_filters")
+	config.SetEnvKeyTransformer("dogstatsd_tag_filters", func(in string) interface{} {
+		var rules []TagFilterRule
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"dogstatsd_tag_filters" can not be parsed: %v`, err)
+		}
+		return rules
+	})
+
 	config.BindEnvAndSetDefault("statsd_forward_host", "")
 	config.BindEnvAndSetDefault("statsd_forward_port", 0)
 	config.BindEnvAndSetDefault("statsd_metric_namespace", "")
@@ -1421,6 +1517,9 @@ func logsagent(config pkgconfigmodel.Setup) {
 	// DEPRECATED in favor of `logs_config.force_use_tcp`.
 	config.BindEnvAndSetDefault("logs_config.use_tcp", false)
 	config.BindEnvAndSetDefault("logs_config.force_use_tcp", false)
+	// Send the logs to an OpenTelemetry collector with OTLP/HTTP instead of the Datadog intake,
+	// 'logs_config.logs_dd_url' must be set to the address of the collector.
+	config.BindEnvAndSetDefault("logs_config.use_otlp", false)
 
 	bindEnvAndSetLogsConfigKeys(config, "logs_config.")
 	bindEnvAndSetLogsConfigKeys(config, "database_monitoring.samples.")
@@ -1441,6 +1540,8 @@ func logsagent(config pkgconfigmodel.Setup) {
 	// maximum time that the windows tailer will hold a log file open, while waiting for
 	// the downstream logs pipeline to be ready to accept more data
 	config.BindEnvAndSetDefault("logs_config.windows_open_file_timeout", 5)
+	// number of bytes at the beginning of the log files used to fingerprint them, 0 disables the fingerprinting
+	config.BindEnvAndSetDefault("logs_config.fingerprint_size", 0)
 	config.BindEnvAndSetDefault("logs_config.experimental_auto_multi_line_detection", false)
 	config.BindEnvAndSetDefault("logs_config.auto_multi_line_detection", false)
 	config.BindEnvAndSetDefault("logs_config.auto_multi_line_extra_patterns", []string{})
@@ -1472,6 +1573,9 @@ func logsagent(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("logs_config.aggregation_timeout", 1000)
 	// Time in seconds
 	config.BindEnvAndSetDefault("logs_config.file_scan_period", 10.0)
+	// If true, the directories of the file sources are watched (inotify on Linux) to tail the new
+	// files as soon as they are created, the periodic scan is still used to detect the other changes.
+	config.BindEnvAndSetDefault("logs_config.file_watcher_enabled", false)
 
 	// Controls how wildcard file log source are prioritized when there are more files
 	// that match wildcard log configurations than the `logs_config.open_files_limit`
@@ -1484,6 +1588,15 @@ func logsagent(config pkgconfigmodel.Setup) {
 
 	// Max size in MB to allow for integrations logs files
 	config.BindEnvAndSetDefault("logs_config.integrations_logs_files_max_size", 100)
+
+	// Disk spool storing the logs payloads when no reliable destination can accept them.
+	// Defaults to `<logs_config.run_path>/logs_spool` when the path is empty.
+	config.BindEnvAndSetDefault("logs_config.disk_spool.enabled", false)
+	config.BindEnvAndSetDefault("logs_config.disk_spool.path", "")
+	// Max size in MB of the spool of each pipeline.
+	config.BindEnvAndSetDefault("logs_config.disk_spool.max_size_mb", 1024)
+	// Spooled payloads older than this duration are dropped.
+	config.BindEnvAndSetDefault("logs_config.disk_spool.max_age", 24*time.Hour)
 }
 
 func vector(config pkgconfigmodel.Setup) {
@@ -2359,6 +2472,71 @@ func getDogstatsdMappingProfilesConfig(config pkgconfigmodel.Reader) ([]MappingP
 	return mappings, nil
 }
 
+// GetDogstatsdContextLimits returns the limits of contexts of the DogStatsD metrics which
+// override `dogstatsd_context_limit_per_metric`
+func GetDogstatsdContextLimits(config pkgconfigmodel.Reader) ([]ContextLimit, error) {
+	var limits []ContextLimit
+	if config.IsSet("dogstatsd_context_limits") {
+		err := config.UnmarshalKey("dogstatsd_context_limits", &limits)
+		if err != nil {
+			return []ContextLimit{}, log.Errorf("Could not parse dogstatsd_context_limits: %v", err)
+		}
+	}
+	return limits, nil
+}
+
+// GetDogstatsdRollups returns the rules aggregating DogStatsD metrics into coarser intervals
+func GetDogstatsdRollups(config pkgconfigmodel.Reader) ([]RollupRule, error) {
+	var rules []RollupRule
+	if config.IsSet("dogstatsd_rollups") {
+		err := config.UnmarshalKey("dogstatsd_rollups", &rules)
+		if err != nil {
+			return []RollupRule{}, log.Errorf("Could not parse dogstatsd_rollups: %v", err)
+		}
+	}
+	return rules, nil
+}
+
+// GetDerivedMetrics returns the metrics computed by the aggregator from the flushed series
+func GetDerivedMetrics(config pkgconfigmodel.Reader) ([]DerivedMetric, error) {
+	var derivedMetrics []DerivedMetric
+	if config.IsSet("derived_metrics") {
+		err := config.UnmarshalKey("derived_metrics", &derivedMetrics)
+		if err != nil {
+			return []DerivedMetric{}, log.Errorf("Could not parse derived_metrics: %v", err)
+		}
+	}
+	return derivedMetrics, nil
+}
+
+// GetDogstatsdTagFilters returns the rules filtering the tag keys of the DogStatsD metrics
+func GetDogstatsdTagFilters(config pkgconfigmodel.Reader) ([]TagFilterRule, error) {
+	var rules []TagFilterRule
+	if config.IsSet("dogstatsd_tag_filters") {
+		err := config.UnmarshalKey("dogstatsd_tag_filters", &rules)
+		if err != nil {
+			return []TagFilterRule{}, log.Errorf("Could not parse dogstatsd_tag_filters: %v", err)
+		}
+	}
+	return rules, nil
+}
+
+// GetMetricRoutes returns the routes sending the series and sketches matching some tags to dedicated endpoints
+func GetMetricRoutes(config pkgconfigmodel.Reader) ([]MetricRoute, error) {
+	var routes []MetricRoute
+	if config.IsSet("metric_routes") {
+		err := config.UnmarshalKey("metric_routes", &routes)
+		if err != nil {
+			return []MetricRoute{}, log.Errorf("Could not parse metric_routes: %v", err)
+		}
+		// only the series sent to the v2 API can be routed
+		if len(routes) > 0 && !config.GetBool("use_v2_api.series") {
+			return []MetricRoute{}, log.Errorf("metric_routes requires use_v2_api.series to be enabled, ignoring the metric routes")
+		}
+	}
+	return routes, nil
+}
+
 // IsCLCRunner returns whether the Agent is in cluster check runner mode
 func IsCLCRunner(config pkgconfigmodel.Reader) bool {
 	if !config.GetBool("clc_runner_enabled") {
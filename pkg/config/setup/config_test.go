@@ -742,6 +742,146 @@ func TestDogstatsdMappingProfilesEnv(t *testing.T) {
 	assert.Equal(t, mappings, expected)
 }
 
+func TestDogstatsdContextLimits(t *testing.T) {
+	datadogYaml := `
+dogstatsd_context_limits:
+  - metric_name: "my.metric"
+    limit: 100
+  - metric_name: "my.other_metric"
+    limit: 0
+`
+	testConfig := ConfFromYAML(datadogYaml)
+
+	limits, err := GetDogstatsdContextLimits(testConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, []ContextLimit{{MetricName: "my.metric", Limit: 100}, {MetricName: "my.other_metric", Limit: 0}}, limits)
+}
+
+func TestDogstatsdContextLimitsEnv(t *testing.T) {
+	t.Setenv("DD_DOGSTATSD_CONTEXT_LIMITS", `[{"metric_name":"my.metric","limit":100}]`)
+	cfg := Conf()
+	limits, err := GetDogstatsdContextLimits(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []ContextLimit{{MetricName: "my.metric", Limit: 100}}, limits)
+}
+
+func TestDogstatsdTagFilters(t *testing.T) {
+	datadogYaml := `
+dogstatsd_tag_filters:
+  - match: "checkout.*"
+    deny: ["user_id", "session"]
+  - match: "payment.latency"
+    allow: ["env", "service"]
+`
+	testConfig := ConfFromYAML(datadogYaml)
+
+	rules, err := GetDogstatsdTagFilters(testConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, []TagFilterRule{
+		{Match: "checkout.*", Deny: []string{"user_id", "session"}},
+		{Match: "payment.latency", Allow: []string{"env", "service"}},
+	}, rules)
+}
+
+func TestDogstatsdTagFiltersEnv(t *testing.T) {
+	t.Setenv("DD_DOGSTATSD_TAG_FILTERS", `[{"match":"checkout.*","deny":["user_id"]}]`)
+	cfg := Conf()
+	rules, err := GetDogstatsdTagFilters(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []TagFilterRule{{Match: "checkout.*", Deny: []string{"user_id"}}}, rules)
+}
+
+func TestDogstatsdRollups(t *testing.T) {
+	datadogYaml := `
+dogstatsd_rollups:
+  - interval: 60
+    prefixes: ["system.", "kubernetes."]
+  - interval: 300
+    prefixes: ["ntp."]
+`
+	cfg := ConfFromYAML(datadogYaml)
+	rules, err := GetDogstatsdRollups(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []RollupRule{
+		{Interval: 60, Prefixes: []string{"system.", "kubernetes."}},
+		{Interval: 300, Prefixes: []string{"ntp."}},
+	}, rules)
+}
+
+func TestDogstatsdRollupsEnv(t *testing.T) {
+	t.Setenv("DD_DOGSTATSD_ROLLUPS", `[{"interval":60,"prefixes":["system."]}]`)
+	cfg := Conf()
+	rules, err := GetDogstatsdRollups(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []RollupRule{{Interval: 60, Prefixes: []string{"system."}}}, rules)
+}
+
+func TestDerivedMetrics(t *testing.T) {
+	datadogYaml := `
+derived_metrics:
+  - name: http.error_ratio
+    expression: http.errors / http.requests by {service}
+`
+	cfg := ConfFromYAML(datadogYaml)
+	derivedMetrics, err := GetDerivedMetrics(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []DerivedMetric{{Name: "http.error_ratio", Expression: "http.errors / http.requests by {service}"}}, derivedMetrics)
+}
+
+func TestDerivedMetricsEnv(t *testing.T) {
+	t.Setenv("DD_DERIVED_METRICS", `[{"name":"http.error_ratio","expression":"http.errors / http.requests"}]`)
+	cfg := Conf()
+	derivedMetrics, err := GetDerivedMetrics(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []DerivedMetric{{Name: "http.error_ratio", Expression: "http.errors / http.requests"}}, derivedMetrics)
+}
+
+func TestMetricRoutes(t *testing.T) {
+	datadogYaml := `
+metric_routes:
+  - name: payments
+    match_tags: ["team:payments", "kube_namespace:payments-*"]
+    endpoints:
+      "https://app.datadoghq.com": ["apikey1"]
+`
+	cfg := ConfFromYAML(datadogYaml)
+	routes, err := GetMetricRoutes(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []MetricRoute{{
+		Name:      "payments",
+		MatchTags: []string{"team:payments", "kube_namespace:payments-*"},
+		Endpoints: map[string][]string{"https://app.datadoghq.com": {"apikey1"}},
+	}}, routes)
+}
+
+func TestMetricRoutesWithoutV2Series(t *testing.T) {
+	datadogYaml := `
+use_v2_api:
+  series: false
+metric_routes:
+  - name: payments
+    match_tags: ["team:payments"]
+    endpoints:
+      "https://app.datadoghq.com": ["apikey1"]
+`
+	cfg := ConfFromYAML(datadogYaml)
+	routes, err := GetMetricRoutes(cfg)
+	assert.Error(t, err)
+	assert.Empty(t, routes)
+}
+
+func TestMetricRoutesEnv(t *testing.T) {
+	t.Setenv("DD_METRIC_ROUTES", `[{"name":"payments","match_tags":["team:payments"],"endpoints":{"https://app.datadoghq.eu":["apikey1"]}}]`)
+	cfg := Conf()
+	routes, err := GetMetricRoutes(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []MetricRoute{{
+		Name:      "payments",
+		MatchTags: []string{"team:payments"},
+		Endpoints: map[string][]string{"https://app.datadoghq.eu": {"apikey1"}},
+	}}, routes)
+}
+
 func TestGetValidHostAliasesWithConfig(t *testing.T) {
 	config := ConfFromYAML(`host_aliases: ["foo", "-bar"]`)
 	assert.EqualValues(t, getValidHostAliasesWithConfig(config), []string{"foo"})
@@ -33,6 +33,10 @@ const registryAPIVersion = 2
 type Registry interface {
 	GetOffset(identifier string) string
 	GetTailingMode(identifier string) string
+	GetLastUpdated(identifier string) time.Time
+	GetFingerprint(identifier string) string
+	GetIdentifierForFingerprint(fingerprint string) string
+	IsCompleted(identifier string) bool
 }
 
 // A RegistryEntry represents an entry in the registry where we keep track
@@ -42,6 +46,12 @@ type RegistryEntry struct {
 	Offset             string
 	TailingMode        string
 	IngestionTimestamp int64
+	// Fingerprint identifies the content of the file, it is empty if the
+	// file is not fingerprinted
+	Fingerprint string `json:",omitempty"`
+	// Completed is true if the whole file has been read, it is only set for
+	// the compressed files which are not appended to
+	Completed bool `json:",omitempty"`
 }
 
 // JSONRegistry represents the registry that will be written on disk
@@ -152,6 +162,55 @@ func (a *RegistryAuditor) GetTailingMode(identifier string) string {
 	return entry.TailingMode
 }
 
+// GetLastUpdated returns the last time an offset was committed for a given identifier,
+// returns the zero time if it does not exist.
+func (a *RegistryAuditor) GetLastUpdated(identifier string) time.Time {
+	r := a.readOnlyRegistryCopy()
+	entry, exists := r[identifier]
+	if !exists {
+		return time.Time{}
+	}
+	return entry.LastUpdated
+}
+
+// GetFingerprint returns the fingerprint of the file for a given identifier,
+// returns an empty string if it does not exist.
+func (a *RegistryAuditor) GetFingerprint(identifier string) string {
+	r := a.readOnlyRegistryCopy()
+	entry, exists := r[identifier]
+	if !exists {
+		return ""
+	}
+	return entry.Fingerprint
+}
+
+// GetIdentifierForFingerprint returns the identifier of the most recently updated
+// entry with the given fingerprint, returns an empty string if it does not exist.
+func (a *RegistryAuditor) GetIdentifierForFingerprint(fingerprint string) string {
+	if fingerprint == "" {
+		return ""
+	}
+	var identifier string
+	var lastUpdated time.Time
+	for id, entry := range a.readOnlyRegistryCopy() {
+		if entry.Fingerprint == fingerprint && entry.LastUpdated.After(lastUpdated) {
+			identifier, lastUpdated = id, entry.LastUpdated
+		}
+	}
+	return identifier
+}
+
+// IsCompleted returns true if the file for a given identifier has been read
+// until its end, returns false if it does not exist.
+func (a *RegistryAuditor) IsCompleted(identifier string) bool {
+	r := a.readOnlyRegistryCopy()
+	entry, exists := r[identifier]
+	if !exists {
+		return false
+	}
+	return entry.Completed
+}
+
 // run keeps up to date the registry depending on different events
 func (a *RegistryAuditor) run() {
 	cleanUpTicker := time.NewTicker(defaultCleanupPeriod)
@@ -174,7 +233,7 @@ func (a *RegistryAuditor) run() {
 			}
 			// update the registry with new entry
 			for _, msg := range payload.Messages {
-				a.updateRegistry(msg.Origin.Identifier, msg.Origin.Offset, msg.Origin.LogSource.Config.TailingMode, msg.IngestionTimestamp)
+				a.updateRegistry(msg.Origin.Identifier, msg.Origin.Offset, msg.Origin.LogSource.Config.TailingMode, msg.IngestionTimestamp, msg.Origin.Fingerprint, msg.Origin.Completed)
 			}
 		case <-cleanUpTicker.C:
 			// remove expired offsets from registry
@@ -228,7 +287,7 @@ func (a *RegistryAuditor) cleanupRegistry() {
 }
 
 // updateRegistry updates the registry entry matching identifier with new the offset and timestamp
-func (a *RegistryAuditor) updateRegistry(identifier string, offset string, tailingMode string, ingestionTimestamp int64) {
+func (a *RegistryAuditor) updateRegistry(identifier string, offset string, tailingMode string, ingestionTimestamp int64, fingerprint string, completed bool) {
 	a.registryMutex.Lock()
 	defer a.registryMutex.Unlock()
 	if identifier == "" {
@@ -251,6 +310,8 @@ func (a *RegistryAuditor) updateRegistry(identifier string, offset string, taili
 		Offset:             offset,
 		TailingMode:        tailingMode,
 		IngestionTimestamp: ingestionTimestamp,
+		Fingerprint:        fingerprint,
+		Completed:          completed,
 	}
 }
 
@@ -51,11 +51,11 @@ func (suite *AuditorTestSuite) TestAuditorStartStop() {
 func (suite *AuditorTestSuite) TestAuditorUpdatesRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.Equal(0, len(suite.a.registry))
-	suite.a.updateRegistry(suite.source.Config.Path, "42", "end", 0)
+	suite.a.updateRegistry(suite.source.Config.Path, "42", "end", 0, "", false)
 	suite.Equal(1, len(suite.a.registry))
 	suite.Equal("42", suite.a.registry[suite.source.Config.Path].Offset)
 	suite.Equal("end", suite.a.registry[suite.source.Config.Path].TailingMode)
-	suite.a.updateRegistry(suite.source.Config.Path, "43", "beginning", 1)
+	suite.a.updateRegistry(suite.source.Config.Path, "43", "beginning", 1, "", false)
 	suite.Equal(1, len(suite.a.registry))
 	suite.Equal("43", suite.a.registry[suite.source.Config.Path].Offset)
 	suite.Equal("beginning", suite.a.registry[suite.source.Config.Path].TailingMode)
@@ -92,6 +92,42 @@ func (suite *AuditorTestSuite) TestAuditorRecoversRegistryForOffset() {
 	suite.Equal("", offset)
 }
 
+func (suite *AuditorTestSuite) TestAuditorRecoversRegistryForFingerprint() {
+	suite.a.registry = make(map[string]*RegistryEntry)
+	suite.a.updateRegistry("file:/var/log/app.log", "42", "end", 0, "5ab1c3", false)
+	suite.a.updateRegistry("file:/var/log/other.log", "43", "end", 0, "", false)
+	suite.a.registry["file:/var/log/app.log.1"] = &RegistryEntry{
+		LastUpdated: time.Date(2006, time.January, 12, 1, 1, 1, 1, time.UTC),
+		Offset:      "10",
+		Fingerprint: "5ab1c3",
+	}
+
+	suite.Equal("5ab1c3", suite.a.GetFingerprint("file:/var/log/app.log"))
+	suite.Equal("", suite.a.GetFingerprint("file:/var/log/other.log"))
+	// the most recently updated entry is returned
+	suite.Equal("file:/var/log/app.log", suite.a.GetIdentifierForFingerprint("5ab1c3"))
+	suite.Equal("", suite.a.GetIdentifierForFingerprint("unknown"))
+	suite.Equal("", suite.a.GetIdentifierForFingerprint(""))
+
+	suite.NoError(suite.a.flushRegistry())
+	suite.a.registry = suite.a.recoverRegistry()
+	suite.Equal("5ab1c3", suite.a.GetFingerprint("file:/var/log/app.log"))
+}
+
+func (suite *AuditorTestSuite) TestAuditorRecoversRegistryForCompleted() {
+	suite.a.registry = make(map[string]*RegistryEntry)
+	suite.a.updateRegistry("file:/var/log/app.log.1.gz", "42", "beginning", 0, "", true)
+	suite.a.updateRegistry("file:/var/log/app.log.2.gz", "43", "beginning", 0, "", false)
+
+	suite.True(suite.a.IsCompleted("file:/var/log/app.log.1.gz"))
+	suite.False(suite.a.IsCompleted("file:/var/log/app.log.2.gz"))
+	suite.False(suite.a.IsCompleted("file:/var/log/unknown.gz"))
+
+	suite.NoError(suite.a.flushRegistry())
+	suite.a.registry = suite.a.recoverRegistry()
+	suite.True(suite.a.IsCompleted("file:/var/log/app.log.1.gz"))
+}
+
 func (suite *AuditorTestSuite) TestAuditorCleansupRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.a.registry[suite.source.Config.Path] = &RegistryEntry{
@@ -6,10 +6,20 @@
 //nolint:revive // TODO(AML) Fix revive linter
 package mock
 
+import "time"
+
 // Registry does nothing
 type Registry struct {
 	offset      string
 	tailingMode string
+	lastUpdated time.Time
+	fingerprint string
+	// identifiers maps the fingerprints to the identifiers
+	identifiers map[string]string
+	// offsets holds the offsets set for specific identifiers
+	offsets map[string]string
+	// completed holds the identifiers of the files read until their end
+	completed map[string]bool
 }
 
 // NewRegistry returns a new registry.
@@ -18,7 +28,10 @@ func NewRegistry() *Registry {
 }
 
 // GetOffset returns the offset.
-func (r *Registry) GetOffset(_ string) string {
+func (r *Registry) GetOffset(identifier string) string {
+	if offset, ok := r.offsets[identifier]; ok {
+		return offset
+	}
 	return r.offset
 }
 
@@ -27,6 +40,14 @@ func (r *Registry) SetOffset(offset string) {
 	r.offset = offset
 }
 
+// SetOffsetForIdentifier sets the offset returned for the identifier.
+func (r *Registry) SetOffsetForIdentifier(identifier string, offset string) {
+	if r.offsets == nil {
+		r.offsets = make(map[string]string)
+	}
+	r.offsets[identifier] = offset
+}
+
 // GetTailingMode returns the tailing mode.
 func (r *Registry) GetTailingMode(_ string) string {
 	return r.tailingMode
@@ -36,3 +57,49 @@ func (r *Registry) GetTailingMode(_ string) string {
 func (r *Registry) SetTailingMode(tailingMode string) {
 	r.tailingMode = tailingMode
 }
+
+// GetLastUpdated returns the last update time.
+func (r *Registry) GetLastUpdated(_ string) time.Time {
+	return r.lastUpdated
+}
+
+// SetLastUpdated sets the last update time.
+func (r *Registry) SetLastUpdated(lastUpdated time.Time) {
+	r.lastUpdated = lastUpdated
+}
+
+// GetFingerprint returns the fingerprint.
+func (r *Registry) GetFingerprint(_ string) string {
+	return r.fingerprint
+}
+
+// SetFingerprint sets the fingerprint.
+func (r *Registry) SetFingerprint(fingerprint string) {
+	r.fingerprint = fingerprint
+}
+
+// GetIdentifierForFingerprint returns the identifier set for the fingerprint.
+func (r *Registry) GetIdentifierForFingerprint(fingerprint string) string {
+	return r.identifiers[fingerprint]
+}
+
+// SetIdentifierForFingerprint sets the identifier returned for the fingerprint.
+func (r *Registry) SetIdentifierForFingerprint(fingerprint string, identifier string) {
+	if r.identifiers == nil {
+		r.identifiers = make(map[string]string)
+	}
+	r.identifiers[fingerprint] = identifier
+}
+
+// IsCompleted returns true if the identifier was set as completed.
+func (r *Registry) IsCompleted(identifier string) bool {
+	return r.completed[identifier]
+}
+
+// SetCompleted sets the file of the identifier as read until its end.
+func (r *Registry) SetCompleted(identifier string) {
+	if r.completed == nil {
+		r.completed = make(map[string]bool)
+	}
+	r.completed[identifier] = true
+}
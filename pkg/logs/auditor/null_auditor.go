@@ -6,6 +6,8 @@
 package auditor
 
 import (
+	"time"
+
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
@@ -36,6 +38,18 @@ func (a *NullAuditor) GetOffset(_ string) string { return "" }
 //nolint:revive // TODO(AML) Fix revive linter
 func (a *NullAuditor) GetTailingMode(_ string) string { return "" }
 
+// GetLastUpdated returns the zero time.
+func (a *NullAuditor) GetLastUpdated(_ string) time.Time { return time.Time{} }
+
+// GetFingerprint returns an empty string.
+func (a *NullAuditor) GetFingerprint(_ string) string { return "" }
+
+// GetIdentifierForFingerprint returns an empty string.
+func (a *NullAuditor) GetIdentifierForFingerprint(_ string) string { return "" }
+
+// IsCompleted returns false.
+func (a *NullAuditor) IsCompleted(_ string) bool { return false }
+
 // Start starts the NullAuditor main loop.
 func (a *NullAuditor) Start() {
 	go a.run()
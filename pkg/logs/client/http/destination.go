@@ -56,6 +56,21 @@ var (
 //nolint:revive // TODO(AML) Fix revive linter
 var emptyJsonPayload = message.Payload{Messages: []*message.Message{}, Encoded: []byte("{}")}
 
+// Intake describes a service other than the Datadog intake receiving the payloads of a
+// Destination, e.g. an OpenTelemetry collector. The payloads are encoded for the intake
+// and posted without the Datadog headers.
+type Intake struct {
+	// Path is the path of the URL the payloads are posted to
+	Path string
+	// ContentType is the content type of the encoded payloads
+	ContentType string
+	// Encode returns the body of the request sending the messages of a payload and its content
+	// encoding, the payloads which can't be encoded are dropped
+	Encode func(messages []*message.Message) (body []byte, encoding string, err error)
+	// IsRetryable returns true if a request failing with an HTTP status code >= 400 can be retried
+	IsRetryable func(statusCode int) bool
+}
+
 // Destination sends a payload over HTTP.
 type Destination struct {
 	// Config
@@ -68,6 +83,8 @@ type Destination struct {
 	protocol            config.IntakeProtocol
 	origin              config.IntakeOrigin
 	isMRF               bool
+	// intake is nil when the payloads are sent to the Datadog intake
+	intake *Intake
 
 	// Concurrency
 	climit chan struct{} // semaphore for limiting concurrent background sends
@@ -107,6 +124,29 @@ func NewDestination(endpoint config.Endpoint,
 		cfg)
 }
 
+// NewIntakeDestination returns a new Destination sending the payloads to an intake other than
+// the Datadog one, with the same concurrency and retries as the Datadog intake destinations.
+func NewIntakeDestination(endpoint config.Endpoint,
+	intake *Intake,
+	destinationsContext *client.DestinationsContext,
+	maxConcurrentBackgroundSends int,
+	shouldRetry bool,
+	telemetryName string,
+	cfg pkgconfigmodel.Reader) *Destination {
+
+	d := newDestination(endpoint,
+		intake.ContentType,
+		destinationsContext,
+		time.Second*10,
+		maxConcurrentBackgroundSends,
+		shouldRetry,
+		telemetryName,
+		cfg)
+	d.url = buildURLWithPath(endpoint, intake.Path)
+	d.intake = intake
+	return d
+}
+
 func newDestination(endpoint config.Endpoint,
 	contentType string,
 	destinationsContext *client.DestinationsContext,
@@ -219,6 +259,24 @@ func (d *Destination) sendConcurrent(payload *message.Payload, output chan *mess
 
 // Send sends a payload over HTTP,
 func (d *Destination) sendAndRetry(payload *message.Payload, output chan *message.Payload, isRetrying chan bool) {
+	encoded := payload
+	if d.intake != nil {
+		body, encoding, err := d.intake.Encode(payload.Messages)
+		if err != nil {
+			// retrying won't help, the payload is dropped
+			log.Warnf("Could not encode payload for %s: %v", d.url, err)
+			tlmDropped.Inc()
+			output <- payload
+			return
+		}
+		encoded = &message.Payload{
+			Messages:      payload.Messages,
+			Encoded:       body,
+			Encoding:      encoding,
+			UnencodedSize: payload.UnencodedSize,
+		}
+	}
+
 	for {
 
 		d.retryLock.Lock()
@@ -234,7 +292,7 @@ func (d *Destination) sendAndRetry(payload *message.Payload, output chan *messag
 			metrics.TlmRetryCount.Add(1)
 		}
 
-		err := d.unconditionalSend(payload)
+		err := d.unconditionalSend(encoded)
 
 		if err != nil {
 			metrics.DestinationErrors.Add(1)
@@ -287,23 +345,25 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 		// this can happen when the method or the url are valid.
 		return err
 	}
-	req.Header.Set("DD-API-KEY", d.endpoint.GetAPIKey())
 	req.Header.Set("Content-Type", d.contentType)
 	req.Header.Set("User-Agent", fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
 
 	if payload.Encoding != "" {
 		req.Header.Set("Content-Encoding", payload.Encoding)
 	}
-	if d.protocol != "" {
-		req.Header.Set("DD-PROTOCOL", string(d.protocol))
-	}
-	if d.origin != "" {
-		req.Header.Set("DD-EVP-ORIGIN", string(d.origin))
-		req.Header.Set("DD-EVP-ORIGIN-VERSION", version.AgentVersion)
-	}
-	req.Header.Set("dd-message-timestamp", strconv.FormatInt(getMessageTimestamp(payload.Messages), 10))
 	then := time.Now()
-	req.Header.Set("dd-current-timestamp", strconv.FormatInt(then.UnixMilli(), 10))
+	if d.intake == nil {
+		req.Header.Set("DD-API-KEY", d.endpoint.GetAPIKey())
+		if d.protocol != "" {
+			req.Header.Set("DD-PROTOCOL", string(d.protocol))
+		}
+		if d.origin != "" {
+			req.Header.Set("DD-EVP-ORIGIN", string(d.origin))
+			req.Header.Set("DD-EVP-ORIGIN-VERSION", version.AgentVersion)
+		}
+		req.Header.Set("dd-message-timestamp", strconv.FormatInt(getMessageTimestamp(payload.Messages), 10))
+		req.Header.Set("dd-current-timestamp", strconv.FormatInt(then.UnixMilli(), 10))
+	}
 
 	req = req.WithContext(ctx)
 	resp, err := d.client.Do(req)
@@ -335,6 +395,13 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 	if resp.StatusCode >= http.StatusBadRequest {
 		log.Warnf("failed to post http payload. code=%d host=%s response=%s", resp.StatusCode, d.host, string(response))
 	}
+	if d.intake != nil && resp.StatusCode >= http.StatusBadRequest {
+		if d.intake.IsRetryable(resp.StatusCode) {
+			return client.NewRetryableError(errServer)
+		}
+		tlmDropped.Inc()
+		return errClient
+	}
 	if resp.StatusCode == http.StatusBadRequest ||
 		resp.StatusCode == http.StatusUnauthorized ||
 		resp.StatusCode == http.StatusForbidden ||
@@ -387,6 +454,14 @@ func httpClientFactory(timeout time.Duration, cfg pkgconfigmodel.Reader) func()
 
 // buildURL buils a url from a config endpoint.
 func buildURL(endpoint config.Endpoint) string {
+	if endpoint.Version == config.EPIntakeVersion2 && endpoint.TrackType != "" {
+		return buildURLWithPath(endpoint, fmt.Sprintf("/api/v2/%s", endpoint.TrackType))
+	}
+	return buildURLWithPath(endpoint, "/v1/input")
+}
+
+// buildURLWithPath builds the url of a path from a config endpoint.
+func buildURLWithPath(endpoint config.Endpoint, path string) string {
 	var scheme string
 	if endpoint.UseSSL() {
 		scheme = "https"
@@ -402,11 +477,7 @@ func buildURL(endpoint config.Endpoint) string {
 	url := url.URL{
 		Scheme: scheme,
 		Host:   address,
-	}
-	if endpoint.Version == config.EPIntakeVersion2 && endpoint.TrackType != "" {
-		url.Path = fmt.Sprintf("/api/v2/%s", endpoint.TrackType)
-	} else {
-		url.Path = "/v1/input"
+		Path:   path,
 	}
 	return url.String()
 }
@@ -371,3 +371,54 @@ func TestDestinationHA(t *testing.T) {
 		assert.Equal(t, isEndpointMRF, isDestMRF)
 	}
 }
+
+func TestIntakeDestination(t *testing.T) {
+	cfg := getNewConfig()
+	server := NewTestServer(200, cfg)
+	defer server.httpServer.Close()
+
+	intake := &Intake{
+		Path:        "/v1/logs",
+		ContentType: ProtobufContentType,
+		Encode: func(messages []*message.Message) ([]byte, string, error) {
+			if len(messages) == 0 {
+				return nil, "", errors.New("no message")
+			}
+			return []byte("encoded"), "", nil
+		},
+		IsRetryable: func(statusCode int) bool {
+			return statusCode == 503
+		},
+	}
+	destination := NewIntakeDestination(server.Endpoint, intake, server.DestCtx, 0, true, "", cfg)
+	assert.Equal(t, server.httpServer.URL+"/v1/logs", destination.Target())
+
+	input := make(chan *message.Payload)
+	output := make(chan *message.Payload)
+	stopChan := destination.Start(input, output, nil)
+
+	// the payload is encoded for the intake, without the Datadog headers
+	payload := &message.Payload{Messages: []*message.Message{message.NewMessage([]byte("hello"), nil, "", 0)}, Encoded: []byte("payload")}
+	input <- payload
+	assert.Equal(t, payload, <-output)
+	assert.Equal(t, "/v1/logs", server.request.URL.Path)
+	assert.Equal(t, ProtobufContentType, server.request.Header.Get("Content-Type"))
+	assert.Empty(t, server.request.Header.Values("DD-API-KEY"))
+	assert.Empty(t, server.request.Header.Values("dd-current-timestamp"))
+
+	// the payloads which can't be encoded are dropped
+	server.ChangeStatus(500)
+	empty := &message.Payload{Encoded: []byte("payload")}
+	input <- empty
+	assert.Equal(t, empty, <-output)
+
+	// the intake decides which status codes are retried
+	_, isRetryable := destination.unconditionalSend(&message.Payload{Encoded: []byte("payload")}).(*client.RetryableError)
+	assert.False(t, isRetryable)
+	server.ChangeStatus(503)
+	_, isRetryable = destination.unconditionalSend(&message.Payload{Encoded: []byte("payload")}).(*client.RetryableError)
+	assert.True(t, isRetryable)
+
+	close(input)
+	<-stopChan
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package otlp implements a destination sending logs to an OpenTelemetry collector with the
+// OTLP/HTTP protocol.
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	logshttp "github.com/DataDog/datadog-agent/pkg/logs/client/http"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// logsPath is the path of the OTLP/HTTP logs receiver
+const logsPath = "/v1/logs"
+
+// NewDestination returns a new destination sending the logs of the payloads to the OTLP/HTTP
+// logs receiver of an endpoint, encoded with protobuf. The messages of the payloads must be
+// encoded with the JSON encoder. The payloads are sent and retried like with the HTTP
+// destinations of the Datadog intake.
+func NewDestination(endpoint config.Endpoint,
+	destinationsContext *client.DestinationsContext,
+	maxConcurrentBackgroundSends int,
+	shouldRetry bool,
+	telemetryName string,
+	cfg pkgconfigmodel.Reader) client.Destination {
+
+	intake := &logshttp.Intake{
+		Path:        logsPath,
+		ContentType: logshttp.ProtobufContentType,
+		Encode: func(messages []*message.Message) ([]byte, string, error) {
+			return encode(endpoint, messages)
+		},
+		IsRetryable: isRetryable,
+	}
+	return logshttp.NewIntakeDestination(endpoint, intake, destinationsContext, maxConcurrentBackgroundSends, shouldRetry, telemetryName, cfg)
+}
+
+// encode returns the body of the request sending the messages, compressed with gzip when
+// the endpoint uses compression, and its content encoding.
+func encode(endpoint config.Endpoint, messages []*message.Message) ([]byte, string, error) {
+	request, err := buildRequest(messages)
+	if err != nil || !endpoint.UseCompression {
+		return request, "", err
+	}
+
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, endpoint.CompressionLevel)
+	if err != nil {
+		writer = gzip.NewWriter(&buf)
+	}
+	if _, err := writer.Write(request); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "gzip", nil
+}
+
+// isRetryable returns true for the status codes which can be retried according to the
+// OTLP/HTTP specification, the other failures are permanent.
+func isRetryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
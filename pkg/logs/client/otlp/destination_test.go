@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+type request struct {
+	path        string
+	contentType string
+	body        []byte
+}
+
+func newTestDestination(t *testing.T, statusCodes []int, compression bool) (client.Destination, chan request) {
+	requests := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			reader = gz
+		}
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		requests <- request{path: r.URL.Path, contentType: r.Header.Get("Content-Type"), body: body}
+
+		statusCode := statusCodes[0]
+		if len(statusCodes) > 1 {
+			statusCodes = statusCodes[1:]
+		}
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+
+	endpoint := config.NewEndpoint("", u.Hostname(), port, false)
+	endpoint.UseOTLP = true
+	endpoint.UseCompression = compression
+	endpoint.CompressionLevel = 6
+	endpoint.BackoffFactor = 1
+	endpoint.BackoffBase = 1
+	endpoint.BackoffMax = 10
+	endpoint.RecoveryInterval = 1
+
+	destinationsCtx := client.NewDestinationsContext()
+	destinationsCtx.Start()
+	t.Cleanup(destinationsCtx.Stop)
+
+	cfg := pkgconfigmodel.NewConfig("test", "DD", strings.NewReplacer(".", "_"))
+	return NewDestination(endpoint, destinationsCtx, 0, true, "", cfg), requests
+}
+
+func newTestPayload(t *testing.T) *message.Payload {
+	msg := newEncodedMessage(t, jsonPayload{Message: "hello", Status: message.StatusInfo, Timestamp: 1700000000000, Hostname: "host"})
+	return &message.Payload{Messages: []*message.Message{msg}}
+}
+
+func TestDestinationTarget(t *testing.T) {
+	cfg := pkgconfigmodel.NewConfig("test", "DD", strings.NewReplacer(".", "_"))
+	destination := NewDestination(config.NewEndpoint("", "collector", 4318, false), client.NewDestinationsContext(), 0, true, "", cfg)
+	assert.Equal(t, "http://collector:4318/v1/logs", destination.Target())
+	destination = NewDestination(config.NewEndpoint("", "collector", 0, true), client.NewDestinationsContext(), 0, true, "", cfg)
+	assert.Equal(t, "https://collector/v1/logs", destination.Target())
+}
+
+func TestDestinationSend(t *testing.T) {
+	for _, compression := range []bool{false, true} {
+		t.Run("compression="+strconv.FormatBool(compression), func(t *testing.T) {
+			destination, requests := newTestDestination(t, []int{http.StatusOK}, compression)
+			input := make(chan *message.Payload)
+			output := make(chan *message.Payload)
+			stopChan := destination.Start(input, output, nil)
+
+			payload := newTestPayload(t)
+			input <- payload
+			assert.Equal(t, payload, <-output)
+
+			req := <-requests
+			assert.Equal(t, logsPath, req.path)
+			assert.Equal(t, "application/x-protobuf", req.contentType)
+			expected, err := buildRequest(payload.Messages)
+			require.NoError(t, err)
+			assert.Equal(t, expected, req.body)
+
+			close(input)
+			<-stopChan
+		})
+	}
+}
+
+func TestDestinationRetriesOnUnavailable(t *testing.T) {
+	destination, requests := newTestDestination(t, []int{http.StatusServiceUnavailable, http.StatusOK}, false)
+	input := make(chan *message.Payload)
+	output := make(chan *message.Payload)
+	isRetrying := make(chan bool, 2)
+	destination.Start(input, output, isRetrying)
+
+	payload := newTestPayload(t)
+	input <- payload
+	assert.Equal(t, payload, <-output)
+	assert.True(t, <-isRetrying)
+	assert.False(t, <-isRetrying)
+	assert.Len(t, requests, 2)
+	close(input)
+}
+
+func TestDestinationDropsOnClientError(t *testing.T) {
+	destination, requests := newTestDestination(t, []int{http.StatusBadRequest}, false)
+	input := make(chan *message.Payload)
+	output := make(chan *message.Payload)
+	destination.Start(input, output, nil)
+
+	payload := newTestPayload(t)
+	input <- payload
+	assert.Equal(t, payload, <-output)
+	assert.Len(t, requests, 1)
+	close(input)
+}
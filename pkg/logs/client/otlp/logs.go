@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// scopeName is the name of the instrumentation scope of the log records.
+const scopeName = "datadog-agent"
+
+// severityNumbers maps the statuses of the messages to the OTLP severity numbers.
+var severityNumbers = map[string]plog.SeverityNumber{
+	message.StatusEmergency: plog.SeverityNumberFatal4,
+	message.StatusAlert:     plog.SeverityNumberFatal3,
+	message.StatusCritical:  plog.SeverityNumberFatal,
+	message.StatusError:     plog.SeverityNumberError,
+	message.StatusWarning:   plog.SeverityNumberWarn,
+	message.StatusNotice:    plog.SeverityNumberInfo2,
+	message.StatusInfo:      plog.SeverityNumberInfo,
+	message.StatusDebug:     plog.SeverityNumberDebug,
+}
+
+// jsonPayload is the JSON encoding of the messages by the logs processor of the HTTP pipelines.
+type jsonPayload struct {
+	Message   string `json:"message"`
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+	Hostname  string `json:"hostname"`
+	Service   string `json:"service"`
+	Source    string `json:"ddsource"`
+	Tags      string `json:"ddtags"`
+}
+
+// resource identifies the resource logs the log records are grouped in.
+type resource struct {
+	hostname string
+	service  string
+}
+
+// buildRequest returns the OTLP ExportLogsServiceRequest of the messages, encoded with protobuf.
+// The hostname and the service of the messages are set as the `host.name` and `service.name`
+// resource attributes, their source and tags as attributes of the log records.
+func buildRequest(messages []*message.Message) ([]byte, error) {
+	logs := plog.NewLogs()
+	scopeLogs := make(map[resource]plog.ScopeLogs)
+
+	for _, msg := range messages {
+		var payload jsonPayload
+		if err := json.Unmarshal(msg.GetContent(), &payload); err != nil {
+			return nil, fmt.Errorf("can't decode the message: %v", err)
+		}
+		r := resource{hostname: payload.Hostname, service: payload.Service}
+		sl, exists := scopeLogs[r]
+		if !exists {
+			rl := logs.ResourceLogs().AppendEmpty()
+			if r.hostname != "" {
+				rl.Resource().Attributes().PutStr("host.name", r.hostname)
+			}
+			if r.service != "" {
+				rl.Resource().Attributes().PutStr("service.name", r.service)
+			}
+			sl = rl.ScopeLogs().AppendEmpty()
+			sl.Scope().SetName(scopeName)
+			sl.Scope().SetVersion(version.AgentVersion)
+			scopeLogs[r] = sl
+		}
+		fillLogRecord(sl.LogRecords().AppendEmpty(), msg, payload)
+	}
+	return plogotlp.NewExportRequestFromLogs(logs).MarshalProto()
+}
+
+// fillLogRecord sets the fields of the log record of a message.
+func fillLogRecord(record plog.LogRecord, msg *message.Message, payload jsonPayload) {
+	record.SetTimestamp(pcommon.Timestamp(payload.Timestamp * 1e6))
+	if severity, ok := severityNumbers[payload.Status]; ok {
+		record.SetSeverityNumber(severity)
+	}
+	record.SetSeverityText(payload.Status)
+	record.Body().SetStr(payload.Message)
+	if payload.Source != "" {
+		record.Attributes().PutStr("ddsource", payload.Source)
+	}
+	putTags(record.Attributes(), payload.Tags)
+	if msg.IngestionTimestamp > 0 {
+		record.SetObservedTimestamp(pcommon.Timestamp(msg.IngestionTimestamp))
+	}
+}
+
+// putTags sets the attributes of the tags of a message, e.g. `env:prod` is the `env`
+// attribute with the value `prod`. The tags with the same key are an array attribute, the
+// tags without value an attribute with an empty value.
+func putTags(attributes pcommon.Map, tags string) {
+	if tags == "" {
+		return
+	}
+	var keys []string
+	values := make(map[string][]string)
+	for _, tag := range strings.Split(tags, ",") {
+		key, value, _ := strings.Cut(tag, ":")
+		if key == "" {
+			continue
+		}
+		if _, exists := values[key]; !exists {
+			keys = append(keys, key)
+		}
+		values[key] = append(values[key], value)
+	}
+
+	for _, key := range keys {
+		if len(values[key]) == 1 {
+			attributes.PutStr(key, values[key][0])
+			continue
+		}
+		array := attributes.PutEmptySlice(key)
+		array.EnsureCapacity(len(values[key]))
+		for _, v := range values[key] {
+			array.AppendEmpty().SetStr(v)
+		}
+	}
+}
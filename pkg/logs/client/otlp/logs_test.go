@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newEncodedMessage(t *testing.T, payload jsonPayload) *message.Message {
+	content, err := json.Marshal(payload)
+	require.NoError(t, err)
+	msg := message.NewMessage(content, nil, payload.Status, 1700000000123456789)
+	return msg
+}
+
+func TestBuildRequest(t *testing.T) {
+	messages := []*message.Message{
+		newEncodedMessage(t, jsonPayload{
+			Message:   "first",
+			Status:    message.StatusError,
+			Timestamp: 1700000000123,
+			Hostname:  "host-a",
+			Service:   "web",
+			Source:    "nginx",
+			Tags:      "env:prod,team:a,team:b,standalone",
+		}),
+		newEncodedMessage(t, jsonPayload{
+			Message:   "second",
+			Status:    message.StatusInfo,
+			Timestamp: 1700000000456,
+			Hostname:  "host-b",
+		}),
+		newEncodedMessage(t, jsonPayload{
+			Message:   "third",
+			Status:    message.StatusWarning,
+			Timestamp: 1700000000789,
+			Hostname:  "host-a",
+			Service:   "web",
+		}),
+	}
+
+	request, err := buildRequest(messages)
+	require.NoError(t, err)
+
+	decoded := plogotlp.NewExportRequest()
+	require.NoError(t, decoded.UnmarshalProto(request))
+	resourceLogs := decoded.Logs().ResourceLogs()
+	require.Equal(t, 2, resourceLogs.Len())
+
+	// the messages of the same host and service are grouped in the same resource logs
+	first := resourceLogs.At(0)
+	assert.Equal(t, map[string]interface{}{"host.name": "host-a", "service.name": "web"}, first.Resource().Attributes().AsRaw())
+
+	require.Equal(t, 1, first.ScopeLogs().Len())
+	scopeLogs := first.ScopeLogs().At(0)
+	assert.Equal(t, scopeName, scopeLogs.Scope().Name())
+
+	records := scopeLogs.LogRecords()
+	require.Equal(t, 2, records.Len())
+	record := records.At(0)
+	assert.Equal(t, pcommon.Timestamp(1700000000123000000), record.Timestamp())
+	assert.Equal(t, pcommon.Timestamp(1700000000123456789), record.ObservedTimestamp())
+	assert.Equal(t, plog.SeverityNumberError, record.SeverityNumber())
+	assert.Equal(t, "error", record.SeverityText())
+	assert.Equal(t, "first", record.Body().Str())
+	assert.Equal(t, map[string]interface{}{
+		"ddsource":   "nginx",
+		"env":        "prod",
+		"team":       []interface{}{"a", "b"},
+		"standalone": "",
+	}, record.Attributes().AsRaw())
+
+	record = records.At(1)
+	assert.Equal(t, plog.SeverityNumberWarn, record.SeverityNumber())
+	assert.Equal(t, "third", record.Body().Str())
+	assert.Equal(t, 0, record.Attributes().Len())
+
+	second := resourceLogs.At(1)
+	assert.Equal(t, map[string]interface{}{"host.name": "host-b"}, second.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, second.ScopeLogs().Len())
+	assert.Equal(t, 1, second.ScopeLogs().At(0).LogRecords().Len())
+}
+
+func TestBuildRequestInvalidContent(t *testing.T) {
+	msg := message.NewMessage([]byte("not json"), nil, message.StatusInfo, 0)
+	_, err := buildRequest([]*message.Message{msg})
+	assert.Error(t, err)
+}
@@ -42,6 +42,11 @@ const (
 	// headers are included in the log frame.  The size in those headers is not
 	// consulted.  The result does not include the trailing newlines.
 	DockerStream
+
+	// Syslog messages framed either with octet counting (`MSG-LEN SP SYSLOG-MSG`)
+	// or terminated by a newline, as described in RFC 6587.  The framing is
+	// detected for each message.
+	Syslog
 )
 
 // Framer gets chunks of bytes (via Process(..)) and uses an
@@ -101,6 +106,8 @@ func NewFramer(
 		matcher = &dockerStreamMatcher{contentLenLimit}
 	case NoFraming:
 		matcher = &noFramingMatcher{}
+	case Syslog:
+		matcher = &syslogMatcher{contentLenLimit: contentLenLimit, newline: oneByteNewLineMatcher{contentLenLimit}}
 	default:
 		panic(fmt.Sprintf("unknown framing %d", framing))
 	}
@@ -158,6 +165,12 @@ func (fr *Framer) Process(input *message.Message) {
 		buf := fr.buffer.Bytes()[framed:]
 
 		content, rawDataLen := fr.matcher.FindFrame(buf, seen-framed)
+		if content == nil && rawDataLen > 0 {
+			// the matcher discarded these bytes
+			framed += rawDataLen
+			seen = framed
+			continue
+		}
 		if content == nil {
 			// if the matcher was asked to match more than contentLenLimit,
 			// chop off contentLenLimit raw bytes and output them
@@ -182,6 +195,7 @@ func (fr *Framer) Process(input *message.Message) {
 			Origin:             input.Origin,
 			Status:             input.Status,
 			IngestionTimestamp: input.IngestionTimestamp,
+			ParsedTimestamp:    input.ParsedTimestamp,
 			ParsingExtra:       input.ParsingExtra,
 			ServerlessExtra:    input.ServerlessExtra,
 		}
@@ -115,6 +115,16 @@ func TestLineBreaking(t *testing.T) {
 		t.Run("one-byte chunks", test(framing, chunk(utf16, 1), lines, lens))
 	})
 
+	t.Run("Syslog", func(t *testing.T) {
+		syslog := []byte("9 <13>first\n<14>second\n11 <15>th\nird \n")
+		lines := []string{"<13>first", "", "<14>second", "<15>th\nird ", ""}
+		lens := []int{11, 1, 11, 14, 1}
+		framing := Syslog
+		t.Run("one chunk", test(framing, chunk(syslog, len(syslog)), lines, lens))
+		t.Run("one-byte chunks", test(framing, chunk(syslog, 1), lines, lens))
+		t.Run("two-byte chunks", test(framing, chunk(syslog, 2), lines, lens))
+	})
+
 	dockerChunk := func(stream byte, data []byte) []byte {
 		header := [8]byte{stream}
 		binary.BigEndian.PutUint32(header[4:8], uint32(len(data)))
@@ -264,6 +274,30 @@ func TestContentLenLimit(t *testing.T) {
 	})
 }
 
+func TestSyslogContentLenLimit(t *testing.T) {
+	// the octet counted frames longer than the limit are truncated, and the rest of
+	// their declared length is not parsed as new frames
+	input := []byte("20 <13>abc 7 <14>abcdef8 <15>abcd<16>abcd\n")
+	lines := []string{"<13>abc 7 <", "<15>abcd", "<16>abcd"}
+	lens := []int{14, 10, 9}
+	for _, size := range []int{len(input), 5, 2, 1} {
+		t.Run(fmt.Sprintf("%d-byte chunks", size), func(t *testing.T) {
+			gotContent := []string{}
+			gotLens := []int{}
+			outputFn := func(msg *message.Message, rawDataLen int) {
+				gotContent = append(gotContent, string(msg.GetContent()))
+				gotLens = append(gotLens, rawDataLen)
+			}
+			fr := NewFramer(outputFn, Syslog, 14)
+			for i := 0; i < len(input); i += size {
+				fr.Process(message.NewMessage(input[i:min(i+size, len(input))], nil, "", 0))
+			}
+			require.Equal(t, lines, gotContent)
+			require.Equal(t, lens, gotLens)
+		})
+	}
+}
+
 func TestLineBreakIncomingData(t *testing.T) {
 	outputFn, outputChan := framerOutput()
 	framer := NewFramer(outputFn, UTF8Newline, contentLenLimit)
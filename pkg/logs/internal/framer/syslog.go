@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package framer
+
+// maxOctetCountDigits is the maximum number of digits of the length of an octet counted frame.
+const maxOctetCountDigits = 9
+
+// syslogMatcher matches the syslog framings described in RFC 6587: octet counting,
+// where each message is prefixed by its length and a space, and non-transparent
+// framing, where each message is terminated by a newline.
+//
+// An octet counted frame longer than contentLenLimit, header included, is
+// truncated to contentLenLimit and the rest of its declared length is discarded.
+type syslogMatcher struct {
+	contentLenLimit int
+	newline         oneByteNewLineMatcher
+
+	// discard is the number of bytes of a truncated frame left to discard
+	discard int
+}
+
+// FindFrame implements EndLineMatcher#FindFrame.
+func (s *syslogMatcher) FindFrame(buf []byte, seen int) ([]byte, int) {
+	if s.discard > 0 {
+		n := min(s.discard, len(buf))
+		s.discard -= n
+		return nil, n
+	}
+	length, headerLen, complete := octetCount(buf)
+	if !complete {
+		// wait for more data to know the framing
+		return nil, 0
+	}
+	if headerLen == 0 {
+		return s.newline.FindFrame(buf, seen)
+	}
+	if headerLen+length > s.contentLenLimit {
+		if len(buf) < s.contentLenLimit {
+			return nil, 0
+		}
+		s.discard = headerLen + length - s.contentLenLimit
+		return buf[min(headerLen, s.contentLenLimit):s.contentLenLimit], s.contentLenLimit
+	}
+	if len(buf) < headerLen+length {
+		return nil, 0
+	}
+	return buf[headerLen : headerLen+length], headerLen + length
+}
+
+// octetCount returns the length of the octet counted frame at the start of buf
+// and the length of its header, or a header length of 0 if the frame is not
+// octet counted. complete is false when buf is too short to know.
+func octetCount(buf []byte) (length int, headerLen int, complete bool) {
+	for i, b := range buf {
+		switch {
+		case b >= '0' && b <= '9' && i < maxOctetCountDigits:
+			length = length*10 + int(b-'0')
+		case b == ' ' && i > 0 && buf[0] != '0':
+			return length, i + 1, true
+		default:
+			return 0, 0, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,332 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package syslog implements a parser for the syslog messages formatted
+// according to RFC 5424 or to the legacy BSD format described in RFC 3164.
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// nilValue is used by RFC 5424 for the header fields which are not set.
+const nilValue = "-"
+
+// rfc3164TimestampLayout is the timestamp layout of RFC 3164, which doesn't contain the year.
+const rfc3164TimestampLayout = time.Stamp
+
+// utf8BOM may prefix the MSG part of RFC 5424 messages.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// severityStatuses maps the syslog severities to the statuses supported by the intake.
+var severityStatuses = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+var (
+	errNoPriority       = errors.New("the syslog message doesn't start with a priority")
+	errInvalidPriority  = errors.New("invalid syslog priority")
+	errInvalidHeader    = errors.New("invalid syslog header")
+	errInvalidStructure = errors.New("invalid syslog structured data")
+)
+
+// New creates a new parser that parses syslog messages into structured messages.
+//
+// The parsed message has the following attributes:
+//
+//	{"message": "<MSG>", "syslog": {"facility": 4, "severity": 2, "hostname": "...", "appname": "...",
+//	 "procid": "...", "msgid": "...", "timestamp": "...", "version": 1, "structured_data": {"<SD-ID>": {"<PARAM>": "<VALUE>"}}}}
+//
+// The status, the hostname and the timestamp of the message are set from the syslog header.
+// The messages which can't be parsed are left untouched.
+func New() parsers.Parser {
+	return &syslogFormat{now: time.Now}
+}
+
+type syslogFormat struct {
+	// now is used to infer the year of the RFC 3164 timestamps
+	now func() time.Time
+}
+
+// Parse implements Parser#Parse
+func (p *syslogFormat) Parse(msg *message.Message) (*message.Message, error) {
+	parsed, err := p.parse(msg.GetContent())
+	if err != nil {
+		return msg, err
+	}
+
+	attributes := map[string]interface{}{
+		"facility": parsed.priority / 8,
+		"severity": parsed.priority % 8,
+	}
+	if parsed.version > 0 {
+		attributes["version"] = parsed.version
+	}
+	setIfNotEmpty(attributes, "hostname", parsed.hostname)
+	setIfNotEmpty(attributes, "appname", parsed.appName)
+	setIfNotEmpty(attributes, "procid", parsed.procID)
+	setIfNotEmpty(attributes, "msgid", parsed.msgID)
+	setIfNotEmpty(attributes, "timestamp", parsed.rawTimestamp)
+	if len(parsed.structuredData) > 0 {
+		attributes["structured_data"] = parsed.structuredData
+	}
+
+	msg.Status = severityStatuses[parsed.priority%8]
+	if parsed.hostname != "" {
+		msg.Hostname = parsed.hostname
+	}
+	if !parsed.timestamp.IsZero() {
+		msg.ParsedTimestamp = parsed.timestamp
+	}
+	msg.SetStructured(&message.BasicStructuredContent{
+		Data: map[string]interface{}{
+			"message": string(parsed.message),
+			"syslog":  attributes,
+		},
+	})
+	return msg, nil
+}
+
+// SupportsPartialLine implements Parser#SupportsPartialLine
+func (p *syslogFormat) SupportsPartialLine() bool {
+	return false
+}
+
+// syslogMessage holds the parts of a syslog message.
+type syslogMessage struct {
+	priority       int
+	version        int
+	timestamp      time.Time
+	rawTimestamp   string
+	hostname       string
+	appName        string
+	procID         string
+	msgID          string
+	structuredData map[string]map[string]string
+	message        []byte
+}
+
+func (p *syslogFormat) parse(content []byte) (*syslogMessage, error) {
+	priority, rest, err := parsePriority(content)
+	if err != nil {
+		return nil, err
+	}
+	// RFC 5424 messages have a version right after the priority
+	if len(rest) > 1 && rest[0] >= '1' && rest[0] <= '9' && rest[1] == ' ' {
+		return parseRFC5424(priority, rest)
+	}
+	return p.parseRFC3164(priority, rest), nil
+}
+
+// parsePriority parses the `<PRI>` part of the message.
+func parsePriority(content []byte) (int, []byte, error) {
+	if len(content) == 0 || content[0] != '<' {
+		return 0, nil, errNoPriority
+	}
+	end := bytes.IndexByte(content, '>')
+	if end < 2 || end > 4 {
+		return 0, nil, errInvalidPriority
+	}
+	priority, err := strconv.Atoi(string(content[1:end]))
+	if err != nil || priority < 0 || priority > 191 {
+		return 0, nil, errInvalidPriority
+	}
+	return priority, content[end+1:], nil
+}
+
+// parseRFC5424 parses `VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA [SP MSG]`.
+func parseRFC5424(priority int, content []byte) (*syslogMessage, error) {
+	parsed := &syslogMessage{priority: priority}
+
+	var fields [6]string
+	for i := range fields {
+		var field []byte
+		field, content = nextField(content)
+		if len(field) == 0 {
+			return nil, errInvalidHeader
+		}
+		fields[i] = string(field)
+	}
+
+	version, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, errInvalidHeader
+	}
+	parsed.version = version
+	if fields[1] != nilValue {
+		parsed.rawTimestamp = fields[1]
+		if ts, err := time.Parse(time.RFC3339Nano, fields[1]); err == nil {
+			parsed.timestamp = ts.UTC()
+		}
+	}
+	parsed.hostname = nilToEmpty(fields[2])
+	parsed.appName = nilToEmpty(fields[3])
+	parsed.procID = nilToEmpty(fields[4])
+	parsed.msgID = nilToEmpty(fields[5])
+
+	if len(content) > 0 && content[0] == '-' {
+		content = content[1:]
+	} else {
+		parsed.structuredData, content, err = parseStructuredData(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(content) > 0 && content[0] == ' ' {
+		content = content[1:]
+	}
+	parsed.message = bytes.TrimPrefix(content, utf8BOM)
+	return parsed, nil
+}
+
+// parseStructuredData parses the `[SD-ID SP PARAM-NAME="PARAM-VALUE" ...]...` elements of a RFC 5424 message.
+func parseStructuredData(content []byte) (map[string]map[string]string, []byte, error) {
+	elements := make(map[string]map[string]string)
+	for len(content) > 0 && content[0] == '[' {
+		content = content[1:]
+		idEnd := bytes.IndexAny(content, " ]")
+		if idEnd <= 0 {
+			return nil, nil, errInvalidStructure
+		}
+		id := string(content[:idEnd])
+		content = content[idEnd:]
+		params := make(map[string]string)
+		for {
+			if len(content) == 0 {
+				return nil, nil, errInvalidStructure
+			}
+			if content[0] == ']' {
+				content = content[1:]
+				break
+			}
+			content = bytes.TrimLeft(content, " ")
+			nameEnd := bytes.IndexByte(content, '=')
+			if nameEnd <= 0 || nameEnd+1 >= len(content) || content[nameEnd+1] != '"' {
+				return nil, nil, errInvalidStructure
+			}
+			name := string(content[:nameEnd])
+			value, rest, err := parseParamValue(content[nameEnd+2:])
+			if err != nil {
+				return nil, nil, err
+			}
+			params[name] = value
+			content = rest
+		}
+		elements[id] = params
+	}
+	return elements, content, nil
+}
+
+// parseParamValue parses a quoted parameter value, in which '"', '\' and ']' are escaped.
+func parseParamValue(content []byte) (string, []byte, error) {
+	var value []byte
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			if i+1 < len(content) && (content[i+1] == '"' || content[i+1] == '\\' || content[i+1] == ']') {
+				i++
+			}
+			value = append(value, content[i])
+		case '"':
+			return string(value), content[i+1:], nil
+		default:
+			value = append(value, content[i])
+		}
+	}
+	return "", nil, errInvalidStructure
+}
+
+// parseRFC3164 parses `TIMESTAMP SP HOSTNAME SP TAG[PID]: MSG`. As RFC 3164 only
+// describes the observed formats, the parsing is lenient: the parts which can't
+// be parsed are kept in the message.
+func (p *syslogFormat) parseRFC3164(priority int, content []byte) *syslogMessage {
+	parsed := &syslogMessage{priority: priority}
+
+	if len(content) >= len(rfc3164TimestampLayout) {
+		raw := string(content[:len(rfc3164TimestampLayout)])
+		if ts, err := time.ParseInLocation(rfc3164TimestampLayout, raw, time.Local); err == nil {
+			parsed.rawTimestamp = raw
+			parsed.timestamp = p.withYear(ts)
+			content = bytes.TrimLeft(content[len(rfc3164TimestampLayout):], " ")
+		}
+	}
+	if parsed.rawTimestamp == "" {
+		// some devices send RFC 3339 timestamps
+		field, rest := nextField(content)
+		if ts, err := time.Parse(time.RFC3339Nano, string(field)); err == nil {
+			parsed.rawTimestamp = string(field)
+			parsed.timestamp = ts.UTC()
+			content = rest
+		}
+	}
+
+	// the hostname follows the timestamp, it is optional and followed by the tag
+	if field, rest := nextField(content); parsed.rawTimestamp != "" && len(field) > 0 && len(rest) > 0 && !isTag(field) {
+		parsed.hostname = string(field)
+		content = rest
+	}
+	if field, rest := nextField(content); isTag(field) {
+		tag := bytes.TrimSuffix(field, []byte{':'})
+		if start := bytes.IndexByte(tag, '['); start > 0 && tag[len(tag)-1] == ']' {
+			parsed.procID = string(tag[start+1 : len(tag)-1])
+			tag = tag[:start]
+		}
+		parsed.appName = string(tag)
+		content = rest
+	}
+	parsed.message = content
+	return parsed
+}
+
+// withYear sets the year of a RFC 3164 timestamp, the timestamps more than
+// a day in the future are considered to be from the previous year.
+func (p *syslogFormat) withYear(ts time.Time) time.Time {
+	now := p.now()
+	ts = ts.AddDate(now.Year()-ts.Year(), 0, 0)
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	return ts.UTC()
+}
+
+// isTag returns true if the field looks like a RFC 3164 tag, e.g. `sshd[42]:` or `kernel:`.
+func isTag(field []byte) bool {
+	return len(field) > 1 && field[len(field)-1] == ':'
+}
+
+// nextField returns the content up to the next space and the content after it.
+func nextField(content []byte) ([]byte, []byte) {
+	end := bytes.IndexByte(content, ' ')
+	if end < 0 {
+		return content, nil
+	}
+	return content[:end], content[end+1:]
+}
+
+func nilToEmpty(field string) string {
+	if field == nilValue {
+		return ""
+	}
+	return field
+}
+
+func setIfNotEmpty(attributes map[string]interface{}, key, value string) {
+	if value != "" {
+		attributes[key] = value
+	}
+}
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func parse(t *testing.T, content string) (*message.Message, map[string]interface{}) {
+	parser := &syslogFormat{now: func() time.Time { return time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC) }}
+	msg, err := parser.Parse(message.NewMessage([]byte(content), nil, message.StatusInfo, 0))
+	require.NoError(t, err)
+	require.Equal(t, message.StateStructured, msg.State)
+	rendered, err := msg.Render()
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(rendered, &fields))
+	return msg, fields
+}
+
+func TestParseRFC5424(t *testing.T) {
+	msg, fields := parse(t, `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Appl\"ication\]"][origin ip="192.0.2.1"] `+"\xEF\xBB\xBF"+`An application event`)
+
+	assert.Equal(t, "An application event", string(msg.GetContent()))
+	assert.Equal(t, message.StatusNotice, msg.GetStatus())
+	assert.Equal(t, "mymachine.example.com", msg.Hostname)
+	assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), msg.ParsedTimestamp)
+	assert.Equal(t, map[string]interface{}{
+		"message": "An application event",
+		"syslog": map[string]interface{}{
+			"facility":  float64(20),
+			"severity":  float64(5),
+			"version":   float64(1),
+			"hostname":  "mymachine.example.com",
+			"appname":   "evntslog",
+			"msgid":     "ID47",
+			"timestamp": "2003-10-11T22:14:15.003Z",
+			"structured_data": map[string]interface{}{
+				"exampleSDID@32473": map[string]interface{}{"iut": "3", "eventSource": `Appl"ication]`},
+				"origin":            map[string]interface{}{"ip": "192.0.2.1"},
+			},
+		},
+	}, fields)
+}
+
+func TestParseRFC5424NilValues(t *testing.T) {
+	msg, fields := parse(t, `<34>1 - - - - - -`)
+	assert.Equal(t, "", string(msg.GetContent()))
+	assert.Equal(t, message.StatusCritical, msg.GetStatus())
+	assert.Equal(t, "", msg.Hostname)
+	assert.True(t, msg.ParsedTimestamp.IsZero())
+	assert.Equal(t, map[string]interface{}{
+		"message": "",
+		"syslog":  map[string]interface{}{"facility": float64(4), "severity": float64(2), "version": float64(1)},
+	}, fields)
+}
+
+func TestParseRFC3164(t *testing.T) {
+	msg, fields := parse(t, `<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8`)
+	assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", string(msg.GetContent()))
+	assert.Equal(t, message.StatusCritical, msg.GetStatus())
+	assert.Equal(t, "mymachine", msg.Hostname)
+	// the timestamp is in the future, it is considered to be from the previous year
+	assert.Equal(t, time.Date(2023, 10, 11, 22, 14, 15, 0, time.Local).UTC(), msg.ParsedTimestamp)
+	assert.Equal(t, map[string]interface{}{
+		"message": "'su root' failed for lonvick on /dev/pts/8",
+		"syslog": map[string]interface{}{
+			"facility":  float64(4),
+			"severity":  float64(2),
+			"hostname":  "mymachine",
+			"appname":   "su",
+			"procid":    "230",
+			"timestamp": "Oct 11 22:14:15",
+		},
+	}, fields)
+
+	// without hostname
+	msg, _ = parse(t, `<13>Feb  5 17:32:18 kernel: device eth0 entered promiscuous mode`)
+	assert.Equal(t, "device eth0 entered promiscuous mode", string(msg.GetContent()))
+	assert.Equal(t, "", msg.Hostname)
+	assert.Equal(t, time.Date(2024, 2, 5, 17, 32, 18, 0, time.Local).UTC(), msg.ParsedTimestamp)
+
+	// RFC 3339 timestamp and no tag
+	msg, fields = parse(t, `<14>2024-02-05T17:32:18+01:00 10.0.0.99 Use the BFG!`)
+	assert.Equal(t, "Use the BFG!", string(msg.GetContent()))
+	assert.Equal(t, "10.0.0.99", msg.Hostname)
+	assert.Equal(t, time.Date(2024, 2, 5, 16, 32, 18, 0, time.UTC), msg.ParsedTimestamp)
+	assert.Equal(t, "2024-02-05T17:32:18+01:00", fields["syslog"].(map[string]interface{})["timestamp"])
+
+	// only a priority
+	msg, _ = parse(t, `<6>just a message`)
+	assert.Equal(t, "just a message", string(msg.GetContent()))
+	assert.Equal(t, "", msg.Hostname)
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+}
+
+func TestParseInvalidMessages(t *testing.T) {
+	parser := New()
+	for _, content := range []string{
+		"no priority",
+		"<abc>1 - - - - - -",
+		"<192>invalid priority",
+		"<1",
+		`<34>1 - - - - - [unterminated`,
+		`<34>1 - - - - - [id key="unterminated]`,
+		`<34>1 - - - -`,
+	} {
+		msg, err := parser.Parse(message.NewMessage([]byte(content), nil, message.StatusInfo, 0))
+		assert.Error(t, err, content)
+		assert.Equal(t, message.StateUnstructured, msg.State)
+		assert.Equal(t, content, string(msg.GetContent()))
+	}
+}
@@ -10,6 +10,7 @@ package tailerfactory
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -42,6 +43,34 @@ func (r *fakeRegistry) GetTailingMode(identifier string) string {
 	panic("unused")
 }
 
+// GetLastUpdated implements auditor.Registry#GetLastUpdated.
+//
+//nolint:revive // TODO(AML) Fix revive linter
+func (r *fakeRegistry) GetLastUpdated(identifier string) time.Time {
+	panic("unused")
+}
+
+// GetFingerprint implements auditor.Registry#GetFingerprint.
+//
+//nolint:revive // TODO(AML) Fix revive linter
+func (r *fakeRegistry) GetFingerprint(identifier string) string {
+	panic("unused")
+}
+
+// GetIdentifierForFingerprint implements auditor.Registry#GetIdentifierForFingerprint.
+//
+//nolint:revive // TODO(AML) Fix revive linter
+func (r *fakeRegistry) GetIdentifierForFingerprint(fingerprint string) string {
+	panic("unused")
+}
+
+// IsCompleted implements auditor.Registry#IsCompleted.
+//
+//nolint:revive // TODO(AML) Fix revive linter
+func (r *fakeRegistry) IsCompleted(identifier string) bool {
+	panic("unused")
+}
+
 func TestUseFile(t *testing.T) {
 	ctrs := containersorpods.LogContainers
 	pods := containersorpods.LogPods
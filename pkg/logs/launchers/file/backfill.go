@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	status "github.com/DataDog/datadog-agent/pkg/logs/status/utils"
+	tailer "github.com/DataDog/datadog-agent/pkg/logs/tailers/file"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// rotatedFile is a compressed rotated file to backfill.
+type rotatedFile struct {
+	file    *tailer.File
+	modTime time.Time
+	// offset is the offset in the decompressed content from which the file is read
+	offset int64
+}
+
+// collectRotatedFiles queues the compressed rotated files of the files of the source
+// which must be backfilled. The rotated files of a file are the compressed files
+// of the same directory whose name starts with its name, e.g. `app.log.1.gz` or
+// `app.log-20240101.zst` for `app.log`.
+func (s *Launcher) collectRotatedFiles(source *sources.LogSource) {
+	files, err := s.fileProvider.CollectFiles(source)
+	if err != nil {
+		// the error is reported when launching the tailers of the source
+		return
+	}
+
+	var rotatedFiles []rotatedFile
+	for _, file := range files {
+		if tailer.IsCompressed(file.Path) {
+			continue
+		}
+		dir, name := filepath.Split(file.Path)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			log.Warnf("Could not list the rotated files of %s: %v", file.Path, err)
+			continue
+		}
+		lastUpdated := s.registry.GetLastUpdated(file.Identifier())
+		var fileRotatedFiles []rotatedFile
+		for _, entry := range entries {
+			if entry.IsDir() || entry.Name() == name || !strings.HasPrefix(entry.Name(), name) || !tailer.IsCompressed(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			rotated := tailer.NewFile(filepath.Join(dir, entry.Name()), source, file.IsWildcardPath)
+			if !s.shouldBackfill(rotated, info.ModTime(), lastUpdated) {
+				continue
+			}
+			offset, _, err := Position(s.registry, rotated.Identifier(), config.Beginning)
+			if err != nil {
+				log.Warnf("Could not recover offset for file with path %v: %v", rotated.Path, err)
+			}
+			fileRotatedFiles = append(fileRotatedFiles, rotatedFile{file: rotated, modTime: info.ModTime(), offset: offset})
+		}
+		s.resumeTailedFile(file, fileRotatedFiles)
+		rotatedFiles = append(rotatedFiles, fileRotatedFiles...)
+	}
+
+	// the oldest files are backfilled first to send the logs in order
+	sort.SliceStable(rotatedFiles, func(i, j int) bool {
+		return rotatedFiles[i].modTime.Before(rotatedFiles[j].modTime)
+	})
+	for _, rotated := range rotatedFiles {
+		if !s.isBackfilled(rotated.file) {
+			s.backfillQueue = append(s.backfillQueue, rotated)
+		}
+	}
+}
+
+// resumeTailedFile sets the offset of the rotated file which was tailed when the
+// agent stopped, to skip the logs which were sent from it. This file is the
+// oldest of the files rotated from file while the agent was stopped, which is
+// the newest rotated file when the file was rotated once. It is read from the
+// last offset committed for file, unless it has already been partially
+// backfilled.
+func (s *Launcher) resumeTailedFile(file *tailer.File, rotatedFiles []rotatedFile) {
+	tailed := -1
+	for i, rotated := range rotatedFiles {
+		if tailed == -1 || rotated.modTime.Before(rotatedFiles[tailed].modTime) {
+			tailed = i
+		}
+	}
+	if tailed == -1 || s.registry.GetOffset(rotatedFiles[tailed].file.Identifier()) != "" {
+		return
+	}
+	offset, _, err := Position(s.registry, file.Identifier(), config.Beginning)
+	if err != nil {
+		log.Warnf("Could not recover offset for file with path %v: %v", file.Path, err)
+		return
+	}
+	rotatedFiles[tailed].offset = offset
+}
+
+// shouldBackfill returns true if the rotated file must be read. lastUpdated is the
+// last time an offset was committed for the file it was rotated from.
+func (s *Launcher) shouldBackfill(rotated *tailer.File, modTime time.Time, lastUpdated time.Time) bool {
+	if s.registry.IsCompleted(rotated.Identifier()) {
+		// the whole file has already been backfilled
+		return false
+	}
+	if s.registry.GetOffset(rotated.Identifier()) != "" {
+		// the file has already been backfilled, possibly partially, it is read from its offset
+		return true
+	}
+	// The content of the file has been sent while it was tailed if it was modified
+	// before the last committed offset or after the agent started, the other files
+	// have been rotated while the agent was stopped. Nothing is backfilled for the
+	// files which have never been tailed.
+	return !lastUpdated.IsZero() && modTime.After(lastUpdated) && modTime.Before(s.startTime)
+}
+
+// isBackfilled returns true if the file is being backfilled or waiting to be.
+func (s *Launcher) isBackfilled(file *tailer.File) bool {
+	if s.backfillTailer != nil && s.backfillTailer.GetId() == file.GetScanKey() {
+		return true
+	}
+	for _, queued := range s.backfillQueue {
+		if queued.file.GetScanKey() == file.GetScanKey() {
+			return true
+		}
+	}
+	return false
+}
+
+// backfillNextFile starts reading the next rotated file of the queue once the
+// previous one has been read until its end.
+func (s *Launcher) backfillNextFile() {
+	if s.backfillTailer != nil {
+		if !s.backfillTailer.IsFinished() {
+			return
+		}
+		s.backfillTailer.Stop()
+		s.backfillTailer = nil
+	}
+
+	for len(s.backfillQueue) > 0 {
+		rotated := s.backfillQueue[0]
+		s.backfillQueue = s.backfillQueue[1:]
+
+		tailer := s.createBackfillTailer(rotated.file)
+		log.Infof("Backfilling the rotated file %s (offset: %d)", rotated.file.Path, rotated.offset)
+		if err := tailer.Start(rotated.offset, io.SeekStart); err != nil {
+			log.Warn(err)
+			continue
+		}
+		s.backfillTailer = tailer
+		return
+	}
+}
+
+// createBackfillTailer returns a new tailer reading a compressed rotated file
+func (s *Launcher) createBackfillTailer(file *tailer.File) *tailer.Tailer {
+	tailerInfo := status.NewInfoRegistry()
+
+	tailerOptions := &tailer.TailerOptions{
+		OutputChan:    s.pipelineProvider.NextPipelineChan(),
+		File:          file,
+		SleepDuration: s.tailerSleepDuration,
+		Decoder:       decoder.NewDecoderFromSource(file.Source, tailerInfo),
+		Info:          tailerInfo,
+		Compressed:    true,
+	}
+
+	return tailer.NewTailer(tailerOptions)
+}
@@ -7,9 +7,12 @@
 package file
 
 import (
+	"os"
 	"regexp"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -34,14 +37,19 @@ const DefaultSleepDuration = 1 * time.Second
 // Launcher checks all files provided by fileProvider and create new tailers
 // or update the old ones if needed
 type Launcher struct {
-	pipelineProvider    pipeline.Provider
-	addedSources        chan *sources.LogSource
-	removedSources      chan *sources.LogSource
-	activeSources       []*sources.LogSource
-	tailingLimit        int
-	fileProvider        *fileprovider.FileProvider
-	tailers             *tailers.TailerContainer[*tailer.Tailer]
-	rotatedTailers      []*tailer.Tailer
+	pipelineProvider pipeline.Provider
+	addedSources     chan *sources.LogSource
+	removedSources   chan *sources.LogSource
+	activeSources    []*sources.LogSource
+	tailingLimit     int
+	fileProvider     *fileprovider.FileProvider
+	tailers          *tailers.TailerContainer[*tailer.Tailer]
+	rotatedTailers   []*tailer.Tailer
+	// backfillTailer reads the compressed rotated file being backfilled, the
+	// files are backfilled one at a time, in the order of backfillQueue.
+	backfillTailer      *tailer.Tailer
+	backfillQueue       []rotatedFile
+	startTime           time.Time
 	registry            auditor.Registry
 	tailerSleepDuration time.Duration
 	stop                chan struct{}
@@ -51,11 +59,15 @@ type Launcher struct {
 	// Feature flag defaulting to false, use `logs_config.validate_pod_container_id`.
 	validatePodContainerID bool
 	scanPeriod             time.Duration
-	flarecontroller        *flareController.FlareController
+	// set to true to watch the directories of the sources for new files in addition
+	// to the periodic scan, use `logs_config.file_watcher_enabled`.
+	fileWatcherEnabled bool
+	watcher            *fileWatcher
+	flarecontroller    *flareController.FlareController
 }
 
 // NewLauncher returns a new launcher.
-func NewLauncher(tailingLimit int, tailerSleepDuration time.Duration, validatePodContainerID bool, scanPeriod time.Duration, wildcardMode string, flarecontroller *flareController.FlareController) *Launcher {
+func NewLauncher(tailingLimit int, tailerSleepDuration time.Duration, validatePodContainerID bool, scanPeriod time.Duration, fileWatcherEnabled bool, wildcardMode string, flarecontroller *flareController.FlareController) *Launcher {
 
 	var wildcardStrategy fileprovider.WildcardSelectionStrategy
 	switch wildcardMode {
@@ -73,11 +85,13 @@ func NewLauncher(tailingLimit int, tailerSleepDuration time.Duration, validatePo
 		fileProvider:           fileprovider.NewFileProvider(tailingLimit, wildcardStrategy),
 		tailers:                tailers.NewTailerContainer[*tailer.Tailer](),
 		rotatedTailers:         []*tailer.Tailer{},
+		startTime:              time.Now(),
 		tailerSleepDuration:    tailerSleepDuration,
 		stop:                   make(chan struct{}),
 		done:                   make(chan struct{}),
 		validatePodContainerID: validatePodContainerID,
 		scanPeriod:             scanPeriod,
+		fileWatcherEnabled:     fileWatcherEnabled,
 		flarecontroller:        flarecontroller,
 	}
 }
@@ -88,6 +102,14 @@ func (s *Launcher) Start(sourceProvider launchers.SourceProvider, pipelineProvid
 	s.addedSources, s.removedSources = sourceProvider.SubscribeForType(config.FileType)
 	s.registry = registry
 	tracker.Add(s.tailers)
+	if s.fileWatcherEnabled {
+		watcher, err := newFileWatcher()
+		if err != nil {
+			log.Warnf("Could not start the file watcher, the new files will be detected by the periodic scan: %v", err)
+		} else {
+			s.watcher = watcher
+		}
+	}
 	go s.run()
 }
 
@@ -103,6 +125,7 @@ func (s *Launcher) run() {
 	scanTicker := time.NewTicker(s.scanPeriod)
 	defer func() {
 		scanTicker.Stop()
+		s.watcher.close()
 		close(s.done)
 	}()
 
@@ -112,8 +135,17 @@ func (s *Launcher) run() {
 			s.addSource(source)
 		case source := <-s.removedSources:
 			s.removeSource(source)
+		case event := <-s.watcher.events():
+			s.handleFileEvents(event)
+		case err := <-s.watcher.errors():
+			log.Debugf("File watcher error: %v", err)
+			if err == fsnotify.ErrEventOverflow {
+				// some events have been lost
+				s.scan()
+			}
 		case <-scanTicker.C:
 			s.cleanUpRotatedTailers()
+			s.backfillNextFile()
 			// check if there are new files to tail, tailers to stop and tailer to restart because of file rotation
 			s.scan()
 		case <-s.stop:
@@ -132,6 +164,11 @@ func (s *Launcher) cleanup() {
 		stopper.Add(tailer)
 	}
 	s.rotatedTailers = []*tailer.Tailer{}
+	if s.backfillTailer != nil {
+		stopper.Add(s.backfillTailer)
+		s.backfillTailer = nil
+	}
+	s.backfillQueue = nil
 
 	for _, tailer := range s.tailers.All() {
 		stopper.Add(tailer)
@@ -247,6 +284,12 @@ func (s *Launcher) cleanUpRotatedTailers() {
 // addSource keeps track of the new source and launch new tailers for this source.
 func (s *Launcher) addSource(source *sources.LogSource) {
 	s.activeSources = append(s.activeSources, source)
+	if source.Config.BackfillRotated {
+		// the rotated files must be collected before the new tailers commit any offset
+		s.collectRotatedFiles(source)
+		s.backfillNextFile()
+	}
+	s.watcher.sync(s.activeSources)
 	s.launchTailers(source)
 }
 
@@ -259,6 +302,82 @@ func (s *Launcher) removeSource(source *sources.LogSource) {
 			break
 		}
 	}
+	s.watcher.sync(s.activeSources)
+	// the files being backfilled are read until their end, the pending ones are dropped
+	pendingFiles := []rotatedFile{}
+	for _, rotated := range s.backfillQueue {
+		if rotated.file.Source.UnderlyingSource() != source {
+			pendingFiles = append(pendingFiles, rotated)
+		}
+	}
+	s.backfillQueue = pendingFiles
+}
+
+// handleFileEvents handles a file system event along with the events already queued,
+// and tails the new files they notify within the tailing limit. The files beyond the
+// limit are left to the periodic scan, which selects the files to tail.
+func (s *Launcher) handleFileEvents(event fsnotify.Event) {
+	newFiles := s.handleFileEvent(event)
+	for pending := true; pending; {
+		select {
+		case event := <-s.watcher.events():
+			newFiles = append(newFiles, s.handleFileEvent(event)...)
+		default:
+			pending = false
+		}
+	}
+
+	tailersLen := s.tailers.Count()
+	for _, file := range newFiles {
+		if tailersLen >= s.tailingLimit {
+			return
+		}
+		if s.tailers.Contains(file.GetScanKey()) {
+			continue
+		}
+		if s.startNewTailer(file, config.Beginning) {
+			tailersLen++
+		}
+	}
+}
+
+// handleFileEvent restarts the tailers of the files which have been recreated by a
+// rotation, and returns the new files matching the sources which have been created
+// in the watched directories.
+func (s *Launcher) handleFileEvent(event fsnotify.Event) []*tailer.File {
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		s.watcher.removed(event.Name)
+	}
+	if !event.Has(fsnotify.Create) {
+		return nil
+	}
+	info, err := os.Stat(event.Name)
+	if err != nil {
+		return nil
+	}
+
+	var newFiles []*tailer.File
+	for _, source := range s.activeSources {
+		if !matchesSource(source, event.Name, info.IsDir()) {
+			continue
+		}
+		isWildcardPath := config.ContainsWildcard(source.Config.Path)
+		if info.IsDir() {
+			for _, path := range s.watcher.watchNewDir(source, event.Name) {
+				newFiles = append(newFiles, tailer.NewFile(path, source, isWildcardPath))
+			}
+			continue
+		}
+		file := tailer.NewFile(event.Name, source, isWildcardPath)
+		if tailer, isTailed := s.tailers.Get(file.GetScanKey()); isTailed {
+			if didRotate, err := tailer.DidRotate(); err == nil && didRotate {
+				s.restartTailerAfterFileRotation(tailer, file)
+			}
+		} else {
+			newFiles = append(newFiles, file)
+		}
+	}
+	return newFiles
 }
 
 // launch launches new tailers for a new source.
@@ -307,7 +426,7 @@ func (s *Launcher) startNewTailer(file *tailer.File, m config.TailingMode) bool
 	var offset int64
 	var whence int
 	mode := s.handleTailingModeChange(tailer.Identifier(), m)
-	offset, whence, err := Position(s.registry, tailer.Identifier(), mode)
+	offset, whence, err := FingerprintedPosition(s.registry, tailer.Identifier(), tailer.Fingerprint(), mode)
 	if err != nil {
 		log.Warnf("Could not recover offset for file with path %v: %v", file.Path, err)
 	}
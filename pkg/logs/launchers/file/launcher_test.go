@@ -8,12 +8,16 @@
 package file
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
@@ -72,7 +76,7 @@ func (suite *LauncherTestSuite) SetupTest() {
 	suite.source = sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Identifier: suite.configID, Path: suite.testPath})
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	suite.s = NewLauncher(suite.openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+	suite.s = NewLauncher(suite.openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 	suite.s.pipelineProvider = suite.pipelineProvider
 	suite.s.registry = auditor.NewRegistry()
 	suite.s.activeSources = append(suite.s.activeSources, suite.source)
@@ -230,7 +234,7 @@ func TestLauncherScanStartNewTailer(t *testing.T) {
 		openFilesLimit := 2
 		sleepDuration := 20 * time.Millisecond
 		fc := flareController.NewFlareController()
-		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 		launcher.pipelineProvider = mock.NewMockProvider()
 		launcher.registry = auditor.NewRegistry()
 		outputChan := launcher.pipelineProvider.NextPipelineChan()
@@ -269,7 +273,7 @@ func TestLauncherWithConcurrentContainerTailer(t *testing.T) {
 	openFilesLimit := 3
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditor.NewRegistry()
 	outputChan := launcher.pipelineProvider.NextPipelineChan()
@@ -317,7 +321,7 @@ func TestLauncherTailFromTheBeginning(t *testing.T) {
 	openFilesLimit := 3
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditor.NewRegistry()
 	outputChan := launcher.pipelineProvider.NextPipelineChan()
@@ -370,7 +374,7 @@ func TestLauncherSetTail(t *testing.T) {
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditor.NewRegistry()
 
@@ -394,7 +398,7 @@ func TestLauncherConfigIdentifier(t *testing.T) {
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditor.NewRegistry()
 
@@ -431,7 +435,7 @@ func TestLauncherScanWithTooManyFiles(t *testing.T) {
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditor.NewRegistry()
 	source := sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
@@ -461,7 +465,7 @@ func TestLauncherUpdatesSourceForExistingTailer(t *testing.T) {
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditor.NewRegistry()
 
@@ -572,7 +576,7 @@ func TestLauncherScanRecentFilesWithNewFiles(t *testing.T) {
 	createLauncher := func() *Launcher {
 		sleepDuration := 20 * time.Millisecond
 		fc := flareController.NewFlareController()
-		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_modification_time", fc)
+		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_modification_time", fc)
 		launcher.pipelineProvider = mock.NewMockProvider()
 		launcher.registry = auditor.NewRegistry()
 		logDirectory := fmt.Sprintf("%s/*.log", testDir)
@@ -633,7 +637,7 @@ func TestLauncherFileRotation(t *testing.T) {
 	createLauncher := func() *Launcher {
 		sleepDuration := 20 * time.Millisecond
 		fc := flareController.NewFlareController()
-		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 		launcher.pipelineProvider = mock.NewMockProvider()
 		launcher.registry = auditor.NewRegistry()
 		logDirectory := fmt.Sprintf("%s/*.log", testDir)
@@ -698,7 +702,7 @@ func TestLauncherFileDetectionSingleScan(t *testing.T) {
 	createLauncher := func() *Launcher {
 		sleepDuration := 20 * time.Millisecond
 		fc := flareController.NewFlareController()
-		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc)
+		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, false, "by_name", fc)
 		launcher.pipelineProvider = mock.NewMockProvider()
 		launcher.registry = auditor.NewRegistry()
 		logDirectory := fmt.Sprintf("%s/*.log", testDir)
@@ -731,3 +735,124 @@ func TestLauncherFileDetectionSingleScan(t *testing.T) {
 func getScanKey(path string, source *sources.LogSource) string {
 	return filetailer.NewFile(path, source, false).GetScanKey()
 }
+
+func TestLauncherBackfillRotatedFiles(t *testing.T) {
+	testDir := t.TempDir()
+	path := fmt.Sprintf("%s/app.log", testDir)
+	assert.Nil(t, os.WriteFile(path, nil, 0o644))
+
+	now := time.Now()
+	writeCompressed := func(name string, content string, modTime time.Time) {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write([]byte(content))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+		assert.Nil(t, os.WriteFile(fmt.Sprintf("%s/%s", testDir, name), buf.Bytes(), 0o644))
+		assert.Nil(t, os.Chtimes(fmt.Sprintf("%s/%s", testDir, name), modTime, modTime))
+	}
+	// rotated while the agent was stopped
+	writeCompressed("app.log.1.gz", "rotated\n", now.Add(-time.Hour))
+	writeCompressed("app.log.2.gz", "rotated earlier\n", now.Add(-2*time.Hour))
+	// rotated while the agent was running
+	writeCompressed("app.log.3.gz", "already sent\n", now.Add(-4*time.Hour))
+	// not rotated from the tailed file
+	writeCompressed("other.log.1.gz", "other\n", now.Add(-time.Hour))
+
+	fc := flareController.NewFlareController()
+	launcher := NewLauncher(10, 20*time.Millisecond, false, 10*time.Second, false, "by_name", fc)
+	launcher.pipelineProvider = mock.NewMockProvider()
+	registry := auditor.NewRegistry()
+	registry.SetLastUpdated(now.Add(-3 * time.Hour))
+	launcher.registry = registry
+	outputChan := launcher.pipelineProvider.NextPipelineChan()
+
+	source := sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path, BackfillRotated: true})
+	launcher.addSource(source)
+	assert.Equal(t, 1, launcher.tailers.Count())
+
+	// the oldest file is backfilled first
+	msg := <-outputChan
+	assert.Equal(t, "rotated earlier", string(msg.GetContent()))
+	assert.Equal(t, fmt.Sprintf("file:%s/app.log.2.gz", testDir), msg.Origin.Identifier)
+	assert.Eventually(t, launcher.backfillTailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, launcher.backfillQueue, 1)
+
+	launcher.backfillNextFile()
+	msg = <-outputChan
+	assert.Equal(t, "rotated", string(msg.GetContent()))
+	assert.Equal(t, fmt.Sprintf("file:%s/app.log.1.gz", testDir), msg.Origin.Identifier)
+	assert.Eventually(t, launcher.backfillTailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+
+	launcher.backfillNextFile()
+	assert.Nil(t, launcher.backfillTailer)
+	assert.Empty(t, launcher.backfillQueue)
+	assert.Len(t, outputChan, 0)
+	launcher.cleanup()
+}
+
+func TestLauncherBackfillRotatedFilesResume(t *testing.T) {
+	testDir := t.TempDir()
+	path := fmt.Sprintf("%s/app.log", testDir)
+	assert.Nil(t, os.WriteFile(path, nil, 0o644))
+
+	now := time.Now()
+	writeCompressed := func(name string, content string, modTime time.Time) {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write([]byte(content))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+		assert.Nil(t, os.WriteFile(fmt.Sprintf("%s/%s", testDir, name), buf.Bytes(), 0o644))
+		assert.Nil(t, os.Chtimes(fmt.Sprintf("%s/%s", testDir, name), modTime, modTime))
+	}
+	// tailed when the agent stopped, then rotated twice
+	writeCompressed("app.log.3.gz", "sent\nnot sent\n", now.Add(-3*time.Hour))
+	writeCompressed("app.log.2.gz", "rotated\n", now.Add(-2*time.Hour))
+	// backfilled until its end by a previous run
+	writeCompressed("app.log.1.gz", "already backfilled\n", now.Add(-time.Hour))
+
+	fc := flareController.NewFlareController()
+	launcher := NewLauncher(10, 20*time.Millisecond, false, 10*time.Second, false, "by_name", fc)
+	launcher.pipelineProvider = mock.NewMockProvider()
+	registry := auditor.NewRegistry()
+	registry.SetLastUpdated(now.Add(-4 * time.Hour))
+	registry.SetOffsetForIdentifier(fmt.Sprintf("file:%s", path), strconv.Itoa(len("sent\n")))
+	registry.SetCompleted(fmt.Sprintf("file:%s/app.log.1.gz", testDir))
+	launcher.registry = registry
+	outputChan := launcher.pipelineProvider.NextPipelineChan()
+
+	launcher.collectRotatedFiles(sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path, BackfillRotated: true}))
+	require.Len(t, launcher.backfillQueue, 2)
+	assert.Equal(t, fmt.Sprintf("%s/app.log.3.gz", testDir), launcher.backfillQueue[0].file.Path)
+	assert.EqualValues(t, len("sent\n"), launcher.backfillQueue[0].offset)
+	assert.Equal(t, fmt.Sprintf("%s/app.log.2.gz", testDir), launcher.backfillQueue[1].file.Path)
+	assert.EqualValues(t, 0, launcher.backfillQueue[1].offset)
+
+	// the logs sent while the file was tailed are skipped
+	launcher.backfillNextFile()
+	msg := <-outputChan
+	assert.Equal(t, "not sent", string(msg.GetContent()))
+	assert.True(t, msg.Origin.Completed)
+	assert.Eventually(t, launcher.backfillTailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	launcher.cleanup()
+}
+
+func TestLauncherBackfillRotatedFilesNeverTailed(t *testing.T) {
+	testDir := t.TempDir()
+	path := fmt.Sprintf("%s/app.log", testDir)
+	assert.Nil(t, os.WriteFile(path, nil, 0o644))
+	assert.Nil(t, os.WriteFile(path+".1.gz", nil, 0o644))
+
+	fc := flareController.NewFlareController()
+	launcher := NewLauncher(10, 20*time.Millisecond, false, 10*time.Second, false, "by_name", fc)
+	launcher.pipelineProvider = mock.NewMockProvider()
+	launcher.registry = auditor.NewRegistry()
+	launcher.startTime = time.Now().Add(time.Hour)
+
+	// nothing was missed for a file which has never been tailed
+	launcher.addSource(sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path, BackfillRotated: true}))
+	assert.Nil(t, launcher.backfillTailer)
+	assert.Empty(t, launcher.backfillQueue)
+	launcher.cleanup()
+}
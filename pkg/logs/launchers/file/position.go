@@ -47,3 +47,28 @@ func Position(registry auditor.Registry, identifier string, mode config.TailingM
 	}
 	return offset, whence, err
 }
+
+// FingerprintedPosition returns the position from where logs should be collected for a
+// file identified by its content fingerprint in addition to its identifier.
+// The offset of a renamed file is recovered from the previous identifier of its
+// fingerprint, and the offset is discarded if the content of the file changed,
+// e.g. after a copytruncate rotation, in which case the file is read from the beginning.
+func FingerprintedPosition(registry auditor.Registry, identifier string, fingerprint string, mode config.TailingMode) (int64, int, error) {
+	if fingerprint == "" || mode == config.ForceBeginning || mode == config.ForceEnd {
+		return Position(registry, identifier, mode)
+	}
+
+	registered := registry.GetFingerprint(identifier)
+	if registered == fingerprint {
+		return Position(registry, identifier, mode)
+	}
+	if previous := registry.GetIdentifierForFingerprint(fingerprint); previous != "" {
+		// the file has been renamed
+		return Position(registry, previous, mode)
+	}
+	if registered != "" {
+		// the file has been replaced by a file with a different content
+		return 0, io.SeekStart, nil
+	}
+	return Position(registry, identifier, mode)
+}
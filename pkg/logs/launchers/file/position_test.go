@@ -68,3 +68,40 @@ func TestPosition(t *testing.T) {
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekEnd, whence)
 }
+
+func TestFingerprintedPosition(t *testing.T) {
+	registry := mock.NewRegistry()
+	registry.SetOffset("42")
+
+	// the files which are not fingerprinted are identified by their identifier only
+	offset, whence, err := FingerprintedPosition(registry, "file:/var/log/app.log", "", config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// same content
+	registry.SetFingerprint("5ab1c3")
+	offset, whence, err = FingerprintedPosition(registry, "file:/var/log/app.log", "5ab1c3", config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// the content changed, the offset is discarded
+	offset, whence, err = FingerprintedPosition(registry, "file:/var/log/app.log", "d4e5f6", config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// unless the tailing mode is forced
+	offset, whence, err = FingerprintedPosition(registry, "file:/var/log/app.log", "d4e5f6", config.ForceEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekEnd, whence)
+
+	// the file has been renamed
+	registry.SetIdentifierForFingerprint("d4e5f6", "file:/var/log/app.log.1")
+	offset, whence, err = FingerprintedPosition(registry, "file:/var/log/app.log", "d4e5f6", config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+}
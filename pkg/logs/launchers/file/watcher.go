@@ -0,0 +1,209 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// fileWatcher watches the directories of the file sources to notify the launcher
+// of the files created in them (inotify on Linux), so that they are tailed without
+// waiting for the next scan. The files of the directories which can't be watched
+// are detected by the periodic scan.
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	// dirs are the watched directories, they are matched against the paths of the
+	// sources when the sources change, the new directories are added from the events
+	dirs map[string]struct{}
+}
+
+// newFileWatcher returns a new file watcher, watching no directory.
+func newFileWatcher() (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fileWatcher{
+		watcher: watcher,
+		dirs:    make(map[string]struct{}),
+	}, nil
+}
+
+// events returns the channel of the file system events, or nil if the watcher
+// is not running.
+func (w *fileWatcher) events() chan fsnotify.Event {
+	if w == nil {
+		return nil
+	}
+	return w.watcher.Events
+}
+
+// errors returns the channel of the watcher errors, or nil if the watcher is
+// not running.
+func (w *fileWatcher) errors() chan error {
+	if w == nil {
+		return nil
+	}
+	return w.watcher.Errors
+}
+
+// sync updates the watched directories to the directories of the sources, it must
+// only be called when the sources change.
+func (w *fileWatcher) sync(activeSources []*sources.LogSource) {
+	if w == nil {
+		return
+	}
+	dirs := make(map[string]struct{})
+	for _, source := range activeSources {
+		for _, dir := range dirsToWatch(source.Config.Path) {
+			dirs[dir] = struct{}{}
+		}
+	}
+
+	for dir := range w.dirs {
+		if _, exists := dirs[dir]; !exists {
+			// the watch has already been removed if the directory has been deleted
+			_ = w.watcher.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	for dir := range dirs {
+		w.add(dir)
+	}
+}
+
+// add watches a directory if it is not watched yet.
+func (w *fileWatcher) add(dir string) {
+	if _, exists := w.dirs[dir]; exists {
+		return
+	}
+	if err := w.watcher.Add(dir); err != nil {
+		// the directory doesn't exist yet or the watches limit has been reached,
+		// its files are detected by the periodic scan
+		log.Debugf("Could not watch directory %s: %v", dir, err)
+		return
+	}
+	w.dirs[dir] = struct{}{}
+}
+
+// watchNewDir watches a new directory matching the path of a source and its
+// subdirectories matching the path, and returns the files of the directory matching
+// the path, which may have been created before the directory was watched.
+func (w *fileWatcher) watchNewDir(source *sources.LogSource, dir string) []string {
+	w.add(dir)
+	depth := len(strings.Split(dir, string(filepath.Separator)))
+	parts := strings.Split(source.Config.Path, string(filepath.Separator))
+	if depth >= len(parts) {
+		return nil
+	}
+	for i := depth + 1; i < len(parts); i++ {
+		matches, err := filepath.Glob(filepath.Join(append([]string{dir}, parts[depth:i]...)...))
+		if err != nil {
+			return nil
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				w.add(match)
+			}
+		}
+	}
+	matches, err := filepath.Glob(filepath.Join(append([]string{dir}, parts[depth:]...)...))
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && !info.IsDir() && matchesSource(source, match, false) {
+			files = append(files, match)
+		}
+	}
+	return files
+}
+
+// removed stops tracking a directory which has been deleted, its watch has been
+// removed by the system.
+func (w *fileWatcher) removed(dir string) {
+	delete(w.dirs, dir)
+}
+
+// close stops the watcher.
+func (w *fileWatcher) close() {
+	if w == nil {
+		return
+	}
+	if err := w.watcher.Close(); err != nil {
+		log.Debugf("Could not close the file watcher: %v", err)
+	}
+}
+
+// dirsToWatch returns the existing directories to watch to be notified of the files
+// matching the path of a source. When the directory of the path contains wildcards,
+// the parent directories are watched as well to be notified of the new directories,
+// e.g. `/var/log/pods`, `/var/log/pods/*` and `/var/log/pods/*/*` for
+// `/var/log/pods/*/*/*.log`.
+func dirsToWatch(path string) []string {
+	dir := filepath.Dir(path)
+	parts := strings.Split(dir, string(filepath.Separator))
+	firstWildcard := -1
+	for i, part := range parts {
+		if config.ContainsWildcard(part) {
+			firstWildcard = i
+			break
+		}
+	}
+	if firstWildcard < 0 {
+		return []string{dir}
+	}
+
+	staticDir := strings.Join(parts[:firstWildcard], string(filepath.Separator))
+	if staticDir == "" {
+		staticDir = string(filepath.Separator)
+	}
+	dirs := []string{staticDir}
+	for i := firstWildcard; i < len(parts); i++ {
+		matches, err := filepath.Glob(strings.Join(parts[:i+1], string(filepath.Separator)))
+		if err != nil {
+			return dirs
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+	return dirs
+}
+
+// matchesSource returns true if the path is matched by the path pattern of the
+// source or by the pattern of one of its parent directories, and is not excluded.
+func matchesSource(source *sources.LogSource, path string, isDir bool) bool {
+	pattern := source.Config.Path
+	if isDir {
+		pattern = filepath.Dir(pattern)
+	}
+	for ; ; pattern = filepath.Dir(pattern) {
+		if matched, _ := filepath.Match(pattern, path); matched {
+			break
+		}
+		if !isDir || pattern == filepath.Dir(pattern) {
+			return false
+		}
+	}
+	for _, excludePattern := range source.Config.ExcludePaths {
+		if excluded, _ := filepath.Match(excludePattern, path); excluded {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	flareController "github.com/DataDog/datadog-agent/comp/logs/agent/flare"
+	auditor "github.com/DataDog/datadog-agent/pkg/logs/auditor/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func TestDirsToWatch(t *testing.T) {
+	testDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(testDir, "pod1", "app"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(testDir, "pod2", "app"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "pod1", "file.log"), nil, 0o644))
+
+	assert.Equal(t, []string{testDir}, dirsToWatch(filepath.Join(testDir, "*.log")))
+	assert.Equal(t, []string{
+		testDir,
+		filepath.Join(testDir, "pod1"),
+		filepath.Join(testDir, "pod2"),
+		filepath.Join(testDir, "pod1", "app"),
+		filepath.Join(testDir, "pod2", "app"),
+	}, dirsToWatch(filepath.Join(testDir, "*", "*", "*.log")))
+}
+
+func TestMatchesSource(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: "/var/log/pods/*/*/*.log", ExcludePaths: []string{"/var/log/pods/*/*/debug.log"}})
+
+	assert.True(t, matchesSource(source, "/var/log/pods/pod/app/0.log", false))
+	assert.False(t, matchesSource(source, "/var/log/pods/pod/app/debug.log", false))
+	assert.False(t, matchesSource(source, "/var/log/pods/pod/app/0.txt", false))
+	assert.False(t, matchesSource(source, "/var/log/pods/pod/0.log", false))
+	assert.True(t, matchesSource(source, "/var/log/pods/pod/app", true))
+	assert.True(t, matchesSource(source, "/var/log/pods/pod", true))
+	assert.False(t, matchesSource(source, "/var/log/other", true))
+}
+
+func TestLauncherWatchesNewFiles(t *testing.T) {
+	testDir := t.TempDir()
+
+	fc := flareController.NewFlareController()
+	launcher := NewLauncher(10, 20*time.Millisecond, false, 10*time.Second, true, "by_name", fc)
+	launcher.pipelineProvider = mock.NewMockProvider()
+	launcher.registry = auditor.NewRegistry()
+	watcher, err := newFileWatcher()
+	require.NoError(t, err)
+	launcher.watcher = watcher
+	defer watcher.close()
+	outputChan := launcher.pipelineProvider.NextPipelineChan()
+
+	launcher.addSource(sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: filepath.Join(testDir, "*", "*.log")}))
+	assert.Equal(t, 0, launcher.tailers.Count())
+
+	// a new directory is watched, and its files are tailed without waiting for a scan
+	require.NoError(t, os.Mkdir(filepath.Join(testDir, "app"), 0o755))
+	handleEvents := func(condition func() bool) {
+		timeout := time.After(5 * time.Second)
+		for !condition() {
+			select {
+			case event := <-launcher.watcher.events():
+				launcher.handleFileEvents(event)
+			case <-timeout:
+				require.Fail(t, "timeout waiting for the file events")
+			}
+		}
+	}
+	handleEvents(func() bool {
+		_, watched := launcher.watcher.dirs[filepath.Join(testDir, "app")]
+		return watched
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "app", "new.log"), []byte("hello world\n"), 0o644))
+	handleEvents(func() bool { return launcher.tailers.Count() == 1 })
+
+	msg := <-outputChan
+	assert.Equal(t, "hello world", string(msg.GetContent()))
+
+	// the files which don't match the source are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "app", "new.txt"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "app", "other.log"), nil, 0o644))
+	handleEvents(func() bool { return launcher.tailers.Count() == 2 })
+	assert.Len(t, launcher.watcher.dirs, 2)
+
+	// the files of the new directories are tailed even if they are created before the directories are watched
+	require.NoError(t, os.MkdirAll(filepath.Join(testDir, "nested", "app"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "nested", "early.log"), nil, 0o644))
+	handleEvents(func() bool { return launcher.tailers.Contains(filepath.Join(testDir, "nested", "early.log")) })
+	_, watched := launcher.watcher.dirs[filepath.Join(testDir, "nested")]
+	assert.True(t, watched)
+	launcher.cleanup()
+}
+
+func TestWatchNewDir(t *testing.T) {
+	testDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(testDir, "pod", "app"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "pod", "app", "0.log"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "pod", "app", "0.txt"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "pod", "1.log"), nil, 0o644))
+
+	watcher, err := newFileWatcher()
+	require.NoError(t, err)
+	defer watcher.close()
+
+	source := sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: filepath.Join(testDir, "*", "*", "*.log")})
+	files := watcher.watchNewDir(source, filepath.Join(testDir, "pod"))
+	assert.Equal(t, []string{filepath.Join(testDir, "pod", "app", "0.log")}, files)
+	assert.Equal(t, map[string]struct{}{
+		filepath.Join(testDir, "pod"):        {},
+		filepath.Join(testDir, "pod", "app"): {},
+	}, watcher.dirs)
+}
+
+func TestLauncherWatchedFilesLimit(t *testing.T) {
+	testDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "a.log"), nil, 0o644))
+
+	fc := flareController.NewFlareController()
+	launcher := NewLauncher(1, 20*time.Millisecond, false, 10*time.Second, true, "by_name", fc)
+	launcher.pipelineProvider = mock.NewMockProvider()
+	launcher.registry = auditor.NewRegistry()
+	watcher, err := newFileWatcher()
+	require.NoError(t, err)
+	launcher.watcher = watcher
+	defer watcher.close()
+
+	launcher.addSource(sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: filepath.Join(testDir, "*.log")}))
+	require.True(t, launcher.tailers.Contains(filepath.Join(testDir, "a.log")))
+
+	// the new files beyond the limit are not tailed
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "z.log"), nil, 0o644))
+	select {
+	case event := <-launcher.watcher.events():
+		launcher.handleFileEvents(event)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting for the file events")
+	}
+	assert.Equal(t, 1, launcher.tailers.Count())
+	assert.True(t, launcher.tailers.Contains(filepath.Join(testDir, "a.log")))
+
+	// the scan selects the files to tail, the new file comes first in the wildcard order
+	launcher.scan()
+	assert.Equal(t, 1, launcher.tailers.Count())
+	assert.True(t, launcher.tailers.Contains(filepath.Join(testDir, "z.log")))
+	launcher.cleanup()
+}
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	tailer "github.com/DataDog/datadog-agent/pkg/logs/tailers/socket"
+)
+
+// fifoFileMode is the mode of the named pipes created by the listener.
+const fifoFileMode = 0666
+
+// A FIFOListener reads the data written to a named pipe with a tailer. The named pipe
+// is created if it does not exist and is left in place when the listener stops, so that
+// the writers can keep opening it.
+type FIFOListener struct {
+	pipelineProvider pipeline.Provider
+	source           *sources.LogSource
+	frameSize        int
+	tailer           *tailer.Tailer
+}
+
+// NewFIFOListener returns an initialized FIFOListener
+func NewFIFOListener(pipelineProvider pipeline.Provider, source *sources.LogSource, frameSize int) *FIFOListener {
+	return &FIFOListener{
+		pipelineProvider: pipelineProvider,
+		source:           source,
+		frameSize:        frameSize,
+	}
+}
+
+// Start opens the named pipe and starts a tailer.
+func (l *FIFOListener) Start() {
+	log.Infof("Starting named pipe forwarder on %s, with read buffer size: %d", l.source.Config.Path, l.frameSize)
+	err := l.startNewTailer()
+	if err != nil {
+		log.Errorf("Can't start named pipe forwarder on %s: %v", l.source.Config.Path, err)
+		l.source.Status.Error(err)
+		return
+	}
+	l.source.Status.Success()
+}
+
+// Stop stops the tailer.
+func (l *FIFOListener) Stop() {
+	if l.tailer != nil {
+		log.Infof("Stopping named pipe forwarder on %s", l.source.Config.Path)
+		l.tailer.Stop()
+	}
+}
+
+// startNewTailer opens the named pipe, creating it if needed, and starts a new Tailer
+func (l *FIFOListener) startNewTailer() error {
+	path := l.source.Config.Path
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		if err := syscall.Mkfifo(path, fifoFileMode); err != nil {
+			return fmt.Errorf("can't create named pipe: %v", err)
+		}
+	case err != nil:
+		return err
+	case info.Mode()&os.ModeNamedPipe == 0:
+		return fmt.Errorf("%s already exists and is not a named pipe", path)
+	}
+
+	// The pipe is opened for writing as well so that it always has a writer, reading it
+	// blocks until new data is written instead of returning EOF when the writers close it.
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	l.tailer = tailer.NewTailer(l.source, &fifoConn{File: f}, l.pipelineProvider.NextPipelineChan(), l.read)
+	l.tailer.Start()
+	return nil
+}
+
+// read reads data from the named pipe.
+func (l *FIFOListener) read(tailer *tailer.Tailer) ([]byte, string, error) {
+	frame := make([]byte, l.frameSize)
+	n, err := tailer.Conn.Read(frame)
+	if errors.Is(err, os.ErrClosed) {
+		// the tailer has been stopped
+		return nil, "", io.EOF
+	}
+	if err != nil {
+		l.source.Status.Error(err)
+		return nil, "", err
+	}
+	return frame[:n], "", nil
+}
+
+// fifoConn is a named pipe read as a connection by the socket tailer.
+type fifoConn struct {
+	*os.File
+}
+
+// LocalAddr returns the path of the named pipe.
+func (c *fifoConn) LocalAddr() net.Addr {
+	return fifoAddr(c.Name())
+}
+
+// RemoteAddr returns the path of the named pipe, the writers don't have an address.
+func (c *fifoConn) RemoteAddr() net.Addr {
+	return fifoAddr(c.Name())
+}
+
+// fifoAddr is the address of a named pipe.
+type fifoAddr string
+
+// Network returns the name of the network.
+func (a fifoAddr) Network() string {
+	return "fifo"
+}
+
+// String returns the path of the named pipe.
+func (a fifoAddr) String() string {
+	return string(a)
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package listener
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func TestFIFOShouldReceiveMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	path := filepath.Join(t.TempDir(), "app.pipe")
+	listener := NewFIFOListener(pp, sources.NewLogSource("", &config.LogsConfig{Type: config.FIFOType, Path: path}), 9000)
+	listener.Start()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeNamedPipe)
+
+	// the messages of the successive writers are all received
+	for _, content := range []string{"hello world", "goodbye world"} {
+		writer, err := os.OpenFile(path, os.O_WRONLY, 0)
+		require.NoError(t, err)
+		fmt.Fprintf(writer, "%s\n", content)
+		writer.Close()
+
+		msg := <-msgChan
+		assert.Equal(t, content, string(msg.GetContent()))
+	}
+
+	listener.Stop()
+	// the named pipe is left for the writers
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
+
+func TestFIFOShouldNotReplaceOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+
+	source := sources.NewLogSource("", &config.LogsConfig{Type: config.FIFOType, Path: path})
+	listener := NewFIFOListener(mock.NewMockProvider(), source, 9000)
+	listener.Start()
+	defer listener.Stop()
+
+	assert.True(t, source.Status.IsError())
+}
@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows
+
+package listener
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+// A FIFOListener reads the data written to a named pipe, named pipes are not supported
+// on Windows.
+type FIFOListener struct {
+	source *sources.LogSource
+}
+
+// NewFIFOListener returns an initialized FIFOListener
+func NewFIFOListener(_ pipeline.Provider, source *sources.LogSource, _ int) *FIFOListener {
+	return &FIFOListener{
+		source: source,
+	}
+}
+
+// Start reports that named pipes are not supported.
+func (l *FIFOListener) Start() {
+	err := errors.New("fifo sources are not supported on Windows")
+	log.Errorf("Can't start named pipe forwarder on %s: %v", l.source.Config.Path, err)
+	l.source.Status.Error(err)
+}
+
+// Stop does nothing.
+func (l *FIFOListener) Stop() {}
@@ -21,6 +21,8 @@ type Launcher struct {
 	frameSize        int
 	tcpSources       chan *sources.LogSource
 	udpSources       chan *sources.LogSource
+	unixSources      chan *sources.LogSource
+	fifoSources      chan *sources.LogSource
 	listeners        []startstop.StartStoppable
 	stop             chan struct{}
 }
@@ -40,10 +42,12 @@ func (l *Launcher) Start(sourceProvider launchers.SourceProvider, pipelineProvid
 	l.pipelineProvider = pipelineProvider
 	l.tcpSources = sourceProvider.GetAddedForType(config.TCPType)
 	l.udpSources = sourceProvider.GetAddedForType(config.UDPType)
+	l.unixSources = sourceProvider.GetAddedForType(config.UnixType)
+	l.fifoSources = sourceProvider.GetAddedForType(config.FIFOType)
 	go l.run()
 }
 
-// run starts new network listeners.
+// run starts new network, unix socket and named pipe listeners.
 func (l *Launcher) run() {
 	for {
 		select {
@@ -55,6 +59,19 @@ func (l *Launcher) run() {
 			listener := NewUDPListener(l.pipelineProvider, source, l.frameSize)
 			listener.Start()
 			l.listeners = append(l.listeners, listener)
+		case source := <-l.unixSources:
+			var listener startstop.StartStoppable
+			if source.Config.SocketType == config.DatagramSocketType {
+				listener = NewUnixDatagramListener(l.pipelineProvider, source, l.frameSize)
+			} else {
+				listener = NewUnixListener(l.pipelineProvider, source, l.frameSize)
+			}
+			listener.Start()
+			l.listeners = append(l.listeners, listener)
+		case source := <-l.fifoSources:
+			listener := NewFIFOListener(l.pipelineProvider, source, l.frameSize)
+			listener.Start()
+			l.listeners = append(l.listeners, listener)
 		case <-l.stop:
 			return
 		}
@@ -6,6 +6,7 @@
 package listener
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
@@ -117,8 +118,25 @@ func (l *TCPListener) run() {
 }
 
 // startListener starts a new listener, returns an error if it failed.
+// The listener terminates TLS when the source has a certificate and a key.
 func (l *TCPListener) startListener() error {
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", l.source.Config.Port))
+	var listener net.Listener
+	var err error
+	address := fmt.Sprintf(":%d", l.source.Config.Port)
+	if l.source.Config.TLSCertPath != "" {
+		// the certificate is loaded on each start to pick up renewed certificates
+		var cert tls.Certificate
+		cert, err = tls.LoadX509KeyPair(l.source.Config.TLSCertPath, l.source.Config.TLSKeyPath)
+		if err != nil {
+			return fmt.Errorf("can't load the TLS certificate: %v", err)
+		}
+		listener, err = tls.Listen("tcp", address, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		})
+	} else {
+		listener, err = net.Listen("tcp", address)
+	}
 	if err != nil {
 		return err
 	}
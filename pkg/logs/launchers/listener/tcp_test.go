@@ -6,12 +6,24 @@
 package listener
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -64,3 +76,63 @@ func TestTCPDoesNotTruncateMessagesThatAreBiggerThanTheReadBufferSize(t *testing
 
 	listener.Stop()
 }
+
+func TestTCPShouldReceiveSyslogMessagesOverTLS(t *testing.T) {
+	certPath, keyPath := writeTestCertificate(t)
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	listener := NewTCPListener(pp, sources.NewLogSource("", &config.LogsConfig{
+		Port:        tcpTestPort,
+		Format:      config.SyslogFormat,
+		TLSCertPath: certPath,
+		TLSKeyPath:  keyPath,
+	}), 9000)
+	listener.Start()
+	defer listener.Stop()
+
+	conn, err := tls.Dial("tcp", listener.listener.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	syslogMsg := "<12>1 2024-03-01T10:00:00Z firewall fw - - - port scan\ndetected"
+	fmt.Fprintf(conn, "%d %s", len(syslogMsg), syslogMsg)
+	msg := <-msgChan
+	assert.Equal(t, message.StateStructured, msg.State)
+	assert.Equal(t, "port scan\ndetected", string(msg.GetContent()))
+	assert.Equal(t, message.StatusWarning, msg.GetStatus())
+	assert.Equal(t, "firewall", msg.Hostname)
+}
+
+func TestTCPShouldFailWithInvalidCertificate(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{
+		Port:        tcpTestPort,
+		TLSCertPath: filepath.Join(t.TempDir(), "missing.pem"),
+		TLSKeyPath:  filepath.Join(t.TempDir(), "missing.key"),
+	})
+	listener := NewTCPListener(mock.NewMockProvider(), source, 9000)
+	listener.Start()
+	assert.True(t, source.Status.IsError())
+}
+
+// writeTestCertificate writes a self-signed certificate and its key, and returns their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600))
+	return certPath, keyPath
+}
@@ -103,19 +103,25 @@ func (l *UDPListener) read(_ *tailer.Tailer) ([]byte, string, error) {
 		go l.resetTailer()
 		return nil, "", err
 	default:
-		// make sure all logs are separated by line feeds, otherwise they don't get properly split downstream
-		if n > l.frameSize {
-			// the message is bigger than the length of the read buffer,
-			// the trailing part of the content will be dropped.
-			frame[l.frameSize] = '\n'
-		} else if n > 0 && frame[n-1] != '\n' {
-			frame[n] = '\n'
-			n++
-		}
-		return frame[:n], udpAddr.IP.String(), nil
+		return terminateDatagram(frame, n, l.frameSize), udpAddr.IP.String(), nil
 	}
 }
 
+// terminateDatagram returns the content of a datagram read in a frame of frameSize+1 bytes,
+// ending with a line feed.
+func terminateDatagram(frame []byte, n int, frameSize int) []byte {
+	// make sure all logs are separated by line feeds, otherwise they don't get properly split downstream
+	if n > frameSize {
+		// the message is bigger than the length of the read buffer,
+		// the trailing part of the content will be dropped.
+		frame[frameSize] = '\n'
+	} else if n > 0 && frame[n-1] != '\n' {
+		frame[n] = '\n'
+		n++
+	}
+	return frame[:n]
+}
+
 // resetTailer creates a new tailer.
 func (l *UDPListener) resetTailer() {
 	log.Infof("Resetting the UDP connection on port: %d", l.source.Config.Port)
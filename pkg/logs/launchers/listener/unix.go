@@ -0,0 +1,259 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	tailer "github.com/DataDog/datadog-agent/pkg/logs/tailers/socket"
+	"github.com/DataDog/datadog-agent/pkg/util/startstop"
+)
+
+// socketFileMode is the mode of the socket files, the processes of all the users
+// must be able to write to them like to /dev/log.
+const socketFileMode = 0666
+
+// A UnixListener listens and accepts connections on a unix stream socket and delegates
+// the read operations to a tailer.
+type UnixListener struct {
+	pipelineProvider pipeline.Provider
+	source           *sources.LogSource
+	idleTimeout      time.Duration
+	frameSize        int
+	listener         net.Listener
+	tailers          []*tailer.Tailer
+	mu               sync.Mutex
+	stop             chan struct{}
+}
+
+// NewUnixListener returns an initialized UnixListener
+func NewUnixListener(pipelineProvider pipeline.Provider, source *sources.LogSource, frameSize int) *UnixListener {
+	var idleTimeout time.Duration
+	if source.Config.IdleTimeout != "" {
+		var err error
+		idleTimeout, err = time.ParseDuration(source.Config.IdleTimeout)
+		if err != nil {
+			log.Errorf("Error parsing log's idle_timeout as a duration: %s", err)
+			idleTimeout = 0
+		}
+	}
+
+	return &UnixListener{
+		pipelineProvider: pipelineProvider,
+		source:           source,
+		idleTimeout:      idleTimeout,
+		frameSize:        frameSize,
+		tailers:          []*tailer.Tailer{},
+		stop:             make(chan struct{}, 1),
+	}
+}
+
+// Start starts the listener to accepts new incoming connections.
+func (l *UnixListener) Start() {
+	log.Infof("Starting unix socket forwarder on %s, with read buffer size: %d", l.source.Config.Path, l.frameSize)
+	err := l.startListener()
+	if err != nil {
+		log.Errorf("Can't start unix socket forwarder on %s: %v", l.source.Config.Path, err)
+		l.source.Status.Error(err)
+		return
+	}
+	l.source.Status.Success()
+	go l.run()
+}
+
+// Stop stops the listener from accepting new connections and all the active tailers.
+// The socket file is removed when the listener is closed.
+func (l *UnixListener) Stop() {
+	log.Infof("Stopping unix socket forwarder on %s", l.source.Config.Path)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.listener == nil {
+		return
+	}
+	l.stop <- struct{}{}
+	l.listener.Close()
+	stopper := startstop.NewParallelStopper()
+	for _, tailer := range l.tailers {
+		stopper.Add(tailer)
+	}
+	stopper.Stop()
+	l.tailers = []*tailer.Tailer{}
+}
+
+// run accepts new connections and create a dedicated tailer for each.
+func (l *UnixListener) run() {
+	for {
+		select {
+		case <-l.stop:
+			// stop accepting new connections.
+			return
+		default:
+			conn, err := l.listener.Accept()
+			switch {
+			case err != nil && isClosedConnError(err):
+				return
+			case err != nil:
+				log.Warnf("Can't accept connections on %s: %v", l.source.Config.Path, err)
+				l.source.Status.Error(err)
+				return
+			default:
+				l.startTailer(conn)
+				l.source.Status.Success()
+			}
+		}
+	}
+}
+
+// startListener creates the socket and starts listening on it.
+func (l *UnixListener) startListener() error {
+	if err := removeStaleSocket(l.source.Config.Path); err != nil {
+		return err
+	}
+	listener, err := net.Listen("unix", l.source.Config.Path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(l.source.Config.Path, socketFileMode); err != nil {
+		listener.Close()
+		return err
+	}
+	l.listener = listener
+	return nil
+}
+
+// read reads data from connection, returns an error if it failed and stop the tailer.
+func (l *UnixListener) read(tailer *tailer.Tailer) ([]byte, string, error) {
+	if l.idleTimeout > 0 {
+		tailer.Conn.SetReadDeadline(time.Now().Add(l.idleTimeout)) //nolint:errcheck
+	}
+	frame := make([]byte, l.frameSize)
+	n, err := tailer.Conn.Read(frame)
+	if err != nil {
+		go l.stopTailer(tailer)
+		return nil, "", err
+	}
+	// the peers of unix sockets don't have an address
+	return frame[:n], "", nil
+}
+
+// startTailer creates and starts a new tailer that reads from the connection.
+func (l *UnixListener) startTailer(conn net.Conn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tailer := tailer.NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.read)
+	l.tailers = append(l.tailers, tailer)
+	tailer.Start()
+}
+
+// stopTailer stops the tailer.
+func (l *UnixListener) stopTailer(tailer *tailer.Tailer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, t := range l.tailers {
+		if t == tailer {
+			tailer.Stop()
+			l.tailers = append(l.tailers[:i], l.tailers[i+1:]...)
+			break
+		}
+	}
+}
+
+// A UnixDatagramListener reads the datagrams received on a unix datagram socket, e.g.
+// /dev/log, with a tailer. Like with UDP, the datagrams bigger than the read buffer are
+// truncated.
+type UnixDatagramListener struct {
+	pipelineProvider pipeline.Provider
+	source           *sources.LogSource
+	frameSize        int
+	tailer           *tailer.Tailer
+	conn             *net.UnixConn
+}
+
+// NewUnixDatagramListener returns an initialized UnixDatagramListener
+func NewUnixDatagramListener(pipelineProvider pipeline.Provider, source *sources.LogSource, frameSize int) *UnixDatagramListener {
+	return &UnixDatagramListener{
+		pipelineProvider: pipelineProvider,
+		source:           source,
+		frameSize:        frameSize,
+	}
+}
+
+// Start creates the socket and starts a tailer.
+func (l *UnixDatagramListener) Start() {
+	log.Infof("Starting unix datagram socket forwarder on %s, with read buffer size: %d", l.source.Config.Path, l.frameSize)
+	err := l.startNewTailer()
+	if err != nil {
+		log.Errorf("Can't start unix datagram socket forwarder on %s: %v", l.source.Config.Path, err)
+		l.source.Status.Error(err)
+		return
+	}
+	l.source.Status.Success()
+}
+
+// Stop stops the tailer and removes the socket file.
+func (l *UnixDatagramListener) Stop() {
+	if l.tailer != nil {
+		log.Infof("Stopping unix datagram socket forwarder on %s", l.source.Config.Path)
+		l.tailer.Stop()
+		os.Remove(l.source.Config.Path)
+	}
+}
+
+// startNewTailer creates the socket and starts a new Tailer
+func (l *UnixDatagramListener) startNewTailer() error {
+	if err := removeStaleSocket(l.source.Config.Path); err != nil {
+		return err
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: l.source.Config.Path, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(l.source.Config.Path, socketFileMode); err != nil {
+		conn.Close()
+		return err
+	}
+	l.conn = conn
+	l.tailer = tailer.NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.read)
+	l.tailer.Start()
+	return nil
+}
+
+// read reads a datagram from the socket.
+func (l *UnixDatagramListener) read(_ *tailer.Tailer) ([]byte, string, error) {
+	frame := make([]byte, l.frameSize+1)
+	n, _, err := l.conn.ReadFromUnix(frame)
+	if err != nil {
+		if !isClosedConnError(err) {
+			l.source.Status.Error(err)
+		}
+		return nil, "", err
+	}
+	return terminateDatagram(frame, n, l.frameSize), "", nil
+}
+
+// removeStaleSocket removes the socket file left by a previous run, it returns an error
+// if the path exists and is not a socket.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s already exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
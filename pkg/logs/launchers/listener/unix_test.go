@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+// socketPath returns a socket path short enough for the limit of the unix socket addresses.
+func socketPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "logs")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "app.sock")
+}
+
+func TestUnixShouldReceiveMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	path := socketPath(t)
+	listener := NewUnixListener(pp, sources.NewLogSource("", &config.LogsConfig{Type: config.UnixType, Path: path}), 9000)
+	listener.Start()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(socketFileMode), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "hello world\n")
+	msg := <-msgChan
+	assert.Equal(t, "hello world", string(msg.GetContent()))
+	assert.Equal(t, 1, len(listener.tailers))
+
+	listener.Stop()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUnixShouldReplaceStaleSocket(t *testing.T) {
+	path := socketPath(t)
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	// leave the socket file behind like a crashed process
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	listener := NewUnixListener(pp, sources.NewLogSource("", &config.LogsConfig{Type: config.UnixType, Path: path}), 9000)
+	listener.Start()
+	defer listener.Stop()
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprintf(conn, "hello world\n")
+	msg := <-msgChan
+	assert.Equal(t, "hello world", string(msg.GetContent()))
+}
+
+func TestUnixShouldNotReplaceOtherFiles(t *testing.T) {
+	path := socketPath(t)
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+
+	pp := mock.NewMockProvider()
+	source := sources.NewLogSource("", &config.LogsConfig{Type: config.UnixType, Path: path})
+	listener := NewUnixListener(pp, source, 9000)
+	listener.Start()
+	defer listener.Stop()
+
+	assert.True(t, source.Status.IsError())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+}
+
+func TestUnixDatagramShouldReceiveMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	frameSize := 100
+	path := socketPath(t)
+	listener := NewUnixDatagramListener(pp, sources.NewLogSource("", &config.LogsConfig{Type: config.UnixType, Path: path, SocketType: config.DatagramSocketType}), frameSize)
+	listener.Start()
+
+	conn, err := net.Dial("unixgram", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var msg *message.Message
+	fmt.Fprint(conn, strings.Repeat("a", 10))
+	msg = <-msgChan
+	assert.Equal(t, strings.Repeat("a", 10), string(msg.GetContent()))
+
+	fmt.Fprint(conn, strings.Repeat("b", 10)+"\n"+strings.Repeat("c", 10))
+	msg = <-msgChan
+	assert.Equal(t, strings.Repeat("b", 10), string(msg.GetContent()))
+	msg = <-msgChan
+	assert.Equal(t, strings.Repeat("c", 10), string(msg.GetContent()))
+
+	fmt.Fprint(conn, strings.Repeat("d", frameSize+10))
+	msg = <-msgChan
+	assert.Equal(t, strings.Repeat("d", frameSize), string(msg.GetContent()))
+
+	listener.Stop()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUnixDatagramShouldParseSyslogMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	path := socketPath(t)
+	listener := NewUnixDatagramListener(pp, sources.NewLogSource("", &config.LogsConfig{Type: config.UnixType, Path: path, SocketType: config.DatagramSocketType, Format: config.SyslogFormat}), 9000)
+	listener.Start()
+	defer listener.Stop()
+
+	conn, err := net.Dial("unixgram", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	fmt.Fprint(conn, "<11>Jan  2 15:04:05 myhost app[123]: something failed")
+	msg := <-msgChan
+	assert.Equal(t, "something failed", string(msg.GetContent()))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+}
@@ -50,6 +50,9 @@ type Message struct {
 	IsMultiLine bool
 	// Tags added on processing
 	ProcessingTags []string
+	// ParsedTimestamp is the time of the log parsed from its content, e.g. by the syslog
+	// parser or the parsing rules. Must be UTC. Zero if the content has no timestamp.
+	ParsedTimestamp time.Time
 	// Extra information from the parsers
 	ParsingExtra
 	// Extra information for Serverless Logs messages
@@ -155,6 +158,14 @@ func (m *MessageContent) SetRendered(content []byte) {
 	m.State = StateRendered
 }
 
+// SetStructured sets the structured content for the MessageContent and sets MessageContent state to structured.
+// E.g. used by the processor when a parsing rule extracted attributes from an unstructured log.
+func (m *MessageContent) SetStructured(content StructuredContent) {
+	m.structuredContent = content
+	m.content = nil
+	m.State = StateStructured
+}
+
 // SetEncoded sets the content for the MessageContent and sets MessageContent state to encoded.
 func (m *MessageContent) SetEncoded(content []byte) {
 	m.content = content
@@ -349,6 +360,18 @@ func (m *Message) GetStatus() string {
 	return m.Status
 }
 
+// GetTimestamp returns the time of the log, parsed from its content or provided by the
+// Serverless Agent, or the current time if it is unknown.
+func (m *Message) GetTimestamp() time.Time {
+	if !m.ParsedTimestamp.IsZero() {
+		return m.ParsedTimestamp
+	}
+	if !m.ServerlessExtra.Timestamp.IsZero() {
+		return m.ServerlessExtra.Timestamp
+	}
+	return time.Now().UTC()
+}
+
 // GetLatency returns the latency delta from ingestion time until now
 func (m *Message) GetLatency() int64 {
 	return time.Now().UnixNano() - m.IngestionTimestamp
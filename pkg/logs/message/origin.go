@@ -16,9 +16,13 @@ type Origin struct {
 	Identifier string
 	LogSource  *sources.LogSource
 	Offset     string
-	service    string
-	source     string
-	tags       []string
+	// Fingerprint identifies the content of the file the log comes from, if any
+	Fingerprint string
+	// Completed is true for the last log of a compressed file read until its end
+	Completed bool
+	service   string
+	source    string
+	tags      []string
 }
 
 // NewOrigin returns a new Origin
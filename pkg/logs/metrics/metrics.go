@@ -25,6 +25,16 @@ var (
 	// TlmLogsProcessed is the total number of processed logs.
 	TlmLogsProcessed = telemetry.NewCounter("logs", "processed",
 		nil, "Total number of processed logs")
+	// LogsDeduplicated is the total number of logs dropped by the deduplicate rules.
+	LogsDeduplicated = expvar.Int{}
+	// TlmLogsDeduplicated is the total number of logs dropped by the deduplicate rules.
+	TlmLogsDeduplicated = telemetry.NewCounter("logs", "deduplicated",
+		[]string{"rule"}, "Total number of logs dropped by the deduplicate rules")
+	// LogsRateLimited is the total number of logs dropped by the rate_limit rules.
+	LogsRateLimited = expvar.Int{}
+	// TlmLogsRateLimited is the total number of logs dropped by the rate_limit rules.
+	TlmLogsRateLimited = telemetry.NewCounter("logs", "rate_limited",
+		[]string{"rule"}, "Total number of logs dropped by the rate_limit rules")
 
 	// LogsSent is the total number of sent logs.
 	LogsSent = expvar.Int{}
@@ -76,6 +86,8 @@ func init() {
 	LogsExpvars = expvar.NewMap("logs-agent")
 	LogsExpvars.Set("LogsDecoded", &LogsDecoded)
 	LogsExpvars.Set("LogsProcessed", &LogsProcessed)
+	LogsExpvars.Set("LogsDeduplicated", &LogsDeduplicated)
+	LogsExpvars.Set("LogsRateLimited", &LogsRateLimited)
 	LogsExpvars.Set("LogsSent", &LogsSent)
 	LogsExpvars.Set("DestinationErrors", &DestinationErrors)
 	LogsExpvars.Set("DestinationLogsDropped", &DestinationLogsDropped)
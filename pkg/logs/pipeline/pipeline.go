@@ -9,6 +9,8 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/comp/core/hostname/hostnameinterface"
@@ -22,8 +24,21 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/processor"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
 	"github.com/DataDog/datadog-agent/pkg/logs/status/statusinterface"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// OTLPDestinationFactory returns the destination of an OTLP endpoint.
+type OTLPDestinationFactory func(endpoint config.Endpoint, destinationsContext *client.DestinationsContext, maxConcurrentBackgroundSends int, shouldRetry bool, telemetryName string, cfg pkgconfigmodel.Reader) client.Destination
+
+// otlpDestinationFactory is registered by the agents sending logs to OTLP endpoints, which
+// keeps the OpenTelemetry dependencies out of the pipelines of the other agents.
+var otlpDestinationFactory OTLPDestinationFactory
+
+// RegisterOTLPDestinationFactory registers the factory of the destinations of the OTLP endpoints.
+func RegisterOTLPDestinationFactory(factory OTLPDestinationFactory) {
+	otlpDestinationFactory = factory
+}
+
 // Pipeline processes and sends messages to the backend
 type Pipeline struct {
 	InputChan  chan *message.Message
@@ -45,6 +60,7 @@ func NewPipeline(outputChan chan *message.Payload,
 	pipelineID int,
 	status statusinterface.Status,
 	hostname hostnameinterface.Component,
+	metricSender processor.MetricSender,
 	cfg pkgconfigmodel.Reader) *Pipeline {
 
 	var senderDoneChan chan *sync.WaitGroup
@@ -74,10 +90,14 @@ func NewPipeline(outputChan chan *message.Payload,
 	}
 
 	strategy := getStrategy(strategyInput, senderInput, flushChan, endpoints, serverless, flushWg, pipelineID)
-	logsSender = sender.NewSender(cfg, senderInput, outputChan, mainDestinations, config.DestinationPayloadChanSize, senderDoneChan, flushWg)
+	var spool *sender.DiskSpool
+	if !serverless && cfg != nil && cfg.GetBool("logs_config.disk_spool.enabled") {
+		spool = getDiskSpool(pipelineID, cfg)
+	}
+	logsSender = sender.NewSender(cfg, senderInput, outputChan, mainDestinations, config.DestinationPayloadChanSize, senderDoneChan, flushWg, spool)
 
 	inputChan := make(chan *message.Message, config.ChanSize)
-	processor := processor.New(inputChan, strategyInput, processingRules, encoder, diagnosticMessageReceiver, hostname, pipelineID)
+	processor := processor.New(inputChan, strategyInput, processingRules, encoder, diagnosticMessageReceiver, hostname, metricSender, pipelineID)
 
 	return &Pipeline{
 		InputChan:  inputChan,
@@ -115,6 +135,22 @@ func (p *Pipeline) Flush(ctx context.Context) {
 	}
 }
 
+// getDiskSpool returns the disk spool of the given pipeline, or nil if it can't be created.
+func getDiskSpool(pipelineID int, cfg pkgconfigmodel.Reader) *sender.DiskSpool {
+	path := cfg.GetString("logs_config.disk_spool.path")
+	if path == "" {
+		path = filepath.Join(cfg.GetString("logs_config.run_path"), "logs_spool")
+	}
+	path = filepath.Join(path, strconv.Itoa(pipelineID))
+	maxSizeInBytes := int64(cfg.GetInt("logs_config.disk_spool.max_size_mb")) * 1024 * 1024
+	spool, err := sender.NewDiskSpool(path, maxSizeInBytes, cfg.GetDuration("logs_config.disk_spool.max_age"))
+	if err != nil {
+		log.Errorf("Could not create the logs disk spool in %s, payloads won't be spooled: %v", path, err)
+		return nil
+	}
+	return spool
+}
+
 func getDestinations(endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, pipelineID int, serverless bool, senderDoneChan chan *sync.WaitGroup, status statusinterface.Status, cfg pkgconfigmodel.Reader) *client.Destinations {
 	reliable := []client.Destination{}
 	additionals := []client.Destination{}
@@ -122,7 +158,11 @@ func getDestinations(endpoints *config.Endpoints, destinationsContext *client.De
 	if endpoints.UseHTTP {
 		for i, endpoint := range endpoints.GetReliableEndpoints() {
 			telemetryName := fmt.Sprintf("logs_%d_reliable_%d", pipelineID, i)
-			if serverless {
+			if endpoint.UseOTLP {
+				if destination := getOTLPDestination(endpoint, destinationsContext, serverless, endpoints.BatchMaxConcurrentSend, true, telemetryName, cfg); destination != nil {
+					reliable = append(reliable, destination)
+				}
+			} else if serverless {
 				reliable = append(reliable, http.NewSyncDestination(endpoint, http.JSONContentType, destinationsContext, senderDoneChan, telemetryName, cfg))
 			} else {
 				reliable = append(reliable, http.NewDestination(endpoint, http.JSONContentType, destinationsContext, endpoints.BatchMaxConcurrentSend, true, telemetryName, cfg))
@@ -130,7 +170,11 @@ func getDestinations(endpoints *config.Endpoints, destinationsContext *client.De
 		}
 		for i, endpoint := range endpoints.GetUnReliableEndpoints() {
 			telemetryName := fmt.Sprintf("logs_%d_unreliable_%d", pipelineID, i)
-			if serverless {
+			if endpoint.UseOTLP {
+				if destination := getOTLPDestination(endpoint, destinationsContext, serverless, endpoints.BatchMaxConcurrentSend, false, telemetryName, cfg); destination != nil {
+					additionals = append(additionals, destination)
+				}
+			} else if serverless {
 				additionals = append(additionals, http.NewSyncDestination(endpoint, http.JSONContentType, destinationsContext, senderDoneChan, telemetryName, cfg))
 			} else {
 				additionals = append(additionals, http.NewDestination(endpoint, http.JSONContentType, destinationsContext, endpoints.BatchMaxConcurrentSend, false, telemetryName, cfg))
@@ -139,15 +183,33 @@ func getDestinations(endpoints *config.Endpoints, destinationsContext *client.De
 		return client.NewDestinations(reliable, additionals)
 	}
 	for _, endpoint := range endpoints.GetReliableEndpoints() {
+		if endpoint.UseOTLP {
+			log.Warnf("Logs can't be sent to the OTLP endpoint %s over TCP", endpoint.Host)
+			continue
+		}
 		reliable = append(reliable, tcp.NewDestination(endpoint, endpoints.UseProto, destinationsContext, !serverless, status))
 	}
 	for _, endpoint := range endpoints.GetUnReliableEndpoints() {
+		if endpoint.UseOTLP {
+			log.Warnf("Logs can't be sent to the OTLP endpoint %s over TCP", endpoint.Host)
+			continue
+		}
 		additionals = append(additionals, tcp.NewDestination(endpoint, endpoints.UseProto, destinationsContext, false, status))
 	}
 
 	return client.NewDestinations(reliable, additionals)
 }
 
+// getOTLPDestination returns the destination of an OTLP endpoint, or nil if OTLP is not supported by
+// the agent, e.g. the serverless agent.
+func getOTLPDestination(endpoint config.Endpoint, destinationsContext *client.DestinationsContext, serverless bool, maxConcurrentBackgroundSends int, shouldRetry bool, telemetryName string, cfg pkgconfigmodel.Reader) client.Destination {
+	if serverless || otlpDestinationFactory == nil {
+		log.Warnf("Logs can't be sent to the OTLP endpoint %s by this agent", endpoint.Host)
+		return nil
+	}
+	return otlpDestinationFactory(endpoint, destinationsContext, maxConcurrentBackgroundSends, shouldRetry, telemetryName, cfg)
+}
+
 //nolint:revive // TODO(AML) Fix revive linter
 func getStrategy(inputChan chan *message.Message, outputChan chan *message.Payload, flushChan chan struct{}, endpoints *config.Endpoints, serverless bool, flushWg *sync.WaitGroup, _ int) sender.Strategy {
 	if endpoints.UseHTTP || serverless {
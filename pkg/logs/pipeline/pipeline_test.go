@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
+)
+
+func TestGetOTLPDestinations(t *testing.T) {
+	defer RegisterOTLPDestinationFactory(nil)
+	cfg := pkgconfigmodel.NewConfig("test", "DD", strings.NewReplacer(".", "_"))
+	destinationsCtx := client.NewDestinationsContext()
+
+	main := config.NewEndpoint("", "intake", 443, true)
+	collector := config.NewEndpoint("", "collector", 4318, false)
+	collector.UseOTLP = true
+
+	// the OTLP endpoints are skipped when no factory is registered
+	destinations := getDestinations(config.NewEndpoints(main, []config.Endpoint{collector}, false, true), destinationsCtx, 0, false, nil, nil, cfg)
+	require.Len(t, destinations.Reliable, 1)
+	assert.IsType(t, &http.Destination{}, destinations.Reliable[0])
+
+	var otlpTargets []string
+	RegisterOTLPDestinationFactory(func(endpoint config.Endpoint, destinationsContext *client.DestinationsContext, maxConcurrentBackgroundSends int, shouldRetry bool, telemetryName string, cfg pkgconfigmodel.Reader) client.Destination {
+		otlpTargets = append(otlpTargets, endpoint.Host)
+		return http.NewDestination(endpoint, http.JSONContentType, destinationsContext, maxConcurrentBackgroundSends, shouldRetry, telemetryName, cfg)
+	})
+	destinations = getDestinations(config.NewEndpoints(main, []config.Endpoint{collector}, false, true), destinationsCtx, 0, false, nil, nil, cfg)
+	assert.Len(t, destinations.Reliable, 2)
+	assert.Equal(t, []string{"collector"}, otlpTargets)
+
+	// OTLP is not supported by the serverless agent nor over TCP
+	otlpTargets = nil
+	destinations = getDestinations(config.NewEndpoints(main, []config.Endpoint{collector}, false, true), destinationsCtx, 0, true, nil, nil, cfg)
+	assert.Len(t, destinations.Reliable, 1)
+	destinations = getDestinations(config.NewEndpoints(main, []config.Endpoint{collector}, false, false), destinationsCtx, 0, false, nil, nil, cfg)
+	assert.Len(t, destinations.Reliable, 1)
+	assert.Empty(t, otlpTargets)
+}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// metricsCommitInterval is the interval at which the metrics generated
+// from logs are committed to the aggregator.
+const metricsCommitInterval = time.Second
+
+// MetricSender submits the metrics generated by the generate_metric processing rules.
+type MetricSender interface {
+	Count(metric string, value float64, hostname string, tags []string)
+	Gauge(metric string, value float64, hostname string, tags []string)
+	Distribution(metric string, value float64, hostname string, tags []string)
+}
+
+// CommittableMetricSender is a MetricSender whose metrics are sent once committed.
+// It is implemented by the aggregator sender.
+type CommittableMetricSender interface {
+	MetricSender
+	Commit()
+}
+
+// MetricsCommitter is the MetricSender shared by the processors of all the pipelines. It submits
+// the metrics to a sender dedicated to the metrics generated from logs, and commits them every
+// metricsCommitInterval from a single goroutine.
+type MetricsCommitter struct {
+	sender  CommittableMetricSender
+	pending atomic.Bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewMetricsCommitter returns a new MetricsCommitter submitting the metrics to the given sender.
+func NewMetricsCommitter(sender CommittableMetricSender) *MetricsCommitter {
+	return &MetricsCommitter{
+		sender: sender,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Count submits a count.
+func (c *MetricsCommitter) Count(metric string, value float64, hostname string, tags []string) {
+	c.sender.Count(metric, value, hostname, tags)
+	c.pending.Store(true)
+}
+
+// Gauge submits a gauge.
+func (c *MetricsCommitter) Gauge(metric string, value float64, hostname string, tags []string) {
+	c.sender.Gauge(metric, value, hostname, tags)
+	c.pending.Store(true)
+}
+
+// Distribution submits a distribution.
+func (c *MetricsCommitter) Distribution(metric string, value float64, hostname string, tags []string) {
+	c.sender.Distribution(metric, value, hostname, tags)
+	c.pending.Store(true)
+}
+
+// Start starts committing the submitted metrics periodically.
+func (c *MetricsCommitter) Start() {
+	go func() {
+		ticker := time.NewTicker(metricsCommitInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.commit()
+			case <-c.stop:
+				c.commit()
+				close(c.done)
+				return
+			}
+		}
+	}()
+}
+
+// Stop commits the pending metrics and stops committing them.
+func (c *MetricsCommitter) Stop() {
+	close(c.stop)
+	<-c.done
+}
+
+// commit commits the metrics submitted since the last commit.
+func (c *MetricsCommitter) commit() {
+	if c.pending.Swap(false) {
+		c.sender.Commit()
+	}
+}
+
+// generateMetric submits the metric of the rule if the content matches its pattern,
+// returns true if a metric has been submitted.
+func (p *Processor) generateMetric(rule *config.ProcessingRule, msg *message.Message, content []byte) bool {
+	if p.metricSender == nil {
+		return false
+	}
+
+	value := 1.0
+	if index := rule.MetricValueGroupIndex(); index > 0 {
+		matches := rule.Regex.FindSubmatch(content)
+		if matches == nil || matches[index] == nil {
+			return false
+		}
+		var err error
+		if value, err = strconv.ParseFloat(string(matches[index]), 64); err != nil {
+			log.Debugf("Can't parse the value of the metric %s from the processing rule %s: %v", rule.MetricName, rule.Name, err)
+			return false
+		}
+	} else if !rule.Regex.Match(content) {
+		return false
+	}
+
+	tags := metricTags(msg)
+	switch rule.MetricType {
+	case config.GaugeMetricType:
+		p.metricSender.Gauge(rule.MetricName, value, "", tags)
+	case config.DistributionMetricType:
+		p.metricSender.Distribution(rule.MetricName, value, "", tags)
+	default:
+		p.metricSender.Count(rule.MetricName, value, "", tags)
+	}
+	return true
+}
+
+// metricTags returns the tags of the metrics generated from the given message.
+func metricTags(msg *message.Message) []string {
+	originTags := msg.Tags()
+	tags := make([]string, 0, len(originTags)+2)
+	if service := msg.Origin.Service(); service != "" {
+		tags = append(tags, "service:"+service)
+	}
+	if source := msg.Origin.Source(); source != "" {
+		tags = append(tags, "source:"+source)
+	}
+	return append(tags, originTags...)
+}
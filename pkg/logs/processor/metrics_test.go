@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+type submittedMetric struct {
+	metricType string
+	name       string
+	value      float64
+	tags       []string
+}
+
+type fakeMetricSender struct {
+	metrics []submittedMetric
+	commits int
+}
+
+func (s *fakeMetricSender) Count(metric string, value float64, _ string, tags []string) {
+	s.metrics = append(s.metrics, submittedMetric{config.CountMetricType, metric, value, tags})
+}
+
+func (s *fakeMetricSender) Gauge(metric string, value float64, _ string, tags []string) {
+	s.metrics = append(s.metrics, submittedMetric{config.GaugeMetricType, metric, value, tags})
+}
+
+func (s *fakeMetricSender) Distribution(metric string, value float64, _ string, tags []string) {
+	s.metrics = append(s.metrics, submittedMetric{config.DistributionMetricType, metric, value, tags})
+}
+
+func (s *fakeMetricSender) Commit() {
+	s.commits++
+}
+
+func TestGenerateMetric(t *testing.T) {
+	rules := []*config.ProcessingRule{
+		{Name: "drop", Type: config.ExcludeAtMatch, Pattern: "healthcheck"},
+		{Name: "errors", Type: config.GenerateMetric, Pattern: "ERROR", MetricName: "app.errors"},
+		{Name: "latency", Type: config.GenerateMetric, Pattern: `took (?P<ms>\d+(\.\d+)?)ms`, MetricName: "app.latency", MetricType: config.DistributionMetricType, ValueGroup: "ms"},
+		{Name: "queue", Type: config.GenerateMetric, Pattern: `queue=(\w+)`, MetricName: "app.queue", MetricType: config.GaugeMetricType, ValueGroup: "1"},
+	}
+	require.NoError(t, config.ValidateProcessingRules(rules))
+	require.NoError(t, config.CompileProcessingRules(rules))
+	source := sources.NewLogSource("", &config.LogsConfig{ProcessingRules: rules, Service: "app", Source: "go", Tags: []string{"env:prod"}})
+
+	metricSender := &fakeMetricSender{}
+	p := &Processor{metricSender: metricSender}
+	tags := []string{"service:app", "source:go", "env:prod"}
+
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("ERROR request took 12.5ms queue=3"), source, "")))
+	assert.False(t, p.applyRedactingRules(newMessage([]byte("ERROR healthcheck took 1ms"), source, "")))
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("INFO request took 3ms queue=full"), source, "")))
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("INFO nothing to see"), source, "")))
+
+	assert.Equal(t, []submittedMetric{
+		{config.CountMetricType, "app.errors", 1, tags},
+		{config.DistributionMetricType, "app.latency", 12.5, tags},
+		{config.GaugeMetricType, "app.queue", 3, tags},
+		{config.DistributionMetricType, "app.latency", 3, tags},
+	}, metricSender.metrics)
+
+}
+
+func TestMetricsCommitter(t *testing.T) {
+	metricSender := &fakeMetricSender{}
+	committer := NewMetricsCommitter(metricSender)
+
+	// the pending metrics are committed once
+	committer.Count("app.errors", 1, "", nil)
+	committer.commit()
+	committer.commit()
+	assert.Equal(t, 1, metricSender.commits)
+
+	// and when the committer stops
+	committer.Start()
+	committer.Gauge("app.queue", 3, "", nil)
+	committer.Stop()
+	assert.Equal(t, 2, metricSender.commits)
+	assert.Len(t, metricSender.metrics, 2)
+}
+
+func TestGenerateMetricWithoutSender(t *testing.T) {
+	source := newSource(config.GenerateMetric, "", "ERROR")
+	p := &Processor{}
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("ERROR"), &source, "")))
+}
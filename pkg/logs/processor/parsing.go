@@ -0,0 +1,301 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// default fields looked up by the parsing rules when the
+// rule does not configure them explicitly.
+var (
+	defaultMessageFields   = []string{"message", "msg"}
+	defaultStatusFields    = []string{"status", "level", "severity"}
+	defaultTimestampFields = []string{"timestamp", "time", "ts"}
+)
+
+// statusAliases maps the usual log level names to the statuses supported by the intake.
+var statusAliases = map[string]string{
+	"emerg":   message.StatusEmergency,
+	"fatal":   message.StatusCritical,
+	"crit":    message.StatusCritical,
+	"err":     message.StatusError,
+	"warning": message.StatusWarning,
+	"trace":   message.StatusDebug,
+}
+
+// applyParsingRule parses the message content with the given parsing rule and
+// lifts the parsed fields into the message attributes, status and timestamp.
+// It returns the new content and the lifted attributes, which the redacting rules
+// following the parsing rule must also apply to before the tags of the tag fields
+// are added with appendTagFields.
+// The message is left untouched if its content can't be parsed.
+func applyParsingRule(rule *config.ProcessingRule, msg *message.Message, content []byte) ([]byte, map[string]interface{}) {
+	// structured messages already carry their attributes
+	if msg.State != message.StateUnstructured {
+		return content, nil
+	}
+	if rule.Regex != nil && rule.Type != config.GrokParsing && !rule.Regex.Match(content) {
+		return content, nil
+	}
+
+	var fields map[string]interface{}
+	switch rule.Type {
+	case config.JSONParsing:
+		fields = parseJSON(content)
+	case config.LogfmtParsing:
+		fields = parseLogfmt(content)
+	case config.GrokParsing:
+		fields = parseGrok(rule, content)
+	}
+	if len(fields) == 0 {
+		return content, nil
+	}
+
+	if key, value, found := lookupField(fields, rule.StatusField, defaultStatusFields); found {
+		if status := normalizeStatus(value); status != "" {
+			msg.Status = status
+			delete(fields, key)
+		}
+	}
+	if key, value, found := lookupField(fields, rule.TimestampField, defaultTimestampFields); found {
+		if ts, err := parseTimestamp(value, rule.TimestampFormat); err == nil {
+			msg.ParsedTimestamp = ts
+			delete(fields, key)
+		}
+	}
+	if key, value, found := lookupField(fields, rule.MessageField, defaultMessageFields); found {
+		delete(fields, key)
+		content = []byte(fieldToString(value))
+	}
+	fields["message"] = string(content)
+
+	msg.SetStructured(&message.BasicStructuredContent{Data: fields})
+	return content, fields
+}
+
+// appendTagFields adds the values of the tag fields of a parsing rule to the message tags,
+// from the attributes it lifted once they have been redacted.
+func appendTagFields(msg *message.Message, tagFields []string, attributes map[string]interface{}) {
+	for _, tagField := range tagFields {
+		if value, exists := attributes[tagField]; exists {
+			msg.ProcessingTags = append(msg.ProcessingTags, tagField+":"+fieldToString(value))
+		}
+	}
+}
+
+// redactAttributes applies redact to the values of the attributes lifted by a parsing rule,
+// except for the message which is redacted with the content. Non-string values are redacted
+// through their JSON representation, and replaced by the redacted string if it changed.
+func redactAttributes(attributes map[string]interface{}, redact func([]byte) []byte) {
+	for key, value := range attributes {
+		if key != "message" {
+			attributes[key] = redactValue(value, redact)
+		}
+	}
+}
+
+func redactValue(value interface{}, redact func([]byte) []byte) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return v
+	case string:
+		return string(redact([]byte(v)))
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = redactValue(elem, redact)
+		}
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = redactValue(elem, redact)
+		}
+		return v
+	default:
+		s := fieldToString(v)
+		if redacted := string(redact([]byte(s))); redacted != s {
+			return redacted
+		}
+		return v
+	}
+}
+
+// parseJSON returns the fields of a JSON object, or nil if the content isn't one.
+func parseJSON(content []byte) map[string]interface{} {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// parseLogfmt returns the key/value pairs of a logfmt formatted content,
+// e.g. `level=info msg="hello world" duration=12ms`.
+// A key without value is set to true. The content is not considered as logfmt
+// unless it has at least one key=value pair, so that plain text isn't parsed.
+func parseLogfmt(content []byte) map[string]interface{} {
+	fields := make(map[string]interface{})
+	pairs := 0
+	i := 0
+	for i < len(content) {
+		// skip the separators
+		for i < len(content) && content[i] <= ' ' {
+			i++
+		}
+		start := i
+		for i < len(content) && content[i] > ' ' && content[i] != '=' && content[i] != '"' {
+			i++
+		}
+		key := string(content[start:i])
+		if key == "" {
+			// garbage, e.g. a quote or an equal sign without a key
+			return nil
+		}
+		if i >= len(content) || content[i] != '=' {
+			fields[key] = true
+			continue
+		}
+		i++ // skip '='
+		pairs++
+		if i < len(content) && content[i] == '"' {
+			end := i + 1
+			for end < len(content) && content[end] != '"' {
+				if content[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(content) {
+				return nil
+			}
+			value, err := strconv.Unquote(string(content[i : end+1]))
+			if err != nil {
+				return nil
+			}
+			fields[key] = value
+			i = end + 1
+			continue
+		}
+		start = i
+		for i < len(content) && content[i] > ' ' {
+			i++
+		}
+		fields[key] = string(content[start:i])
+	}
+	if pairs == 0 {
+		return nil
+	}
+	return fields
+}
+
+// parseGrok returns the named groups captured by the grok pattern of the rule.
+func parseGrok(rule *config.ProcessingRule, content []byte) map[string]interface{} {
+	matches := rule.Regex.FindSubmatch(content)
+	if matches == nil {
+		return nil
+	}
+	fields := make(map[string]interface{})
+	for i, name := range rule.Regex.SubexpNames() {
+		if name == "" || matches[i] == nil {
+			continue
+		}
+		fields[config.GrokFieldName(name)] = string(matches[i])
+	}
+	return fields
+}
+
+// lookupField returns the configured field if any, otherwise the first default field found.
+func lookupField(fields map[string]interface{}, configured string, defaults []string) (string, interface{}, bool) {
+	if configured != "" {
+		value, exists := fields[configured]
+		return configured, value, exists
+	}
+	for _, key := range defaults {
+		if value, exists := fields[key]; exists {
+			return key, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// normalizeStatus returns the message status matching the given level,
+// or an empty string if it doesn't look like a level.
+func normalizeStatus(value interface{}) string {
+	level, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	level = strings.ToLower(strings.TrimSpace(level))
+	if status, exists := statusAliases[level]; exists {
+		return status
+	}
+	switch level {
+	case message.StatusEmergency, message.StatusAlert, message.StatusCritical, message.StatusError,
+		message.StatusWarning, message.StatusNotice, message.StatusInfo, message.StatusDebug:
+		return level
+	}
+	return ""
+}
+
+// parseTimestamp parses the given value with the given layout (RFC3339 by default).
+// Numbers are considered as UNIX timestamps in seconds or in milliseconds.
+func parseTimestamp(value interface{}, layout string) (time.Time, error) {
+	switch v := value.(type) {
+	case float64:
+		return epochToTime(v), nil
+	case string:
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		if ts, err := time.Parse(layout, v); err == nil {
+			return ts.UTC(), nil
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return epochToTime(f), nil
+		}
+		return time.Time{}, fmt.Errorf("can't parse timestamp %q with layout %q", v, layout)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp type %T", value)
+	}
+}
+
+// epochToTime converts a UNIX timestamp in seconds or in milliseconds to a time.
+func epochToTime(epoch float64) time.Time {
+	// timestamps greater than 1e11 seconds are after year 5000,
+	// they are most likely expressed in milliseconds.
+	if epoch > 1e11 {
+		return time.UnixMilli(int64(epoch)).UTC()
+	}
+	return time.Unix(0, int64(epoch*float64(time.Second))).UTC()
+}
+
+// fieldToString converts a parsed field to a string.
+func fieldToString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+}
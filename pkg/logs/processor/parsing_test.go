@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func newParsingSource(t *testing.T, rule *config.ProcessingRule) *sources.LogSource {
+	rule.Name = "test"
+	rules := []*config.ProcessingRule{rule}
+	require.NoError(t, config.ValidateProcessingRules(rules))
+	require.NoError(t, config.CompileProcessingRules(rules))
+	return sources.NewLogSource("", &config.LogsConfig{ProcessingRules: rules})
+}
+
+func renderedFields(t *testing.T, msg *message.Message) map[string]interface{} {
+	rendered, err := msg.Render()
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(rendered, &fields))
+	return fields
+}
+
+func TestJSONParsing(t *testing.T) {
+	p := &Processor{}
+	source := newParsingSource(t, &config.ProcessingRule{Type: config.JSONParsing, TagFields: []string{"env"}})
+
+	msg := newMessage([]byte(`{"msg":"user logged in","level":"WARNING","ts":1700000000,"env":"prod","user":{"id":42}}`), source, "")
+	assert.True(t, p.applyRedactingRules(msg))
+	assert.Equal(t, message.StateStructured, msg.State)
+	assert.Equal(t, []byte("user logged in"), msg.GetContent())
+	assert.Equal(t, message.StatusWarning, msg.GetStatus())
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), msg.ParsedTimestamp)
+	assert.Equal(t, []string{"env:prod"}, msg.ProcessingTags)
+	assert.Equal(t, map[string]interface{}{
+		"message": "user logged in",
+		"env":     "prod",
+		"user":    map[string]interface{}{"id": float64(42)},
+	}, renderedFields(t, msg))
+
+	// not a JSON object, the message is left untouched
+	msg = newMessage([]byte(`not json {"a":1}`), source, "")
+	assert.True(t, p.applyRedactingRules(msg))
+	assert.Equal(t, message.StateUnstructured, msg.State)
+	assert.Equal(t, []byte(`not json {"a":1}`), msg.GetContent())
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+}
+
+func TestLogfmtParsing(t *testing.T) {
+	p := &Processor{}
+	source := newParsingSource(t, &config.ProcessingRule{Type: config.LogfmtParsing, MessageField: "event"})
+
+	msg := newMessage([]byte(`time=2024-03-01T10:00:00.5Z level=error event="db \"timeout\"" retry duration=12ms`), source, "")
+	assert.True(t, p.applyRedactingRules(msg))
+	assert.Equal(t, []byte(`db "timeout"`), msg.GetContent())
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 500000000, time.UTC), msg.ParsedTimestamp)
+	assert.Equal(t, map[string]interface{}{
+		"message":  `db "timeout"`,
+		"retry":    true,
+		"duration": "12ms",
+	}, renderedFields(t, msg))
+
+	assert.Nil(t, parseLogfmt([]byte(`key="unterminated`)))
+	assert.Nil(t, parseLogfmt([]byte(`=value`)))
+
+	// plain text is not logfmt
+	assert.Nil(t, parseLogfmt([]byte(`Starting server`)))
+	msg = newMessage([]byte(`Starting server`), source, "")
+	assert.True(t, p.applyRedactingRules(msg))
+	assert.Equal(t, message.StateUnstructured, msg.State)
+	assert.Equal(t, []byte("Starting server"), msg.GetContent())
+}
+
+func TestGrokParsing(t *testing.T) {
+	p := &Processor{}
+	source := newParsingSource(t, &config.ProcessingRule{
+		Type:            config.GrokParsing,
+		Pattern:         `%{HTTPDATE:date} %{LOGLEVEL:level} %{WORD:http.method} %{URIPATHPARAM:http.url} %{GREEDYDATA:message}`,
+		TimestampField:  "date",
+		TimestampFormat: "02/Jan/2006:15:04:05 -0700",
+		TagFields:       []string{"http.method"},
+	})
+
+	msg := newMessage([]byte(`10/Oct/2023:13:55:36 +0200 info GET /api/v1/users?id=2 request served`), source, "")
+	assert.True(t, p.applyRedactingRules(msg))
+	assert.Equal(t, []byte("request served"), msg.GetContent())
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Equal(t, time.Date(2023, 10, 10, 11, 55, 36, 0, time.UTC), msg.ParsedTimestamp)
+	assert.Equal(t, []string{"http.method:GET"}, msg.ProcessingTags)
+	assert.Equal(t, map[string]interface{}{
+		"message":     "request served",
+		"http.method": "GET",
+		"http.url":    "/api/v1/users?id=2",
+	}, renderedFields(t, msg))
+
+	// not matching, the message is left untouched
+	msg = newMessage([]byte("something else"), source, "")
+	assert.True(t, p.applyRedactingRules(msg))
+	assert.Equal(t, message.StateUnstructured, msg.State)
+	assert.Equal(t, []byte("something else"), msg.GetContent())
+}
+
+func TestParsingThenMasking(t *testing.T) {
+	p := &Processor{}
+	parsing := &config.ProcessingRule{Name: "parse", Type: config.JSONParsing, TagFields: []string{"card"}}
+	masking := &config.ProcessingRule{Name: "mask", Type: config.MaskSequences, Pattern: `\d{4}`, ReplacePlaceholder: "[masked]"}
+	rules := []*config.ProcessingRule{parsing, masking}
+	require.NoError(t, config.CompileProcessingRules(rules))
+	source := sources.NewLogSource("", &config.LogsConfig{ProcessingRules: rules})
+
+	msg := newMessage([]byte(`{"message":"card 1234 used","card":"1234","pin":5678,"payment":{"cards":["4321"],"count":1}}`), source, "")
+	assert.True(t, p.applyRedactingRules(msg))
+	assert.Equal(t, []byte("card [masked] used"), msg.GetContent())
+	// the fields lifted as tags are masked as well
+	assert.Equal(t, []string{"card:[masked]"}, msg.ProcessingTags)
+	assert.Equal(t, map[string]interface{}{
+		"message": "card [masked] used",
+		"card":    "[masked]",
+		"pin":     "[masked]",
+		"payment": map[string]interface{}{
+			"cards": []interface{}{"[masked]"},
+			"count": float64(1),
+		},
+	}, renderedFields(t, msg))
+}
@@ -7,7 +7,9 @@ package processor
 
 import (
 	"context"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/hostname/hostnameinterface"
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
@@ -38,12 +40,18 @@ type Processor struct {
 	mu                        sync.Mutex
 	hostname                  hostnameinterface.Component
 
+	// metricSender submits the metrics generated by the generate_metric rules
+	metricSender MetricSender
+
+	// throttler holds the state of the deduplicate and rate_limit rules.
+	throttler throttler
+
 	sds *sds.Scanner // configured through RC
 }
 
 // New returns an initialized Processor.
 func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder,
-	diagnosticMessageReceiver diagnostic.MessageReceiver, hostname hostnameinterface.Component, pipelineID int) *Processor {
+	diagnosticMessageReceiver diagnostic.MessageReceiver, hostname hostnameinterface.Component, metricSender MetricSender, pipelineID int) *Processor {
 	sdsScanner := sds.CreateScanner(pipelineID)
 
 	return &Processor{
@@ -57,6 +65,7 @@ func New(inputChan, outputChan chan *message.Message, processingRules []*config.
 		sds:                       sdsScanner,
 		diagnosticMessageReceiver: diagnosticMessageReceiver,
 		hostname:                  hostname,
+		metricSender:              metricSender,
 	}
 }
 
@@ -98,7 +107,13 @@ func (p *Processor) Flush(ctx context.Context) {
 
 // run starts the processing of the inputChan
 func (p *Processor) run() {
+	throttlingTicker := time.NewTicker(throttlingInterval)
+	defer throttlingTicker.Stop()
+
 	defer func() {
+		// send the summaries of the pending deduplication windows
+		p.throttler.closeWindows()
+		p.sendSummaries()
 		p.done <- struct{}{}
 	}()
 
@@ -121,6 +136,11 @@ func (p *Processor) run() {
 				order.ResponseChan <- nil
 			}
 			p.mu.Unlock()
+		case now := <-throttlingTicker.C:
+			p.mu.Lock()
+			p.throttler.expire(now)
+			p.sendSummaries()
+			p.mu.Unlock()
 		}
 	}
 }
@@ -129,35 +149,57 @@ func (p *Processor) processMessage(msg *message.Message) {
 	metrics.LogsDecoded.Add(1)
 	metrics.TlmLogsDecoded.Inc()
 
-	if toSend := p.applyRedactingRules(msg); toSend {
+	toSend := p.applyRedactingRules(msg)
+
+	// the summaries of the deduplication windows closed by this message are sent first
+	p.sendSummaries()
+
+	if toSend {
 		metrics.LogsProcessed.Add(1)
 		metrics.TlmLogsProcessed.Inc()
+		p.sendMessage(msg)
+	}
+}
 
-		// render the message
-		rendered, err := msg.Render()
-		if err != nil {
-			log.Error("can't render the msg", err)
-			return
-		}
-		msg.SetRendered(rendered)
+// sendSummaries sends the summary logs of the closed deduplication windows.
+func (p *Processor) sendSummaries() {
+	for _, summary := range p.throttler.flushSummaries() {
+		p.sendMessage(summary)
+	}
+}
 
-		// report this message to diagnostic receivers (e.g. `stream-logs` command)
-		p.diagnosticMessageReceiver.HandleMessage(msg, rendered, "")
+// sendMessage renders and encodes the message before sending it to the strategy.
+func (p *Processor) sendMessage(msg *message.Message) {
+	// render the message
+	rendered, err := msg.Render()
+	if err != nil {
+		log.Error("can't render the msg", err)
+		return
+	}
+	msg.SetRendered(rendered)
 
-		// encode the message to its final format, it is done in-place
-		if err := p.encoder.Encode(msg, p.GetHostname(msg)); err != nil {
-			log.Error("unable to encode msg ", err)
-			return
-		}
+	// report this message to diagnostic receivers (e.g. `stream-logs` command)
+	p.diagnosticMessageReceiver.HandleMessage(msg, rendered, "")
 
-		p.outputChan <- msg
+	// encode the message to its final format, it is done in-place
+	if err := p.encoder.Encode(msg, p.GetHostname(msg)); err != nil {
+		log.Error("unable to encode msg ", err)
+		return
 	}
+
+	p.outputChan <- msg
 }
 
 // applyRedactingRules returns given a message if we should process it or not,
 // it applies the change directly on the Message content.
-func (p *Processor) applyRedactingRules(msg *message.Message) bool {
+func (p *Processor) applyRedactingRules(msg *message.Message) (toSend bool) {
+	// the deduplication windows opened by the message summarize its redacted content
+	defer func() { p.throttler.commitWindows(msg, toSend) }()
+
 	var content []byte = msg.GetContent()
+	// attributes lifted out of the content by a parsing rule, and its fields lifted as tags
+	var attributes map[string]interface{}
+	var tagFields []string
 
 	// Use the internal scrubbing implementation of the Agent
 	// ---------------------------
@@ -177,6 +219,25 @@ func (p *Processor) applyRedactingRules(msg *message.Message) bool {
 			}
 		case config.MaskSequences:
 			content = rule.Regex.ReplaceAll(content, rule.Placeholder)
+			redactAttributes(attributes, func(value []byte) []byte {
+				return rule.Regex.ReplaceAll(value, rule.Placeholder)
+			})
+		case config.JSONParsing, config.LogfmtParsing, config.GrokParsing:
+			var parsed map[string]interface{}
+			if content, parsed = applyParsingRule(rule, msg, content); parsed != nil {
+				attributes = parsed
+				tagFields = rule.TagFields
+			}
+		case config.GenerateMetric:
+			p.generateMetric(rule, msg, content)
+		case config.Deduplicate:
+			if p.throttler.isDuplicate(rule, msg, content, time.Now()) {
+				return false
+			}
+		case config.RateLimit:
+			if p.throttler.isRateLimited(rule, msg, content, time.Now()) {
+				return false
+			}
 		}
 	}
 
@@ -191,12 +252,38 @@ func (p *Processor) applyRedactingRules(msg *message.Message) bool {
 		} else if mutated {
 			content = evtProcessed
 		}
+		if attributes != nil {
+			p.scanAttributes(attributes, msg)
+		}
 	}
 
+	// the tags are derived from the redacted attributes so that they don't leak the redacted values
+	appendTagFields(msg, tagFields, attributes)
+
 	msg.SetContent(content)
 	return true // we want to send this message
 }
 
+// scanAttributes scans the values of the attributes lifted by a parsing rule with SDS.
+// The tags of the matching rules are added once to the message.
+func (p *Processor) scanAttributes(attributes map[string]interface{}, msg *message.Message) {
+	scanned := &message.Message{}
+	redactAttributes(attributes, func(value []byte) []byte {
+		mutated, evtProcessed, err := p.sds.Scan(value, scanned)
+		if err != nil {
+			log.Error("while using SDS to scan the log attributes:", err)
+		} else if mutated {
+			return evtProcessed
+		}
+		return value
+	})
+	for _, tag := range scanned.ProcessingTags {
+		if !slices.Contains(msg.ProcessingTags, tag) {
+			msg.ProcessingTags = append(msg.ProcessingTags, tag)
+		}
+	}
+}
+
 // GetHostname returns the hostname to applied the given log message
 func (p *Processor) GetHostname(msg *message.Message) string {
 	if msg.Hostname != "" {
@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"math"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+// throttlingInterval is the interval at which the expired deduplication windows
+// are flushed and the idle rate limiting buckets are released.
+const throttlingInterval = time.Second
+
+// maxDedupWindows is the maximum number of deduplication windows open at the same
+// time in a processor. The logs opening new windows past this limit are not deduplicated.
+const maxDedupWindows = 10000
+
+// repeatCountAttribute is the attribute of the summary log emitted at the end
+// of a deduplication window, it contains the number of logs dropped.
+const repeatCountAttribute = "repeat_count"
+
+// throttlingKey identifies the state of a deduplicate or a rate_limit rule.
+// The state of the rules is kept per source, the value is the deduplicated
+// content or the value captured by the rate_limit pattern.
+type throttlingKey struct {
+	rule   *config.ProcessingRule
+	source *sources.LogSource
+	value  string
+}
+
+// dedupWindow tracks the logs collapsed by a deduplicate rule since the first one.
+type dedupWindow struct {
+	content        []byte
+	origin         message.Origin
+	status         string
+	hostname       string
+	processingTags []string
+	expiresAt      time.Time
+	repeated       int
+}
+
+// tokenBucket is the token bucket of a rate_limit rule.
+type tokenBucket struct {
+	tokens     float64
+	lastUpdate time.Time
+}
+
+// throttler holds the state of the deduplicate and rate_limit rules of a processor.
+// The rules are enforced per logs pipeline. It is not thread safe, it is only used
+// by the processor main loop.
+type throttler struct {
+	windows map[throttlingKey]*dedupWindow
+	buckets map[throttlingKey]*tokenBucket
+	// summaries are the summary logs of the closed windows waiting to be sent
+	summaries []*message.Message
+	// opened are the keys of the windows opened by the log being processed, the content
+	// of their summary is only known once the log went through all the rules.
+	opened []throttlingKey
+}
+
+// isDuplicate returns true if the content has already been seen by the rule in
+// the current window, in which case the log must be dropped.
+// If the rule has a pattern, the parts of the content matching it are ignored
+// to compare the logs.
+func (t *throttler) isDuplicate(rule *config.ProcessingRule, msg *message.Message, content []byte, now time.Time) bool {
+	if t.windows == nil {
+		t.windows = make(map[throttlingKey]*dedupWindow)
+	}
+	key := throttlingKey{rule: rule, source: msg.Origin.LogSource, value: string(content)}
+	if rule.Regex != nil {
+		key.value = string(rule.Regex.ReplaceAll(content, nil))
+	}
+
+	if window, exists := t.windows[key]; exists {
+		if now.Before(window.expiresAt) {
+			window.repeated++
+			metrics.LogsDeduplicated.Add(1)
+			metrics.TlmLogsDeduplicated.Inc(rule.Name)
+			return true
+		}
+		t.closeWindow(key, window)
+	}
+	if len(t.windows) >= maxDedupWindows {
+		return false
+	}
+
+	origin := *msg.Origin
+	// the summary log must not commit any offset
+	origin.Identifier = ""
+	origin.Offset = ""
+	t.windows[key] = &dedupWindow{
+		content:        append([]byte(nil), content...),
+		origin:         origin,
+		status:         msg.GetStatus(),
+		hostname:       msg.Hostname,
+		processingTags: append([]string(nil), msg.ProcessingTags...),
+		expiresAt:      now.Add(rule.WindowDuration),
+	}
+	t.opened = append(t.opened, key)
+	return false
+}
+
+// commitWindows sets the content of the summaries of the windows opened by msg to its
+// content once all the rules, including the redacting ones, were applied. If the message
+// was dropped by a later rule, the windows are discarded instead.
+func (t *throttler) commitWindows(msg *message.Message, sent bool) {
+	for _, key := range t.opened {
+		window, exists := t.windows[key]
+		if !exists {
+			continue
+		}
+		if !sent {
+			delete(t.windows, key)
+			continue
+		}
+		window.content = append([]byte(nil), msg.GetContent()...)
+		window.status = msg.GetStatus()
+		window.processingTags = append([]string(nil), msg.ProcessingTags...)
+	}
+	t.opened = t.opened[:0]
+}
+
+// isRateLimited returns true if the log exceeds the rate of the rule, in which
+// case the log must be dropped. Only the logs matching the pattern of the rule,
+// if any, are rate limited. If the pattern has a capturing group, each captured
+// value is rate limited separately.
+func (t *throttler) isRateLimited(rule *config.ProcessingRule, msg *message.Message, content []byte, now time.Time) bool {
+	key := throttlingKey{rule: rule, source: msg.Origin.LogSource}
+	if rule.Regex != nil {
+		matches := rule.Regex.FindSubmatch(content)
+		if matches == nil {
+			return false
+		}
+		if len(matches) > 1 {
+			key.value = string(matches[1])
+		}
+	}
+
+	if t.buckets == nil {
+		t.buckets = make(map[throttlingKey]*tokenBucket)
+	}
+	burst := burstOf(rule)
+	bucket, exists := t.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: burst, lastUpdate: now}
+		t.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.lastUpdate).Seconds()*rule.Rate)
+	bucket.lastUpdate = now
+
+	if bucket.tokens < 1 {
+		metrics.LogsRateLimited.Add(1)
+		metrics.TlmLogsRateLimited.Inc(rule.Name)
+		return true
+	}
+	bucket.tokens--
+	return false
+}
+
+// expire closes the deduplication windows which are over and releases the
+// rate limiting buckets which are full again.
+func (t *throttler) expire(now time.Time) {
+	for key, window := range t.windows {
+		if !now.Before(window.expiresAt) {
+			t.closeWindow(key, window)
+		}
+	}
+	for key, bucket := range t.buckets {
+		if bucket.tokens+now.Sub(bucket.lastUpdate).Seconds()*key.rule.Rate >= burstOf(key.rule) {
+			delete(t.buckets, key)
+		}
+	}
+}
+
+// closeWindows closes all the deduplication windows.
+func (t *throttler) closeWindows() {
+	for key, window := range t.windows {
+		t.closeWindow(key, window)
+	}
+}
+
+// flushSummaries returns the summary logs waiting to be sent.
+func (t *throttler) flushSummaries() []*message.Message {
+	summaries := t.summaries
+	t.summaries = nil
+	return summaries
+}
+
+// closeWindow removes the window and, if logs have been dropped during the
+// window, queues a summary log containing the number of logs dropped.
+func (t *throttler) closeWindow(key throttlingKey, window *dedupWindow) {
+	delete(t.windows, key)
+	if window.repeated == 0 {
+		return
+	}
+	content := &message.BasicStructuredContent{
+		Data: map[string]interface{}{
+			"message":            string(window.content),
+			repeatCountAttribute: window.repeated,
+		},
+	}
+	summary := message.NewStructuredMessage(content, &window.origin, window.status, time.Now().UnixNano())
+	summary.Hostname = window.hostname
+	summary.ProcessingTags = window.processingTags
+	t.summaries = append(t.summaries, summary)
+}
+
+// burstOf returns the burst of the rate_limit rule, defaulting to its rate.
+func burstOf(rule *config.ProcessingRule) float64 {
+	if rule.Burst > 0 {
+		return float64(rule.Burst)
+	}
+	return math.Max(1, math.Ceil(rule.Rate))
+}
@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func newThrottlingRule(t *testing.T, rule *config.ProcessingRule) *config.ProcessingRule {
+	rule.Name = rule.Type
+	rules := []*config.ProcessingRule{rule}
+	require.NoError(t, config.ValidateProcessingRules(rules))
+	require.NoError(t, config.CompileProcessingRules(rules))
+	return rule
+}
+
+func TestDeduplicate(t *testing.T) {
+	rule := newThrottlingRule(t, &config.ProcessingRule{Type: config.Deduplicate, Window: "10s", Pattern: `\d{2}:\d{2}:\d{2}`})
+	source := sources.NewLogSource("", &config.LogsConfig{})
+	otherSource := sources.NewLogSource("", &config.LogsConfig{})
+	now := time.Now()
+
+	var th throttler
+	first := newMessage([]byte("10:00:00 connection refused"), source, message.StatusError)
+	first.Origin.Identifier = "file:/var/log/app.log"
+	first.Origin.Offset = "42"
+	assert.False(t, th.isDuplicate(rule, first, first.GetContent(), now))
+	// near-identical logs are collapsed
+	assert.True(t, th.isDuplicate(rule, newMessage([]byte("10:00:01 connection refused"), source, ""), []byte("10:00:01 connection refused"), now.Add(time.Second)))
+	assert.True(t, th.isDuplicate(rule, newMessage([]byte("10:00:02 connection refused"), source, ""), []byte("10:00:02 connection refused"), now.Add(2*time.Second)))
+	// different logs or sources are not
+	assert.False(t, th.isDuplicate(rule, newMessage([]byte("10:00:02 connection reset"), source, ""), []byte("10:00:02 connection reset"), now.Add(2*time.Second)))
+	assert.False(t, th.isDuplicate(rule, newMessage([]byte("10:00:02 connection refused"), otherSource, ""), []byte("10:00:02 connection refused"), now.Add(2*time.Second)))
+
+	th.expire(now.Add(5 * time.Second))
+	assert.Empty(t, th.flushSummaries())
+
+	// the end of the window emits a summary of the dropped logs
+	th.expire(now.Add(10 * time.Second))
+	summaries := th.flushSummaries()
+	require.Len(t, summaries, 1)
+	summary := summaries[0]
+	assert.Equal(t, message.StatusError, summary.GetStatus())
+	assert.Equal(t, []byte("10:00:00 connection refused"), summary.GetContent())
+	assert.Equal(t, map[string]interface{}{"message": "10:00:00 connection refused", "repeat_count": float64(2)}, renderedFields(t, summary))
+	// the summary doesn't commit any offset
+	assert.Equal(t, "", summary.Origin.Identifier)
+	assert.Equal(t, "", summary.Origin.Offset)
+	assert.Equal(t, "file:/var/log/app.log", first.Origin.Identifier)
+	// the windows started later are still open
+	assert.Len(t, th.windows, 2)
+
+	// a new window is started by the next log
+	assert.False(t, th.isDuplicate(rule, newMessage([]byte("10:00:11 connection refused"), source, ""), []byte("10:00:11 connection refused"), now.Add(11*time.Second)))
+}
+
+func TestDeduplicateWindowClosedByNextLog(t *testing.T) {
+	rule := newThrottlingRule(t, &config.ProcessingRule{Type: config.Deduplicate, Window: "1s"})
+	source := sources.NewLogSource("", &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{rule}})
+	outputChan := make(chan *message.Message, 10)
+	p := &Processor{
+		outputChan:                outputChan,
+		encoder:                   JSONEncoder,
+		diagnosticMessageReceiver: diagnostic.NewBufferedMessageReceiver(nil, nil),
+	}
+
+	p.processMessage(newMessage([]byte("crash"), source, ""))
+	p.processMessage(newMessage([]byte("crash"), source, ""))
+	p.processMessage(newMessage([]byte("crash"), source, ""))
+	require.Len(t, outputChan, 1)
+	<-outputChan
+
+	// simulate the end of the window
+	for _, window := range p.throttler.windows {
+		window.expiresAt = time.Now()
+	}
+	p.processMessage(newMessage([]byte("crash"), source, ""))
+	require.Len(t, outputChan, 2)
+	summary := <-outputChan
+	assert.Equal(t, message.StateEncoded, summary.State)
+	assert.Contains(t, string(summary.GetContent()), `\"repeat_count\":2`)
+	assert.Equal(t, message.StateEncoded, (<-outputChan).State)
+}
+
+func TestDeduplicateThenMasking(t *testing.T) {
+	dedup := &config.ProcessingRule{Name: "dedup", Type: config.Deduplicate, Window: "1s"}
+	mask := &config.ProcessingRule{Name: "mask", Type: config.MaskSequences, Pattern: `\d{4}`, ReplacePlaceholder: "[masked]"}
+	exclude := &config.ProcessingRule{Name: "exclude", Type: config.ExcludeAtMatch, Pattern: "debug"}
+	rules := []*config.ProcessingRule{dedup, mask, exclude}
+	require.NoError(t, config.CompileProcessingRules(rules))
+	source := sources.NewLogSource("", &config.LogsConfig{ProcessingRules: rules})
+	p := &Processor{}
+
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("card 1234 declined"), source, "")))
+	assert.False(t, p.applyRedactingRules(newMessage([]byte("card 1234 declined"), source, "")))
+	// the summary holds the redacted content
+	p.throttler.closeWindows()
+	summaries := p.throttler.flushSummaries()
+	require.Len(t, summaries, 1)
+	assert.Equal(t, []byte("card [masked] declined"), summaries[0].GetContent())
+
+	// the windows opened by the logs dropped by a later rule are discarded
+	assert.False(t, p.applyRedactingRules(newMessage([]byte("debug 1234"), source, "")))
+	assert.Empty(t, p.throttler.windows)
+}
+
+func TestDeduplicateMaxWindows(t *testing.T) {
+	rule := newThrottlingRule(t, &config.ProcessingRule{Type: config.Deduplicate, Window: "10s"})
+	source := sources.NewLogSource("", &config.LogsConfig{})
+	now := time.Now()
+
+	var th throttler
+	for i := 0; i < maxDedupWindows; i++ {
+		content := []byte(strconv.Itoa(i))
+		assert.False(t, th.isDuplicate(rule, newMessage(content, source, ""), content, now))
+	}
+	// past the limit, the logs are not deduplicated
+	assert.False(t, th.isDuplicate(rule, newMessage([]byte("new"), source, ""), []byte("new"), now))
+	assert.False(t, th.isDuplicate(rule, newMessage([]byte("new"), source, ""), []byte("new"), now))
+	assert.Len(t, th.windows, maxDedupWindows)
+	// the open windows still are
+	assert.True(t, th.isDuplicate(rule, newMessage([]byte("0"), source, ""), []byte("0"), now))
+}
+
+func TestRateLimit(t *testing.T) {
+	rule := newThrottlingRule(t, &config.ProcessingRule{Type: config.RateLimit, Rate: 2, Burst: 3})
+	source := sources.NewLogSource("", &config.LogsConfig{})
+	now := time.Now()
+	msg := newMessage([]byte("log"), source, "")
+
+	var th throttler
+	for i := 0; i < 3; i++ {
+		assert.False(t, th.isRateLimited(rule, msg, msg.GetContent(), now))
+	}
+	assert.True(t, th.isRateLimited(rule, msg, msg.GetContent(), now))
+
+	// the bucket is refilled at the configured rate
+	assert.False(t, th.isRateLimited(rule, msg, msg.GetContent(), now.Add(500*time.Millisecond)))
+	assert.True(t, th.isRateLimited(rule, msg, msg.GetContent(), now.Add(500*time.Millisecond)))
+
+	// full buckets are released
+	th.expire(now.Add(time.Second))
+	assert.Len(t, th.buckets, 1)
+	th.expire(now.Add(3 * time.Second))
+	assert.Empty(t, th.buckets)
+}
+
+func TestRateLimitPerMatch(t *testing.T) {
+	rule := newThrottlingRule(t, &config.ProcessingRule{Type: config.RateLimit, Rate: 1, Pattern: `user=(\w+)`})
+	source := sources.NewLogSource("", &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{rule}})
+	p := &Processor{}
+
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("login user=alice"), source, "")))
+	assert.False(t, p.applyRedactingRules(newMessage([]byte("login user=alice"), source, "")))
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("login user=bob"), source, "")))
+	// the logs not matching the pattern are not rate limited
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("startup"), source, "")))
+	assert.True(t, p.applyRedactingRules(newMessage([]byte("startup"), source, "")))
+}
@@ -0,0 +1,225 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const spoolFileExtension = ".spool"
+const spoolFileFormat = "2006_01_02__15_04_05.000000000_"
+
+// spoolHeaderSize is the size of the header written before each spooled payload:
+// the length of the encoding and the unencoded size of the payload. The header
+// is followed by the encoding and by the encoded payload.
+const spoolHeaderSize = 4 + 8
+
+var (
+	tlmSpoolPayloadsStored   = telemetry.NewCounter("logs_sender", "spool_payloads_stored", []string{}, "Payloads stored in the disk spool")
+	tlmSpoolPayloadsReplayed = telemetry.NewCounter("logs_sender", "spool_payloads_replayed", []string{}, "Payloads replayed from the disk spool")
+	tlmSpoolPayloadsDropped  = telemetry.NewCounter("logs_sender", "spool_payloads_dropped", []string{"reason"}, "Payloads dropped from the disk spool")
+	tlmSpoolSizeInBytes      = telemetry.NewGauge("logs_sender", "spool_size_bytes", []string{"path"}, "Disk space used by the disk spool")
+)
+
+type spoolFile struct {
+	path      string
+	size      int64
+	createdAt time.Time
+}
+
+// DiskSpool stores on disk the payloads the reliable destinations can't accept,
+// so they can be replayed once the destinations recover.
+// The spool is bounded both in size and in age: the oldest payloads are
+// dropped first when the limits are reached.
+// DiskSpool is not thread safe, it is only used by the sender main loop.
+type DiskSpool struct {
+	path               string
+	maxSizeInBytes     int64
+	maxAge             time.Duration
+	files              []spoolFile
+	currentSizeInBytes int64
+}
+
+// NewDiskSpool returns a new disk spool storing payloads in the given directory.
+// The payloads spooled by a previous run of the agent are reloaded.
+func NewDiskSpool(path string, maxSizeInBytes int64, maxAge time.Duration) (*DiskSpool, error) {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	spool := &DiskSpool{
+		path:           path,
+		maxSizeInBytes: maxSizeInBytes,
+		maxAge:         maxAge,
+	}
+	if err := spool.reloadExistingFiles(); err != nil {
+		return nil, err
+	}
+	return spool, nil
+}
+
+// Store writes the payload to disk, removing the oldest payloads if needed to
+// stay under the size limit. Once Store returns without error, the payload is
+// durably stored and its offsets can be committed.
+func (s *DiskSpool) Store(payload *message.Payload) error {
+	size := int64(spoolHeaderSize + len(payload.Encoding) + len(payload.Encoded))
+	if size > s.maxSizeInBytes {
+		return fmt.Errorf("the payload is too big to be spooled. Current:%v Maximum:%v", size, s.maxSizeInBytes)
+	}
+	for len(s.files) > 0 && s.currentSizeInBytes+size > s.maxSizeInBytes {
+		log.Warnf("Maximum disk space for the logs spool is reached. Removing %s", s.files[0].path)
+		tlmSpoolPayloadsDropped.Inc("size")
+		s.removeFirst()
+	}
+
+	now := time.Now().UTC()
+	file, err := os.CreateTemp(s.path, now.Format(spoolFileFormat)+"*"+spoolFileExtension)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, spoolHeaderSize, spoolHeaderSize+len(payload.Encoding))
+	binary.LittleEndian.PutUint32(header[0:4], uint32(len(payload.Encoding)))
+	binary.LittleEndian.PutUint64(header[4:12], uint64(payload.UnencodedSize))
+	header = append(header, payload.Encoding...)
+	if _, err = file.Write(header); err == nil {
+		_, err = file.Write(payload.Encoded)
+	}
+	if err == nil {
+		// make sure the payload is durably stored before committing its offsets
+		err = file.Sync()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	s.files = append(s.files, spoolFile{path: file.Name(), size: size, createdAt: now})
+	s.currentSizeInBytes += size
+	tlmSpoolPayloadsStored.Inc()
+	tlmSpoolSizeInBytes.Set(float64(s.currentSizeInBytes), s.path)
+	return nil
+}
+
+// Front returns the oldest payload stored in the spool without removing it,
+// or nil if the spool is empty. Expired and unreadable payloads are discarded.
+// Replayed payloads don't contain any message: their offsets have already been
+// committed when they were spooled.
+func (s *DiskSpool) Front() *message.Payload {
+	for len(s.files) > 0 {
+		file := s.files[0]
+		if s.maxAge > 0 && time.Since(file.createdAt) > s.maxAge {
+			log.Warnf("Removing expired payload %s from the logs spool", file.path)
+			tlmSpoolPayloadsDropped.Inc("age")
+			s.removeFirst()
+			continue
+		}
+		payload, err := readSpoolFile(file.path)
+		if err != nil {
+			log.Errorf("Cannot read the spooled payload %s: %v", file.path, err)
+			tlmSpoolPayloadsDropped.Inc("corrupted")
+			s.removeFirst()
+			continue
+		}
+		return payload
+	}
+	return nil
+}
+
+// Pop removes the oldest payload stored in the spool.
+func (s *DiskSpool) Pop() {
+	if len(s.files) > 0 {
+		tlmSpoolPayloadsReplayed.Inc()
+		s.removeFirst()
+	}
+}
+
+// Len returns the number of payloads stored in the spool.
+func (s *DiskSpool) Len() int {
+	return len(s.files)
+}
+
+// SizeInBytes returns the disk space used by the spool.
+func (s *DiskSpool) SizeInBytes() int64 {
+	return s.currentSizeInBytes
+}
+
+func (s *DiskSpool) removeFirst() {
+	file := s.files[0]
+	s.files = s.files[1:]
+	s.currentSizeInBytes -= file.size
+	if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+		log.Errorf("Cannot remove the spooled payload %s: %v", file.path, err)
+	}
+	tlmSpoolSizeInBytes.Set(float64(s.currentSizeInBytes), s.path)
+}
+
+func (s *DiskSpool) reloadExistingFiles() error {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolFileExtension) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		createdAt := info.ModTime().UTC()
+		if len(entry.Name()) > len(spoolFileFormat) {
+			if ts, err := time.Parse(spoolFileFormat, entry.Name()[:len(spoolFileFormat)]); err == nil {
+				createdAt = ts
+			}
+		}
+		s.files = append(s.files, spoolFile{
+			path:      filepath.Join(s.path, entry.Name()),
+			size:      info.Size(),
+			createdAt: createdAt,
+		})
+		s.currentSizeInBytes += info.Size()
+	}
+	sort.SliceStable(s.files, func(i, j int) bool {
+		return s.files[i].createdAt.Before(s.files[j].createdAt)
+	})
+	if len(s.files) > 0 {
+		log.Infof("Reloaded %d payloads from the logs spool %s", len(s.files), s.path)
+	}
+	tlmSpoolSizeInBytes.Set(float64(s.currentSizeInBytes), s.path)
+	return nil
+}
+
+func readSpoolFile(path string) (*message.Payload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < spoolHeaderSize {
+		return nil, fmt.Errorf("truncated header")
+	}
+	encodingLen := int(binary.LittleEndian.Uint32(data[0:4]))
+	unencodedSize := int(binary.LittleEndian.Uint64(data[4:12]))
+	if len(data) < spoolHeaderSize+encodingLen {
+		return nil, fmt.Errorf("truncated encoding")
+	}
+	return &message.Payload{
+		Encoding:      string(data[spoolHeaderSize : spoolHeaderSize+encodingLen]),
+		Encoded:       data[spoolHeaderSize+encodingLen:],
+		UnencodedSize: unencodedSize,
+	}, nil
+}
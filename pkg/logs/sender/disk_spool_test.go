@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newSpoolPayload(content string) *message.Payload {
+	return &message.Payload{
+		Messages:      []*message.Message{message.NewMessage([]byte(content), nil, "", 0)},
+		Encoded:       []byte(content),
+		Encoding:      "gzip",
+		UnencodedSize: 2 * len(content),
+	}
+}
+
+func TestDiskSpoolStoreAndReplay(t *testing.T) {
+	path := t.TempDir()
+	spool, err := NewDiskSpool(path, 1024, time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, spool.Front())
+
+	require.NoError(t, spool.Store(newSpoolPayload("first")))
+	require.NoError(t, spool.Store(newSpoolPayload("second")))
+	assert.Equal(t, 2, spool.Len())
+	assert.Equal(t, int64(2*spoolHeaderSize+2*len("gzip")+len("first")+len("second")), spool.SizeInBytes())
+
+	// the payloads are replayed in order, without their messages
+	payload := spool.Front()
+	assert.Equal(t, &message.Payload{Encoded: []byte("first"), Encoding: "gzip", UnencodedSize: 10}, payload)
+	assert.Equal(t, payload, spool.Front())
+	spool.Pop()
+	assert.Equal(t, []byte("second"), spool.Front().Encoded)
+
+	// the remaining payload is reloaded by a new spool
+	spool, err = NewDiskSpool(path, 1024, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, spool.Len())
+	assert.Equal(t, []byte("second"), spool.Front().Encoded)
+	spool.Pop()
+	assert.Equal(t, 0, spool.Len())
+	assert.Equal(t, int64(0), spool.SizeInBytes())
+
+	files, err := os.ReadDir(path)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestDiskSpoolMaxSize(t *testing.T) {
+	payloadSize := int64(spoolHeaderSize + len("gzip") + len("payload-0"))
+	spool, err := NewDiskSpool(t.TempDir(), 2*payloadSize, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, spool.Store(newSpoolPayload("payload-0")))
+	require.NoError(t, spool.Store(newSpoolPayload("payload-1")))
+	require.NoError(t, spool.Store(newSpoolPayload("payload-2")))
+
+	// the oldest payload has been dropped
+	assert.Equal(t, 2, spool.Len())
+	assert.Equal(t, []byte("payload-1"), spool.Front().Encoded)
+
+	// a payload bigger than the spool is rejected
+	assert.Error(t, spool.Store(newSpoolPayload("a payload way too big to fit in the spool")))
+	assert.Equal(t, 2, spool.Len())
+}
+
+func TestDiskSpoolMaxAge(t *testing.T) {
+	spool, err := NewDiskSpool(t.TempDir(), 1024, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, spool.Store(newSpoolPayload("expired")))
+	require.NoError(t, spool.Store(newSpoolPayload("fresh")))
+	spool.files[0].createdAt = time.Now().Add(-2 * time.Hour)
+
+	assert.Equal(t, []byte("fresh"), spool.Front().Encoded)
+	assert.Equal(t, 1, spool.Len())
+}
+
+func TestDiskSpoolCorruptedFile(t *testing.T) {
+	path := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(path, "2024_01_01__00_00_00.000000000_1"+spoolFileExtension), []byte("bad"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "ignored.txt"), []byte("ignored"), 0600))
+
+	spool, err := NewDiskSpool(path, 1024, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, spool.Len())
+
+	require.NoError(t, spool.Store(newSpoolPayload("valid")))
+	assert.Equal(t, []byte("valid"), spool.Front().Encoded)
+	assert.Equal(t, 1, spool.Len())
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 var (
@@ -22,6 +23,14 @@ var (
 	tlmSendWaitTime    = telemetry.NewCounter("logs_sender", "send_wait", []string{}, "Time spent waiting for all sends to finish")
 )
 
+const (
+	// spoolReplayInterval is the interval at which the sender tries to replay the spooled payloads.
+	spoolReplayInterval = time.Second
+	// spoolReplayBatchSize is the maximum number of spooled payloads replayed at each interval,
+	// so the replay doesn't starve the incoming payloads.
+	spoolReplayBatchSize = 100
+)
+
 // Sender sends logs to different destinations. Destinations can be either
 // reliable or unreliable. The sender ensures that logs are sent to at least
 // one reliable destination and will block the pipeline if they are in an
@@ -29,6 +38,9 @@ var (
 // one reliable destination is also sending logs. However they do not update
 // the auditor or block the pipeline if they fail. There will always be at
 // least 1 reliable destination (the main destination).
+// When a disk spool is configured, the payloads that can't be sent to any
+// reliable destination are stored on disk instead of blocking the pipeline,
+// and replayed once a reliable destination recovers.
 type Sender struct {
 	config         pkgconfigmodel.Reader
 	inputChan      chan *message.Payload
@@ -38,10 +50,11 @@ type Sender struct {
 	bufferSize     int
 	senderDoneChan chan *sync.WaitGroup
 	flushWg        *sync.WaitGroup
+	spool          *DiskSpool
 }
 
 // NewSender returns a new sender.
-func NewSender(config pkgconfigmodel.Reader, inputChan chan *message.Payload, outputChan chan *message.Payload, destinations *client.Destinations, bufferSize int, senderDoneChan chan *sync.WaitGroup, flushWg *sync.WaitGroup) *Sender {
+func NewSender(config pkgconfigmodel.Reader, inputChan chan *message.Payload, outputChan chan *message.Payload, destinations *client.Destinations, bufferSize int, senderDoneChan chan *sync.WaitGroup, flushWg *sync.WaitGroup, spool *DiskSpool) *Sender {
 	return &Sender{
 		config:         config,
 		inputChan:      inputChan,
@@ -51,6 +64,7 @@ func NewSender(config pkgconfigmodel.Reader, inputChan chan *message.Payload, ou
 		bufferSize:     bufferSize,
 		senderDoneChan: senderDoneChan,
 		flushWg:        flushWg,
+		spool:          spool,
 	}
 }
 
@@ -72,73 +86,144 @@ func (s *Sender) run() {
 	sink := additionalDestinationsSink(s.bufferSize)
 	unreliableDestinations := buildDestinationSenders(s.config, s.destinations.Unreliable, sink, s.bufferSize)
 
-	for payload := range s.inputChan {
-		var startInUse = time.Now()
-		senderDoneWg := &sync.WaitGroup{}
+	var replayTick <-chan time.Time
+	if s.spool != nil {
+		replayTicker := time.NewTicker(spoolReplayInterval)
+		defer replayTicker.Stop()
+		replayTick = replayTicker.C
+	}
 
-		sent := false
-		for !sent {
-			for _, destSender := range reliableDestinations {
-				if destSender.Send(payload) {
-					sent = true
-					if s.senderDoneChan != nil {
-						senderDoneWg.Add(1)
-						s.senderDoneChan <- senderDoneWg
-					}
+	for {
+		select {
+		case payload, ok := <-s.inputChan:
+			if !ok {
+				// Cleanup the destinations
+				for _, destSender := range reliableDestinations {
+					destSender.Stop()
 				}
+				for _, destSender := range unreliableDestinations {
+					destSender.Stop()
+				}
+				close(sink)
+				s.done <- struct{}{}
+				return
 			}
+			s.send(payload, reliableDestinations, unreliableDestinations)
+		case <-replayTick:
+			s.replaySpool(reliableDestinations)
+		}
+	}
+}
+
+func (s *Sender) send(payload *message.Payload, reliableDestinations []*DestinationSender, unreliableDestinations []*DestinationSender) {
+	var startInUse = time.Now()
+	senderDoneWg := &sync.WaitGroup{}
 
-			if !sent {
-				// Throttle the poll loop while waiting for a send to succeed
-				// This will only happen when all reliable destinations
-				// are blocked so logs have no where to go.
-				time.Sleep(100 * time.Millisecond)
+	sent := false
+	for !sent {
+		for _, destSender := range reliableDestinations {
+			if destSender.Send(payload) {
+				sent = true
+				if s.senderDoneChan != nil {
+					senderDoneWg.Add(1)
+					s.senderDoneChan <- senderDoneWg
+				}
 			}
 		}
 
-		for i, destSender := range reliableDestinations {
-			// If an endpoint is stuck in the previous step, try to buffer the payloads if we have room to mitigate
-			// loss on intermittent failures.
-			if !destSender.lastSendSucceeded {
-				if !destSender.NonBlockingSend(payload) {
-					tlmPayloadsDropped.Inc("true", strconv.Itoa(i))
-					tlmMessagesDropped.Add(float64(len(payload.Messages)), "true", strconv.Itoa(i))
+		if !sent {
+			// All the reliable destinations are blocked, spool the payload on disk
+			// if possible rather than blocking the pipeline.
+			if s.spoolPayload(payload) {
+				tlmSendWaitTime.Add(float64(time.Since(startInUse) / time.Millisecond))
+				if s.senderDoneChan != nil && s.flushWg != nil {
+					// The payload is stored on disk, there is no destination to wait for
+					s.flushWg.Done()
 				}
+				return
 			}
+			// Throttle the poll loop while waiting for a send to succeed
+			// This will only happen when all reliable destinations
+			// are blocked so logs have no where to go.
+			time.Sleep(100 * time.Millisecond)
 		}
+	}
 
-		// Attempt to send to unreliable destinations
-		for i, destSender := range unreliableDestinations {
+	for i, destSender := range reliableDestinations {
+		// If an endpoint is stuck in the previous step, try to buffer the payloads if we have room to mitigate
+		// loss on intermittent failures.
+		if !destSender.lastSendSucceeded {
 			if !destSender.NonBlockingSend(payload) {
-				tlmPayloadsDropped.Inc("false", strconv.Itoa(i))
-				tlmMessagesDropped.Add(float64(len(payload.Messages)), "false", strconv.Itoa(i))
-				if s.senderDoneChan != nil {
-					senderDoneWg.Add(1)
-					s.senderDoneChan <- senderDoneWg
-				}
+				tlmPayloadsDropped.Inc("true", strconv.Itoa(i))
+				tlmMessagesDropped.Add(float64(len(payload.Messages)), "true", strconv.Itoa(i))
 			}
 		}
+	}
 
-		inUse := float64(time.Since(startInUse) / time.Millisecond)
-		tlmSendWaitTime.Add(inUse)
-
-		if s.senderDoneChan != nil && s.flushWg != nil {
-			// Wait for all destinations to finish sending the payload
-			senderDoneWg.Wait()
-			// Decrement the wait group when this payload has been sent
-			s.flushWg.Done()
+	// Attempt to send to unreliable destinations
+	for i, destSender := range unreliableDestinations {
+		if !destSender.NonBlockingSend(payload) {
+			tlmPayloadsDropped.Inc("false", strconv.Itoa(i))
+			tlmMessagesDropped.Add(float64(len(payload.Messages)), "false", strconv.Itoa(i))
+			if s.senderDoneChan != nil {
+				senderDoneWg.Add(1)
+				s.senderDoneChan <- senderDoneWg
+			}
 		}
 	}
 
-	// Cleanup the destinations
-	for _, destSender := range reliableDestinations {
-		destSender.Stop()
+	inUse := float64(time.Since(startInUse) / time.Millisecond)
+	tlmSendWaitTime.Add(inUse)
+
+	if s.senderDoneChan != nil && s.flushWg != nil {
+		// Wait for all destinations to finish sending the payload
+		senderDoneWg.Wait()
+		// Decrement the wait group when this payload has been sent
+		s.flushWg.Done()
+	}
+}
+
+// spoolPayload stores the payload in the disk spool and forwards it to the
+// auditor, returns false if the payload could not be spooled.
+func (s *Sender) spoolPayload(payload *message.Payload) bool {
+	if s.spool == nil {
+		return false
+	}
+	if err := s.spool.Store(payload); err != nil {
+		log.Warnf("Could not spool the logs payload on disk: %v", err)
+		return false
 	}
-	for _, destSender := range unreliableDestinations {
-		destSender.Stop()
+	// the payload is durably stored, it's now safe to commit its offsets.
+	s.outputChan <- payload
+	return true
+}
+
+// replaySpool sends the spooled payloads to the reliable destinations
+// as long as at least one of them accepts them.
+func (s *Sender) replaySpool(reliableDestinations []*DestinationSender) {
+	for i := 0; i < spoolReplayBatchSize && s.spool.Len() > 0; i++ {
+		payload := s.spool.Front()
+		if payload == nil {
+			return
+		}
+		sent := false
+		senderDoneWg := &sync.WaitGroup{}
+		for _, destSender := range reliableDestinations {
+			if destSender.Send(payload) {
+				sent = true
+				if s.senderDoneChan != nil {
+					senderDoneWg.Add(1)
+					s.senderDoneChan <- senderDoneWg
+				}
+			}
+		}
+		// like the other payloads, wait for the destinations to send the replayed payload
+		senderDoneWg.Wait()
+		if !sent {
+			return
+		}
+		s.spool.Pop()
 	}
-	close(sink)
-	s.done <- struct{}{}
 }
 
 // Drains the output channel from destinations that don't update the auditor.
@@ -7,7 +7,9 @@ package sender
 
 import (
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -50,7 +52,7 @@ func TestSender(t *testing.T) {
 	destinations := client.NewDestinations([]client.Destination{destination}, nil)
 
 	cfg := getNewConfig()
-	sender := NewSender(cfg, input, output, destinations, 0, nil, nil)
+	sender := NewSender(cfg, input, output, destinations, 0, nil, nil, nil)
 	sender.Start()
 
 	expectedMessage := newMessage([]byte("fake line"), source, "")
@@ -78,7 +80,7 @@ func TestSenderSingleDestination(_ *testing.T) {
 
 	destinations := client.NewDestinations([]client.Destination{server.Destination}, nil)
 
-	sender := NewSender(cfg, input, output, destinations, 10, nil, nil)
+	sender := NewSender(cfg, input, output, destinations, 10, nil, nil, nil)
 	sender.Start()
 
 	input <- &message.Payload{}
@@ -108,7 +110,7 @@ func TestSenderDualReliableDestination(_ *testing.T) {
 
 	destinations := client.NewDestinations([]client.Destination{server1.Destination, server2.Destination}, nil)
 
-	sender := NewSender(cfg, input, output, destinations, 10, nil, nil)
+	sender := NewSender(cfg, input, output, destinations, 10, nil, nil, nil)
 	sender.Start()
 
 	input <- &message.Payload{}
@@ -143,7 +145,7 @@ func TestSenderUnreliableAdditionalDestination(_ *testing.T) {
 
 	destinations := client.NewDestinations([]client.Destination{server1.Destination}, []client.Destination{server2.Destination})
 
-	sender := NewSender(cfg, input, output, destinations, 10, nil, nil)
+	sender := NewSender(cfg, input, output, destinations, 10, nil, nil, nil)
 	sender.Start()
 
 	input <- &message.Payload{}
@@ -175,7 +177,7 @@ func TestSenderUnreliableStopsWhenMainFails(t *testing.T) {
 
 	destinations := client.NewDestinations([]client.Destination{reliableServer.Destination}, []client.Destination{unreliableServer.Destination})
 
-	sender := NewSender(cfg, input, output, destinations, 10, nil, nil)
+	sender := NewSender(cfg, input, output, destinations, 10, nil, nil, nil)
 	sender.Start()
 
 	input <- &message.Payload{}
@@ -224,7 +226,7 @@ func TestSenderReliableContinuseWhenOneFails(_ *testing.T) {
 
 	destinations := client.NewDestinations([]client.Destination{reliableServer1.Destination, reliableServer2.Destination}, nil)
 
-	sender := NewSender(cfg, input, output, destinations, 10, nil, nil)
+	sender := NewSender(cfg, input, output, destinations, 10, nil, nil, nil)
 	sender.Start()
 
 	input <- &message.Payload{}
@@ -270,7 +272,7 @@ func TestSenderReliableWhenOneFailsAndRecovers(_ *testing.T) {
 
 	destinations := client.NewDestinations([]client.Destination{reliableServer1.Destination, reliableServer2.Destination}, nil)
 
-	sender := NewSender(cfg, input, output, destinations, 10, nil, nil)
+	sender := NewSender(cfg, input, output, destinations, 10, nil, nil, nil)
 	sender.Start()
 
 	input <- &message.Payload{}
@@ -321,3 +323,79 @@ func TestSenderReliableWhenOneFailsAndRecovers(_ *testing.T) {
 	reliableServer2.Stop()
 	sender.Stop()
 }
+
+// blockableDestination forwards the payloads to its output, unless blocked. Like the
+// synchronous destinations, it notifies the sender of each payload sent on senderDoneChan.
+type blockableDestination struct {
+	sync.Mutex
+	started        chan struct{}
+	isRetrying     chan bool
+	senderDoneChan chan *sync.WaitGroup
+}
+
+func (d *blockableDestination) IsMRF() bool { return false }
+
+func (d *blockableDestination) Target() string { return "blockable" }
+
+func (d *blockableDestination) Start(input chan *message.Payload, output chan *message.Payload, isRetrying chan bool) <-chan struct{} {
+	stopChan := make(chan struct{})
+	d.isRetrying = isRetrying
+	go func() {
+		for {
+			d.Lock()
+			d.Unlock() //nolint:staticcheck
+			payload, ok := <-input
+			if !ok {
+				close(stopChan)
+				return
+			}
+			output <- payload
+			if d.senderDoneChan != nil {
+				senderDoneWg := <-d.senderDoneChan
+				senderDoneWg.Done()
+			}
+		}
+	}()
+	close(d.started)
+	return stopChan
+}
+
+func TestSenderSpoolsWhenReliableDestinationsAreRetrying(t *testing.T) {
+	cfg := getNewConfig()
+	input := make(chan *message.Payload, 1)
+	output := make(chan *message.Payload, 1)
+
+	senderDoneChan := make(chan *sync.WaitGroup, 1)
+	destination := &blockableDestination{started: make(chan struct{}), senderDoneChan: senderDoneChan}
+	destinations := client.NewDestinations([]client.Destination{destination}, nil)
+
+	spool, err := NewDiskSpool(t.TempDir(), 1024, time.Hour)
+	assert.NoError(t, err)
+
+	// the destination is down when the sender starts
+	destination.Lock()
+	flushWg := &sync.WaitGroup{}
+	sender := NewSender(cfg, input, output, destinations, 0, senderDoneChan, flushWg, spool)
+	sender.Start()
+	<-destination.started
+	destination.isRetrying <- true
+
+	// the payload is spooled and forwarded to the auditor
+	expectedPayload := newMessage([]byte("fake line"), sources.NewLogSource("", &config.LogsConfig{}), "")
+	flushWg.Add(1)
+	input <- expectedPayload
+	assert.Equal(t, expectedPayload, <-output)
+	assert.Equal(t, 1, spool.Len())
+	// the flush does not wait for the spooled payload
+	flushWg.Wait()
+
+	// the destination recovers, the spooled payload is replayed without its messages
+	destination.isRetrying <- false
+	destination.Unlock()
+	replayed := <-output
+	assert.Equal(t, expectedPayload.Encoded, replayed.Encoded)
+	assert.Empty(t, replayed.Messages)
+
+	sender.Stop()
+	assert.Equal(t, 0, spool.Len())
+}
@@ -168,6 +168,11 @@ func (b *Builder) toDictionary(c *config.LogsConfig) map[string]interface{} {
 	switch c.Type {
 	case config.TCPType, config.UDPType:
 		dictionary["Port"] = c.Port
+	case config.UnixType:
+		dictionary["Path"] = c.Path
+		dictionary["SocketType"] = c.SocketType
+	case config.FIFOType:
+		dictionary["Path"] = c.Path
 	case config.FileType:
 		dictionary["Path"] = c.Path
 		dictionary["TailingMode"] = c.TailingMode
@@ -199,6 +204,8 @@ func (b *Builder) toDictionary(c *config.LogsConfig) map[string]interface{} {
 func (b *Builder) getMetricsStatus() map[string]string {
 	var metrics = make(map[string]string)
 	metrics["LogsProcessed"] = fmt.Sprintf("%v", b.logsExpVars.Get("LogsProcessed").(*expvar.Int).Value())
+	metrics["LogsDeduplicated"] = fmt.Sprintf("%v", b.logsExpVars.Get("LogsDeduplicated").(*expvar.Int).Value())
+	metrics["LogsRateLimited"] = fmt.Sprintf("%v", b.logsExpVars.Get("LogsRateLimited").(*expvar.Int).Value())
 	metrics["LogsSent"] = fmt.Sprintf("%v", b.logsExpVars.Get("LogsSent").(*expvar.Int).Value())
 	metrics["BytesSent"] = fmt.Sprintf("%v", b.logsExpVars.Get("BytesSent").(*expvar.Int).Value())
 	metrics["RetryCount"] = fmt.Sprintf("%v", b.logsExpVars.Get("RetryCount").(*expvar.Int).Value())
@@ -96,13 +96,13 @@ func TestStatusDeduplicateErrorsAndWarnings(t *testing.T) {
 func TestMetrics(t *testing.T) {
 	defer Clear()
 	Clear()
-	var expected = `{"BytesSent": 0, "DestinationErrors": 0, "DestinationLogsDropped": {}, "EncodedBytesSent": 0, "Errors": "", "HttpDestinationStats": {}, "IsRunning": false, "LogsDecoded": 0, "LogsProcessed": 0, "LogsSent": 0, "RetryCount": 0, "RetryTimeSpent": 0, "SenderLatency": 0, "Warnings": ""}`
+	var expected = `{"BytesSent": 0, "DestinationErrors": 0, "DestinationLogsDropped": {}, "EncodedBytesSent": 0, "Errors": "", "HttpDestinationStats": {}, "IsRunning": false, "LogsDecoded": 0, "LogsDeduplicated": 0, "LogsProcessed": 0, "LogsRateLimited": 0, "LogsSent": 0, "RetryCount": 0, "RetryTimeSpent": 0, "SenderLatency": 0, "Warnings": ""}`
 	assert.Equal(t, expected, metrics.LogsExpvars.String())
 
 	initStatus()
 	AddGlobalWarning("bar", "Unique Warning")
 	AddGlobalError("bar", "I am an error")
-	expected = `{"BytesSent": 0, "DestinationErrors": 0, "DestinationLogsDropped": {}, "EncodedBytesSent": 0, "Errors": "I am an error", "HttpDestinationStats": {}, "IsRunning": true, "LogsDecoded": 0, "LogsProcessed": 0, "LogsSent": 0, "RetryCount": 0, "RetryTimeSpent": 0, "SenderLatency": 0, "Warnings": "Unique Warning"}`
+	expected = `{"BytesSent": 0, "DestinationErrors": 0, "DestinationLogsDropped": {}, "EncodedBytesSent": 0, "Errors": "I am an error", "HttpDestinationStats": {}, "IsRunning": true, "LogsDecoded": 0, "LogsDeduplicated": 0, "LogsProcessed": 0, "LogsRateLimited": 0, "LogsSent": 0, "RetryCount": 0, "RetryTimeSpent": 0, "SenderLatency": 0, "Warnings": "Unique Warning"}`
 	assert.Equal(t, expected, metrics.LogsExpvars.String())
 }
 
@@ -112,6 +112,8 @@ func TestStatusMetrics(t *testing.T) {
 
 	status := Get(false)
 	assert.Equal(t, "0", status.StatusMetrics["LogsProcessed"])
+	assert.Equal(t, "0", status.StatusMetrics["LogsDeduplicated"])
+	assert.Equal(t, "0", status.StatusMetrics["LogsRateLimited"])
 	assert.Equal(t, "0", status.StatusMetrics["LogsSent"])
 	assert.Equal(t, "0", status.StatusMetrics["BytesSent"])
 	assert.Equal(t, "0", status.StatusMetrics["EncodedBytesSent"])
@@ -119,6 +121,8 @@ func TestStatusMetrics(t *testing.T) {
 	assert.Equal(t, "0s", status.StatusMetrics["RetryTimeSpent"])
 
 	metrics.LogsProcessed.Set(5)
+	metrics.LogsDeduplicated.Set(7)
+	metrics.LogsRateLimited.Set(9)
 	metrics.LogsSent.Set(3)
 	metrics.BytesSent.Set(42)
 	metrics.EncodedBytesSent.Set(21)
@@ -127,6 +131,8 @@ func TestStatusMetrics(t *testing.T) {
 	status = Get(false)
 
 	assert.Equal(t, "5", status.StatusMetrics["LogsProcessed"])
+	assert.Equal(t, "7", status.StatusMetrics["LogsDeduplicated"])
+	assert.Equal(t, "9", status.StatusMetrics["LogsRateLimited"])
 	assert.Equal(t, "3", status.StatusMetrics["LogsSent"])
 	assert.Equal(t, "42", status.StatusMetrics["BytesSent"])
 	assert.Equal(t, "21", status.StatusMetrics["EncodedBytesSent"])
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/DataDog/zstd"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// IsCompressed returns true if the path is a compressed file which can be
+// read by the tailer, i.e. a gzip (.gz) or a zstd (.zst) file.
+func IsCompressed(path string) bool {
+	switch filepath.Ext(path) {
+	case ".gz", ".zst":
+		return true
+	}
+	return false
+}
+
+// newDecompressor returns a reader of the decompressed content of the file.
+func newDecompressor(r io.Reader, path string) (io.ReadCloser, error) {
+	switch filepath.Ext(path) {
+	case ".gz":
+		return gzip.NewReader(r)
+	case ".zst":
+		return zstd.NewReader(r), nil
+	}
+	return nil, fmt.Errorf("unsupported compression for file %s", path)
+}
+
+// setupCompressed sets up the tailer of a compressed file. The offsets of a
+// compressed file are offsets in its decompressed content, the content before
+// the offset is decompressed and discarded.
+func (t *Tailer) setupCompressed(offset int64, whence int) error {
+	if whence != io.SeekStart {
+		return fmt.Errorf("compressed file %s can only be read from its beginning", t.file.Path)
+	}
+	fullpath, err := filepath.Abs(t.file.Path)
+	if err != nil {
+		return err
+	}
+	t.fullpath = fullpath
+
+	// adds metadata to enable users to filter logs by filename
+	t.tags = t.buildTailerTags()
+
+	log.Info("Opening compressed file", t.file.Path, "for tailer key", t.file.GetScanKey())
+	f, err := filesystem.OpenShared(fullpath)
+	if err != nil {
+		return err
+	}
+	decompressor, err := newDecompressor(f, fullpath)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	skipped, err := io.CopyN(io.Discard, decompressor, offset)
+	if err != nil && err != io.EOF {
+		decompressor.Close()
+		f.Close()
+		return err
+	}
+
+	t.osFile = f
+	t.decompressor = decompressor
+	t.lastReadOffset.Store(skipped)
+	t.decodedOffset.Store(skipped)
+
+	return nil
+}
+
+// readCompressed reads the decompressed content of a compressed file. Unlike
+// the plain files, the compressed files are not appended to, so it returns
+// io.EOF to stop the tailer once the whole file has been read.
+func (t *Tailer) readCompressed() (int, error) {
+	inBuf := make([]byte, 4096)
+	n, err := t.decompressor.Read(inBuf)
+	if err != nil && err != io.EOF {
+		// the file is corrupted or truncated, stop the tailer
+		t.file.Source.Status().Error(err)
+		return 0, log.Error("Unexpected error occurred while decompressing file: ", err)
+	}
+	if n == 0 {
+		if err == io.EOF {
+			t.decompressed.Store(true)
+		}
+		return 0, err
+	}
+	t.lastReadOffset.Add(int64(n))
+	t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
+	return n, nil
+}
@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"hash/fnv"
+	"io"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// computeFingerprint returns the fingerprint of the first size bytes of the file.
+// An empty fingerprint is returned when the fingerprinting is disabled or when the
+// file is smaller than size, in which case the file is identified by its path only.
+func computeFingerprint(f io.ReaderAt, size int) (string, error) {
+	if size <= 0 {
+		return "", nil
+	}
+	buf := make([]byte, size)
+	n, err := f.ReadAt(buf, 0)
+	if n < size {
+		if err == io.EOF {
+			err = nil
+		}
+		return "", err
+	}
+	h := fnv.New64a()
+	h.Write(buf)
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
+
+// Fingerprint returns the fingerprint of the content of the tailed file, it is
+// computed once the file is large enough to be fingerprinted. It returns an empty
+// string if the file is not fingerprinted.
+func (t *Tailer) Fingerprint() string {
+	if fingerprint := t.fingerprint.Load(); fingerprint != "" || t.fingerprintSize <= 0 {
+		return fingerprint
+	}
+	f, err := filesystem.OpenShared(t.file.Path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	return t.updateFingerprint(f)
+}
+
+// updateFingerprint sets the fingerprint of the tailed file from f if it is not
+// known yet, and returns it.
+func (t *Tailer) updateFingerprint(f io.ReaderAt) string {
+	if fingerprint := t.fingerprint.Load(); fingerprint != "" || t.fingerprintSize <= 0 {
+		return fingerprint
+	}
+	fingerprint, err := computeFingerprint(f, t.fingerprintSize)
+	if err != nil {
+		log.Debugf("Could not fingerprint file %s: %v", t.file.Path, err)
+		return ""
+	}
+	if fingerprint != "" {
+		t.fingerprint.Store(fingerprint)
+	}
+	return fingerprint
+}
+
+// didFingerprintChange returns true if the content of f, the file currently at
+// the path of the tailer, is not the content of the tailed file.
+func (t *Tailer) didFingerprintChange(f io.ReaderAt) bool {
+	fingerprint := t.fingerprint.Load()
+	if fingerprint == "" {
+		return false
+	}
+	current, err := computeFingerprint(f, t.fingerprintSize)
+	if err != nil || current == "" {
+		// the file is smaller than the fingerprint, it has been truncated which
+		// is detected by the caller
+		return false
+	}
+	return current != fingerprint
+}
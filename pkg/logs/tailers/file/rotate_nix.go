@@ -21,6 +21,8 @@ import (
 // - renamed and recreated
 // - removed and recreated
 // - truncated
+// - truncated and written again (copytruncate), or replaced by a file reusing
+// its inode, which are detected when the file is fingerprinted
 func (t *Tailer) DidRotate() (bool, error) {
 	f, err := filesystem.OpenShared(t.osFile.Name())
 	if err != nil {
@@ -41,14 +43,20 @@ func (t *Tailer) DidRotate() (bool, error) {
 
 	fileSize := fi1.Size()
 
+	// the tailed file may not have been large enough to be fingerprinted yet
+	t.updateFingerprint(t.osFile)
+
 	recreated := !os.SameFile(fi1, fi2)
 	truncated := fileSize < lastReadOffset
+	replaced := t.didFingerprintChange(f)
 
 	if recreated {
 		log.Debugf("File rotation detected due to recreation, f1: %+v, f2: %+v", fi1, fi2)
 	} else if truncated {
 		log.Debugf("File rotation detected due to size change, lastReadOffset=%d, fileSize=%d", lastReadOffset, fileSize)
+	} else if replaced {
+		log.Debugf("File rotation detected due to fingerprint change, lastReadOffset=%d, fileSize=%d", lastReadOffset, fileSize)
 	}
 
-	return recreated || truncated, nil
+	return recreated || truncated || replaced, nil
 }
@@ -17,7 +17,8 @@ import (
 // DidRotate returns true if the file has been log-rotated.
 //
 // On Windows, log rotation is identified by the file size being smaller
-// than the last offset read.
+// than the last offset read, or by the fingerprint of the file being changed
+// when the file is fingerprinted.
 func (t *Tailer) DidRotate() (bool, error) {
 	f, err := filesystem.OpenShared(t.fullpath)
 	if err != nil {
@@ -43,5 +44,12 @@ func (t *Tailer) DidRotate() (bool, error) {
 		return true, nil
 	}
 
+	if t.didFingerprintChange(f) {
+		log.Debugf("File rotation detected due to fingerprint change, lastReadOffset=%d, fileSize=%d", offset, sz)
+		return true, nil
+	}
+	// the file may not have been large enough to be fingerprinted yet
+	t.updateFingerprint(f)
+
 	return false, nil
 }
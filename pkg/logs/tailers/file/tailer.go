@@ -61,6 +61,21 @@ type Tailer struct {
 	// is platform-specific.
 	osFile *os.File
 
+	// compressed is true when the file is a compressed file, which is read once
+	// until its end.
+	compressed bool
+
+	// decompressor reads the decompressed content of osFile when the file is compressed.
+	decompressor io.ReadCloser
+
+	// decompressed is true once the whole content of a compressed file has been read.
+	decompressed *atomic.Bool
+
+	// fingerprint identifies the content of the file, it is the hash of its first
+	// fingerprintSize bytes. It is empty until the file is large enough.
+	fingerprint     *atomic.String
+	fingerprintSize int
+
 	// tags are the tags to be attached to each log message, excluding tags provided
 	// by the tag provider.
 	tags []string
@@ -128,6 +143,7 @@ type TailerOptions struct {
 	Decoder       *decoder.Decoder      // Required
 	Info          *status.InfoRegistry  // Required
 	Rotated       bool                  // Optional
+	Compressed    bool                  // Optional
 }
 
 // NewTailer returns an initialized Tailer, read to be started.
@@ -150,6 +166,11 @@ func NewTailer(opts *TailerOptions) *Tailer {
 	forwardContext, stopForward := context.WithCancel(context.Background())
 	closeTimeout := coreConfig.Datadog().GetDuration("logs_config.close_timeout") * time.Second
 	windowsOpenFileTimeout := coreConfig.Datadog().GetDuration("logs_config.windows_open_file_timeout") * time.Second
+	fingerprintSize := coreConfig.Datadog().GetInt("logs_config.fingerprint_size")
+	if opts.Compressed {
+		// the compressed files are not appended to, they are identified by their path
+		fingerprintSize = 0
+	}
 
 	bytesRead := status.NewCountInfo("Bytes Read")
 	fileRotated := opts.Rotated
@@ -180,6 +201,10 @@ func NewTailer(opts *TailerOptions) *Tailer {
 		info:                   opts.Info,
 		bytesRead:              bytesRead,
 		movingSum:              movingSum,
+		compressed:             opts.Compressed,
+		decompressed:           atomic.NewBool(false),
+		fingerprint:            atomic.NewString(""),
+		fingerprintSize:        fingerprintSize,
 	}
 
 	if fileRotated {
@@ -220,12 +245,18 @@ func (t *Tailer) Identifier() string {
 	//
 	// This is the identifier used in the registry, so changing it will invalidate existing
 	// registry entries on upgrade.
-	return fmt.Sprintf("file:%s", t.file.Path)
+	return t.file.Identifier()
 }
 
 // Start begins the tailer's operation in a dedicated goroutine.
+// A compressed file can only be read from an offset relative to its beginning.
 func (t *Tailer) Start(offset int64, whence int) error {
-	err := t.setup(offset, whence)
+	var err error
+	if t.compressed {
+		err = t.setupCompressed(offset, whence)
+	} else {
+		err = t.setup(offset, whence)
+	}
 	if err != nil {
 		t.file.Source.Status().Error(err)
 		return err
@@ -281,13 +312,21 @@ func (t *Tailer) StopAfterFileRotation() {
 // until it is closed or the tailer is stopped.
 func (t *Tailer) readForever() {
 	defer func() {
+		if t.decompressor != nil {
+			t.decompressor.Close()
+		}
 		t.osFile.Close()
 		t.decoder.Stop()
 		log.Info("Closed", t.file.Path, "for tailer key", t.file.GetScanKey(), "read", t.Source().BytesRead.Get(), "bytes and", t.decoder.GetLineCount(), "lines")
 	}()
 
+	read := t.read
+	if t.compressed {
+		read = t.readCompressed
+	}
+
 	for {
-		n, err := t.read()
+		n, err := read()
 		if err != nil {
 			return
 		}
@@ -333,6 +372,9 @@ func (t *Tailer) forwardMessages() {
 		t.isFinished.Store(true)
 		close(t.done)
 	}()
+	// the last message of a compressed file is held back until the decoder is
+	// flushed, to mark it as completed if the file was read until its end
+	var pending *message.Message
 	for output := range t.decoder.OutputChan {
 		offset := t.decodedOffset.Load() + int64(output.RawDataLen)
 		identifier := t.Identifier()
@@ -344,20 +386,37 @@ func (t *Tailer) forwardMessages() {
 		origin := message.NewOrigin(t.file.Source.UnderlyingSource())
 		origin.Identifier = identifier
 		origin.Offset = strconv.FormatInt(offset, 10)
+		origin.Fingerprint = t.fingerprint.Load()
 		origin.SetTags(append(t.tags, t.tagProvider.GetTags()...))
 		// Ignore empty lines once the registry offset is updated
 		if len(output.GetContent()) == 0 {
 			continue
 		}
-		// Make the write to the output chan cancellable to be able to stop the tailer
-		// after a file rotation when it is stuck on it.
-		// We don't return directly to keep the same shutdown sequence that in the
-		// normal case.
-		select {
 		// XXX(remy): is it ok recreating a message like this here?
-		case t.outputChan <- message.NewMessage(output.GetContent(), origin, output.Status, output.IngestionTimestamp):
-		case <-t.forwardContext.Done():
+		msg := message.NewMessage(output.GetContent(), origin, output.Status, output.IngestionTimestamp)
+		if t.compressed {
+			msg, pending = pending, msg
+			if msg == nil {
+				continue
+			}
 		}
+		t.forwardMessage(msg)
+	}
+	if pending != nil {
+		pending.Origin.Completed = t.decompressed.Load()
+		t.forwardMessage(pending)
+	}
+}
+
+// forwardMessage sends msg to the output channel.
+func (t *Tailer) forwardMessage(msg *message.Message) {
+	// Make the write to the output chan cancellable to be able to stop the tailer
+	// after a file rotation when it is stuck on it.
+	// We don't return directly to keep the same shutdown sequence that in the
+	// normal case.
+	select {
+	case t.outputChan <- msg:
+	case <-t.forwardContext.Done():
 	}
 }
 
@@ -6,6 +6,8 @@
 package file
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
@@ -15,6 +17,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DataDog/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
@@ -252,6 +257,50 @@ func (suite *TailerTestSuite) TestTailerIdentifier() {
 		suite.tailer.Identifier())
 }
 
+func (suite *TailerTestSuite) TestTailerFingerprint() {
+	suite.tailer.fingerprintSize = 16
+
+	_, err := suite.testFile.WriteString("hello world\n")
+	suite.Nil(err)
+	suite.Nil(suite.tailer.StartFromBeginning())
+	msg := <-suite.outputChan
+	// the file is too small to be fingerprinted
+	suite.Equal("", msg.Origin.Fingerprint)
+	suite.Equal("", suite.tailer.Fingerprint())
+
+	_, err = suite.testFile.WriteString("hello again\n")
+	suite.Nil(err)
+	msg = <-suite.outputChan
+	suite.Equal("hello again", string(msg.GetContent()))
+	fingerprint := suite.tailer.Fingerprint()
+	suite.NotEqual("", fingerprint)
+
+	_, err = suite.testFile.WriteString("good bye\n")
+	suite.Nil(err)
+	msg = <-suite.outputChan
+	suite.Equal(fingerprint, msg.Origin.Fingerprint)
+}
+
+func (suite *TailerTestSuite) TestDidRotateWhenFingerprintChanges() {
+	suite.tailer.fingerprintSize = 16
+
+	_, err := suite.testFile.WriteString("hello world, hello again\n")
+	suite.Nil(err)
+	suite.Nil(suite.tailer.StartFromBeginning())
+	<-suite.outputChan
+	didRotate, err := suite.tailer.DidRotate()
+	suite.Nil(err)
+	suite.False(didRotate)
+
+	// copytruncate followed by more writes than what has been read
+	suite.Nil(suite.testFile.Truncate(0))
+	_, err = suite.testFile.WriteAt([]byte("a different content, which is longer\n"), 0)
+	suite.Nil(err)
+	didRotate, err = suite.tailer.DidRotate()
+	suite.Nil(err)
+	suite.True(didRotate)
+}
+
 func (suite *TailerTestSuite) TestOriginTagsWhenTailingFiles() {
 
 	suite.tailer.StartFromBeginning()
@@ -398,3 +447,69 @@ func toInt(str string) int {
 	}
 	return 0
 }
+
+func TestTailCompressedFiles(t *testing.T) {
+	lines := "hello world\nhello again\ngood bye\n"
+	for _, ext := range []string{".gz", ".zst"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tailer.log.1"+ext)
+			var compressed bytes.Buffer
+			if ext == ".gz" {
+				w := gzip.NewWriter(&compressed)
+				_, err := w.Write([]byte(lines))
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+			} else {
+				data, err := zstd.Compress(nil, []byte(lines))
+				require.NoError(t, err)
+				compressed.Write(data)
+			}
+			require.NoError(t, os.WriteFile(path, compressed.Bytes(), 0o644))
+
+			outputChan := make(chan *message.Message, chanSize)
+			source := sources.NewReplaceableSource(sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path}))
+			info := status.NewInfoRegistry()
+			tailer := NewTailer(&TailerOptions{
+				OutputChan:    outputChan,
+				File:          NewFile(path, source.UnderlyingSource(), false),
+				SleepDuration: 10 * time.Millisecond,
+				Decoder:       decoder.NewDecoderFromSource(source, info),
+				Info:          info,
+				Compressed:    true,
+			})
+
+			// the offset is an offset in the decompressed content
+			require.NoError(t, tailer.Start(int64(len("hello world\n")), io.SeekStart))
+
+			msg := <-outputChan
+			assert.Equal(t, "hello again", string(msg.GetContent()))
+			assert.Equal(t, "file:"+path, msg.Origin.Identifier)
+			assert.Equal(t, len("hello world\nhello again\n"), toInt(msg.Origin.Offset))
+			assert.False(t, msg.Origin.Completed)
+			msg = <-outputChan
+			assert.Equal(t, "good bye", string(msg.GetContent()))
+			assert.Equal(t, len(lines), toInt(msg.Origin.Offset))
+			// the last log marks the file as completed
+			assert.True(t, msg.Origin.Completed)
+
+			// the tailer stops once the whole file has been read
+			assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+			tailer.Stop()
+		})
+	}
+}
+
+func TestTailCompressedFileFromEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tailer.log.gz")
+	source := sources.NewReplaceableSource(sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path}))
+	info := status.NewInfoRegistry()
+	tailer := NewTailer(&TailerOptions{
+		OutputChan:    make(chan *message.Message),
+		File:          NewFile(path, source.UnderlyingSource(), false),
+		SleepDuration: 10 * time.Millisecond,
+		Decoder:       decoder.NewDecoderFromSource(source, info),
+		Info:          info,
+		Compressed:    true,
+	})
+	assert.Error(t, tailer.Start(0, io.SeekEnd))
+}
@@ -12,9 +12,12 @@ import (
 	"net"
 	"strings"
 
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/framer"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers/noop"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers/syslog"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/sources"
 	status "github.com/DataDog/datadog-agent/pkg/logs/status/utils"
@@ -40,13 +43,21 @@ func NewTailer(source *sources.LogSource, conn net.Conn, outputChan chan *messag
 		Conn:       conn,
 		outputChan: outputChan,
 		read:       read,
-		// tailer info is currently unused for this tailer type.
-		decoder: decoder.InitializeDecoder(sources.NewReplaceableSource(source), noop.New(), status.NewInfoRegistry()),
-		stop:    make(chan struct{}, 1),
-		done:    make(chan struct{}, 1),
+		decoder:    newDecoder(source),
+		stop:       make(chan struct{}, 1),
+		done:       make(chan struct{}, 1),
 	}
 }
 
+// newDecoder returns the decoder matching the format of the source.
+func newDecoder(source *sources.LogSource) *decoder.Decoder {
+	// tailer info is currently unused for this tailer type.
+	if source.Config.Format == config.SyslogFormat {
+		return decoder.NewDecoderWithFraming(sources.NewReplaceableSource(source), syslog.New(), framer.Syslog, nil, status.NewInfoRegistry())
+	}
+	return decoder.InitializeDecoder(sources.NewReplaceableSource(source), noop.New(), status.NewInfoRegistry())
+}
+
 // Start prepares the tailer to read and decode data from the connection
 func (t *Tailer) Start() {
 	go t.forwardMessages()
@@ -68,6 +79,11 @@ func (t *Tailer) forwardMessages() {
 		t.done <- struct{}{}
 	}()
 	for output := range t.decoder.OutputChan {
+		if output.State == message.StateStructured {
+			// the syslog messages have been parsed with their attributes
+			t.outputChan <- output
+			continue
+		}
 		if len(output.GetContent()) > 0 {
 			t.outputChan <- message.NewMessage(output.GetContent(), output.Origin, output.Status, output.IngestionTimestamp)
 		}
@@ -40,6 +40,30 @@ func TestReadAndForwardShouldSucceedWithSuccessfulRead(t *testing.T) {
 	tailer.Stop()
 }
 
+func TestReadAndForwardSyslogMessages(t *testing.T) {
+	msgChan := make(chan *message.Message)
+	r, w := net.Pipe()
+	tailer := NewTailer(sources.NewLogSource("", &config.LogsConfig{Format: config.SyslogFormat}), r, msgChan, read)
+	tailer.Start()
+
+	// octet counted framing, the message can contain newlines
+	go w.Write([]byte("46 <11>1 - host app 42 - - first line\nsecond line"))
+	msg := <-msgChan
+	assert.Equal(t, message.StateStructured, msg.State)
+	assert.Equal(t, "first line\nsecond line", string(msg.GetContent()))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "host", msg.Hostname)
+
+	// newline framing
+	go w.Write([]byte("<13>Feb  5 17:32:18 router sshd[7]: accepted\n"))
+	msg = <-msgChan
+	assert.Equal(t, "accepted", string(msg.GetContent()))
+	assert.Equal(t, message.StatusNotice, msg.GetStatus())
+	assert.Equal(t, "router", msg.Hostname)
+
+	tailer.Stop()
+}
+
 func TestReadShouldFailWithError(t *testing.T) {
 	msgChan := make(chan *message.Message)
 	r, w := net.Pipe()
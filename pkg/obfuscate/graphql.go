@@ -0,0 +1,205 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+)
+
+// ObfuscatedGraphQL holds the result of obfuscating a GraphQL document.
+type ObfuscatedGraphQL struct {
+	// Query holds the obfuscated document, where the literal values of the arguments and
+	// of the default values of the variables are replaced by "?", and where the comments
+	// and the insignificant whitespaces are removed.
+	Query string
+
+	// Signature holds the normalized signature of the operations of the document. It is
+	// the obfuscated document without aliases and commas, so that all the executions of
+	// an operation share the same signature.
+	Signature string
+}
+
+// ObfuscateGraphQLString obfuscates the given GraphQL document. The aliases of the fields are
+// removed from the obfuscated query if GraphQLConfig.RemoveAliases is set. An error is returned
+// if the document can not be tokenized.
+func (o *Obfuscator) ObfuscateGraphQLString(query string) (*ObfuscatedGraphQL, error) {
+	var toks []graphQLToken
+	tokenizer := newGraphQLTokenizer(query)
+	for {
+		tok, typ, err := tokenizer.scan()
+		if err != nil {
+			return nil, err
+		}
+		if typ == graphQLTokenEOF {
+			break
+		}
+		toks = append(toks, graphQLToken{tok, typ})
+	}
+	q := writeGraphQL(toks, o.opts.GraphQL.RemoveAliases, false)
+	return &ObfuscatedGraphQL{
+		Query:     q,
+		Signature: writeGraphQL(toks, true, true),
+	}, nil
+}
+
+// graphQLToken is a token returned by the tokenizer.
+type graphQLToken struct {
+	val string
+	typ graphQLTokenType
+}
+
+// graphQLWriter writes an obfuscated GraphQL document.
+type graphQLWriter struct {
+	toks          []graphQLToken
+	removeAliases bool // remove the aliases of the fields
+	removeCommas  bool // remove the (insignificant) commas
+	out           strings.Builder
+	last          graphQLToken // last written token
+}
+
+// writeGraphQL writes the obfuscated document made of toks.
+func writeGraphQL(toks []graphQLToken, removeAliases, removeCommas bool) string {
+	w := &graphQLWriter{toks: toks, removeAliases: removeAliases, removeCommas: removeCommas}
+	var (
+		depth   int  // depth of the selection sets
+		inArgs  bool // in the arguments of a field or a directive
+		inDefs  bool // in the variable definitions of an operation, possibly in the arguments of their directives
+		pending bool // the next token starts a value
+	)
+	for i := 0; i < len(toks); {
+		tok := toks[i]
+		if pending {
+			pending = false
+			i = w.writeValue(i)
+			continue
+		}
+		switch {
+		case tok.val == "(":
+			// outside of the selection sets, parentheses hold the variable definitions of an
+			// operation, unless they follow the name of a directive
+			if depth == 0 && (i < 2 || toks[i-2].val != "@") {
+				inDefs = true
+			} else {
+				inArgs = true
+			}
+		case tok.val == ")":
+			// the arguments of the directive of a variable definition are closed before the
+			// variable definitions
+			if inArgs {
+				inArgs = false
+			} else {
+				inDefs = false
+			}
+		case inArgs && tok.val == ":", inDefs && tok.val == "=":
+			pending = true
+		case inArgs || inDefs:
+		case tok.val == "{":
+			depth++
+		case tok.val == "}":
+			depth--
+		case w.removeAliases && depth > 0 && tok.typ == graphQLTokenName && i+1 < len(toks) && toks[i+1].val == ":":
+			// skip the alias and the colon
+			i += 2
+			continue
+		}
+		w.write(tok)
+		i++
+	}
+	return w.out.String()
+}
+
+// writeValue writes the value starting at toks[i], replacing its literals with "?", and returns the
+// index of the token following it.
+func (w *graphQLWriter) writeValue(i int) int {
+	tok := w.toks[i]
+	switch tok.typ {
+	case graphQLTokenInt, graphQLTokenFloat, graphQLTokenString:
+		w.write(graphQLToken{"?", graphQLTokenPunctuator})
+		return i + 1
+	case graphQLTokenName:
+		switch tok.val {
+		case "true", "false", "null":
+			w.write(graphQLToken{"?", graphQLTokenPunctuator})
+		default:
+			// enum values are part of the schema
+			w.write(tok)
+		}
+		return i + 1
+	}
+	switch tok.val {
+	case "$":
+		w.write(tok)
+		if i+1 < len(w.toks) {
+			w.write(w.toks[i+1])
+		}
+		return i + 2
+	case "[":
+		// lists are replaced as a whole so that their length does not change the result
+		for depth := 0; i < len(w.toks); i++ {
+			switch w.toks[i].val {
+			case "[":
+				depth++
+			case "]":
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		w.write(graphQLToken{"?", graphQLTokenPunctuator})
+		return i + 1
+	case "{":
+		w.write(tok)
+		for i++; i < len(w.toks) && w.toks[i].val != "}"; {
+			if w.toks[i].val == ":" {
+				w.write(w.toks[i])
+				if i+1 < len(w.toks) {
+					i = w.writeValue(i + 1)
+				} else {
+					i++
+				}
+				continue
+			}
+			w.write(w.toks[i])
+			i++
+		}
+		if i < len(w.toks) {
+			w.write(w.toks[i])
+		}
+		return i + 1
+	}
+	// not a value, leave it to the caller
+	return i
+}
+
+// write writes tok, separating it from the previous token with a space where needed.
+func (w *graphQLWriter) write(tok graphQLToken) {
+	if tok.val == "," && w.removeCommas {
+		return
+	}
+	if w.out.Len() > 0 && w.needsSpace(tok) {
+		w.out.WriteByte(' ')
+	}
+	w.out.WriteString(tok.val)
+	w.last = tok
+}
+
+// needsSpace reports whether tok should be separated from the last written token with a space.
+func (w *graphQLWriter) needsSpace(tok graphQLToken) bool {
+	switch w.last.val {
+	case "(", "[", "$", "@":
+		return false
+	case "...":
+		return tok.val == "on" || tok.val == "@" || tok.val == "{"
+	}
+	switch tok.val {
+	case ")", "]", ":", ",", "!":
+		return false
+	case "(":
+		return w.last.typ != graphQLTokenName
+	}
+	return true
+}
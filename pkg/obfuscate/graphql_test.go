@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLTokenizer(t *testing.T) {
+	type testResult struct {
+		tok string
+		typ graphQLTokenType
+	}
+	for _, tt := range []struct {
+		in  string
+		out []testResult
+	}{
+		{
+			in:  "",
+			out: nil,
+		},
+		{
+			in: "\ufeff# comment\nquery Q { a }",
+			out: []testResult{
+				{"query", graphQLTokenName},
+				{"Q", graphQLTokenName},
+				{"{", graphQLTokenPunctuator},
+				{"a", graphQLTokenName},
+				{"}", graphQLTokenPunctuator},
+			},
+		},
+		{
+			in: `f(a: -12, b: 1.5e-3, c: "x\"y", d: """block "quoted" \""" text""") { ...F ... on T }`,
+			out: []testResult{
+				{"f", graphQLTokenName},
+				{"(", graphQLTokenPunctuator},
+				{"a", graphQLTokenName},
+				{":", graphQLTokenPunctuator},
+				{"-12", graphQLTokenInt},
+				{",", graphQLTokenPunctuator},
+				{"b", graphQLTokenName},
+				{":", graphQLTokenPunctuator},
+				{"1.5e-3", graphQLTokenFloat},
+				{",", graphQLTokenPunctuator},
+				{"c", graphQLTokenName},
+				{":", graphQLTokenPunctuator},
+				{`"x\"y"`, graphQLTokenString},
+				{",", graphQLTokenPunctuator},
+				{"d", graphQLTokenName},
+				{":", graphQLTokenPunctuator},
+				{`"""block "quoted" \""" text"""`, graphQLTokenString},
+				{")", graphQLTokenPunctuator},
+				{"{", graphQLTokenPunctuator},
+				{"...", graphQLTokenPunctuator},
+				{"F", graphQLTokenName},
+				{"...", graphQLTokenPunctuator},
+				{"on", graphQLTokenName},
+				{"T", graphQLTokenName},
+				{"}", graphQLTokenPunctuator},
+			},
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			tokenizer := newGraphQLTokenizer(tt.in)
+			var out []testResult
+			for {
+				tok, typ, err := tokenizer.scan()
+				require.NoError(t, err)
+				if typ == graphQLTokenEOF {
+					break
+				}
+				out = append(out, testResult{tok, typ})
+			}
+			assert.Equal(t, tt.out, out)
+		})
+	}
+
+	for _, in := range []string{
+		`{ a(b: "unterminated) }`,
+		`{ a(b: "new` + "\n" + `line") }`,
+		`{ a(b: """unterminated) }`,
+		`{ a(b: 12abc) }`,
+		`{ a(b: 1.) }`,
+		`{ a(b: -) }`,
+		`{ a.b }`,
+		`{ a(b: 'c') }`,
+	} {
+		t.Run("invalid", func(t *testing.T) {
+			tokenizer := newGraphQLTokenizer(in)
+			for {
+				_, typ, err := tokenizer.scan()
+				if err != nil {
+					return
+				}
+				if typ == graphQLTokenEOF {
+					t.Fatalf("no error tokenizing %q", in)
+				}
+			}
+		})
+	}
+}
+
+func TestObfuscateGraphQLString(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		in        string
+		query     string
+		signature string
+	}{
+		{
+			name:      "shorthand",
+			in:        "{ user { name } }",
+			query:     "{ user { name } }",
+			signature: "{ user { name } }",
+		},
+		{
+			name: "arguments",
+			in: `query GetUser {
+  user(id: 42, email: "jane@example.com", ratio: 1.5, active: true, deleted: null, role: ADMIN) {
+    name # the full name
+  }
+}`,
+			query:     "query GetUser { user(id: ?, email: ?, ratio: ?, active: ?, deleted: ?, role: ADMIN) { name } }",
+			signature: "query GetUser { user(id: ? email: ? ratio: ? active: ? deleted: ? role: ADMIN) { name } }",
+		},
+		{
+			name:      "variables",
+			in:        `query Search($term: String! = "secret", $first: Int = 10, $ids: [ID!]!) { search(term: $term, first: $first, ids: $ids) { id } }`,
+			query:     "query Search($term: String! = ?, $first: Int = ?, $ids: [ID!]!) { search(term: $term, first: $first, ids: $ids) { id } }",
+			signature: "query Search($term: String! = ? $first: Int = ? $ids: [ID!]!) { search(term: $term first: $first ids: $ids) { id } }",
+		},
+		{
+			name:      "lists and objects",
+			in:        `mutation { createUser(input: {name: "Jane", tags: ["a", "b"], address: {zip: 12345, country: FR}}, ids: [[1, 2], [3]]) { id } }`,
+			query:     "mutation { createUser(input: { name: ?, tags: ?, address: { zip: ?, country: FR } }, ids: ?) { id } }",
+			signature: "mutation { createUser(input: { name: ? tags: ? address: { zip: ? country: FR } } ids: ?) { id } }",
+		},
+		{
+			name:      "aliases",
+			in:        `{ me: user(id: 1) { fullName: name } other: user(id: 2) { name } }`,
+			query:     "{ me: user(id: ?) { fullName: name } other: user(id: ?) { name } }",
+			signature: "{ user(id: ?) { name } user(id: ?) { name } }",
+		},
+		{
+			name:      "directives and fragments",
+			in:        `query Q($withFriends: Boolean!) @cached(ttl: 60) { user(id: "1") { ...UserFields friends @include(if: $withFriends) { ... on User { name } } } } fragment UserFields on User { id avatar(size: 64) }`,
+			query:     "query Q($withFriends: Boolean!) @cached(ttl: ?) { user(id: ?) { ...UserFields friends @include(if: $withFriends) { ... on User { name } } } } fragment UserFields on User { id avatar(size: ?) }",
+			signature: "query Q($withFriends: Boolean!) @cached(ttl: ?) { user(id: ?) { ...UserFields friends @include(if: $withFriends) { ... on User { name } } } } fragment UserFields on User { id avatar(size: ?) }",
+		},
+		{
+			name:      "directives of variable definitions",
+			in:        `query Q($a: Int @d(x: 1), $b: String = "secret") { user(a: $a, b: $b) { id } }`,
+			query:     "query Q($a: Int @d(x: ?), $b: String = ?) { user(a: $a, b: $b) { id } }",
+			signature: "query Q($a: Int @d(x: ?) $b: String = ?) { user(a: $a b: $b) { id } }",
+		},
+		{
+			name:      "block string",
+			in:        `mutation { post(body: """multi` + "\n" + `line""") { id } }`,
+			query:     "mutation { post(body: ?) { id } }",
+			signature: "mutation { post(body: ?) { id } }",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			oq, err := NewObfuscator(Config{}).ObfuscateGraphQLString(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.query, oq.Query)
+			assert.Equal(t, tt.signature, oq.Signature)
+		})
+	}
+
+	t.Run("remove_aliases", func(t *testing.T) {
+		o := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true, RemoveAliases: true}})
+		oq, err := o.ObfuscateGraphQLString(`{ me: user(id: 1) { fullName: name } }`)
+		require.NoError(t, err)
+		assert.Equal(t, "{ user(id: ?) { name } }", oq.Query)
+		assert.Equal(t, "{ user(id: ?) { name } }", oq.Signature)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewObfuscator(Config{}).ObfuscateGraphQLString(`{ user(email: "jane@example.com) { id } }`)
+		assert.EqualError(t, err, "unterminated string at position 14")
+	})
+}
+
+func BenchmarkObfuscateGraphQLString(b *testing.B) {
+	o := NewObfuscator(Config{})
+	query := `query Search($term: String! = "secret") { me: user(id: 42) { name friends(first: 10, after: "abc") { edges { node { id } } } } search(term: $term) { id } }`
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := o.ObfuscateGraphQLString(query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
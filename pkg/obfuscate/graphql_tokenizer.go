@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"fmt"
+)
+
+// graphQLTokenType specifies the token type returned by the tokenizer.
+type graphQLTokenType int
+
+const (
+	// graphQLTokenEOF is returned once the whole document was scanned.
+	graphQLTokenEOF graphQLTokenType = iota
+
+	// graphQLTokenPunctuator is one of "!", "$", "&", "(", ")", "...", ":", "=", "@", "[", "]", "{", "|", "}"
+	// or a comma. Commas are insignificant in GraphQL but are kept to preserve the formatting of lists.
+	graphQLTokenPunctuator
+
+	// graphQLTokenName is a name, such as a field, an argument, a keyword or an enum value.
+	graphQLTokenName
+
+	// graphQLTokenInt is an integer literal.
+	graphQLTokenInt
+
+	// graphQLTokenFloat is a float literal.
+	graphQLTokenFloat
+
+	// graphQLTokenString is a string or a block string literal.
+	graphQLTokenString
+)
+
+// String implements fmt.Stringer.
+func (t graphQLTokenType) String() string {
+	return map[graphQLTokenType]string{
+		graphQLTokenEOF:        "EOF",
+		graphQLTokenPunctuator: "punctuator",
+		graphQLTokenName:       "name",
+		graphQLTokenInt:        "int",
+		graphQLTokenFloat:      "float",
+		graphQLTokenString:     "string",
+	}[t]
+}
+
+// graphQLTokenizer tokenizes a GraphQL document, skipping the whitespaces and the comments.
+// See https://spec.graphql.org/October2021/#sec-Language.Source-Text.
+type graphQLTokenizer struct {
+	data string
+	off  int
+}
+
+// newGraphQLTokenizer returns a new tokenizer for the given document.
+func newGraphQLTokenizer(data string) *graphQLTokenizer {
+	return &graphQLTokenizer{data: data}
+}
+
+// scan returns the next token and its type, or an error if the document is malformed.
+func (t *graphQLTokenizer) scan() (tok string, typ graphQLTokenType, err error) {
+	t.skipIgnored()
+	if t.off >= len(t.data) {
+		return "", graphQLTokenEOF, nil
+	}
+	start := t.off
+	ch := t.data[t.off]
+	switch {
+	case isGraphQLNameStart(ch):
+		t.off++
+		for t.off < len(t.data) && isGraphQLNameContinue(t.data[t.off]) {
+			t.off++
+		}
+		return t.data[start:t.off], graphQLTokenName, nil
+	case ch == '-' || isDigit(rune(ch)):
+		return t.scanNumber()
+	case ch == '"':
+		return t.scanString()
+	case ch == '.':
+		if len(t.data)-t.off < 3 || t.data[t.off:t.off+3] != "..." {
+			return "", graphQLTokenEOF, fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+		}
+		t.off += 3
+		return "...", graphQLTokenPunctuator, nil
+	}
+	switch ch {
+	case '!', '$', '&', '(', ')', ':', '=', '@', '[', ']', '{', '|', '}', ',':
+		t.off++
+		return t.data[start:t.off], graphQLTokenPunctuator, nil
+	}
+	return "", graphQLTokenEOF, fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+}
+
+// skipIgnored moves the cursor past any whitespace, line terminator, byte order mark and comment.
+func (t *graphQLTokenizer) skipIgnored() {
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case ' ', '\t', '\n', '\r':
+			t.off++
+		case '#':
+			for t.off < len(t.data) && t.data[t.off] != '\n' && t.data[t.off] != '\r' {
+				t.off++
+			}
+		case 0xef:
+			// UTF-8 encoded byte order mark
+			if len(t.data)-t.off < 3 || t.data[t.off:t.off+3] != "\ufeff" {
+				return
+			}
+			t.off += 3
+		default:
+			return
+		}
+	}
+}
+
+// scanNumber scans an integer or a float literal.
+func (t *graphQLTokenizer) scanNumber() (tok string, typ graphQLTokenType, err error) {
+	start := t.off
+	typ = graphQLTokenInt
+	if t.data[t.off] == '-' {
+		t.off++
+	}
+	if !t.skipDigits() {
+		return "", graphQLTokenEOF, fmt.Errorf("invalid number at position %d", start)
+	}
+	if t.off < len(t.data) && t.data[t.off] == '.' {
+		t.off++
+		typ = graphQLTokenFloat
+		if !t.skipDigits() {
+			return "", graphQLTokenEOF, fmt.Errorf("invalid number at position %d", start)
+		}
+	}
+	if t.off < len(t.data) && (t.data[t.off] == 'e' || t.data[t.off] == 'E') {
+		t.off++
+		typ = graphQLTokenFloat
+		if t.off < len(t.data) && (t.data[t.off] == '+' || t.data[t.off] == '-') {
+			t.off++
+		}
+		if !t.skipDigits() {
+			return "", graphQLTokenEOF, fmt.Errorf("invalid number at position %d", start)
+		}
+	}
+	if t.off < len(t.data) && (isGraphQLNameStart(t.data[t.off]) || t.data[t.off] == '.') {
+		return "", graphQLTokenEOF, fmt.Errorf("invalid number at position %d", start)
+	}
+	return t.data[start:t.off], typ, nil
+}
+
+// skipDigits moves the cursor past a sequence of digits, reporting whether there was any.
+func (t *graphQLTokenizer) skipDigits() bool {
+	start := t.off
+	for t.off < len(t.data) && isDigit(rune(t.data[t.off])) {
+		t.off++
+	}
+	return t.off > start
+}
+
+// scanString scans a string or a block string literal.
+func (t *graphQLTokenizer) scanString() (tok string, typ graphQLTokenType, err error) {
+	start := t.off
+	if len(t.data)-t.off >= 3 && t.data[t.off:t.off+3] == `"""` {
+		t.off += 3
+		for t.off < len(t.data) {
+			switch {
+			case len(t.data)-t.off >= 4 && t.data[t.off:t.off+4] == `\"""`:
+				t.off += 4
+			case len(t.data)-t.off >= 3 && t.data[t.off:t.off+3] == `"""`:
+				t.off += 3
+				return t.data[start:t.off], graphQLTokenString, nil
+			default:
+				t.off++
+			}
+		}
+		return "", graphQLTokenEOF, fmt.Errorf("unterminated string at position %d", start)
+	}
+	t.off++
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case '\\':
+			t.off += 2
+		case '"':
+			t.off++
+			return t.data[start:t.off], graphQLTokenString, nil
+		case '\n', '\r':
+			return "", graphQLTokenEOF, fmt.Errorf("unterminated string at position %d", start)
+		default:
+			t.off++
+		}
+	}
+	return "", graphQLTokenEOF, fmt.Errorf("unterminated string at position %d", start)
+}
+
+func isGraphQLNameStart(ch byte) bool {
+	return ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+}
+
+func isGraphQLNameContinue(ch byte) bool {
+	return isGraphQLNameStart(ch) || isDigit(rune(ch))
+}
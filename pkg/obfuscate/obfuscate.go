@@ -94,6 +94,9 @@ type Config struct {
 	// Memcached holds the obfuscation settings for Memcached commands.
 	Memcached MemcachedConfig
 
+	// GraphQL holds the obfuscation settings for GraphQL queries.
+	GraphQL GraphQLConfig
+
 	// Memcached holds the obfuscation settings for obfuscation of CC numbers in meta.
 	CreditCard CreditCardsConfig
 
@@ -234,6 +237,16 @@ type MemcachedConfig struct {
 	KeepCommand bool `mapstructure:"keep_command"`
 }
 
+// GraphQLConfig holds the configuration settings for GraphQL obfuscation
+type GraphQLConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// RemoveAliases specifies whether the aliases of the fields should
+	// be removed from the obfuscated query.
+	RemoveAliases bool `mapstructure:"remove_aliases"`
+}
+
 // JSONConfig holds the obfuscation configuration for sensitive
 // data found in JSON objects.
 type JSONConfig struct {
@@ -137,6 +137,25 @@ func (z *ClientGroupedStats) DecodeMsg(dc *msgp.Reader) (err error) {
 				}
 				z.IsTraceRoot = Trilean(zb0003)
 			}
+		case "CustomTags":
+			var zb0004 uint32
+			zb0004, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "CustomTags")
+				return
+			}
+			if cap(z.CustomTags) >= int(zb0004) {
+				z.CustomTags = (z.CustomTags)[:zb0004]
+			} else {
+				z.CustomTags = make([]string, zb0004)
+			}
+			for za0002 := range z.CustomTags {
+				z.CustomTags[za0002], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "CustomTags", za0002)
+					return
+				}
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -150,9 +169,9 @@ func (z *ClientGroupedStats) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *ClientGroupedStats) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 16
+	// map header, size 17
 	// write "Service"
-	err = en.Append(0xde, 0x0, 0x10, 0xa7, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65)
+	err = en.Append(0xde, 0x0, 0x11, 0xa7, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65)
 	if err != nil {
 		return
 	}
@@ -318,15 +337,32 @@ func (z *ClientGroupedStats) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "IsTraceRoot")
 		return
 	}
+	// write "CustomTags"
+	err = en.Append(0xaa, 0x43, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x54, 0x61, 0x67, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.CustomTags)))
+	if err != nil {
+		err = msgp.WrapError(err, "CustomTags")
+		return
+	}
+	for za0002 := range z.CustomTags {
+		err = en.WriteString(z.CustomTags[za0002])
+		if err != nil {
+			err = msgp.WrapError(err, "CustomTags", za0002)
+			return
+		}
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *ClientGroupedStats) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 16
+	// map header, size 17
 	// string "Service"
-	o = append(o, 0xde, 0x0, 0x10, 0xa7, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65)
+	o = append(o, 0xde, 0x0, 0x11, 0xa7, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65)
 	o = msgp.AppendString(o, z.Service)
 	// string "Name"
 	o = append(o, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
@@ -376,6 +412,12 @@ func (z *ClientGroupedStats) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "IsTraceRoot"
 	o = append(o, 0xab, 0x49, 0x73, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x6f, 0x6f, 0x74)
 	o = msgp.AppendInt32(o, int32(z.IsTraceRoot))
+	// string "CustomTags"
+	o = append(o, 0xaa, 0x43, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x54, 0x61, 0x67, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.CustomTags)))
+	for za0002 := range z.CustomTags {
+		o = msgp.AppendString(o, z.CustomTags[za0002])
+	}
 	return
 }
 
@@ -510,6 +552,25 @@ func (z *ClientGroupedStats) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				}
 				z.IsTraceRoot = Trilean(zb0003)
 			}
+		case "CustomTags":
+			var zb0004 uint32
+			zb0004, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "CustomTags")
+				return
+			}
+			if cap(z.CustomTags) >= int(zb0004) {
+				z.CustomTags = (z.CustomTags)[:zb0004]
+			} else {
+				z.CustomTags = make([]string, zb0004)
+			}
+			for za0002 := range z.CustomTags {
+				z.CustomTags[za0002], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "CustomTags", za0002)
+					return
+				}
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -528,7 +589,10 @@ func (z *ClientGroupedStats) Msgsize() (s int) {
 	for za0001 := range z.PeerTags {
 		s += msgp.StringPrefixSize + len(z.PeerTags[za0001])
 	}
-	s += 12 + msgp.Int32Size
+	s += 12 + msgp.Int32Size + 11 + msgp.ArrayHeaderSize
+	for za0002 := range z.CustomTags {
+		s += msgp.StringPrefixSize + len(z.CustomTags[za0002])
+	}
 	return
 }
 
@@ -194,6 +194,49 @@ func (series *IterableSeries) MarshalSplitCompressMultiple(config config.Compone
 	return pb.payloads, pb2.payloads, nil
 }
 
+// MarshalSplitCompressPartitions uses the stream compressor to marshal and compress one series into several
+// sets of payloads, in a single pass over the series. partitionFunc appends to partitions the indexes of the
+// sets of payloads a serie is added to, there are partitionCount sets of payloads.
+func (series *IterableSeries) MarshalSplitCompressPartitions(config config.Component, strategy compression.Component, partitionCount int, partitionFunc func(s *metrics.Serie, partitions []int) []int) ([]transaction.BytesPayloads, error) {
+	builders := make([]PayloadsBuilder, partitionCount)
+	for i := range builders {
+		pb, err := series.NewPayloadsBuilder(marshaler.NewBufferContext(), config, strategy)
+		if err != nil {
+			return nil, err
+		}
+		err = pb.startPayload()
+		if err != nil {
+			return nil, err
+		}
+		builders[i] = pb
+	}
+
+	var partitions []int
+	// Use series.source.MoveNext() instead of series.MoveNext() because this function supports
+	// the serie.NoIndex field.
+	for series.source.MoveNext() {
+		serie := series.source.Current()
+		partitions = partitionFunc(serie, partitions[:0])
+		for _, partition := range partitions {
+			err := builders[partition].writeSerie(serie)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	payloads := make([]transaction.BytesPayloads, partitionCount)
+	for i := range builders {
+		// if the last payload has any data, flush it
+		err := builders[i].finishPayload()
+		if err != nil {
+			return nil, err
+		}
+		payloads[i] = builders[i].payloads
+	}
+	return payloads, nil
+}
+
 // NewPayloadsBuilder initializes a new PayloadsBuilder to be used for serializing series into a set of output payloads.
 func (series *IterableSeries) NewPayloadsBuilder(bufferContext *marshaler.BufferContext, config config.Component, strategy compression.Component) (PayloadsBuilder, error) {
 	buf := bufferContext.PrecompressionBuf
@@ -133,6 +133,49 @@ func (sl SketchSeriesList) MarshalSplitCompressMultiple(config config.Component,
 	return pb.payloads, pb2.payloads, nil
 }
 
+// MarshalSplitCompressPartitions uses the stream compressor to marshal and
+// compress one sketch list into several sets of payloads, in a single pass
+// over the input data. partitionFunc appends to partitions the indexes of the
+// sets of payloads a sketch series is added to, there are partitionCount sets
+// of payloads. The sets of payloads without any sketch series are empty.
+func (sl SketchSeriesList) MarshalSplitCompressPartitions(config config.Component, strategy compression.Component, partitionCount int, partitionFunc func(ss *metrics.SketchSeries, partitions []int) []int) ([]transaction.BytesPayloads, error) {
+	builders := make([]payloadsBuilder, partitionCount)
+	for i := range builders {
+		builders[i] = newPayloadsBuilder(marshaler.NewBufferContext(), config, strategy)
+		err := builders[i].startPayload()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var partitions []int
+	used := make([]bool, partitionCount)
+	for sl.MoveNext() {
+		ss := sl.Current()
+		partitions = partitionFunc(ss, partitions[:0])
+		for _, partition := range partitions {
+			err := builders[partition].marshal(ss)
+			if err != nil {
+				return nil, err
+			}
+			used[partition] = true
+		}
+	}
+
+	payloads := make([]transaction.BytesPayloads, partitionCount)
+	for i := range builders {
+		err := builders[i].finishPayload()
+		if err != nil {
+			log.Debugf("Failed to finish payload with err %v", err)
+			return nil, err
+		}
+		if used[i] {
+			payloads[i] = builders[i].payloads
+		}
+	}
+	return payloads, nil
+}
+
 func newPayloadsBuilder(bufferContext *marshaler.BufferContext, config config.Component, strategy compression.Component) payloadsBuilder {
 	buf := bufferContext.PrecompressionBuf
 	pb := payloadsBuilder{
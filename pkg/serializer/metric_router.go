@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package serializer
+
+import (
+	"strings"
+
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+// metricRouter selects the metric route of the series and sketches from their tags.
+type metricRouter struct {
+	routes []metricRoute
+}
+
+type metricRoute struct {
+	name string
+	// tags holds the tags matched exactly
+	tags map[string]struct{}
+	// prefixes holds the prefixes of the tags matched by the patterns ending with a wildcard
+	prefixes []string
+}
+
+// newMetricRouter returns a router for the given routes, or nil if there isn't any. Like the
+// forwarder, it ignores the routes without name and the duplicated ones.
+func newMetricRouter(routes []pkgconfigsetup.MetricRoute) *metricRouter {
+	r := &metricRouter{routes: make([]metricRoute, 0, len(routes))}
+	names := make(map[string]struct{}, len(routes))
+	for _, route := range routes {
+		if _, ok := names[route.Name]; ok || route.Name == "" {
+			continue
+		}
+		names[route.Name] = struct{}{}
+
+		mr := metricRoute{name: route.Name, tags: make(map[string]struct{})}
+		for _, pattern := range route.MatchTags {
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+				mr.prefixes = append(mr.prefixes, prefix)
+			} else {
+				mr.tags[pattern] = struct{}{}
+			}
+		}
+		r.routes = append(r.routes, mr)
+	}
+	if len(r.routes) == 0 {
+		return nil
+	}
+	return r
+}
+
+// route returns the index of the first route matching one of the tags, or -1 if none matches.
+func (r *metricRouter) route(tags tagset.CompositeTags) int {
+	for i := range r.routes {
+		if tags.Find(r.routes[i].matches) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *metricRoute) matches(tag string) bool {
+	if _, ok := r.tags[tag]; ok {
+		return true
+	}
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(tag, prefix) {
+			return true
+		}
+	}
+	return false
+}
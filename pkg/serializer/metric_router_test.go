@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package serializer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func TestMetricRouter(t *testing.T) {
+	assert.Nil(t, newMetricRouter(nil))
+	assert.Nil(t, newMetricRouter([]pkgconfigsetup.MetricRoute{{MatchTags: []string{"team:payments"}}}))
+
+	r := newMetricRouter([]pkgconfigsetup.MetricRoute{
+		{Name: "payments", MatchTags: []string{"team:payments", "kube_namespace:payments-*"}},
+		{Name: "payments", MatchTags: []string{"team:search"}},
+		{MatchTags: []string{"team:search"}},
+		{Name: "all", MatchTags: []string{"team:*"}},
+	})
+	require.NotNil(t, r)
+	require.Len(t, r.routes, 2)
+
+	route := func(tags ...string) int {
+		return r.route(tagset.CompositeTagsFromSlice(tags))
+	}
+	assert.Equal(t, -1, route())
+	assert.Equal(t, -1, route("env:prod", "kube_namespace:search"))
+	assert.Equal(t, 0, route("env:prod", "team:payments"))
+	assert.Equal(t, 0, route("kube_namespace:payments-eu"))
+	// the first matching route is selected
+	assert.Equal(t, 0, route("team:search", "kube_namespace:payments-eu"))
+	assert.Equal(t, 1, route("team:search"))
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/comp/serializer/compression"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
@@ -31,6 +32,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
 	"github.com/DataDog/datadog-agent/pkg/serializer/types"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -130,6 +132,9 @@ type Serializer struct {
 	enableEventsJSONStream        bool
 	enableSketchProtobufStream    bool
 	hostname                      string
+
+	// metricRouter selects the metric route of the series and sketches, nil when no route is configured
+	metricRouter *metricRouter
 }
 
 // NewSerializer returns a new Serializer initialized
@@ -161,6 +166,12 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder orchestr
 
 	initExtraHeaders(s)
 
+	metricRoutes, _ := pkgconfigsetup.GetMetricRoutes(config)
+	s.metricRouter = newMetricRouter(metricRoutes)
+	if s.metricRouter != nil && !s.enableSketchProtobufStream {
+		log.Warn("'metric_routes' is set but 'enable_sketch_stream_payload_serialization' is disabled: the sketches won't be routed")
+	}
+
 	if !s.enableEvents {
 		log.Warn("event payloads are disabled: all events will be dropped")
 	}
@@ -352,7 +363,17 @@ func (s *Serializer) SendIterableSeries(serieSource metrics.SerieSource) error {
 	} else {
 		failoverActive, allowlist := s.getFailoverAllowlist()
 
-		if failoverActive && len(allowlist) > 0 {
+		if s.metricRouter != nil {
+			failover := failoverActive && len(allowlist) > 0
+			if !failover {
+				allowlist = nil
+			}
+			var partitions []transaction.BytesPayloads
+			partitions, err = seriesSerializer.MarshalSplitCompressPartitions(s.config, s.Strategy, firstRoutePartition+len(s.metricRouter.routes), func(serie *metrics.Serie, partitions []int) []int {
+				return s.routePartitions(serie.Name, serie.Tags, allowlist, partitions)
+			})
+			seriesBytesPayloads = s.routedPayloads(partitions, failover)
+		} else if failoverActive && len(allowlist) > 0 {
 			var filtered transaction.BytesPayloads
 			seriesBytesPayloads, filtered, err = seriesSerializer.MarshalSplitCompressMultiple(s.config, s.Strategy, func(s *metrics.Serie) bool {
 				_, allowed := allowlist[s.Name]
@@ -397,6 +418,49 @@ func (s *Serializer) getFailoverAllowlist() (bool, map[string]struct{}) {
 	return failoverActive, allowlist
 }
 
+// The partitions of the payloads of the series and sketches when metric routes are configured,
+// the payloads of each route come after the payloads sent to the default endpoints.
+const (
+	defaultPartition = iota
+	failoverPartition
+	firstRoutePartition
+)
+
+// routePartitions appends the partitions of the payloads a serie or a sketch is added to. The
+// series and sketches matching a route are only added to the payloads of the route.
+func (s *Serializer) routePartitions(name string, tags tagset.CompositeTags, failoverAllowlist map[string]struct{}, partitions []int) []int {
+	if route := s.metricRouter.route(tags); route >= 0 {
+		return append(partitions, firstRoutePartition+route)
+	}
+	partitions = append(partitions, defaultPartition)
+	if _, allowed := failoverAllowlist[name]; allowed {
+		partitions = append(partitions, failoverPartition)
+	}
+	return partitions
+}
+
+// routedPayloads sets the destination and the route of the payloads of each partition, and returns all of them.
+func (s *Serializer) routedPayloads(partitions []transaction.BytesPayloads, failover bool) transaction.BytesPayloads {
+	var payloads transaction.BytesPayloads
+	for i, partition := range partitions {
+		for _, payload := range partition {
+			switch {
+			case i == defaultPartition && failover:
+				payload.Destination = transaction.PrimaryOnly
+			case i == failoverPartition:
+				payload.Destination = transaction.SecondaryOnly
+			default:
+				payload.Destination = transaction.AllRegions
+			}
+			if i >= firstRoutePartition {
+				payload.Route = s.metricRouter.routes[i-firstRoutePartition].name
+			}
+		}
+		payloads = append(payloads, partition...)
+	}
+	return payloads
+}
+
 // AreSketchesEnabled returns whether sketches are enabled for serialization
 func (s *Serializer) AreSketchesEnabled() bool {
 	return s.enableSketches
@@ -412,7 +476,20 @@ func (s *Serializer) SendSketch(sketches metrics.SketchesSource) error {
 	if s.enableSketchProtobufStream {
 		failoverActive, allowlist := s.getFailoverAllowlist()
 
-		if failoverActive && len(allowlist) > 0 {
+		if s.metricRouter != nil {
+			failover := failoverActive && len(allowlist) > 0
+			if !failover {
+				allowlist = nil
+			}
+			partitions, err := sketchesSerializer.MarshalSplitCompressPartitions(s.config, s.Strategy, firstRoutePartition+len(s.metricRouter.routes), func(ss *metrics.SketchSeries, partitions []int) []int {
+				return s.routePartitions(ss.Name, ss.Tags, allowlist, partitions)
+			})
+			if err != nil {
+				return fmt.Errorf("dropping sketch payload: %v", err)
+			}
+
+			return s.Forwarder.SubmitSketchSeries(s.routedPayloads(partitions, failover), s.protobufExtraHeadersWithCompression)
+		} else if failoverActive && len(allowlist) > 0 {
 			payloads, filteredPayloads, err := sketchesSerializer.MarshalSplitCompressMultiple(s.config, s.Strategy, func(ss *metrics.SketchSeries) bool {
 				_, allowed := allowlist[ss.Name]
 				return allowed
@@ -30,6 +30,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
 )
 
 func TestInitExtraHeadersNoopCompression(t *testing.T) {
@@ -420,6 +421,47 @@ func TestSendSketch(t *testing.T) {
 
 }
 
+func TestSendWithMetricRoutes(t *testing.T) {
+	f := &forwarder.MockedForwarder{}
+	mockConfig := pkgconfigsetup.ConfFromYAML(`
+metric_routes:
+  - name: payments
+    match_tags: ["team:payments"]
+  - name: search
+    match_tags: ["kube_namespace:search-*"]
+`)
+	s := NewSerializer(f, nil, compressionimpl.NewCompressor(mockConfig), mockConfig, "testhost")
+
+	// pointsPerRoute returns a matcher of payloads with the given count of points per route
+	pointsPerRoute := func(expected map[string]int) interface{} {
+		return mock.MatchedBy(func(payloads transaction.BytesPayloads) bool {
+			points := make(map[string]int)
+			for _, payload := range payloads {
+				if payload.Destination != transaction.AllRegions {
+					return false
+				}
+				points[payload.Route] += payload.GetPointCount()
+			}
+			return reflect.DeepEqual(expected, points)
+		})
+	}
+
+	f.On("SubmitSeries", pointsPerRoute(map[string]int{"": 1, "payments": 2, "search": 1}), s.protobufExtraHeadersWithCompression).Return(nil).Times(1)
+	err := s.SendIterableSeries(metricsserializer.CreateSerieSource(metrics.Series{
+		&metrics.Serie{Name: "default", Points: []metrics.Point{{Ts: 1, Value: 1}}},
+		&metrics.Serie{Name: "payments", Tags: tagset.CompositeTagsFromSlice([]string{"env:prod", "team:payments"}), Points: []metrics.Point{{Ts: 1, Value: 1}, {Ts: 2, Value: 1}}},
+		&metrics.Serie{Name: "search", Tags: tagset.CompositeTagsFromSlice([]string{"kube_namespace:search-eu"}), Points: []metrics.Point{{Ts: 1, Value: 1}}},
+	}))
+	require.Nil(t, err)
+
+	f.On("SubmitSketchSeries", pointsPerRoute(map[string]int{"payments": 0}), s.protobufExtraHeadersWithCompression).Return(nil).Times(1)
+	sketches := metrics.NewSketchesSourceTest()
+	sketches.Append(&metrics.SketchSeries{Name: "payments", Tags: tagset.CompositeTagsFromSlice([]string{"team:payments"})})
+	err = s.SendSketch(sketches)
+	require.Nil(t, err)
+	f.AssertExpectations(t)
+}
+
 func TestSendMetadata(t *testing.T) {
 
 	tests := map[string]struct {
@@ -51,6 +51,9 @@ const (
 	// probabilitySampling is the value for _dd.p.dm when the agent is configured to use the ProbabilitySampler.
 	probabilitySampling = "-9"
 
+	// tailSampling is the value for _dd.p.dm when the trace is kept by the TailSampler.
+	tailSampling = "-13"
+
 	// tagDecisionMaker specifies the sampling decision maker
 	tagDecisionMaker = "_dd.p.dm"
 )
@@ -81,15 +84,18 @@ type Concentrator interface {
 type Agent struct {
 	Receiver              *api.HTTPReceiver
 	OTLPReceiver          *api.OTLPReceiver
+	JaegerReceiver        *api.JaegerGRPCReceiver
 	Concentrator          Concentrator
 	ClientStatsAggregator *stats.ClientStatsAggregator
 	Blacklister           *filters.Blacklister
 	Replacer              *filters.Replacer
+	SpanRules             *filters.SpanRules
 	PrioritySampler       *sampler.PrioritySampler
 	ErrorsSampler         *sampler.ErrorsSampler
 	RareSampler           *sampler.RareSampler
 	NoPrioritySampler     *sampler.NoPrioritySampler
 	ProbabilisticSampler  *sampler.ProbabilisticSampler
+	TailSampler           *sampler.TailSampler
 	EventProcessor        *event.Processor
 	TraceWriter           TraceWriter
 	StatsWriter           *writer.DatadogStatsWriter
@@ -146,6 +152,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		ClientStatsAggregator: stats.NewClientStatsAggregator(conf, statsWriter, statsd),
 		Blacklister:           filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:              filters.NewReplacer(conf.ReplaceTags),
+		SpanRules:             filters.NewSpanRules(conf.SpanRules),
 		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf, statsd),
 		ErrorsSampler:         sampler.NewErrorsSampler(conf, statsd),
 		RareSampler:           sampler.NewRareSampler(conf, statsd),
@@ -163,8 +170,10 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt, telemetryCollector, statsd, timing)
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf, statsd, timing)
+	agnt.JaegerReceiver = api.NewJaegerGRPCReceiver(in, conf, statsd, timing)
 	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler)
 	agnt.TraceWriter = writer.NewTraceWriter(conf, agnt.PrioritySampler, agnt.ErrorsSampler, agnt.RareSampler, telemetryCollector, statsd, timing, comp)
+	agnt.TailSampler = sampler.NewTailSampler(conf, statsd, agnt.writeTailDecision)
 	return agnt
 }
 
@@ -180,8 +189,10 @@ func (a *Agent) Run() {
 		a.ErrorsSampler,
 		a.NoPrioritySampler,
 		a.ProbabilisticSampler,
+		a.TailSampler,
 		a.EventProcessor,
 		a.OTLPReceiver,
+		a.JaegerReceiver,
 		a.RemoteConfigHandler,
 		a.DebugServer,
 	} {
@@ -241,12 +252,14 @@ func (a *Agent) loop() {
 	log.Info("Exiting...")
 
 	a.OTLPReceiver.Stop() // Stop OTLPReceiver before Receiver to avoid sending to closed channel
+	a.JaegerReceiver.Stop()
 	if err := a.Receiver.Stop(); err != nil {
 		log.Error(err)
 	}
 	for _, stopper := range []interface{ Stop() }{
 		a.Concentrator,
 		a.ClientStatsAggregator,
+		a.TailSampler, // before the TraceWriter, to write the traces still buffered
 		a.TraceWriter,
 		a.StatsWriter,
 		a.PrioritySampler,
@@ -301,6 +314,8 @@ func (a *Agent) Process(p *api.Payload) {
 	ts := p.Source
 	sampledChunks := new(writer.SampledChunks)
 	statsInput := stats.NewStatsInput(len(p.TracerPayload.Chunks), p.TracerPayload.ContainerID, p.ClientComputedStats, a.conf)
+	// payloadHeader holds the metadata of the payload for the chunks buffered by the TailSampler.
+	var payloadHeader *pb.TracerPayload
 
 	p.TracerPayload.Env = traceutil.NormalizeTag(p.TracerPayload.Env)
 
@@ -362,6 +377,17 @@ func (a *Agent) Process(p *api.Payload) {
 			}
 		}
 		a.Replacer.Replace(chunk.Spans)
+		if spans := a.SpanRules.Apply(chunk.Spans); len(spans) < len(chunk.Spans) {
+			ts.SpansFiltered.Add(int64(len(chunk.Spans) - len(spans)))
+			chunk.Spans = spans
+			if len(spans) == 0 {
+				log.Debugf("Trace rejected as all its spans were dropped by the span rules. root: %v", root)
+				ts.TracesFiltered.Inc()
+				p.RemoveChunk(i)
+				continue
+			}
+			root = traceutil.GetRoot(spans)
+		}
 
 		a.setRootSpanTags(root)
 		if !p.ClientComputedTopLevel {
@@ -377,6 +403,16 @@ func (a *Agent) Process(p *api.Payload) {
 			statsInput.Traces = append(statsInput.Traces, *pt.Clone())
 		}
 
+		if a.conf.TailSampling.Enabled {
+			// The chunk is sampled along with the other chunks of its trace, once it is assembled.
+			if payloadHeader == nil {
+				payloadHeader = newTracerPayloadHeader(p.TracerPayload)
+			}
+			a.TailSampler.Add(now, &sampler.TailChunk{Trace: pt, Payload: payloadHeader, Size: pt.TraceChunk.Msgsize()})
+			p.RemoveChunk(i)
+			continue
+		}
+
 		keep, numEvents := a.sample(now, ts, pt)
 		if !keep && len(pt.TraceChunk.Spans) == 0 {
 			// The entire trace was dropped and no spans were kept.
@@ -455,6 +491,61 @@ func processedTrace(p *api.Payload, chunk *pb.TraceChunk, root *pb.Span, contain
 	return pt
 }
 
+// newTracerPayloadHeader returns a tracer payload holding the metadata of p, without its chunks.
+func newTracerPayloadHeader(p *pb.TracerPayload) *pb.TracerPayload {
+	return &pb.TracerPayload{
+		ContainerID:     p.GetContainerID(),
+		LanguageName:    p.GetLanguageName(),
+		LanguageVersion: p.GetLanguageVersion(),
+		TracerVersion:   p.GetTracerVersion(),
+		RuntimeID:       p.GetRuntimeID(),
+		Env:             p.GetEnv(),
+		Hostname:        p.GetHostname(),
+		AppVersion:      p.GetAppVersion(),
+		Tags:            p.GetTags(),
+	}
+}
+
+// writeTailDecision writes the chunks of a trace sampled by the TailSampler, grouped by the
+// payloads they were received in. The kept chunks which were not kept by the tracer are given an
+// auto keep priority and the tail sampling decision maker. The chunks of the dropped traces are only written if single
+// span sampling keeps some of their spans. App Analytics events are not extracted in this mode.
+func (a *Agent) writeTailDecision(d sampler.TailDecision) {
+	payloads := make(map[*pb.TracerPayload]*writer.SampledChunks)
+	var order []*pb.TracerPayload
+	for _, c := range d.Chunks {
+		pt := c.Trace
+		pt.TraceChunk.DroppedTrace = !d.Keep
+		if d.Keep {
+			if priority, ok := sampler.GetSamplingPriority(pt.TraceChunk); !ok || priority <= sampler.PriorityAutoDrop {
+				pt.TraceChunk.Priority = int32(sampler.PriorityAutoKeep)
+				if pt.TraceChunk.Tags == nil {
+					pt.TraceChunk.Tags = make(map[string]string)
+				}
+				pt.TraceChunk.Tags[tagDecisionMaker] = tailSampling
+			}
+			a.setFirstTraceTags(pt.Root)
+		} else if !sampler.SingleSpanSampling(pt) {
+			continue
+		}
+
+		sampledChunks, ok := payloads[c.Payload]
+		if !ok {
+			sampledChunks = &writer.SampledChunks{TracerPayload: newTracerPayloadHeader(c.Payload)}
+			payloads[c.Payload] = sampledChunks
+			order = append(order, c.Payload)
+		}
+		sampledChunks.TracerPayload.Chunks = append(sampledChunks.TracerPayload.Chunks, pt.TraceChunk)
+		if !pt.TraceChunk.DroppedTrace {
+			sampledChunks.SpanCount += int64(len(pt.TraceChunk.Spans))
+		}
+		sampledChunks.Size += pt.TraceChunk.Msgsize()
+	}
+	for _, p := range order {
+		a.TraceWriter.WriteChunks(payloads[p])
+	}
+}
+
 // newChunksArray creates a new array which will point only to sampled chunks.
 // The underlying array behind TracePayload.Chunks points to unsampled chunks
 // preventing them from being collected by the GC.
@@ -244,6 +244,64 @@ func TestProcess(t *testing.T) {
 		assert.EqualValues(2, want.SpansFiltered.Load())
 	})
 
+	t.Run("SpanRules", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.SpanRules = []*config.SpanRule{
+			{Action: config.SpanRuleDrop, TagsRe: map[string]*regexp.Regexp{"http.route": regexp.MustCompile("^/health")}},
+			{Action: config.SpanRuleCopyTag, Key: "customer.id", Target: "usr.id"},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+		defer cancel()
+
+		now := time.Now()
+		newSpan := func(spanID, parentID uint64, meta map[string]string) *pb.Span {
+			return &pb.Span{
+				TraceID:  1,
+				SpanID:   spanID,
+				ParentID: parentID,
+				Service:  "web",
+				Name:     "http.request",
+				Resource: "GET /",
+				Start:    now.Add(-time.Second).UnixNano(),
+				Duration: (500 * time.Millisecond).Nanoseconds(),
+				Meta:     meta,
+			}
+		}
+		want := agnt.Receiver.Stats.GetTagStats(info.Tags{})
+		assert := assert.New(t)
+
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpans([]*pb.Span{
+				newSpan(1, 0, map[string]string{"customer.id": "42"}),
+				newSpan(2, 1, map[string]string{"http.route": "/health"}),
+				newSpan(3, 2, nil),
+			})),
+			Source: want,
+		})
+		assert.EqualValues(0, want.TracesFiltered.Load())
+		assert.EqualValues(1, want.SpansFiltered.Load())
+		// the stats are computed on the remaining spans
+		inputs := agnt.Concentrator.(*mockConcentrator).Reset()
+		assert.Len(inputs, 1)
+		spans := inputs[0].Traces[0].TraceChunk.Spans
+		assert.Len(spans, 2)
+		assert.Equal("42", spans[0].Meta["usr.id"])
+		assert.EqualValues(3, spans[1].SpanID)
+		assert.EqualValues(1, spans[1].ParentID)
+
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpan(
+				newSpan(1, 0, map[string]string{"http.route": "/healthz"}),
+			)),
+			Source: want,
+		})
+		assert.EqualValues(1, want.TracesFiltered.Load())
+		assert.EqualValues(2, want.SpansFiltered.Load())
+		assert.Empty(agnt.Concentrator.(*mockConcentrator).Reset())
+	})
+
 	t.Run("Block-all", func(t *testing.T) {
 		cfg := config.New()
 		cfg.Endpoints[0].APIKey = "test"
@@ -1455,6 +1513,67 @@ func TestPartialSamplingFree(t *testing.T) {
 	assert.Len(t, agnt.TraceWriter.(*mockTraceWriter).payloads, 1)
 }
 
+func TestTailSampling(t *testing.T) {
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.TailSampling.Enabled = true
+	cfg.TailSampling.DecisionWait = time.Hour
+	cfg.TailSampling.Policies = []*config.TailSamplingPolicy{{Name: "errors", Type: config.TailSamplingPolicyError}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+	agnt.TailSampler.Start()
+
+	now := time.Now().UnixNano()
+	span := func(traceID, spanID, parentID uint64, isError int32) *pb.Span {
+		return &pb.Span{
+			TraceID:  traceID,
+			SpanID:   spanID,
+			ParentID: parentID,
+			Service:  "s",
+			Name:     "n",
+			Resource: "r",
+			Start:    now,
+			Duration: 1000,
+			Error:    isError,
+			Metrics:  map[string]float64{"_sampling_priority_v1": 0},
+		}
+	}
+	// trace 1 is flushed in two chunks, the error being in the second one
+	agnt.Process(&api.Payload{
+		TracerPayload: testutil.TracerPayloadWithChunks([]*pb.TraceChunk{
+			testutil.TraceChunkWithSpan(span(1, 1, 0, 0)),
+			testutil.TraceChunkWithSpan(span(2, 3, 0, 0)),
+		}),
+		Source: agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+	})
+	agnt.Process(&api.Payload{
+		TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpan(span(1, 2, 1, 1))),
+		Source:        agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+	})
+	assert.Empty(t, agnt.TraceWriter.(*mockTraceWriter).payloads)
+
+	agnt.TailSampler.Stop()
+	payloads := agnt.TraceWriter.(*mockTraceWriter).payloads
+	var spanIDs []uint64
+	for _, p := range payloads {
+		for _, c := range p.TracerPayload.Chunks {
+			assert.False(t, c.DroppedTrace)
+			for _, s := range c.Spans {
+				assert.EqualValues(t, 1, s.TraceID)
+				spanIDs = append(spanIDs, s.SpanID)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []uint64{1, 2}, spanIDs)
+	for _, p := range payloads {
+		for _, c := range p.TracerPayload.Chunks {
+			assert.EqualValues(t, sampler.PriorityAutoKeep, c.Priority)
+			assert.Equal(t, tailSampling, c.Tags[tagDecisionMaker])
+		}
+	}
+}
+
 func TestEventProcessorFromConf(t *testing.T) {
 	if _, ok := os.LookupEnv("INTEGRATION"); !ok {
 		t.Skip("set INTEGRATION environment variable to run")
@@ -6,6 +6,8 @@
 package agent
 
 import (
+	"strings"
+
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
@@ -19,10 +21,15 @@ const (
 	tagOpenSearchBody   = "opensearch.body"
 	tagSQLQuery         = "sql.query"
 	tagHTTPURL          = "http.url"
+	tagGraphQLQuery     = "graphql.query"
+	tagGraphQLSource    = "graphql.source"
+	// tagGraphQLVariablesPrefix prefixes the tags holding the values of the variables of a GraphQL operation.
+	tagGraphQLVariablesPrefix = "graphql.variables."
 )
 
 const (
-	textNonParsable = "Non-parsable SQL query"
+	textNonParsable        = "Non-parsable SQL query"
+	textNonParsableGraphQL = "Non-parsable GraphQL query"
 )
 
 func (a *Agent) obfuscateSpan(span *pb.Span) {
@@ -103,6 +110,33 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 				span.Meta[tagOpenSearchBody] = o.ObfuscateOpenSearchString(span.Meta[tagOpenSearchBody])
 			}
 		}
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		if span.Resource != "" {
+			oq, err := o.ObfuscateGraphQLString(span.Resource)
+			if err != nil {
+				// discard the query to avoid leaking user input in resources.
+				log.Debugf("Error parsing GraphQL query: %v. Resource: %q", err, span.Resource)
+				span.Resource = textNonParsableGraphQL
+			} else {
+				span.Resource = oq.Signature
+			}
+		}
+		for k, v := range span.Meta {
+			switch {
+			case k == tagGraphQLQuery, k == tagGraphQLSource:
+				oq, err := o.ObfuscateGraphQLString(v)
+				if err != nil {
+					span.Meta[k] = textNonParsableGraphQL
+				} else {
+					span.Meta[k] = oq.Query
+				}
+			case strings.HasPrefix(k, tagGraphQLVariablesPrefix):
+				span.Meta[k] = "?"
+			}
+		}
 	}
 }
 
@@ -119,5 +153,16 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 		}
 	case "redis":
 		b.Resource = o.QuantizeRedisString(b.Resource)
+	case "graphql":
+		if a.conf.Obfuscation == nil || !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		oq, err := o.ObfuscateGraphQLString(b.Resource)
+		if err != nil {
+			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
+			b.Resource = textNonParsableGraphQL
+		} else {
+			b.Resource = oq.Signature
+		}
 	}
 }
@@ -254,6 +254,49 @@ func TestObfuscateConfig(t *testing.T) {
 		&config.ObfuscationConfig{},
 	))
 
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`{ me: user(id: 42) { name } }`,
+		"{ me: user(id: ?) { name } }",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/remove_aliases", testConfig(
+		"graphql",
+		"graphql.source",
+		`{ me: user(id: 42) { name } }`,
+		"{ user(id: ?) { name } }",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{
+			Enabled:       true,
+			RemoveAliases: true,
+		}},
+	))
+
+	t.Run("graphql/variables", testConfig(
+		"graphql",
+		"graphql.variables.email",
+		"jane@example.com",
+		"?",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/invalid", testConfig(
+		"graphql",
+		"graphql.query",
+		`{ user(email: "jane@example.com) { id } }`,
+		textNonParsableGraphQL,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`{ user(id: 42) { name } }`,
+		`{ user(id: 42) { name } }`,
+		&config.ObfuscationConfig{},
+	))
+
 	t.Run("creditcard", func(t *testing.T) {
 		for _, tt := range []struct {
 			k, v string
@@ -280,6 +323,28 @@ func TestObfuscateConfig(t *testing.T) {
 	})
 }
 
+func TestObfuscateGraphQLResource(t *testing.T) {
+	agnt, stop := agentWithDefaults()
+	defer stop()
+	agnt.conf.Obfuscation.GraphQL.Enabled = true
+
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{"GetUser", "GetUser"},
+		{`query GetUser { me: user(id: 42, email: "jane@example.com") { name } }`, "query GetUser { user(id: ? email: ?) { name } }"},
+		{`{ user(email: "jane@example.com) { id } }`, textNonParsableGraphQL},
+	} {
+		span := &pb.Span{Type: "graphql", Resource: tt.in}
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, tt.out, span.Resource)
+
+		b := &pb.ClientGroupedStats{Type: "graphql", Resource: tt.in}
+		agnt.obfuscateStatsGroup(b)
+		assert.Equal(t, tt.out, b.Resource)
+	}
+}
+
 func SQLSpan(query string) *pb.Span {
 	return &pb.Span{
 		Resource: query,
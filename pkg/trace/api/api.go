@@ -465,6 +465,30 @@ func decodeTracerPayload(v Version, req *http.Request, cIDProvider IDProvider, l
 			Chunks:          traceChunksFromTraces(traces),
 			TracerVersion:   tracerVersion,
 		}, err
+	case zipkinV2:
+		spans, err := decodeZipkinSpans(req)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.TracerPayload{
+			LanguageName:    lang,
+			LanguageVersion: langVersion,
+			ContainerID:     cIDProvider.GetContainerID(req.Context(), req.Header),
+			Chunks:          zipkinTraceChunks(spans),
+			TracerVersion:   tracerVersion,
+		}, nil
+	case jaegerThrift:
+		batch, err := decodeJaegerBatch(req)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.TracerPayload{
+			LanguageName:    lang,
+			LanguageVersion: langVersion,
+			ContainerID:     cIDProvider.GetContainerID(req.Context(), req.Header),
+			Chunks:          jaegerTraceChunks(batch),
+			TracerVersion:   tracerVersion,
+		}, nil
 	case V07:
 		buf := getBuffer()
 		defer putBuffer(buf)
@@ -494,7 +518,7 @@ func decodeTracerPayload(v Version, req *http.Request, cIDProvider IDProvider, l
 // was successful.
 func (r *HTTPReceiver) replyOK(req *http.Request, v Version, w http.ResponseWriter) (n uint64, ok bool) {
 	switch v {
-	case v01, v02, v03:
+	case v01, v02, v03, zipkinV2, jaegerThrift:
 		return httpOK(w)
 	default:
 		ratesVersion := req.Header.Get(header.RatesPayloadVersion)
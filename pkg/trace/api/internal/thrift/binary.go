@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package thrift implements the subset of the Thrift binary protocol needed to decode
+// the payloads of the Jaeger clients, without depending on generated code.
+package thrift
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Type is the type of a Thrift value.
+type Type byte
+
+// The types of the Thrift binary protocol.
+const (
+	STOP   Type = 0
+	BOOL   Type = 2
+	BYTE   Type = 3
+	DOUBLE Type = 4
+	I16    Type = 6
+	I32    Type = 8
+	I64    Type = 10
+	STRING Type = 11
+	STRUCT Type = 12
+	MAP    Type = 13
+	SET    Type = 14
+	LIST   Type = 15
+	UUID   Type = 16
+)
+
+// maxSkipDepth is the maximum nesting of the values skipped by Reader.Skip.
+const maxSkipDepth = 64
+
+// ErrShortBuffer is returned when the payload ends in the middle of a value.
+var ErrShortBuffer = errors.New("thrift: unexpected end of payload")
+
+// Reader decodes values encoded with the Thrift binary protocol.
+type Reader struct {
+	buf []byte
+	pos int
+}
+
+// NewReader returns a Reader decoding b.
+func NewReader(b []byte) *Reader {
+	return &Reader{buf: b}
+}
+
+func (r *Reader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.buf)-r.pos < n {
+		return nil, ErrShortBuffer
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// ReadFieldBegin reads the header of a struct field. The type is STOP at the end of the struct.
+func (r *Reader) ReadFieldBegin() (Type, int16, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return STOP, 0, err
+	}
+	if typ := Type(b[0]); typ == STOP {
+		return STOP, 0, nil
+	}
+	id, err := r.next(2)
+	if err != nil {
+		return STOP, 0, err
+	}
+	return Type(b[0]), int16(binary.BigEndian.Uint16(id)), nil
+}
+
+// ReadListBegin reads the header of a list or a set, returning the type and the number of its elements.
+func (r *Reader) ReadListBegin() (Type, int, error) {
+	b, err := r.next(5)
+	if err != nil {
+		return STOP, 0, err
+	}
+	size := int(int32(binary.BigEndian.Uint32(b[1:])))
+	// each element takes at least one byte, this bounds the allocations made by the callers
+	if size < 0 || size > len(r.buf)-r.pos {
+		return STOP, 0, fmt.Errorf("thrift: invalid list size %d", size)
+	}
+	return Type(b[0]), size, nil
+}
+
+// ReadBool reads a bool.
+func (r *Reader) ReadBool() (bool, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return false, err
+	}
+	return b[0] != 0, nil
+}
+
+// ReadI32 reads a 32-bit integer.
+func (r *Reader) ReadI32() (int32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+// ReadI64 reads a 64-bit integer.
+func (r *Reader) ReadI64() (int64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// ReadDouble reads a double.
+func (r *Reader) ReadDouble() (float64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+}
+
+// ReadBinary reads a binary value. The returned slice references the decoded payload.
+func (r *Reader) ReadBinary() ([]byte, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(int32(binary.BigEndian.Uint32(b))))
+}
+
+// ReadString reads a string.
+func (r *Reader) ReadString() (string, error) {
+	b, err := r.ReadBinary()
+	return string(b), err
+}
+
+// Skip skips a value of type typ.
+func (r *Reader) Skip(typ Type) error {
+	return r.skip(typ, 0)
+}
+
+func (r *Reader) skip(typ Type, depth int) error {
+	if depth > maxSkipDepth {
+		return errors.New("thrift: maximum nesting depth exceeded")
+	}
+	var err error
+	switch typ {
+	case BOOL, BYTE:
+		_, err = r.next(1)
+	case I16:
+		_, err = r.next(2)
+	case I32:
+		_, err = r.next(4)
+	case DOUBLE, I64:
+		_, err = r.next(8)
+	case UUID:
+		_, err = r.next(16)
+	case STRING:
+		_, err = r.ReadBinary()
+	case STRUCT:
+		for {
+			ftyp, _, err := r.ReadFieldBegin()
+			if err != nil {
+				return err
+			}
+			if ftyp == STOP {
+				return nil
+			}
+			if err := r.skip(ftyp, depth+1); err != nil {
+				return err
+			}
+		}
+	case MAP:
+		b, err := r.next(6)
+		if err != nil {
+			return err
+		}
+		ktyp, vtyp, size := Type(b[0]), Type(b[1]), int(int32(binary.BigEndian.Uint32(b[2:])))
+		if size < 0 {
+			return fmt.Errorf("thrift: invalid map size %d", size)
+		}
+		for i := 0; i < size; i++ {
+			if err := r.skip(ktyp, depth+1); err != nil {
+				return err
+			}
+			if err := r.skip(vtyp, depth+1); err != nil {
+				return err
+			}
+		}
+	case SET, LIST:
+		etyp, size, err := r.ReadListBegin()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < size; i++ {
+			if err := r.skip(etyp, depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("thrift: unknown type %d", typ)
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package thrift_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/thrift"
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/thrift/thrifttest"
+)
+
+func TestReader(t *testing.T) {
+	var w thrifttest.Writer
+	w.WriteFieldBegin(thrift.I64, 1)
+	w.WriteI64(-42)
+	w.WriteFieldBegin(thrift.STRING, 2)
+	w.WriteString("hello")
+	w.WriteFieldBegin(thrift.LIST, 3)
+	w.WriteListBegin(thrift.I32, 2)
+	w.WriteI32(1)
+	w.WriteI32(2)
+	w.WriteFieldBegin(thrift.DOUBLE, 4)
+	w.WriteDouble(1.5)
+	w.WriteFieldBegin(thrift.BOOL, 5)
+	w.WriteBool(true)
+	w.WriteFieldStop()
+
+	r := thrift.NewReader(w.Bytes())
+	typ, id, err := r.ReadFieldBegin()
+	require.NoError(t, err)
+	assert.Equal(t, thrift.I64, typ)
+	assert.EqualValues(t, 1, id)
+	i64, err := r.ReadI64()
+	require.NoError(t, err)
+	assert.EqualValues(t, -42, i64)
+
+	_, _, err = r.ReadFieldBegin()
+	require.NoError(t, err)
+	s, err := r.ReadString()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", s)
+
+	_, _, err = r.ReadFieldBegin()
+	require.NoError(t, err)
+	etyp, size, err := r.ReadListBegin()
+	require.NoError(t, err)
+	assert.Equal(t, thrift.I32, etyp)
+	assert.Equal(t, 2, size)
+	for i := 1; i <= size; i++ {
+		v, err := r.ReadI32()
+		require.NoError(t, err)
+		assert.EqualValues(t, i, v)
+	}
+
+	_, _, err = r.ReadFieldBegin()
+	require.NoError(t, err)
+	d, err := r.ReadDouble()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, d)
+
+	_, _, err = r.ReadFieldBegin()
+	require.NoError(t, err)
+	b, err := r.ReadBool()
+	require.NoError(t, err)
+	assert.True(t, b)
+
+	typ, _, err = r.ReadFieldBegin()
+	require.NoError(t, err)
+	assert.Equal(t, thrift.STOP, typ)
+}
+
+func TestReaderSkip(t *testing.T) {
+	var w thrifttest.Writer
+	// a struct holding a list of structs and a map
+	w.WriteFieldBegin(thrift.LIST, 1)
+	w.WriteListBegin(thrift.STRUCT, 2)
+	for i := 0; i < 2; i++ {
+		w.WriteFieldBegin(thrift.STRING, 1)
+		w.WriteString("value")
+		w.WriteFieldStop()
+	}
+	w.WriteFieldBegin(thrift.MAP, 2)
+	w.WriteMapBegin(thrift.STRING, thrift.I16, 1)
+	w.WriteString("key")
+	w.WriteI16(7)
+	w.WriteFieldStop()
+	w.WriteI32(7)
+
+	r := thrift.NewReader(w.Bytes())
+	require.NoError(t, r.Skip(thrift.STRUCT))
+	v, err := r.ReadI32()
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, v)
+}
+
+func TestReaderErrors(t *testing.T) {
+	t.Run("short", func(t *testing.T) {
+		var w thrifttest.Writer
+		w.WriteString("hello")
+		_, err := thrift.NewReader(w.Bytes()[:6]).ReadString()
+		assert.Equal(t, thrift.ErrShortBuffer, err)
+	})
+
+	t.Run("list-size", func(t *testing.T) {
+		var w thrifttest.Writer
+		w.WriteListBegin(thrift.I64, 1<<30)
+		_, _, err := thrift.NewReader(w.Bytes()).ReadListBegin()
+		assert.EqualError(t, err, "thrift: invalid list size 1073741824")
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		var w thrifttest.Writer
+		for i := 0; i < 100; i++ {
+			w.WriteFieldBegin(thrift.STRUCT, 1)
+		}
+		assert.EqualError(t, thrift.NewReader(w.Bytes()).Skip(thrift.STRUCT), "thrift: maximum nesting depth exceeded")
+	})
+
+	t.Run("type", func(t *testing.T) {
+		assert.EqualError(t, thrift.NewReader([]byte{0}).Skip(thrift.Type(42)), "thrift: unknown type 42")
+	})
+}
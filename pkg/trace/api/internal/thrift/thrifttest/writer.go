@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package thrifttest provides an encoder of the Thrift binary protocol to build the payloads
+// of the tests.
+package thrifttest
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/thrift"
+)
+
+// Writer encodes values with the Thrift binary protocol.
+type Writer struct {
+	buf []byte
+}
+
+// Bytes returns the encoded values.
+func (w *Writer) Bytes() []byte {
+	return w.buf
+}
+
+// WriteFieldBegin writes the header of a struct field.
+func (w *Writer) WriteFieldBegin(typ thrift.Type, id int16) {
+	w.buf = append(w.buf, byte(typ))
+	w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(id))
+}
+
+// WriteFieldStop writes the end of a struct.
+func (w *Writer) WriteFieldStop() {
+	w.buf = append(w.buf, byte(thrift.STOP))
+}
+
+// WriteListBegin writes the header of a list of size elements of type typ.
+func (w *Writer) WriteListBegin(typ thrift.Type, size int) {
+	w.buf = append(w.buf, byte(typ))
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(size))
+}
+
+// WriteMapBegin writes the header of a map of size entries.
+func (w *Writer) WriteMapBegin(keyType, valueType thrift.Type, size int) {
+	w.buf = append(w.buf, byte(keyType), byte(valueType))
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(size))
+}
+
+// WriteBool writes a bool.
+func (w *Writer) WriteBool(v bool) {
+	if v {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// WriteI16 writes a 16-bit integer.
+func (w *Writer) WriteI16(v int16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(v))
+}
+
+// WriteI32 writes a 32-bit integer.
+func (w *Writer) WriteI32(v int32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(v))
+}
+
+// WriteI64 writes a 64-bit integer.
+func (w *Writer) WriteI64(v int64) {
+	w.buf = binary.BigEndian.AppendUint64(w.buf, uint64(v))
+}
+
+// WriteDouble writes a double.
+func (w *Writer) WriteDouble(v float64) {
+	w.buf = binary.BigEndian.AppendUint64(w.buf, math.Float64bits(v))
+}
+
+// WriteString writes a string.
+func (w *Writer) WriteString(v string) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(v)))
+	w.buf = append(w.buf, v...)
+}
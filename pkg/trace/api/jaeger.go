@@ -0,0 +1,392 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/thrift"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+)
+
+// The types of the values of the Jaeger tags
+const (
+	jaegerTagString int32 = 0
+	jaegerTagDouble int32 = 1
+	jaegerTagBool   int32 = 2
+	jaegerTagLong   int32 = 3
+	jaegerTagBinary int32 = 4
+)
+
+const (
+	// jaegerRefChildOf is the type of the reference of a span to its parent.
+	jaegerRefChildOf int32 = 0
+	// jaegerFlagDebug is the flag set on the spans of the traces forced by the client.
+	jaegerFlagDebug int32 = 2
+)
+
+// jaegerBatch is a batch of spans sent by a Jaeger client.
+// See https://github.com/jaegertracing/jaeger-idl/blob/main/thrift/jaeger.thrift.
+type jaegerBatch struct {
+	Process jaegerProcess
+	Spans   []*jaegerSpan
+}
+
+type jaegerProcess struct {
+	ServiceName string
+	Tags        []jaegerTag
+}
+
+type jaegerSpan struct {
+	TraceIDLow    int64
+	TraceIDHigh   int64
+	SpanID        int64
+	ParentSpanID  int64
+	OperationName string
+	References    []jaegerSpanRef
+	Flags         int32
+	StartTime     int64 // nanoseconds
+	Duration      int64 // nanoseconds
+	Tags          []jaegerTag
+	Logs          []jaegerLog
+	// Process is the process of the span when it differs from the process of its batch.
+	Process *jaegerProcess
+}
+
+type jaegerSpanRef struct {
+	RefType    int32
+	TraceIDLow int64
+	SpanID     int64
+}
+
+type jaegerTag struct {
+	Key     string
+	VType   int32
+	VStr    string
+	VDouble float64
+	VBool   bool
+	VLong   int64
+	VBinary []byte
+}
+
+type jaegerLog struct {
+	Timestamp int64 // nanoseconds
+	Fields    []jaegerTag
+}
+
+// decodeJaegerBatch decodes the Thrift encoded batch of Jaeger spans of the request.
+func decodeJaegerBatch(req *http.Request) (*jaegerBatch, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := copyRequestBody(buf, req); err != nil {
+		return nil, err
+	}
+	batch := &jaegerBatch{}
+	r := thrift.NewReader(buf.Bytes())
+	err := readThriftStruct(r, func(typ thrift.Type, id int16) (bool, error) {
+		switch {
+		case id == 1 && typ == thrift.STRUCT:
+			return true, readThriftStruct(r, func(typ thrift.Type, id int16) (bool, error) {
+				var err error
+				switch {
+				case id == 1 && typ == thrift.STRING:
+					batch.Process.ServiceName, err = r.ReadString()
+				case id == 2 && typ == thrift.LIST:
+					batch.Process.Tags, err = readJaegerTags(r)
+				default:
+					return false, nil
+				}
+				return true, err
+			})
+		case id == 2 && typ == thrift.LIST:
+			return true, readThriftList(r, thrift.STRUCT, func() error {
+				span, err := readJaegerSpan(r)
+				batch.Spans = append(batch.Spans, span)
+				return err
+			})
+		default:
+			return false, nil
+		}
+	})
+	return batch, err
+}
+
+func readJaegerSpan(r *thrift.Reader) (*jaegerSpan, error) {
+	span := &jaegerSpan{}
+	err := readThriftStruct(r, func(typ thrift.Type, id int16) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.I64:
+			span.TraceIDLow, err = r.ReadI64()
+		case id == 2 && typ == thrift.I64:
+			span.TraceIDHigh, err = r.ReadI64()
+		case id == 3 && typ == thrift.I64:
+			span.SpanID, err = r.ReadI64()
+		case id == 4 && typ == thrift.I64:
+			span.ParentSpanID, err = r.ReadI64()
+		case id == 5 && typ == thrift.STRING:
+			span.OperationName, err = r.ReadString()
+		case id == 6 && typ == thrift.LIST:
+			err = readThriftList(r, thrift.STRUCT, func() error {
+				var ref jaegerSpanRef
+				err := readThriftStruct(r, func(typ thrift.Type, id int16) (bool, error) {
+					var err error
+					switch {
+					case id == 1 && typ == thrift.I32:
+						ref.RefType, err = r.ReadI32()
+					case id == 2 && typ == thrift.I64:
+						ref.TraceIDLow, err = r.ReadI64()
+					case id == 4 && typ == thrift.I64:
+						ref.SpanID, err = r.ReadI64()
+					default:
+						return false, nil
+					}
+					return true, err
+				})
+				span.References = append(span.References, ref)
+				return err
+			})
+		case id == 7 && typ == thrift.I32:
+			span.Flags, err = r.ReadI32()
+		case id == 8 && typ == thrift.I64:
+			span.StartTime, err = r.ReadI64()
+			span.StartTime *= 1000
+		case id == 9 && typ == thrift.I64:
+			span.Duration, err = r.ReadI64()
+			span.Duration *= 1000
+		case id == 10 && typ == thrift.LIST:
+			span.Tags, err = readJaegerTags(r)
+		case id == 11 && typ == thrift.LIST:
+			err = readThriftList(r, thrift.STRUCT, func() error {
+				var l jaegerLog
+				err := readThriftStruct(r, func(typ thrift.Type, id int16) (bool, error) {
+					var err error
+					switch {
+					case id == 1 && typ == thrift.I64:
+						l.Timestamp, err = r.ReadI64()
+						l.Timestamp *= 1000
+					case id == 2 && typ == thrift.LIST:
+						l.Fields, err = readJaegerTags(r)
+					default:
+						return false, nil
+					}
+					return true, err
+				})
+				span.Logs = append(span.Logs, l)
+				return err
+			})
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return span, err
+}
+
+func readJaegerTags(r *thrift.Reader) ([]jaegerTag, error) {
+	var tags []jaegerTag
+	err := readThriftList(r, thrift.STRUCT, func() error {
+		var t jaegerTag
+		err := readThriftStruct(r, func(typ thrift.Type, id int16) (bool, error) {
+			var err error
+			switch {
+			case id == 1 && typ == thrift.STRING:
+				t.Key, err = r.ReadString()
+			case id == 2 && typ == thrift.I32:
+				t.VType, err = r.ReadI32()
+			case id == 3 && typ == thrift.STRING:
+				t.VStr, err = r.ReadString()
+			case id == 4 && typ == thrift.DOUBLE:
+				t.VDouble, err = r.ReadDouble()
+			case id == 5 && typ == thrift.BOOL:
+				t.VBool, err = r.ReadBool()
+			case id == 6 && typ == thrift.I64:
+				t.VLong, err = r.ReadI64()
+			case id == 7 && typ == thrift.STRING:
+				t.VBinary, err = r.ReadBinary()
+			default:
+				return false, nil
+			}
+			return true, err
+		})
+		tags = append(tags, t)
+		return err
+	})
+	return tags, err
+}
+
+// readThriftStruct reads a struct, calling field with the type and the ID of each of its fields.
+// field reads the values of the fields it knows and returns false for the others, which are skipped.
+func readThriftStruct(r *thrift.Reader, field func(typ thrift.Type, id int16) (bool, error)) error {
+	for {
+		typ, id, err := r.ReadFieldBegin()
+		if err != nil {
+			return err
+		}
+		if typ == thrift.STOP {
+			return nil
+		}
+		ok, err := field(typ, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := r.Skip(typ); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readThriftList reads a list of elements of type typ, calling elem to read each of them.
+func readThriftList(r *thrift.Reader, typ thrift.Type, elem func() error) error {
+	etyp, size, err := r.ReadListBegin()
+	if err != nil {
+		return err
+	}
+	if etyp != typ {
+		return fmt.Errorf("unexpected list of type %d", etyp)
+	}
+	for i := 0; i < size; i++ {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jaegerTraceChunks converts the spans of the Jaeger batch to Datadog trace chunks.
+func jaegerTraceChunks(batch *jaegerBatch) []*pb.TraceChunk {
+	spans := make([]*pb.Span, 0, len(batch.Spans))
+	priorities := make(map[uint64]sampler.SamplingPriority)
+	for _, s := range batch.Spans {
+		span := convertJaegerSpan(batch, s)
+		if s.Flags&jaegerFlagDebug != 0 {
+			priorities[span.TraceID] = sampler.PriorityUserKeep
+		}
+		spans = append(spans, span)
+	}
+	return traceChunksFromSampledSpans(spans, priorities)
+}
+
+// convertJaegerSpan converts the Jaeger span in of the batch to a Datadog span.
+func convertJaegerSpan(batch *jaegerBatch, in *jaegerSpan) *pb.Span {
+	process := &batch.Process
+	if in.Process != nil {
+		process = in.Process
+	}
+	span := &pb.Span{
+		Service:  process.ServiceName,
+		TraceID:  uint64(in.TraceIDLow),
+		SpanID:   uint64(in.SpanID),
+		ParentID: uint64(in.ParentSpanID),
+		Start:    in.StartTime,
+		Duration: in.Duration,
+		Meta:     make(map[string]string, len(process.Tags)+len(in.Tags)),
+		Metrics:  map[string]float64{},
+	}
+	if span.ParentID == 0 {
+		for _, ref := range in.References {
+			if ref.RefType == jaegerRefChildOf && ref.TraceIDLow == in.TraceIDLow {
+				span.ParentID = uint64(ref.SpanID)
+				break
+			}
+		}
+	}
+	for i := range process.Tags {
+		setJaegerTag(span, &process.Tags[i])
+	}
+	for i := range in.Tags {
+		t := &in.Tags[i]
+		if t.Key == "error" {
+			if (t.VType == jaegerTagBool && t.VBool) || (t.VType == jaegerTagString && t.VStr == "true") {
+				span.Error = 1
+			}
+			continue
+		}
+		setJaegerTag(span, t)
+	}
+	if len(in.Logs) > 0 {
+		events := make([]spanEvent, 0, len(in.Logs))
+		for _, l := range in.Logs {
+			events = append(events, jaegerLogToEvent(span, l))
+		}
+		setMetaOTLP(span, "events", marshalSpanEvents(events))
+	}
+	kind := spanKindFromName(span.Meta["span.kind"])
+	setTraceIDHigh(span, uint64(in.TraceIDHigh))
+	completeSpan(span, "jaeger", kind, in.OperationName)
+	return span
+}
+
+// setJaegerTag sets the Jaeger tag t as a tag or a metric on span s.
+func setJaegerTag(s *pb.Span, t *jaegerTag) {
+	switch t.VType {
+	case jaegerTagDouble:
+		setMetricOTLP(s, t.Key, t.VDouble)
+	case jaegerTagLong:
+		setMetricOTLP(s, t.Key, float64(t.VLong))
+		if t.Key == "http.status_code" {
+			// http.status_code is a tag in the Datadog APM conventions
+			setMetaOTLP(s, t.Key, strconv.FormatInt(t.VLong, 10))
+		}
+	case jaegerTagBool:
+		setMetaOTLP(s, t.Key, strconv.FormatBool(t.VBool))
+	case jaegerTagBinary:
+		setMetaOTLP(s, t.Key, base64.StdEncoding.EncodeToString(t.VBinary))
+	default:
+		setMetaOTLP(s, t.Key, t.VStr)
+	}
+}
+
+// jaegerLogToEvent converts the Jaeger log l to a span event. The error logs set the error
+// tags of span.
+func jaegerLogToEvent(span *pb.Span, l jaegerLog) spanEvent {
+	e := spanEvent{
+		TimeUnixNano: uint64(l.Timestamp),
+		Name:         "log",
+		Attributes:   make(map[string]string, len(l.Fields)),
+	}
+	for _, f := range l.Fields {
+		var v string
+		switch f.VType {
+		case jaegerTagDouble:
+			v = strconv.FormatFloat(f.VDouble, 'f', -1, 64)
+		case jaegerTagLong:
+			v = strconv.FormatInt(f.VLong, 10)
+		case jaegerTagBool:
+			v = strconv.FormatBool(f.VBool)
+		case jaegerTagBinary:
+			v = base64.StdEncoding.EncodeToString(f.VBinary)
+		default:
+			v = f.VStr
+		}
+		if f.Key == "event" {
+			e.Name = v
+			continue
+		}
+		e.Attributes[f.Key] = v
+	}
+	if e.Name == "error" {
+		span.Error = 1
+		if msg, ok := e.Attributes["message"]; ok {
+			span.Meta["error.msg"] = msg
+		} else if msg, ok := e.Attributes["error.object"]; ok {
+			span.Meta["error.msg"] = msg
+		}
+		if typ, ok := e.Attributes["error.kind"]; ok {
+			span.Meta["error.type"] = typ
+		}
+		if stack, ok := e.Attributes["stack"]; ok {
+			span.Meta["error.stack"] = stack
+		}
+	}
+	return e
+}
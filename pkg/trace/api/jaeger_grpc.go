@@ -0,0 +1,332 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package api
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/timing"
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+// jaegerGRPCEndpointVersion is the endpoint version reported in the stats of the spans received over gRPC.
+const jaegerGRPCEndpointVersion = "jaeger_grpc"
+
+// JaegerGRPCReceiver implements the gRPC API of the Jaeger collector, receiving the spans of
+// the Jaeger agents and clients on a dedicated port.
+//
+// The messages are decoded from their protobuf encoding without generated code, the server
+// passing them as raw bytes to the handler.
+type JaegerGRPCReceiver struct {
+	wg      sync.WaitGroup      // waits for a graceful shutdown
+	grpcsrv *grpc.Server        // the running GRPC server on a started receiver, if enabled
+	out     chan<- *Payload     // the outgoing payload channel
+	conf    *config.AgentConfig // receiver config
+	statsd  statsd.ClientInterface
+	timing  timing.Reporter
+}
+
+// NewJaegerGRPCReceiver returns a new JaegerGRPCReceiver which sends any incoming traces down the out channel.
+func NewJaegerGRPCReceiver(out chan<- *Payload, cfg *config.AgentConfig, statsd statsd.ClientInterface, timing timing.Reporter) *JaegerGRPCReceiver {
+	return &JaegerGRPCReceiver{out: out, conf: cfg, statsd: statsd, timing: timing}
+}
+
+// Start starts the JaegerGRPCReceiver, if its port is configured.
+func (j *JaegerGRPCReceiver) Start() {
+	if j.conf.JaegerGRPCPort == 0 {
+		return
+	}
+	addr := net.JoinHostPort(j.conf.ReceiverHost, fmt.Sprint(j.conf.JaegerGRPCPort))
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Criticalf("Error starting Jaeger gRPC server: %v", err)
+		return
+	}
+	j.grpcsrv = grpc.NewServer(
+		grpc.MaxRecvMsgSize(10*1024*1024),
+		grpc.ForceServerCodec(rawCodec{}),
+	)
+	j.grpcsrv.RegisterService(&jaegerCollectorServiceDesc, j)
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		if err := j.grpcsrv.Serve(ln); err != nil {
+			log.Criticalf("Error starting Jaeger gRPC server: %v", err)
+		}
+	}()
+	log.Infof("Listening for Jaeger traces on gRPC at %s", addr)
+}
+
+// Stop stops any running server.
+func (j *JaegerGRPCReceiver) Stop() {
+	if j.grpcsrv != nil {
+		go j.grpcsrv.Stop()
+	}
+	j.wg.Wait()
+}
+
+// postSpans handles the PostSpans requests of the Jaeger collector API.
+func (j *JaegerGRPCReceiver) postSpans(_ context.Context, in []byte) ([]byte, error) {
+	defer j.timing.Since("datadog.trace_agent.receiver.jaeger_grpc_process_ms", time.Now())
+	batch, err := decodeJaegerPostSpansRequest(in)
+	if err != nil {
+		_ = j.statsd.Count("datadog.trace_agent.receiver.error", 1, []string{"handler:jaeger_grpc", "error:decoding-error"}, 1)
+		return nil, status.Errorf(codes.InvalidArgument, "cannot decode Jaeger spans: %v", err)
+	}
+	ts := &info.TagStats{
+		Tags:  info.Tags{EndpointVersion: jaegerGRPCEndpointVersion, Service: batch.Process.ServiceName},
+		Stats: info.NewStats(),
+	}
+	chunks := jaegerTraceChunks(batch)
+	ts.TracesReceived.Add(int64(len(chunks)))
+	ts.TracesBytes.Add(int64(len(in)))
+	ts.PayloadAccepted.Inc()
+	_ = j.statsd.Count("datadog.trace_agent.receiver.jaeger_grpc.spans", int64(len(batch.Spans)), ts.AsTags(), 1)
+
+	j.out <- &Payload{
+		Source: ts,
+		TracerPayload: &pb.TracerPayload{
+			Chunks: chunks,
+			Env:    j.conf.DefaultEnv,
+		},
+	}
+	// PostSpansResponse is an empty message
+	return []byte{}, nil
+}
+
+// jaegerCollectorServiceDesc describes the CollectorService of the Jaeger API v2.
+// See https://github.com/jaegertracing/jaeger-idl/blob/main/proto/api_v2/collector.proto.
+var jaegerCollectorServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jaeger.api_v2.CollectorService",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "PostSpans",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+			var in []byte
+			if err := dec(&in); err != nil {
+				return nil, err
+			}
+			out, err := srv.(*JaegerGRPCReceiver).postSpans(ctx, in)
+			if err != nil {
+				return nil, err
+			}
+			return &out, nil
+		},
+	}},
+	Metadata: "api_v2/collector.proto",
+}
+
+// rawCodec is a gRPC codec passing the messages as raw bytes, to decode them without generated code.
+type rawCodec struct{}
+
+// Marshal implements encoding.Codec.
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message of type %T", v)
+	}
+	return *b, nil
+}
+
+// Unmarshal implements encoding.Codec.
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message of type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// Name implements encoding.Codec.
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// jaegerProtoTagTypes maps the types of the values of the tags in the protobuf encoding to their
+// type in the Thrift encoding.
+var jaegerProtoTagTypes = map[uint64]int32{
+	0: jaegerTagString,
+	1: jaegerTagBool,
+	2: jaegerTagLong,
+	3: jaegerTagDouble,
+	4: jaegerTagBinary,
+}
+
+// decodeJaegerPostSpansRequest decodes a protobuf encoded PostSpansRequest.
+// See https://github.com/jaegertracing/jaeger-idl/blob/main/proto/api_v2/model.proto.
+func decodeJaegerPostSpansRequest(b []byte) (*jaegerBatch, error) {
+	batch := &jaegerBatch{}
+	err := consumeProtoFields(b, func(num protowire.Number, _ uint64, v []byte) error {
+		if num != 1 {
+			return nil
+		}
+		return consumeProtoFields(v, func(num protowire.Number, _ uint64, v []byte) error {
+			switch num {
+			case 1:
+				span, err := decodeJaegerProtoSpan(v)
+				batch.Spans = append(batch.Spans, span)
+				return err
+			case 2:
+				return decodeJaegerProtoProcess(v, &batch.Process)
+			}
+			return nil
+		})
+	})
+	return batch, err
+}
+
+func decodeJaegerProtoSpan(b []byte) (*jaegerSpan, error) {
+	span := &jaegerSpan{}
+	err := consumeProtoFields(b, func(num protowire.Number, u uint64, v []byte) error {
+		var err error
+		switch num {
+		case 1:
+			span.TraceIDHigh, span.TraceIDLow, err = jaegerProtoTraceID(v)
+		case 2:
+			span.SpanID, err = jaegerProtoSpanID(v)
+		case 3:
+			span.OperationName = string(v)
+		case 4:
+			var ref jaegerSpanRef
+			err = consumeProtoFields(v, func(num protowire.Number, u uint64, v []byte) error {
+				var err error
+				switch num {
+				case 1:
+					_, ref.TraceIDLow, err = jaegerProtoTraceID(v)
+				case 2:
+					ref.SpanID, err = jaegerProtoSpanID(v)
+				case 3:
+					ref.RefType = int32(u)
+				}
+				return err
+			})
+			span.References = append(span.References, ref)
+		case 5:
+			span.Flags = int32(u)
+		case 6:
+			span.StartTime, err = jaegerProtoNanos(v)
+		case 7:
+			span.Duration, err = jaegerProtoNanos(v)
+		case 8:
+			var t jaegerTag
+			t, err = decodeJaegerProtoTag(v)
+			span.Tags = append(span.Tags, t)
+		case 9:
+			var l jaegerLog
+			err = consumeProtoFields(v, func(num protowire.Number, _ uint64, v []byte) error {
+				var err error
+				switch num {
+				case 1:
+					l.Timestamp, err = jaegerProtoNanos(v)
+				case 2:
+					var t jaegerTag
+					t, err = decodeJaegerProtoTag(v)
+					l.Fields = append(l.Fields, t)
+				}
+				return err
+			})
+			span.Logs = append(span.Logs, l)
+		case 10:
+			span.Process = &jaegerProcess{}
+			err = decodeJaegerProtoProcess(v, span.Process)
+		}
+		return err
+	})
+	return span, err
+}
+
+func decodeJaegerProtoProcess(b []byte, p *jaegerProcess) error {
+	return consumeProtoFields(b, func(num protowire.Number, _ uint64, v []byte) error {
+		switch num {
+		case 1:
+			p.ServiceName = string(v)
+		case 2:
+			t, err := decodeJaegerProtoTag(v)
+			if err != nil {
+				return err
+			}
+			p.Tags = append(p.Tags, t)
+		}
+		return nil
+	})
+}
+
+func decodeJaegerProtoTag(b []byte) (jaegerTag, error) {
+	var t jaegerTag
+	err := consumeProtoFields(b, func(num protowire.Number, u uint64, v []byte) error {
+		switch num {
+		case 1:
+			t.Key = string(v)
+		case 2:
+			typ, ok := jaegerProtoTagTypes[u]
+			if !ok {
+				return fmt.Errorf("unknown tag type %d", u)
+			}
+			t.VType = typ
+		case 3:
+			t.VStr = string(v)
+		case 4:
+			t.VBool = u != 0
+		case 5:
+			t.VLong = int64(u)
+		case 6:
+			t.VDouble = math.Float64frombits(u)
+		case 7:
+			t.VBinary = v
+		}
+		return nil
+	})
+	return t, err
+}
+
+func jaegerProtoTraceID(b []byte) (high, low int64, err error) {
+	switch len(b) {
+	case 16:
+		return int64(binary.BigEndian.Uint64(b[:8])), int64(binary.BigEndian.Uint64(b[8:])), nil
+	case 8:
+		return 0, int64(binary.BigEndian.Uint64(b)), nil
+	default:
+		return 0, 0, fmt.Errorf("invalid trace ID of %d bytes", len(b))
+	}
+}
+
+func jaegerProtoSpanID(b []byte) (int64, error) {
+	if len(b) != 8 {
+		return 0, fmt.Errorf("invalid span ID of %d bytes", len(b))
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// jaegerProtoNanos returns the nanoseconds of a google.protobuf.Timestamp or google.protobuf.Duration.
+func jaegerProtoNanos(b []byte) (int64, error) {
+	var seconds, nanos int64
+	err := consumeProtoFields(b, func(num protowire.Number, u uint64, _ []byte) error {
+		switch num {
+		case 1:
+			seconds = int64(u)
+		case 2:
+			nanos = int64(int32(u))
+		}
+		return nil
+	})
+	return seconds*int64(time.Second) + nanos, err
+}
@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package api
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/timing"
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+// encodeJaegerPostSpansRequest encodes the batch as a protobuf PostSpansRequest.
+func encodeJaegerPostSpansRequest(batch *jaegerBatch) []byte {
+	message := func(b []byte, num protowire.Number, m []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, m)
+	}
+	id := func(hi, lo int64) []byte {
+		var b []byte
+		if hi != 0 {
+			b = binary.BigEndian.AppendUint64(b, uint64(hi))
+		}
+		return binary.BigEndian.AppendUint64(b, uint64(lo))
+	}
+	nanos := func(ns int64) []byte {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(ns/int64(time.Second)))
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		return protowire.AppendVarint(b, uint64(ns%int64(time.Second)))
+	}
+	protoTypes := map[int32]uint64{jaegerTagString: 0, jaegerTagBool: 1, jaegerTagLong: 2, jaegerTagDouble: 3, jaegerTagBinary: 4}
+	tag := func(t jaegerTag) []byte {
+		b := message(nil, 1, []byte(t.Key))
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, protoTypes[t.VType])
+		switch t.VType {
+		case jaegerTagBool:
+			b = protowire.AppendTag(b, 4, protowire.VarintType)
+			b = protowire.AppendVarint(b, protowire.EncodeBool(t.VBool))
+		case jaegerTagLong:
+			b = protowire.AppendTag(b, 5, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(t.VLong))
+		case jaegerTagDouble:
+			b = protowire.AppendTag(b, 6, protowire.Fixed64Type)
+			b = protowire.AppendFixed64(b, math.Float64bits(t.VDouble))
+		case jaegerTagBinary:
+			b = message(b, 7, t.VBinary)
+		default:
+			b = message(b, 3, []byte(t.VStr))
+		}
+		return b
+	}
+
+	var bb []byte
+	for _, s := range batch.Spans {
+		var sb []byte
+		sb = message(sb, 1, id(s.TraceIDHigh, s.TraceIDLow))
+		sb = message(sb, 2, id(0, s.SpanID))
+		sb = message(sb, 3, []byte(s.OperationName))
+		for _, ref := range s.References {
+			rb := message(nil, 1, id(0, ref.TraceIDLow))
+			rb = message(rb, 2, id(0, ref.SpanID))
+			rb = protowire.AppendTag(rb, 3, protowire.VarintType)
+			rb = protowire.AppendVarint(rb, uint64(ref.RefType))
+			sb = message(sb, 4, rb)
+		}
+		sb = protowire.AppendTag(sb, 5, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(s.Flags))
+		sb = message(sb, 6, nanos(s.StartTime))
+		sb = message(sb, 7, nanos(s.Duration))
+		for _, t := range s.Tags {
+			sb = message(sb, 8, tag(t))
+		}
+		for _, l := range s.Logs {
+			lb := message(nil, 1, nanos(l.Timestamp))
+			for _, t := range l.Fields {
+				lb = message(lb, 2, tag(t))
+			}
+			sb = message(sb, 9, lb)
+		}
+		bb = message(bb, 1, sb)
+	}
+	pb := message(nil, 1, []byte(batch.Process.ServiceName))
+	for _, t := range batch.Process.Tags {
+		pb = message(pb, 2, tag(t))
+	}
+	bb = message(bb, 2, pb)
+	return message(nil, 1, bb)
+}
+
+func TestDecodeJaegerPostSpansRequest(t *testing.T) {
+	batch, err := decodeJaegerPostSpansRequest(encodeJaegerPostSpansRequest(jaegerTestBatch))
+	require.NoError(t, err)
+	assert.Equal(t, jaegerTestBatch, batch)
+
+	_, err = decodeJaegerPostSpansRequest([]byte{0x0a, 0x05, 0x0a, 0x03, 0x0a, 0x01, 0x00})
+	assert.EqualError(t, err, "invalid trace ID of 1 bytes")
+}
+
+func TestJaegerGRPCReceiver(t *testing.T) {
+	t.Run("Start/disabled", func(t *testing.T) {
+		j := NewJaegerGRPCReceiver(nil, newTestReceiverConfig(), &statsd.NoOpClient{}, &timing.NoopReporter{})
+		j.Start()
+		defer j.Stop()
+		assert.Nil(t, j.grpcsrv)
+	})
+
+	t.Run("PostSpans", func(t *testing.T) {
+		port := testutil.FreeTCPPort(t)
+		conf := newTestReceiverConfig()
+		conf.ReceiverHost = "localhost"
+		conf.JaegerGRPCPort = port
+		out := make(chan *Payload, 1)
+		j := NewJaegerGRPCReceiver(out, conf, &statsd.NoOpClient{}, &timing.NoopReporter{})
+		j.Start()
+		defer j.Stop()
+		require.NotNil(t, j.grpcsrv)
+
+		conn, err := grpc.NewClient(net.JoinHostPort("localhost", strconv.Itoa(port)),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+		)
+		require.NoError(t, err)
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req := encodeJaegerPostSpansRequest(jaegerTestBatch)
+		var resp []byte
+		require.NoError(t, conn.Invoke(ctx, "/jaeger.api_v2.CollectorService/PostSpans", &req, &resp))
+		assert.Empty(t, resp)
+
+		var p *Payload
+		select {
+		case p = <-out:
+		case <-time.After(time.Second):
+			t.Fatal("no payload received")
+		}
+		assert.Equal(t, "jaeger_grpc", p.Source.EndpointVersion)
+		require.Len(t, p.TracerPayload.Chunks, 2)
+		chunk := p.TracerPayload.Chunks[0]
+		assert.EqualValues(t, sampler.PriorityUserKeep, chunk.Priority)
+		require.Len(t, chunk.Spans, 2)
+		assert.Equal(t, "checkout", chunk.Spans[0].Service)
+		assert.Equal(t, "POST /cart", chunk.Spans[0].Resource)
+		assert.EqualValues(t, 1700000000000000000, chunk.Spans[0].Start)
+		assert.EqualValues(t, 11, chunk.Spans[1].ParentID)
+
+		invalid := []byte{0x0a, 0x01}
+		err = conn.Invoke(ctx, "/jaeger.api_v2.CollectorService/PostSpans", &invalid, &resp)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Empty(t, out)
+	})
+}
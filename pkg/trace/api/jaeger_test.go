@@ -0,0 +1,246 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/thrift"
+	"github.com/DataDog/datadog-agent/pkg/trace/api/internal/thrift/thrifttest"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+)
+
+// encodeJaegerBatch encodes the batch with the Thrift binary protocol.
+func encodeJaegerBatch(batch *jaegerBatch) []byte {
+	var w thrifttest.Writer
+	writeTags := func(id int16, tags []jaegerTag) {
+		w.WriteFieldBegin(thrift.LIST, id)
+		w.WriteListBegin(thrift.STRUCT, len(tags))
+		for _, t := range tags {
+			w.WriteFieldBegin(thrift.STRING, 1)
+			w.WriteString(t.Key)
+			w.WriteFieldBegin(thrift.I32, 2)
+			w.WriteI32(t.VType)
+			switch t.VType {
+			case jaegerTagDouble:
+				w.WriteFieldBegin(thrift.DOUBLE, 4)
+				w.WriteDouble(t.VDouble)
+			case jaegerTagBool:
+				w.WriteFieldBegin(thrift.BOOL, 5)
+				w.WriteBool(t.VBool)
+			case jaegerTagLong:
+				w.WriteFieldBegin(thrift.I64, 6)
+				w.WriteI64(t.VLong)
+			case jaegerTagBinary:
+				w.WriteFieldBegin(thrift.STRING, 7)
+				w.WriteString(string(t.VBinary))
+			default:
+				w.WriteFieldBegin(thrift.STRING, 3)
+				w.WriteString(t.VStr)
+			}
+			w.WriteFieldStop()
+		}
+	}
+
+	w.WriteFieldBegin(thrift.STRUCT, 1)
+	w.WriteFieldBegin(thrift.STRING, 1)
+	w.WriteString(batch.Process.ServiceName)
+	writeTags(2, batch.Process.Tags)
+	w.WriteFieldStop()
+
+	w.WriteFieldBegin(thrift.LIST, 2)
+	w.WriteListBegin(thrift.STRUCT, len(batch.Spans))
+	for _, s := range batch.Spans {
+		for id, v := range map[int16]int64{1: s.TraceIDLow, 2: s.TraceIDHigh, 3: s.SpanID, 4: s.ParentSpanID, 8: s.StartTime / 1000, 9: s.Duration / 1000} {
+			w.WriteFieldBegin(thrift.I64, id)
+			w.WriteI64(v)
+		}
+		w.WriteFieldBegin(thrift.STRING, 5)
+		w.WriteString(s.OperationName)
+		w.WriteFieldBegin(thrift.LIST, 6)
+		w.WriteListBegin(thrift.STRUCT, len(s.References))
+		for _, ref := range s.References {
+			w.WriteFieldBegin(thrift.I32, 1)
+			w.WriteI32(ref.RefType)
+			w.WriteFieldBegin(thrift.I64, 2)
+			w.WriteI64(ref.TraceIDLow)
+			w.WriteFieldBegin(thrift.I64, 3)
+			w.WriteI64(0)
+			w.WriteFieldBegin(thrift.I64, 4)
+			w.WriteI64(ref.SpanID)
+			w.WriteFieldStop()
+		}
+		w.WriteFieldBegin(thrift.I32, 7)
+		w.WriteI32(s.Flags)
+		writeTags(10, s.Tags)
+		w.WriteFieldBegin(thrift.LIST, 11)
+		w.WriteListBegin(thrift.STRUCT, len(s.Logs))
+		for _, l := range s.Logs {
+			w.WriteFieldBegin(thrift.I64, 1)
+			w.WriteI64(l.Timestamp / 1000)
+			writeTags(2, l.Fields)
+			w.WriteFieldStop()
+		}
+		w.WriteFieldStop()
+	}
+	// unknown fields are skipped
+	w.WriteFieldBegin(thrift.I64, 3)
+	w.WriteI64(42)
+	w.WriteFieldStop()
+	return w.Bytes()
+}
+
+var jaegerTestBatch = &jaegerBatch{
+	Process: jaegerProcess{
+		ServiceName: "checkout",
+		Tags: []jaegerTag{
+			{Key: "hostname", VStr: "host-1"},
+			{Key: "jaeger.version", VStr: "Go-2.30.0"},
+		},
+	},
+	Spans: []*jaegerSpan{
+		{
+			TraceIDLow:    10,
+			TraceIDHigh:   1,
+			SpanID:        11,
+			OperationName: "HTTP POST",
+			Flags:         1,
+			StartTime:     1700000000000000000,
+			Duration:      2000000,
+			Tags: []jaegerTag{
+				{Key: "span.kind", VStr: "server"},
+				{Key: "http.method", VStr: "POST"},
+				{Key: "http.route", VStr: "/cart"},
+				{Key: "http.status_code", VType: jaegerTagLong, VLong: 500},
+				{Key: "error", VType: jaegerTagBool, VBool: true},
+				{Key: "retries", VType: jaegerTagDouble, VDouble: 1.5},
+			},
+			Logs: []jaegerLog{{
+				Timestamp: 1700000000000500000,
+				Fields: []jaegerTag{
+					{Key: "event", VStr: "error"},
+					{Key: "message", VStr: "out of stock"},
+					{Key: "error.kind", VStr: "StockError"},
+				},
+			}},
+		},
+		{
+			TraceIDLow:    10,
+			TraceIDHigh:   1,
+			SpanID:        12,
+			OperationName: "reserve",
+			References:    []jaegerSpanRef{{RefType: jaegerRefChildOf, TraceIDLow: 10, SpanID: 11}},
+			Flags:         1,
+			StartTime:     1700000000000100000,
+			Duration:      500000,
+			Tags: []jaegerTag{
+				{Key: "span.kind", VStr: "client"},
+				{Key: "db.system", VStr: "redis"},
+				{Key: "sampling.priority", VType: jaegerTagLong, VLong: 2},
+			},
+		},
+		{
+			TraceIDLow:    20,
+			SpanID:        21,
+			OperationName: "cleanup",
+			Flags:         jaegerFlagDebug | 1,
+			StartTime:     1700000000000000000,
+			Duration:      10000,
+		},
+	},
+}
+
+func TestJaegerIntake(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.JaegerReceiverEnabled = true
+	rcv := newTestReceiverFromConfig(conf)
+	server := httptest.NewServer(rcv.buildMux())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/traces", "application/x-thrift", bytes.NewReader(encodeJaegerBatch(jaegerTestBatch)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var p *Payload
+	select {
+	case p = <-rcv.out:
+	case <-time.After(time.Second):
+		t.Fatal("no payload received")
+	}
+	require.Len(t, p.TracerPayload.Chunks, 2)
+	assert.Equal(t, "jaeger_thrift", p.Source.EndpointVersion)
+
+	chunk := p.TracerPayload.Chunks[0]
+	// the sampling priority set on a span wins over the client sampling decision
+	assert.EqualValues(t, sampler.PriorityUserKeep, chunk.Priority)
+	require.Len(t, chunk.Spans, 2)
+
+	root := chunk.Spans[0]
+	assert.EqualValues(t, 10, root.TraceID)
+	assert.EqualValues(t, 11, root.SpanID)
+	assert.EqualValues(t, 0, root.ParentID)
+	assert.EqualValues(t, 1700000000000000000, root.Start)
+	assert.EqualValues(t, 2000000, root.Duration)
+	assert.Equal(t, "checkout", root.Service)
+	assert.Equal(t, "jaeger.server", root.Name)
+	assert.Equal(t, "POST /cart", root.Resource)
+	assert.Equal(t, "web", root.Type)
+	assert.EqualValues(t, 1, root.Error)
+	assert.Equal(t, "out of stock", root.Meta["error.msg"])
+	assert.Equal(t, "StockError", root.Meta["error.type"])
+	assert.Equal(t, "500", root.Meta["http.status_code"])
+	assert.Equal(t, "host-1", root.Meta["hostname"])
+	assert.Equal(t, "0000000000000001", root.Meta["_dd.p.tid"])
+	assert.Equal(t, 1.5, root.Metrics["retries"])
+	assert.NotContains(t, root.Meta, "error")
+	assert.Equal(t, `[{"time_unix_nano":1700000000000500000,"name":"error","attributes":{"error.kind":"StockError","message":"out of stock"}}]`, root.Meta["events"])
+
+	child := chunk.Spans[1]
+	assert.EqualValues(t, 11, child.ParentID)
+	assert.Equal(t, "jaeger.client", child.Name)
+	assert.Equal(t, "reserve", child.Resource)
+	assert.Equal(t, "cache", child.Type)
+	assert.EqualValues(t, 0, child.Error)
+
+	debug := p.TracerPayload.Chunks[1]
+	assert.EqualValues(t, sampler.PriorityUserKeep, debug.Priority)
+	assert.Equal(t, "jaeger.internal", debug.Spans[0].Name)
+	assert.Equal(t, "cleanup", debug.Spans[0].Resource)
+}
+
+func TestJaegerIntakeInvalid(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.JaegerReceiverEnabled = true
+	rcv := newTestReceiverFromConfig(conf)
+	server := httptest.NewServer(rcv.buildMux())
+	defer server.Close()
+
+	payload := encodeJaegerBatch(jaegerTestBatch)
+	resp, err := http.Post(server.URL+"/api/traces", "application/x-thrift", bytes.NewReader(payload[:len(payload)/2]))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, rcv.out)
+}
+
+func TestJaegerIntakeDisabled(t *testing.T) {
+	rcv := newTestReceiverFromConfig(newTestReceiverConfig())
+	server := httptest.NewServer(rcv.buildMux())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/traces", "application/x-thrift", bytes.NewReader(encodeJaegerBatch(jaegerTestBatch)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -127,4 +127,24 @@ const (
 	// Response: Service sampling rates (see description in v04).
 	//
 	V07 Version = "v0.7"
+
+	// zipkinV2 API
+	//
+	// Request: Zipkin spans.
+	// 	Content-Type: application/json or application/x-protobuf
+	// 	Payload: ListOfSpans (https://zipkin.io/zipkin-api/#/default/post_spans)
+	//
+	// Response: OK.
+	//
+	zipkinV2 Version = "zipkin_v2"
+
+	// jaegerThrift API
+	//
+	// Request: Jaeger spans.
+	// 	Content-Type: application/x-thrift
+	// 	Payload: Batch encoded with the Thrift binary protocol (https://github.com/jaegertracing/jaeger-idl/blob/main/thrift/jaeger.thrift)
+	//
+	// Response: OK.
+	//
+	jaegerThrift Version = "jaeger_thrift"
 )
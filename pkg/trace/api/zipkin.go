@@ -0,0 +1,431 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// zipkinSpan is a span of the Zipkin v2 API. See https://zipkin.io/zipkin-api/#/default/post_spans.
+type zipkinSpan struct {
+	TraceID        zipkinTraceID      `json:"traceId"`
+	ParentID       zipkinSpanID       `json:"parentId"`
+	ID             zipkinSpanID       `json:"id"`
+	Kind           string             `json:"kind"`
+	Name           string             `json:"name"`
+	Timestamp      uint64             `json:"timestamp"` // microseconds
+	Duration       uint64             `json:"duration"`  // microseconds
+	Debug          bool               `json:"debug"`
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint"`
+	Annotations    []zipkinAnnotation `json:"annotations"`
+	Tags           map[string]string  `json:"tags"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int32  `json:"port"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"` // microseconds
+	Value     string `json:"value"`
+}
+
+// zipkinTraceID is a 64 or 128-bit trace ID, hex encoded in JSON.
+type zipkinTraceID struct {
+	High, Low uint64
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (id *zipkinTraceID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if len(s) > 32 {
+		return fmt.Errorf("invalid trace ID %q", s)
+	}
+	var err error
+	if len(s) > 16 {
+		if id.High, err = strconv.ParseUint(s[:len(s)-16], 16, 64); err != nil {
+			return fmt.Errorf("invalid trace ID %q", s)
+		}
+		s = s[len(s)-16:]
+	}
+	if id.Low, err = strconv.ParseUint(s, 16, 64); err != nil {
+		return fmt.Errorf("invalid trace ID %q", s)
+	}
+	return nil
+}
+
+// zipkinSpanID is a 64-bit span ID, hex encoded in JSON.
+type zipkinSpanID uint64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (id *zipkinSpanID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*id = 0
+		return nil
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid span ID %q", s)
+	}
+	*id = zipkinSpanID(v)
+	return nil
+}
+
+// decodeZipkinSpans decodes the JSON or protobuf encoded list of Zipkin spans of the request.
+func decodeZipkinSpans(req *http.Request) ([]*zipkinSpan, error) {
+	switch getMediaType(req) {
+	case "application/x-protobuf", "application/protobuf":
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if _, err := copyRequestBody(buf, req); err != nil {
+			return nil, err
+		}
+		return decodeZipkinProto(buf.Bytes())
+	default:
+		var spans []*zipkinSpan
+		if err := json.NewDecoder(req.Body).Decode(&spans); err != nil {
+			return nil, err
+		}
+		return spans, nil
+	}
+}
+
+// zipkinTraceChunks converts the Zipkin spans to Datadog trace chunks.
+func zipkinTraceChunks(spans []*zipkinSpan) []*pb.TraceChunk {
+	converted := make([]*pb.Span, 0, len(spans))
+	priorities := make(map[uint64]sampler.SamplingPriority)
+	for _, s := range spans {
+		span := convertZipkinSpan(s)
+		if s.Debug {
+			priorities[span.TraceID] = sampler.PriorityUserKeep
+		}
+		converted = append(converted, span)
+	}
+	return traceChunksFromSampledSpans(converted, priorities)
+}
+
+// convertZipkinSpan converts the Zipkin span in to a Datadog span.
+func convertZipkinSpan(in *zipkinSpan) *pb.Span {
+	span := &pb.Span{
+		TraceID:  in.TraceID.Low,
+		SpanID:   uint64(in.ID),
+		ParentID: uint64(in.ParentID),
+		Start:    int64(in.Timestamp) * 1000,
+		Duration: int64(in.Duration) * 1000,
+		Meta:     make(map[string]string, len(in.Tags)+2),
+		Metrics:  map[string]float64{},
+	}
+	if in.LocalEndpoint != nil {
+		span.Service = in.LocalEndpoint.ServiceName
+	}
+	for k, v := range in.Tags {
+		if k == "error" {
+			// Zipkin holds the error message in the error tag
+			span.Error = 1
+			if v != "" && v != "true" {
+				span.Meta["error.msg"] = v
+			}
+			continue
+		}
+		setMetaOTLP(span, k, v)
+	}
+	if e := in.RemoteEndpoint; e != nil {
+		if e.ServiceName != "" {
+			setMetaOTLP(span, "peer.service", e.ServiceName)
+		}
+		if host := e.IPv4; host != "" {
+			setMetaOTLP(span, "out.host", host)
+		} else if host := e.IPv6; host != "" {
+			setMetaOTLP(span, "out.host", host)
+		}
+		if e.Port != 0 {
+			setMetaOTLP(span, "out.port", strconv.Itoa(int(e.Port)))
+		}
+	}
+	if len(in.Annotations) > 0 {
+		events := make([]spanEvent, 0, len(in.Annotations))
+		for _, a := range in.Annotations {
+			events = append(events, spanEvent{TimeUnixNano: a.Timestamp * 1000, Name: a.Value})
+		}
+		setMetaOTLP(span, "events", marshalSpanEvents(events))
+	}
+	kind := spanKindFromName(in.Kind)
+	setTraceIDHigh(span, in.TraceID.High)
+	completeSpan(span, "zipkin", kind, in.Name)
+	return span
+}
+
+// spanEvent is an event of a span, as set in the events tag.
+type spanEvent struct {
+	TimeUnixNano uint64            `json:"time_unix_nano,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// marshalSpanEvents marshals events into JSON.
+func marshalSpanEvents(events []spanEvent) string {
+	b, err := json.Marshal(events)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// spanKindFromName returns the span kind named name, as used by Zipkin and Jaeger.
+func spanKindFromName(name string) ptrace.SpanKind {
+	switch strings.ToLower(name) {
+	case "server":
+		return ptrace.SpanKindServer
+	case "client":
+		return ptrace.SpanKindClient
+	case "producer":
+		return ptrace.SpanKindProducer
+	case "consumer":
+		return ptrace.SpanKindConsumer
+	default:
+		return ptrace.SpanKindInternal
+	}
+}
+
+// setTraceIDHigh sets the upper 64 bits of a 128-bit trace ID on span.
+func setTraceIDHigh(span *pb.Span, high uint64) {
+	if high != 0 {
+		span.Meta["_dd.p.tid"] = fmt.Sprintf("%016x", high)
+	}
+}
+
+// completeSpan sets the span kind, and the name, service, resource and type of span when they
+// were not set by its tags. The name defaults to the span kind prefixed with intake, and the
+// resource to the operation name of the span.
+func completeSpan(span *pb.Span, intake string, kind ptrace.SpanKind, operationName string) {
+	setMetaOTLP(span, "span.kind", traceutil.OTelSpanKindName(kind))
+	if span.Name == "" {
+		span.Name = intake + "." + traceutil.OTelSpanKindName(kind)
+	}
+	if span.Service == "" {
+		span.Service = intake + "-unknown-service"
+	}
+	if span.Resource == "" {
+		if r := resourceFromTags(span.Meta); r != "" {
+			span.Resource = r
+		} else if operationName != "" {
+			span.Resource = operationName
+		} else {
+			span.Resource = span.Name
+		}
+	}
+	if span.Type == "" {
+		span.Type = spanKind2Type(kind, span)
+	}
+}
+
+// traceChunksFromSampledSpans groups the spans in trace chunks. The spans are assumed to have been
+// kept by the sampler of the client, the chunks are given the priority found in priorities for their
+// trace ID, or in the sampling priority of their spans, and otherwise PriorityAutoKeep.
+func traceChunksFromSampledSpans(spans []*pb.Span, priorities map[uint64]sampler.SamplingPriority) []*pb.TraceChunk {
+	var traceChunks []*pb.TraceChunk
+	byID := make(map[uint64]*pb.TraceChunk)
+	for _, s := range spans {
+		chunk, ok := byID[s.TraceID]
+		if !ok {
+			chunk = &pb.TraceChunk{
+				Priority: int32(sampler.PriorityAutoKeep),
+				Tags:     make(map[string]string),
+			}
+			if p, ok := priorities[s.TraceID]; ok {
+				chunk.Priority = int32(p)
+			}
+			byID[s.TraceID] = chunk
+			traceChunks = append(traceChunks, chunk)
+		}
+		if p, ok := s.Metrics["_sampling_priority_v1"]; ok {
+			chunk.Priority = int32(p)
+		}
+		chunk.Spans = append(chunk.Spans, s)
+	}
+	return traceChunks
+}
+
+// decodeZipkinProto decodes a protobuf encoded list of Zipkin spans.
+// See https://github.com/openzipkin/zipkin-api/blob/master/zipkin.proto.
+func decodeZipkinProto(b []byte) ([]*zipkinSpan, error) {
+	var spans []*zipkinSpan
+	err := consumeProtoFields(b, func(num protowire.Number, _ uint64, v []byte) error {
+		if num != 1 {
+			return nil
+		}
+		span, err := decodeZipkinProtoSpan(v)
+		if err != nil {
+			return err
+		}
+		spans = append(spans, span)
+		return nil
+	})
+	return spans, err
+}
+
+func decodeZipkinProtoSpan(b []byte) (*zipkinSpan, error) {
+	span := &zipkinSpan{}
+	err := consumeProtoFields(b, func(num protowire.Number, u uint64, v []byte) error {
+		var err error
+		switch num {
+		case 1:
+			switch len(v) {
+			case 16:
+				span.TraceID.High = binary.BigEndian.Uint64(v[:8])
+				span.TraceID.Low = binary.BigEndian.Uint64(v[8:])
+			case 8:
+				span.TraceID.Low = binary.BigEndian.Uint64(v)
+			default:
+				return fmt.Errorf("invalid trace ID of %d bytes", len(v))
+			}
+		case 2:
+			var id uint64
+			id, err = zipkinProtoSpanID(v)
+			span.ParentID = zipkinSpanID(id)
+		case 3:
+			var id uint64
+			id, err = zipkinProtoSpanID(v)
+			span.ID = zipkinSpanID(id)
+		case 4:
+			span.Kind = zipkinProtoKinds[u]
+		case 5:
+			span.Name = string(v)
+		case 6:
+			span.Timestamp = u
+		case 7:
+			span.Duration = u
+		case 8:
+			span.LocalEndpoint, err = decodeZipkinProtoEndpoint(v)
+		case 9:
+			span.RemoteEndpoint, err = decodeZipkinProtoEndpoint(v)
+		case 10:
+			var a zipkinAnnotation
+			err = consumeProtoFields(v, func(num protowire.Number, u uint64, v []byte) error {
+				switch num {
+				case 1:
+					a.Timestamp = u
+				case 2:
+					a.Value = string(v)
+				}
+				return nil
+			})
+			span.Annotations = append(span.Annotations, a)
+		case 11:
+			var key, value string
+			err = consumeProtoFields(v, func(num protowire.Number, _ uint64, v []byte) error {
+				switch num {
+				case 1:
+					key = string(v)
+				case 2:
+					value = string(v)
+				}
+				return nil
+			})
+			if span.Tags == nil {
+				span.Tags = make(map[string]string)
+			}
+			span.Tags[key] = value
+		case 12:
+			span.Debug = u != 0
+		}
+		return err
+	})
+	return span, err
+}
+
+// zipkinProtoKinds holds the names of the span kinds by their value in the protobuf encoding.
+var zipkinProtoKinds = map[uint64]string{
+	1: "CLIENT",
+	2: "SERVER",
+	3: "PRODUCER",
+	4: "CONSUMER",
+}
+
+func zipkinProtoSpanID(b []byte) (uint64, error) {
+	if len(b) != 8 {
+		return 0, fmt.Errorf("invalid span ID of %d bytes", len(b))
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+func decodeZipkinProtoEndpoint(b []byte) (*zipkinEndpoint, error) {
+	e := &zipkinEndpoint{}
+	err := consumeProtoFields(b, func(num protowire.Number, u uint64, v []byte) error {
+		switch num {
+		case 1:
+			e.ServiceName = string(v)
+		case 2:
+			e.IPv4 = net.IP(v).String()
+		case 3:
+			e.IPv6 = net.IP(v).String()
+		case 4:
+			e.Port = int32(u)
+		}
+		return nil
+	})
+	return e, err
+}
+
+// consumeProtoFields calls f with each field of the protobuf encoded message b. The value of the
+// field is passed in u for the varint and fixed types, and in v for the length-delimited type.
+func consumeProtoFields(b []byte, f func(num protowire.Number, u uint64, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		var u uint64
+		var v []byte
+		switch typ {
+		case protowire.VarintType:
+			u, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			u, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var u32 uint32
+			u32, n = protowire.ConsumeFixed32(b)
+			u = uint64(u32)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := f(num, u, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
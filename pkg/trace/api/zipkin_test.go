@@ -0,0 +1,248 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+)
+
+const zipkinTestPayload = `[
+  {
+    "traceId": "463ac35c9f6413ad48485a3953bb6124",
+    "id": "a2fb4a1d1a96d312",
+    "kind": "SERVER",
+    "name": "get /users",
+    "timestamp": 1700000000000000,
+    "duration": 1500,
+    "localEndpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1"},
+    "tags": {"http.method": "GET", "http.route": "/users", "env": "prod"}
+  },
+  {
+    "traceId": "463ac35c9f6413ad48485a3953bb6124",
+    "parentId": "a2fb4a1d1a96d312",
+    "id": "0020000000000001",
+    "kind": "CLIENT",
+    "name": "select",
+    "timestamp": 1700000000000100,
+    "duration": 800,
+    "localEndpoint": {"serviceName": "frontend"},
+    "remoteEndpoint": {"serviceName": "postgres", "ipv4": "10.0.0.2", "port": 5432},
+    "annotations": [{"timestamp": 1700000000000200, "value": "retry"}],
+    "tags": {"db.system": "postgresql", "error": "connection reset"}
+  },
+  {
+    "traceId": "0000000000000002",
+    "id": "0000000000000003",
+    "name": "compute",
+    "timestamp": 1700000000000000,
+    "duration": 10,
+    "debug": true
+  }
+]`
+
+func TestZipkinIntake(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.ZipkinReceiverEnabled = true
+	rcv := newTestReceiverFromConfig(conf)
+	server := httptest.NewServer(rcv.buildMux())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v2/spans", "application/json", strings.NewReader(zipkinTestPayload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var p *Payload
+	select {
+	case p = <-rcv.out:
+	case <-time.After(time.Second):
+		t.Fatal("no payload received")
+	}
+	require.Len(t, p.TracerPayload.Chunks, 2)
+	assert.Equal(t, "zipkin_v2", p.Source.EndpointVersion)
+
+	chunk := p.TracerPayload.Chunks[0]
+	assert.EqualValues(t, sampler.PriorityAutoKeep, chunk.Priority)
+	require.Len(t, chunk.Spans, 2)
+
+	server1 := chunk.Spans[0]
+	assert.EqualValues(t, 0x48485a3953bb6124, server1.TraceID)
+	assert.Equal(t, uint64(0xa2fb4a1d1a96d312), server1.SpanID)
+	assert.EqualValues(t, 0, server1.ParentID)
+	assert.EqualValues(t, 1700000000000000000, server1.Start)
+	assert.EqualValues(t, 1500000, server1.Duration)
+	assert.Equal(t, "frontend", server1.Service)
+	assert.Equal(t, "zipkin.server", server1.Name)
+	assert.Equal(t, "GET /users", server1.Resource)
+	assert.Equal(t, "web", server1.Type)
+	assert.Equal(t, "463ac35c9f6413ad", server1.Meta["_dd.p.tid"])
+	assert.Equal(t, "server", server1.Meta["span.kind"])
+	assert.Equal(t, "prod", server1.Meta["env"])
+
+	client := chunk.Spans[1]
+	assert.Equal(t, uint64(0xa2fb4a1d1a96d312), client.ParentID)
+	assert.Equal(t, "zipkin.client", client.Name)
+	assert.Equal(t, "select", client.Resource)
+	assert.Equal(t, "db", client.Type)
+	assert.EqualValues(t, 1, client.Error)
+	assert.Equal(t, "connection reset", client.Meta["error.msg"])
+	assert.NotContains(t, client.Meta, "error")
+	assert.Equal(t, "postgres", client.Meta["peer.service"])
+	assert.Equal(t, "10.0.0.2", client.Meta["out.host"])
+	assert.Equal(t, "5432", client.Meta["out.port"])
+	assert.Equal(t, `[{"time_unix_nano":1700000000000200000,"name":"retry"}]`, client.Meta["events"])
+
+	debug := p.TracerPayload.Chunks[1]
+	assert.EqualValues(t, sampler.PriorityUserKeep, debug.Priority)
+	assert.Equal(t, "zipkin-unknown-service", debug.Spans[0].Service)
+	assert.Equal(t, "zipkin.internal", debug.Spans[0].Name)
+	assert.Equal(t, "compute", debug.Spans[0].Resource)
+	assert.Equal(t, "custom", debug.Spans[0].Type)
+}
+
+func TestZipkinIntakeDisabled(t *testing.T) {
+	rcv := newTestReceiverFromConfig(newTestReceiverConfig())
+	server := httptest.NewServer(rcv.buildMux())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v2/spans", "application/json", strings.NewReader(zipkinTestPayload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestZipkinIntakeInvalid(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.ZipkinReceiverEnabled = true
+	rcv := newTestReceiverFromConfig(conf)
+	server := httptest.NewServer(rcv.buildMux())
+	defer server.Close()
+
+	for _, payload := range []string{
+		`[{"traceId": "not hex", "id": "1"}]`,
+		`[{"traceId": "463ac35c9f6413ad48485a3953bb61240", "id": "1"}]`,
+		`{"traceId": "1"}`,
+	} {
+		resp, err := http.Post(server.URL+"/api/v2/spans", "application/json", strings.NewReader(payload))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, payload)
+	}
+	assert.Empty(t, rcv.out)
+}
+
+func TestDecodeZipkinProto(t *testing.T) {
+	id := func(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+	message := func(fields ...func([]byte) []byte) []byte {
+		var b []byte
+		for _, f := range fields {
+			b = f(b)
+		}
+		return b
+	}
+	bytesField := func(num protowire.Number, v []byte) func([]byte) []byte {
+		return func(b []byte) []byte {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			return protowire.AppendBytes(b, v)
+		}
+	}
+	varintField := func(num protowire.Number, v uint64) func([]byte) []byte {
+		return func(b []byte) []byte {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			return protowire.AppendVarint(b, v)
+		}
+	}
+	fixedField := func(num protowire.Number, v uint64) func([]byte) []byte {
+		return func(b []byte) []byte {
+			b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+			return protowire.AppendFixed64(b, v)
+		}
+	}
+
+	span := message(
+		bytesField(1, append(id(0x463ac35c9f6413ad), id(0x48485a3953bb6124)...)),
+		bytesField(2, id(1)),
+		bytesField(3, id(2)),
+		varintField(4, 1),
+		bytesField(5, []byte("select")),
+		fixedField(6, 1700000000000100),
+		varintField(7, 800),
+		bytesField(8, message(bytesField(1, []byte("frontend")))),
+		bytesField(9, message(bytesField(1, []byte("postgres")), bytesField(2, []byte{10, 0, 0, 2}), varintField(4, 5432))),
+		bytesField(10, message(fixedField(1, 1700000000000200), bytesField(2, []byte("retry")))),
+		bytesField(11, message(bytesField(1, []byte("db.system")), bytesField(2, []byte("postgresql")))),
+		varintField(12, 1),
+		varintField(99, 1), // unknown fields are ignored
+	)
+	spans, err := decodeZipkinProto(message(bytesField(1, span)))
+	require.NoError(t, err)
+	assert.Equal(t, []*zipkinSpan{{
+		TraceID:        zipkinTraceID{High: 0x463ac35c9f6413ad, Low: 0x48485a3953bb6124},
+		ParentID:       1,
+		ID:             2,
+		Kind:           "CLIENT",
+		Name:           "select",
+		Timestamp:      1700000000000100,
+		Duration:       800,
+		Debug:          true,
+		LocalEndpoint:  &zipkinEndpoint{ServiceName: "frontend"},
+		RemoteEndpoint: &zipkinEndpoint{ServiceName: "postgres", IPv4: "10.0.0.2", Port: 5432},
+		Annotations:    []zipkinAnnotation{{Timestamp: 1700000000000200, Value: "retry"}},
+		Tags:           map[string]string{"db.system": "postgresql"},
+	}}, spans)
+
+	t.Run("intake", func(t *testing.T) {
+		conf := newTestReceiverConfig()
+		conf.ZipkinReceiverEnabled = true
+		rcv := newTestReceiverFromConfig(conf)
+		server := httptest.NewServer(rcv.buildMux())
+		defer server.Close()
+
+		resp, err := http.Post(server.URL+"/api/v2/spans", "application/x-protobuf", bytes.NewReader(message(bytesField(1, span))))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		p := <-rcv.out
+		require.Len(t, p.TracerPayload.Chunks, 1)
+		assert.EqualValues(t, sampler.PriorityUserKeep, p.TracerPayload.Chunks[0].Priority)
+		assert.Equal(t, "postgres", p.TracerPayload.Chunks[0].Spans[0].Meta["peer.service"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := decodeZipkinProto(message(bytesField(1, message(bytesField(1, []byte{1, 2, 3})))))
+		assert.EqualError(t, err, "invalid trace ID of 3 bytes")
+		_, err = decodeZipkinProto([]byte{0x0a, 0x10, 0x01})
+		assert.Error(t, err)
+	})
+}
+
+func TestTraceChunksFromSampledSpans(t *testing.T) {
+	spans := []*pb.Span{
+		{TraceID: 1, SpanID: 1},
+		{TraceID: 2, SpanID: 2},
+		{TraceID: 1, SpanID: 3, Metrics: map[string]float64{"_sampling_priority_v1": -1}},
+		{TraceID: 3, SpanID: 4},
+	}
+	chunks := traceChunksFromSampledSpans(spans, map[uint64]sampler.SamplingPriority{3: sampler.PriorityUserKeep})
+	require.Len(t, chunks, 3)
+	assert.EqualValues(t, sampler.PriorityUserDrop, chunks[0].Priority)
+	assert.Equal(t, []*pb.Span{spans[0], spans[2]}, chunks[0].Spans)
+	assert.EqualValues(t, sampler.PriorityAutoKeep, chunks[1].Priority)
+	assert.EqualValues(t, sampler.PriorityUserKeep, chunks[2].Priority)
+}
@@ -113,6 +113,10 @@ type ObfuscationConfig struct {
 	// for spans of type "memcached".
 	Memcached obfuscate.MemcachedConfig `mapstructure:"memcached"`
 
+	// GraphQL holds the configuration for obfuscating the resource and the "graphql.query"
+	// and "graphql.source" tags for spans of type "graphql".
+	GraphQL obfuscate.GraphQLConfig `mapstructure:"graphql"`
+
 	// CreditCards holds the configuration for obfuscating credit cards.
 	CreditCards obfuscate.CreditCardsConfig `mapstructure:"credit_cards"`
 }
@@ -135,6 +139,7 @@ func (o *ObfuscationConfig) Export(conf *AgentConfig) obfuscate.Config {
 		HTTP:                 o.HTTP,
 		Redis:                o.Redis,
 		Memcached:            o.Memcached,
+		GraphQL:              o.GraphQL,
 		CreditCard:           o.CreditCards,
 		Logger:               new(debugLogger),
 	}
@@ -175,6 +180,87 @@ type ReplaceRule struct {
 	Repl string `mapstructure:"repl"`
 }
 
+const (
+	// SpanRuleDrop removes the matching spans from their trace, re-parenting their children.
+	SpanRuleDrop = "drop"
+	// SpanRuleSetTag sets the tag Key to Value on the matching spans.
+	SpanRuleSetTag = "set_tag"
+	// SpanRuleRenameTag renames the tag Key to Target on the matching spans.
+	SpanRuleRenameTag = "rename_tag"
+	// SpanRuleDeleteTag removes the tag Key from the matching spans.
+	SpanRuleDeleteTag = "delete_tag"
+	// SpanRuleCopyTag copies the value of the tag Key to the tag Target on the matching spans.
+	SpanRuleCopyTag = "copy_tag"
+)
+
+// SpanRule specifies a rule applied to the spans before they are sampled and their stats computed.
+type SpanRule struct {
+	// Service specifies a regexp pattern the service of the span must match. Any service matches if empty.
+	Service string `mapstructure:"service"`
+	// Name specifies a regexp pattern the operation name of the span must match. Any name matches if empty.
+	Name string `mapstructure:"name"`
+	// Tags maps tag keys to regexp patterns their value must match. The span must hold all of them.
+	Tags map[string]string `mapstructure:"tags"`
+
+	// Action specifies what is done to the matching spans: "drop", "set_tag", "rename_tag",
+	// "delete_tag" or "copy_tag".
+	Action string `mapstructure:"action"`
+	// Key specifies the tag the action applies to.
+	Key string `mapstructure:"key"`
+	// Value specifies the value set by the "set_tag" rules.
+	Value string `mapstructure:"value"`
+	// Target specifies the destination tag of the "rename_tag" and "copy_tag" rules.
+	Target string `mapstructure:"target"`
+
+	// ServiceRe, NameRe and TagsRe hold the compiled patterns and are only used internally.
+	ServiceRe *regexp.Regexp            `mapstructure:"-"`
+	NameRe    *regexp.Regexp            `mapstructure:"-"`
+	TagsRe    map[string]*regexp.Regexp `mapstructure:"-"`
+}
+
+// The types of the tail sampling policies.
+const (
+	// TailSamplingPolicyError keeps the traces with at least one span in error.
+	TailSamplingPolicyError = "error"
+	// TailSamplingPolicyLatency keeps the traces whose root span lasts at least the policy threshold.
+	TailSamplingPolicyLatency = "latency"
+	// TailSamplingPolicyTag keeps the traces with at least one span holding the policy tag.
+	TailSamplingPolicyTag = "tag"
+	// TailSamplingPolicyRateLimit keeps at most the policy number of traces per second.
+	TailSamplingPolicyRateLimit = "rate_limit"
+)
+
+// TailSamplingConfig specifies the configuration of the tail-based sampling.
+type TailSamplingConfig struct {
+	// Enabled specifies whether the trace chunks are buffered by trace ID and sampled once the
+	// whole trace is assembled, instead of being sampled as they are received.
+	Enabled bool
+	// DecisionWait specifies how long the chunks of a trace are buffered, from the first one
+	// received, before deciding whether to keep the trace.
+	DecisionWait time.Duration
+	// MaxMemoryBytes specifies the maximum size of the buffered chunks. Above it, the decision is
+	// taken early on the oldest traces.
+	MaxMemoryBytes int64
+	// Policies specifies the rules keeping a trace. A trace is kept if any of them matches.
+	Policies []*TailSamplingPolicy
+}
+
+// TailSamplingPolicy specifies a rule keeping the traces assembled by the tail-based sampling.
+type TailSamplingPolicy struct {
+	// Name identifies the policy in the telemetry. It defaults to the type.
+	Name string `mapstructure:"name"`
+	// Type specifies the kind of rule: "error", "latency", "tag" or "rate_limit".
+	Type string `mapstructure:"type"`
+	// Threshold specifies the minimum duration of the root span for the "latency" policies.
+	Threshold time.Duration `mapstructure:"threshold"`
+	// Key specifies the tag looked for by the "tag" policies.
+	Key string `mapstructure:"key"`
+	// Value specifies the value of the tag looked for by the "tag" policies. Any value matches if empty.
+	Value string `mapstructure:"value"`
+	// TPS specifies the maximum number of traces per second kept by the "rate_limit" policies.
+	TPS float64 `mapstructure:"tps"`
+}
+
 // WriterConfig specifies configuration for an API writer.
 type WriterConfig struct {
 	// ConnectionLimit specifies the maximum number of concurrent outgoing
@@ -291,6 +377,12 @@ type AgentConfig struct {
 	ComputeStatsBySpanKind bool          // enables/disables the computing of stats based on a span's `span.kind` field
 	PeerTags               []string      // additional tags to use for peer entity stats aggregation
 
+	// StatsCustomTags are span tags used as additional stats aggregation dimensions by the Concentrator.
+	StatsCustomTags []string
+	// StatsCustomTagsCardinalityLimit is the maximum number of distinct values of each of the StatsCustomTags
+	// in a stats bucket. The values past the limit are aggregated together. It is unlimited if not positive.
+	StatsCustomTagsCardinalityLimit int
+
 	// Sampler configuration
 	ExtraSampleRate float64
 	TargetTPS       float64
@@ -309,6 +401,9 @@ type AgentConfig struct {
 	ProbabilisticSamplerHashSeed           uint32
 	ProbabilisticSamplerSamplingPercentage float32
 
+	// TailSampling holds the configuration of the tail-based sampling.
+	TailSampling TailSamplingConfig
+
 	// Receiver
 	ReceiverEnabled bool // specifies whether Receiver listeners are enabled. Unless OTLPReceiver is used, this should always be true.
 	ReceiverHost    string
@@ -368,6 +463,9 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// SpanRules drop individual spans and rewrite their tags, applied in order before sampling and stats.
+	SpanRules []*SpanRule
+
 	// GlobalTags list metadata that will be added to all spans
 	GlobalTags map[string]string
 
@@ -399,6 +497,15 @@ type AgentConfig struct {
 	// OTLPReceiver holds the configuration for OpenTelemetry receiver.
 	OTLPReceiver *OTLP
 
+	// ZipkinReceiverEnabled enables the intake of the Zipkin v2 spans on /api/v2/spans.
+	ZipkinReceiverEnabled bool
+
+	// JaegerReceiverEnabled enables the intake of the Jaeger Thrift spans on /api/traces.
+	JaegerReceiverEnabled bool
+
+	// JaegerGRPCPort specifies the port of the Jaeger gRPC intake. It is disabled when 0.
+	JaegerGRPCPort int
+
 	// ProfilingProxy specifies settings for the profiling proxy.
 	ProfilingProxy ProfilingProxyConfig
 
@@ -474,7 +581,8 @@ func New() *AgentConfig {
 		Site:                "datadoghq.com",
 		MaxCatalogEntries:   5000,
 
-		BucketInterval: time.Duration(10) * time.Second,
+		BucketInterval:                  time.Duration(10) * time.Second,
+		StatsCustomTagsCardinalityLimit: 100,
 
 		ExtraSampleRate: 1.0,
 		TargetTPS:       10,
@@ -487,6 +595,11 @@ func New() *AgentConfig {
 		RareSamplerCooldownPeriod: 5 * time.Minute,
 		RareSamplerCardinality:    200,
 
+		TailSampling: TailSamplingConfig{
+			DecisionWait:   10 * time.Second,
+			MaxMemoryBytes: 64 * 1024 * 1024, // 64MB
+		},
+
 		ReceiverEnabled:        true,
 		ReceiverHost:           "localhost",
 		ReceiverPort:           8126,
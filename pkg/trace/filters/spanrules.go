@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package filters
+
+import (
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+// SpanRules is a filter which drops individual spans and rewrites their tags
+// based on its rules.
+type SpanRules struct {
+	rules []*config.SpanRule
+}
+
+// NewSpanRules returns a new SpanRules which will use the given set of compiled rules.
+func NewSpanRules(rules []*config.SpanRule) *SpanRules {
+	return &SpanRules{rules: rules}
+}
+
+// Apply applies the rules, in order, to each span of the trace and returns the spans that
+// were kept. The children of a dropped span are re-parented onto its closest kept ancestor.
+func (f *SpanRules) Apply(trace pb.Trace) pb.Trace {
+	if f == nil || len(f.rules) == 0 {
+		return trace
+	}
+	var dropped map[uint64]uint64 // maps the IDs of the dropped spans to their parent ID
+	for _, s := range trace {
+		if f.apply(s) {
+			continue
+		}
+		if dropped == nil {
+			dropped = make(map[uint64]uint64)
+		}
+		dropped[s.SpanID] = s.ParentID
+	}
+	if len(dropped) == 0 {
+		return trace
+	}
+	kept := trace[:0]
+	for _, s := range trace {
+		if _, ok := dropped[s.SpanID]; ok {
+			continue
+		}
+		// bound the walk to the number of dropped spans in case of a cycle
+		for i := 0; i < len(dropped); i++ {
+			parentID, ok := dropped[s.ParentID]
+			if !ok {
+				break
+			}
+			s.ParentID = parentID
+		}
+		kept = append(kept, s)
+	}
+	for i := len(kept); i < len(trace); i++ {
+		trace[i] = nil
+	}
+	return kept
+}
+
+// apply applies the rules to s. It returns false if s must be dropped.
+func (f *SpanRules) apply(s *pb.Span) bool {
+	for _, r := range f.rules {
+		if !spanRuleMatches(r, s) {
+			continue
+		}
+		switch r.Action {
+		case config.SpanRuleDrop:
+			return false
+		case config.SpanRuleSetTag:
+			if s.Meta == nil {
+				s.Meta = make(map[string]string)
+			}
+			s.Meta[r.Key] = r.Value
+		case config.SpanRuleDeleteTag:
+			delete(s.Meta, r.Key)
+			delete(s.Metrics, r.Key)
+		case config.SpanRuleRenameTag:
+			if v, ok := s.Meta[r.Key]; ok {
+				delete(s.Meta, r.Key)
+				s.Meta[r.Target] = v
+			}
+			if v, ok := s.Metrics[r.Key]; ok {
+				delete(s.Metrics, r.Key)
+				s.Metrics[r.Target] = v
+			}
+		case config.SpanRuleCopyTag:
+			if v, ok := s.Meta[r.Key]; ok {
+				s.Meta[r.Target] = v
+			}
+		}
+	}
+	return true
+}
+
+// spanRuleMatches reports whether s meets all the conditions of r.
+func spanRuleMatches(r *config.SpanRule, s *pb.Span) bool {
+	if r.ServiceRe != nil && !r.ServiceRe.MatchString(s.Service) {
+		return false
+	}
+	if r.NameRe != nil && !r.NameRe.MatchString(s.Name) {
+		return false
+	}
+	for k, re := range r.TagsRe {
+		v, ok := s.Meta[k]
+		if !ok || !re.MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package filters
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+func TestSpanRules(t *testing.T) {
+	newSpan := func() *pb.Span {
+		return &pb.Span{
+			SpanID:  1,
+			Service: "checkout",
+			Name:    "http.request",
+			Meta:    map[string]string{"http.route": "/cart", "customer.id": "42", "tier": "gold"},
+			Metrics: map[string]float64{"retries": 2},
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		rule        *config.SpanRule
+		wantMeta    map[string]string
+		wantMetrics map[string]float64
+	}{
+		{
+			name:        "set_tag",
+			rule:        &config.SpanRule{Action: config.SpanRuleSetTag, Key: "team", Value: "payments"},
+			wantMeta:    map[string]string{"http.route": "/cart", "customer.id": "42", "tier": "gold", "team": "payments"},
+			wantMetrics: map[string]float64{"retries": 2},
+		},
+		{
+			name:        "delete_tag",
+			rule:        &config.SpanRule{Action: config.SpanRuleDeleteTag, Key: "customer.id"},
+			wantMeta:    map[string]string{"http.route": "/cart", "tier": "gold"},
+			wantMetrics: map[string]float64{"retries": 2},
+		},
+		{
+			name:        "delete_tag/metric",
+			rule:        &config.SpanRule{Action: config.SpanRuleDeleteTag, Key: "retries"},
+			wantMeta:    map[string]string{"http.route": "/cart", "customer.id": "42", "tier": "gold"},
+			wantMetrics: map[string]float64{},
+		},
+		{
+			name:        "rename_tag",
+			rule:        &config.SpanRule{Action: config.SpanRuleRenameTag, Key: "tier", Target: "customer.tier"},
+			wantMeta:    map[string]string{"http.route": "/cart", "customer.id": "42", "customer.tier": "gold"},
+			wantMetrics: map[string]float64{"retries": 2},
+		},
+		{
+			name:        "rename_tag/metric",
+			rule:        &config.SpanRule{Action: config.SpanRuleRenameTag, Key: "retries", Target: "http.retries"},
+			wantMeta:    map[string]string{"http.route": "/cart", "customer.id": "42", "tier": "gold"},
+			wantMetrics: map[string]float64{"http.retries": 2},
+		},
+		{
+			name:        "copy_tag",
+			rule:        &config.SpanRule{Action: config.SpanRuleCopyTag, Key: "customer.id", Target: "usr.id"},
+			wantMeta:    map[string]string{"http.route": "/cart", "customer.id": "42", "tier": "gold", "usr.id": "42"},
+			wantMetrics: map[string]float64{"retries": 2},
+		},
+		{
+			name:        "copy_tag/missing",
+			rule:        &config.SpanRule{Action: config.SpanRuleCopyTag, Key: "missing", Target: "usr.id"},
+			wantMeta:    map[string]string{"http.route": "/cart", "customer.id": "42", "tier": "gold"},
+			wantMetrics: map[string]float64{"retries": 2},
+		},
+		{
+			name: "conditions",
+			rule: &config.SpanRule{
+				ServiceRe: regexp.MustCompile("^checkout$"),
+				NameRe:    regexp.MustCompile("^http"),
+				TagsRe:    map[string]*regexp.Regexp{"tier": regexp.MustCompile("gold|platinum")},
+				Action:    config.SpanRuleDeleteTag,
+				Key:       "customer.id",
+			},
+			wantMeta:    map[string]string{"http.route": "/cart", "tier": "gold"},
+			wantMetrics: map[string]float64{"retries": 2},
+		},
+		{
+			name: "conditions/service",
+			rule: &config.SpanRule{
+				ServiceRe: regexp.MustCompile("^web$"),
+				Action:    config.SpanRuleDeleteTag,
+				Key:       "customer.id",
+			},
+			wantMeta:    map[string]string{"http.route": "/cart", "customer.id": "42", "tier": "gold"},
+			wantMetrics: map[string]float64{"retries": 2},
+		},
+		{
+			name: "conditions/missing-tag",
+			rule: &config.SpanRule{
+				TagsRe: map[string]*regexp.Regexp{"db.system": regexp.MustCompile(".*")},
+				Action: config.SpanRuleDeleteTag,
+				Key:    "customer.id",
+			},
+			wantMeta:    map[string]string{"http.route": "/cart", "customer.id": "42", "tier": "gold"},
+			wantMetrics: map[string]float64{"retries": 2},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSpan()
+			trace := NewSpanRules([]*config.SpanRule{tt.rule}).Apply(pb.Trace{s})
+			assert.Equal(t, pb.Trace{s}, trace)
+			assert.Equal(t, tt.wantMeta, s.Meta)
+			assert.Equal(t, tt.wantMetrics, s.Metrics)
+		})
+	}
+}
+
+func TestSpanRulesDrop(t *testing.T) {
+	rules := NewSpanRules([]*config.SpanRule{
+		{Action: config.SpanRuleDrop, NameRe: regexp.MustCompile("^internal$")},
+		// not applied to the dropped spans
+		{Action: config.SpanRuleSetTag, Key: "kept", Value: "true"},
+	})
+
+	t.Run("reparent", func(t *testing.T) {
+		// 1 <- 2 (dropped) <- 3 (dropped) <- 4, and 2 <- 5
+		trace := pb.Trace{
+			{SpanID: 1, Name: "http.request"},
+			{SpanID: 2, ParentID: 1, Name: "internal"},
+			{SpanID: 3, ParentID: 2, Name: "internal"},
+			{SpanID: 4, ParentID: 3, Name: "db.query"},
+			{SpanID: 5, ParentID: 2, Name: "cache.get"},
+		}
+		dropped := trace[2]
+		kept := rules.Apply(trace)
+		assert.Len(t, kept, 3)
+		parents := make(map[uint64]uint64)
+		for _, s := range kept {
+			parents[s.SpanID] = s.ParentID
+			assert.Equal(t, "true", s.Meta["kept"])
+		}
+		assert.Equal(t, map[uint64]uint64{1: 0, 4: 1, 5: 1}, parents)
+		assert.Nil(t, dropped.Meta)
+	})
+
+	t.Run("root", func(t *testing.T) {
+		trace := pb.Trace{
+			{SpanID: 1, Name: "internal"},
+			{SpanID: 2, ParentID: 1, Name: "db.query"},
+		}
+		kept := rules.Apply(trace)
+		assert.Len(t, kept, 1)
+		assert.EqualValues(t, 2, kept[0].SpanID)
+		assert.EqualValues(t, 0, kept[0].ParentID)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		assert.Empty(t, rules.Apply(pb.Trace{{SpanID: 1, Name: "internal"}}))
+	})
+
+	t.Run("none", func(t *testing.T) {
+		trace := pb.Trace{{SpanID: 1, Name: "http.request"}}
+		assert.Equal(t, trace, NewSpanRules(nil).Apply(trace))
+	})
+}
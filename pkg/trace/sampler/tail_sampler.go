@@ -0,0 +1,363 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package sampler
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+const (
+	// tailPolicyManualKeep is the policy reported for the traces kept because of a manual keep priority.
+	tailPolicyManualKeep = "manual_keep"
+	// tailSamplerMinTick is the minimum period at which the buffered traces are checked for a decision.
+	tailSamplerMinTick = 100 * time.Millisecond
+	// tailSamplerDecidedTraces is the number of decided traces whose decision is remembered to be
+	// applied to their late chunks.
+	tailSamplerDecidedTraces = 50000
+)
+
+// TailChunk is a trace chunk buffered by the TailSampler.
+type TailChunk struct {
+	// Trace holds the processed chunk.
+	Trace *traceutil.ProcessedTrace
+	// Payload holds the metadata of the payload the chunk was received in, without its chunks.
+	Payload *pb.TracerPayload
+	// Size is the size of the chunk, accounted in the memory budget.
+	Size int
+}
+
+// TailDecision is the sampling decision taken on the chunks of a trace.
+type TailDecision struct {
+	Keep bool
+	// Policy is the name of the policy which kept the trace.
+	Policy string
+	Chunks []*TailChunk
+}
+
+// TailSampler buffers the chunks of the traces by trace ID and decides whether to keep each trace
+// once it is assembled, applying its policies over all the spans of the trace. This allows
+// catching slow or erroneous traces made of chunks flushed by several processes.
+//
+// The decision on a trace is taken once the decision wait has elapsed since its first chunk was
+// received, or earlier when the buffered chunks exceed the memory budget, starting with the
+// oldest traces. The decisions are passed to the onDecision callback, outside of any lock.
+type TailSampler struct {
+	enabled      bool
+	decisionWait time.Duration
+	maxMemory    int64
+	policies     []*tailPolicy
+	onDecision   func(TailDecision)
+
+	mu sync.Mutex
+	// traces holds the buffered traces by trace ID, and order holds them by arrival time.
+	traces map[uint64]*list.Element
+	order  *list.List
+	memory int64
+	// decided holds the last decided traces by trace ID, and decidedOrder holds them from the
+	// least to the most recently used.
+	decided      map[uint64]*list.Element
+	decidedOrder *list.List
+
+	statsd  statsd.ClientInterface
+	dropped *atomic.Int64
+	evicted *atomic.Int64
+	late    *atomic.Int64
+
+	// start/stop synchronization
+	stopOnce sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// tailTrace holds the buffered chunks of a trace.
+type tailTrace struct {
+	traceID   uint64
+	firstSeen time.Time
+	chunks    []*TailChunk
+	size      int64
+}
+
+// tailDecided holds the decision taken on a trace.
+type tailDecided struct {
+	traceID uint64
+	keep    bool
+	policy  string
+}
+
+// tailPolicy is a tail sampling policy, along with its state.
+type tailPolicy struct {
+	*config.TailSamplingPolicy
+	limiter *rate.Limiter
+	kept    *atomic.Int64
+}
+
+// NewTailSampler returns a TailSampler applying the policies of the configuration, and passing
+// its decisions to onDecision.
+func NewTailSampler(conf *config.AgentConfig, statsd statsd.ClientInterface, onDecision func(TailDecision)) *TailSampler {
+	s := &TailSampler{
+		enabled:      conf.TailSampling.Enabled,
+		decisionWait: conf.TailSampling.DecisionWait,
+		maxMemory:    conf.TailSampling.MaxMemoryBytes,
+		onDecision:   onDecision,
+		traces:       make(map[uint64]*list.Element),
+		order:        list.New(),
+		decided:      make(map[uint64]*list.Element),
+		decidedOrder: list.New(),
+		statsd:       statsd,
+		dropped:      atomic.NewInt64(0),
+		evicted:      atomic.NewInt64(0),
+		late:         atomic.NewInt64(0),
+		stop:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	for _, p := range conf.TailSampling.Policies {
+		policy := &tailPolicy{TailSamplingPolicy: p, kept: atomic.NewInt64(0)}
+		if p.Type == config.TailSamplingPolicyRateLimit {
+			burst := int(p.TPS)
+			if burst < 1 {
+				burst = 1
+			}
+			policy.limiter = rate.NewLimiter(rate.Limit(p.TPS), burst)
+		}
+		s.policies = append(s.policies, policy)
+	}
+	return s
+}
+
+// Start starts the routine taking the decisions on the buffered traces.
+func (s *TailSampler) Start() {
+	if !s.enabled {
+		close(s.stopped)
+		return
+	}
+	go func() {
+		defer watchdog.LogOnPanic(s.statsd)
+		tick := s.decisionWait / 10
+		if tick < tailSamplerMinTick {
+			tick = tailSamplerMinTick
+		}
+		decisionTicker := time.NewTicker(tick)
+		defer decisionTicker.Stop()
+		statsTicker := time.NewTicker(10 * time.Second)
+		defer statsTicker.Stop()
+		for {
+			select {
+			case now := <-decisionTicker.C:
+				s.decide(s.expired(now))
+			case <-statsTicker.C:
+				s.report()
+			case <-s.stop:
+				s.decide(s.expired(time.Time{}))
+				s.report()
+				close(s.stopped)
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the TailSampler, taking the decisions on all the buffered traces.
+func (s *TailSampler) Stop() {
+	if !s.enabled {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.stopped
+	})
+}
+
+// Add buffers a chunk with the other chunks of its trace. The chunks received after the decision
+// on their trace was taken are passed to onDecision right away with the same decision.
+func (s *TailSampler) Add(now time.Time, chunk *TailChunk) {
+	if len(chunk.Trace.TraceChunk.Spans) == 0 {
+		return
+	}
+	traceID := chunk.Trace.TraceChunk.Spans[0].TraceID
+
+	s.mu.Lock()
+	if e, ok := s.decided[traceID]; ok {
+		s.decidedOrder.MoveToBack(e)
+		d := e.Value.(*tailDecided)
+		s.mu.Unlock()
+
+		s.late.Inc()
+		s.onDecision(TailDecision{Keep: d.keep, Policy: d.policy, Chunks: []*TailChunk{chunk}})
+		return
+	}
+	var t *tailTrace
+	if e, ok := s.traces[traceID]; ok {
+		t = e.Value.(*tailTrace)
+	} else {
+		t = &tailTrace{traceID: traceID, firstSeen: now}
+		s.traces[traceID] = s.order.PushBack(t)
+	}
+	t.chunks = append(t.chunks, chunk)
+	t.size += int64(chunk.Size)
+	s.memory += int64(chunk.Size)
+
+	// Take the decision early on the oldest traces to stay within the memory budget.
+	var evicted []*tailTrace
+	for s.memory > s.maxMemory && s.order.Len() > 0 {
+		evicted = append(evicted, s.remove(s.order.Front()))
+	}
+	s.mu.Unlock()
+
+	s.evicted.Add(int64(len(evicted)))
+	s.decide(evicted)
+}
+
+// expired removes and returns the traces whose decision wait has elapsed at now, or all the
+// traces if now is zero.
+func (s *TailSampler) expired(now time.Time) []*tailTrace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var expired []*tailTrace
+	for e := s.order.Front(); e != nil; e = s.order.Front() {
+		if !now.IsZero() && now.Sub(e.Value.(*tailTrace).firstSeen) < s.decisionWait {
+			break
+		}
+		expired = append(expired, s.remove(e))
+	}
+	return expired
+}
+
+// remove removes a trace from the buffer. It must be called with the lock held.
+func (s *TailSampler) remove(e *list.Element) *tailTrace {
+	t := s.order.Remove(e).(*tailTrace)
+	delete(s.traces, t.traceID)
+	s.memory -= t.size
+	return t
+}
+
+// decide takes the decisions on the traces and passes them to onDecision. The decisions are
+// remembered so that they apply to the chunks of the traces received afterwards.
+func (s *TailSampler) decide(traces []*tailTrace) {
+	for _, t := range traces {
+		keep, policy := s.sample(t)
+		if !keep {
+			s.dropped.Inc()
+		}
+
+		s.mu.Lock()
+		s.remember(&tailDecided{traceID: t.traceID, keep: keep, policy: policy})
+		// chunks of the trace may have been buffered again while the decision was taken
+		if e, ok := s.traces[t.traceID]; ok {
+			t.chunks = append(t.chunks, s.remove(e).chunks...)
+		}
+		s.mu.Unlock()
+
+		s.onDecision(TailDecision{Keep: keep, Policy: policy, Chunks: t.chunks})
+	}
+}
+
+// remember records the decision taken on a trace, forgetting the least recently used decision
+// beyond tailSamplerDecidedTraces. It must be called with the lock held.
+func (s *TailSampler) remember(d *tailDecided) {
+	if e, ok := s.decided[d.traceID]; ok {
+		e.Value = d
+		s.decidedOrder.MoveToBack(e)
+		return
+	}
+	s.decided[d.traceID] = s.decidedOrder.PushBack(d)
+	for s.decidedOrder.Len() > tailSamplerDecidedTraces {
+		delete(s.decided, s.decidedOrder.Remove(s.decidedOrder.Front()).(*tailDecided).traceID)
+	}
+}
+
+// sample reports whether the trace should be kept, and the name of the policy keeping it.
+func (s *TailSampler) sample(t *tailTrace) (bool, string) {
+	var spans []*pb.Span
+	for _, c := range t.chunks {
+		if priority, ok := GetSamplingPriority(c.Trace.TraceChunk); ok && priority == PriorityUserKeep {
+			return true, tailPolicyManualKeep
+		}
+		spans = append(spans, c.Trace.TraceChunk.Spans...)
+	}
+
+	for _, p := range s.policies {
+		if p.matches(spans) {
+			p.kept.Inc()
+			return true, p.Name
+		}
+	}
+	return false, ""
+}
+
+// matches reports whether the policy keeps the trace made of the spans.
+func (p *tailPolicy) matches(spans []*pb.Span) bool {
+	switch p.Type {
+	case config.TailSamplingPolicyError:
+		return traceContainsError(spans)
+	case config.TailSamplingPolicyLatency:
+		return traceDuration(spans) >= p.Threshold.Nanoseconds()
+	case config.TailSamplingPolicyTag:
+		for _, span := range spans {
+			if v, ok := span.Meta[p.Key]; ok && (p.Value == "" || v == p.Value) {
+				return true
+			}
+		}
+		return false
+	case config.TailSamplingPolicyRateLimit:
+		return p.limiter.Allow()
+	default:
+		return false
+	}
+}
+
+// traceDuration returns the duration of the root span of the trace. If the root span is missing,
+// it returns the time elapsed between the start of the first span and the end of the last one.
+func traceDuration(spans []*pb.Span) int64 {
+	var start, end int64
+	for i, span := range spans {
+		if span.ParentID == 0 {
+			return span.Duration
+		}
+		if i == 0 || span.Start < start {
+			start = span.Start
+		}
+		if spanEnd := span.Start + span.Duration; spanEnd > end {
+			end = spanEnd
+		}
+	}
+	return end - start
+}
+
+func traceContainsError(spans []*pb.Span) bool {
+	for _, span := range spans {
+		if span.Error != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *TailSampler) report() {
+	s.mu.Lock()
+	traces, memory := s.order.Len(), s.memory
+	s.mu.Unlock()
+
+	_ = s.statsd.Gauge("datadog.trace_agent.sampler.tail.buffered_traces", float64(traces), nil, 1)
+	_ = s.statsd.Gauge("datadog.trace_agent.sampler.tail.buffered_bytes", float64(memory), nil, 1)
+	_ = s.statsd.Count("datadog.trace_agent.sampler.tail.dropped", s.dropped.Swap(0), nil, 1)
+	_ = s.statsd.Count("datadog.trace_agent.sampler.tail.evicted", s.evicted.Swap(0), nil, 1)
+	_ = s.statsd.Count("datadog.trace_agent.sampler.tail.late_chunks", s.late.Swap(0), nil, 1)
+	for _, p := range s.policies {
+		_ = s.statsd.Count("datadog.trace_agent.sampler.tail.kept", p.kept.Swap(0), []string{"policy:" + p.Name}, 1)
+	}
+}
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+func newTailTestSampler(policies []*config.TailSamplingPolicy, decisions *[]TailDecision) *TailSampler {
+	conf := &config.AgentConfig{TailSampling: config.TailSamplingConfig{
+		Enabled:        true,
+		DecisionWait:   time.Second,
+		MaxMemoryBytes: 1000,
+		Policies:       policies,
+	}}
+	return NewTailSampler(conf, &statsd.NoOpClient{}, func(d TailDecision) {
+		*decisions = append(*decisions, d)
+	})
+}
+
+func tailChunk(size int, spans ...*pb.Span) *TailChunk {
+	return &TailChunk{
+		Trace: &traceutil.ProcessedTrace{TraceChunk: &pb.TraceChunk{Spans: spans, Priority: int32(PriorityNone)}},
+		Size:  size,
+	}
+}
+
+func TestTailSamplerPolicies(t *testing.T) {
+	policies := []*config.TailSamplingPolicy{
+		{Name: "error", Type: config.TailSamplingPolicyError},
+		{Name: "slow", Type: config.TailSamplingPolicyLatency, Threshold: time.Second},
+		{Name: "tier", Type: config.TailSamplingPolicyTag, Key: "customer.tier", Value: "gold"},
+	}
+	for _, tt := range []struct {
+		name   string
+		spans  []*pb.Span
+		policy string
+	}{
+		{"error in a child span", []*pb.Span{{TraceID: 1, SpanID: 1}, {TraceID: 1, SpanID: 2, ParentID: 1, Error: 1}}, "error"},
+		{"slow root", []*pb.Span{{TraceID: 1, SpanID: 1, Duration: int64(2 * time.Second)}}, "slow"},
+		{"slow without root", []*pb.Span{
+			{TraceID: 1, SpanID: 2, ParentID: 1, Start: 0, Duration: int64(500 * time.Millisecond)},
+			{TraceID: 1, SpanID: 3, ParentID: 1, Start: int64(time.Second), Duration: int64(500 * time.Millisecond)},
+		}, "slow"},
+		{"fast root with a slow gap", []*pb.Span{
+			{TraceID: 1, SpanID: 1, Duration: int64(time.Millisecond)},
+			{TraceID: 1, SpanID: 2, ParentID: 1, Start: int64(2 * time.Second), Duration: int64(time.Millisecond)},
+		}, ""},
+		{"tag", []*pb.Span{{TraceID: 1, SpanID: 1}, {TraceID: 1, SpanID: 2, ParentID: 1, Meta: map[string]string{"customer.tier": "gold"}}}, "tier"},
+		{"other tag value", []*pb.Span{{TraceID: 1, SpanID: 1, Meta: map[string]string{"customer.tier": "silver"}}}, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var decisions []TailDecision
+			s := newTailTestSampler(policies, &decisions)
+			s.Add(time.Now(), tailChunk(10, tt.spans...))
+			s.decide(s.expired(time.Time{}))
+			require.Len(t, decisions, 1)
+			assert.Equal(t, tt.policy != "", decisions[0].Keep)
+			assert.Equal(t, tt.policy, decisions[0].Policy)
+		})
+	}
+}
+
+func TestTailSamplerAssemblesChunks(t *testing.T) {
+	var decisions []TailDecision
+	s := newTailTestSampler([]*config.TailSamplingPolicy{{Name: "error", Type: config.TailSamplingPolicyError}}, &decisions)
+	now := time.Now()
+
+	// the error is in a chunk flushed by another process, after the root chunk
+	s.Add(now, tailChunk(10, &pb.Span{TraceID: 1, SpanID: 1}))
+	s.Add(now, tailChunk(10, &pb.Span{TraceID: 2, SpanID: 4}))
+	s.Add(now.Add(500*time.Millisecond), tailChunk(10, &pb.Span{TraceID: 1, SpanID: 3, ParentID: 2, Error: 1}))
+	s.Add(now.Add(500*time.Millisecond), tailChunk(10, &pb.Span{TraceID: 3, SpanID: 5}))
+	assert.EqualValues(t, 40, s.memory)
+
+	// the decision is only taken once the decision wait has elapsed since the first chunk
+	s.decide(s.expired(now.Add(900 * time.Millisecond)))
+	assert.Empty(t, decisions)
+	s.decide(s.expired(now.Add(time.Second)))
+	require.Len(t, decisions, 2)
+	assert.True(t, decisions[0].Keep)
+	assert.Len(t, decisions[0].Chunks, 2)
+	assert.False(t, decisions[1].Keep)
+	assert.EqualValues(t, 10, s.memory)
+}
+
+func TestTailSamplerMemoryBudget(t *testing.T) {
+	var decisions []TailDecision
+	s := newTailTestSampler(nil, &decisions)
+	now := time.Now()
+
+	for i := uint64(1); i <= 3; i++ {
+		s.Add(now, tailChunk(400, &pb.Span{TraceID: i, SpanID: i}))
+	}
+	// the oldest trace is decided early to stay within the budget
+	require.Len(t, decisions, 1)
+	assert.Equal(t, uint64(1), decisions[0].Chunks[0].Trace.TraceChunk.Spans[0].TraceID)
+	assert.EqualValues(t, 800, s.memory)
+	assert.EqualValues(t, 1, s.evicted.Load())
+}
+
+func TestTailSamplerRateLimitAndManualKeep(t *testing.T) {
+	var decisions []TailDecision
+	s := newTailTestSampler([]*config.TailSamplingPolicy{{Name: "baseline", Type: config.TailSamplingPolicyRateLimit, TPS: 1}}, &decisions)
+	now := time.Now()
+
+	for i := uint64(1); i <= 3; i++ {
+		s.Add(now, tailChunk(10, &pb.Span{TraceID: i, SpanID: i}))
+	}
+	keep := tailChunk(10, &pb.Span{TraceID: 4, SpanID: 4})
+	keep.Trace.TraceChunk.Priority = int32(PriorityUserKeep)
+	s.Add(now, keep)
+	s.decide(s.expired(time.Time{}))
+
+	require.Len(t, decisions, 4)
+	assert.Equal(t, "baseline", decisions[0].Policy)
+	assert.False(t, decisions[1].Keep)
+	assert.False(t, decisions[2].Keep)
+	assert.True(t, decisions[3].Keep)
+	assert.Equal(t, tailPolicyManualKeep, decisions[3].Policy)
+}
+
+func TestTailSamplerLateChunks(t *testing.T) {
+	var decisions []TailDecision
+	s := newTailTestSampler([]*config.TailSamplingPolicy{{Name: "error", Type: config.TailSamplingPolicyError}}, &decisions)
+	now := time.Now()
+
+	s.Add(now, tailChunk(10, &pb.Span{TraceID: 1, SpanID: 1, Error: 1}))
+	s.Add(now, tailChunk(10, &pb.Span{TraceID: 2, SpanID: 2}))
+	s.decide(s.expired(time.Time{}))
+	require.Len(t, decisions, 2)
+
+	// the late chunks get the decision taken on their trace, even though they do not match the policy
+	s.Add(now.Add(2*time.Second), tailChunk(10, &pb.Span{TraceID: 1, SpanID: 3, ParentID: 1}))
+	s.Add(now.Add(2*time.Second), tailChunk(10, &pb.Span{TraceID: 2, SpanID: 4, ParentID: 2, Error: 1}))
+	require.Len(t, decisions, 4)
+	assert.True(t, decisions[2].Keep)
+	assert.Equal(t, "error", decisions[2].Policy)
+	assert.False(t, decisions[3].Keep)
+	assert.Zero(t, s.order.Len())
+	assert.EqualValues(t, 2, s.late.Load())
+}
+
+func TestTailSamplerDecidedTracesBounded(t *testing.T) {
+	var decisions []TailDecision
+	s := newTailTestSampler(nil, &decisions)
+	for i := uint64(1); i <= tailSamplerDecidedTraces+1; i++ {
+		s.remember(&tailDecided{traceID: i})
+	}
+	assert.Len(t, s.decided, tailSamplerDecidedTraces)
+	assert.NotContains(t, s.decided, uint64(1))
+	assert.Contains(t, s.decided, uint64(tailSamplerDecidedTraces+1))
+}
+
+func TestTailSamplerStop(t *testing.T) {
+	var decisions []TailDecision
+	s := newTailTestSampler(nil, &decisions)
+	s.Start()
+	s.Add(time.Now(), tailChunk(10, &pb.Span{TraceID: 1, SpanID: 1}))
+	s.Stop()
+	// the buffered traces are decided when stopping
+	assert.Len(t, decisions, 1)
+}
@@ -22,6 +22,9 @@ const (
 	tagStatusCode = "http.status_code"
 	tagSynthetics = "synthetics"
 	tagSpanKind   = "span.kind"
+
+	// customTagOverflowValue replaces the values of a custom tag past its cardinality limit.
+	customTagOverflowValue = "_other"
 )
 
 // Aggregation contains all the dimension on which we aggregate statistics.
@@ -32,15 +35,16 @@ type Aggregation struct {
 
 // BucketsAggregationKey specifies the key by which a bucket is aggregated.
 type BucketsAggregationKey struct {
-	Service      string
-	Name         string
-	Resource     string
-	Type         string
-	SpanKind     string
-	StatusCode   uint32
-	Synthetics   bool
-	PeerTagsHash uint64
-	IsTraceRoot  pb.Trilean
+	Service        string
+	Name           string
+	Resource       string
+	Type           string
+	SpanKind       string
+	StatusCode     uint32
+	Synthetics     bool
+	PeerTagsHash   uint64
+	CustomTagsHash uint64
+	IsTraceRoot    pb.Trilean
 }
 
 // PayloadAggregationKey specifies the key by which a payload is aggregated.
@@ -106,12 +110,12 @@ func NewAggregationFromSpan(s *pb.Span, origin string, aggKey PayloadAggregation
 				peerTags = append(peerTags, t+":"+v)
 			}
 		}
-		agg.PeerTagsHash = peerTagsHash(peerTags)
+		agg.PeerTagsHash = tagsHash(peerTags)
 	}
 	return agg, peerTags
 }
 
-func peerTagsHash(tags []string) uint64 {
+func tagsHash(tags []string) uint64 {
 	if len(tags) == 0 {
 		return 0
 	}
@@ -128,18 +132,61 @@ func peerTagsHash(tags []string) uint64 {
 	return h.Sum64()
 }
 
+// customTagsLimiter extracts the custom tags used as additional aggregation dimensions from the spans,
+// capping the number of distinct values of each of them.
+type customTagsLimiter struct {
+	keys   []string
+	limit  int // maximum number of distinct values per key, unlimited if not positive
+	values map[string]map[string]struct{}
+}
+
+func newCustomTagsLimiter(keys []string, limit int) *customTagsLimiter {
+	return &customTagsLimiter{
+		keys:   keys,
+		limit:  limit,
+		values: make(map[string]map[string]struct{}, len(keys)),
+	}
+}
+
+// tags returns the custom tags of s as key:value. The values seen after the cardinality limit of their
+// key was reached are replaced by customTagOverflowValue.
+func (l *customTagsLimiter) tags(s *pb.Span) []string {
+	var tags []string
+	for _, k := range l.keys {
+		v, ok := s.Meta[k]
+		if !ok || v == "" {
+			continue
+		}
+		seen, ok := l.values[k]
+		if !ok {
+			seen = make(map[string]struct{})
+			l.values[k] = seen
+		}
+		if _, ok := seen[v]; !ok {
+			if l.limit > 0 && len(seen) >= l.limit {
+				v = customTagOverflowValue
+			} else {
+				seen[v] = struct{}{}
+			}
+		}
+		tags = append(tags, k+":"+v)
+	}
+	return tags
+}
+
 // NewAggregationFromGroup gets the Aggregation key of grouped stats.
 func NewAggregationFromGroup(g *pb.ClientGroupedStats) Aggregation {
 	return Aggregation{
 		BucketsAggregationKey: BucketsAggregationKey{
-			Resource:     g.Resource,
-			Service:      g.Service,
-			Name:         g.Name,
-			SpanKind:     g.SpanKind,
-			StatusCode:   g.HTTPStatusCode,
-			Synthetics:   g.Synthetics,
-			PeerTagsHash: peerTagsHash(g.PeerTags),
-			IsTraceRoot:  g.IsTraceRoot,
+			Resource:       g.Resource,
+			Service:        g.Service,
+			Name:           g.Name,
+			SpanKind:       g.SpanKind,
+			StatusCode:     g.HTTPStatusCode,
+			Synthetics:     g.Synthetics,
+			PeerTagsHash:   tagsHash(g.PeerTags),
+			CustomTagsHash: tagsHash(g.CustomTags),
+			IsTraceRoot:    g.IsTraceRoot,
 		},
 	}
 }
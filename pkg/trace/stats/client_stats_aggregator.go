@@ -263,6 +263,7 @@ func (b *bucket) aggregateCounts(p *pb.ClientStatsPayload) {
 				agg = &aggregatedCounts{}
 				payloadAgg[aggKey] = agg
 				agg.peerTags = sb.PeerTags
+				agg.customTags = sb.CustomTags
 			}
 			agg.hits += sb.Hits
 			agg.errors += sb.Errors
@@ -293,6 +294,7 @@ func (b *bucket) aggregationToPayloads() []*pb.ClientStatsPayload {
 				Synthetics:     aggrKey.Synthetics,
 				IsTraceRoot:    aggrKey.IsTraceRoot,
 				PeerTags:       counts.peerTags,
+				CustomTags:     counts.customTags,
 				Hits:           counts.hits,
 				Errors:         counts.errors,
 				Duration:       counts.duration,
@@ -340,7 +342,10 @@ func newBucketAggregationKey(b *pb.ClientGroupedStats) BucketsAggregationKey {
 		IsTraceRoot: b.IsTraceRoot,
 	}
 	if tags := b.GetPeerTags(); len(tags) > 0 {
-		k.PeerTagsHash = peerTagsHash(tags)
+		k.PeerTagsHash = tagsHash(tags)
+	}
+	if tags := b.GetCustomTags(); len(tags) > 0 {
+		k.CustomTagsHash = tagsHash(tags)
 	}
 	return k
 }
@@ -366,4 +371,5 @@ func trimCounts(p *pb.ClientStatsPayload) *pb.ClientStatsPayload {
 type aggregatedCounts struct {
 	hits, errors, duration uint64
 	peerTags               []string
+	customTags             []string
 }
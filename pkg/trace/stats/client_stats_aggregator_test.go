@@ -671,6 +671,11 @@ func TestNewBucketAggregationKeyPeerTags(t *testing.T) {
 		r := newBucketAggregationKey(&proto.ClientGroupedStats{Service: "a", PeerTags: []string{"peer.service:remote-service"}})
 		assert.Equal(BucketsAggregationKey{Service: "a", PeerTagsHash: peerTagsHash}, r)
 	})
+	t.Run("custom tags", func(t *testing.T) {
+		assert := assert.New(t)
+		r := newBucketAggregationKey(&proto.ClientGroupedStats{Service: "a", CustomTags: []string{"peer.service:remote-service"}})
+		assert.Equal(BucketsAggregationKey{Service: "a", CustomTagsHash: peerTagsHash}, r)
+	})
 }
 
 func deepCopy(p *proto.ClientStatsPayload) *proto.ClientStatsPayload {
@@ -735,6 +740,7 @@ func deepCopyGroupedStats(s []*proto.ClientGroupedStats) []*proto.ClientGroupedS
 			SpanKind:       b.GetSpanKind(),
 			PeerTags:       b.GetPeerTags(),
 			IsTraceRoot:    b.GetIsTraceRoot(),
+			CustomTags:     b.GetCustomTags(),
 		}
 		if b.OkSummary != nil {
 			stats[i].OkSummary = make([]byte, len(b.OkSummary))
@@ -51,9 +51,11 @@ type Concentrator struct {
 func NewConcentrator(conf *config.AgentConfig, writer Writer, now time.Time, statsd statsd.ClientInterface) *Concentrator {
 	bsize := conf.BucketInterval.Nanoseconds()
 	sc := NewSpanConcentrator(&SpanConcentratorConfig{
-		ComputeStatsBySpanKind: conf.ComputeStatsBySpanKind,
-		BucketInterval:         bsize,
-		PeerTags:               conf.ConfiguredPeerTags(),
+		ComputeStatsBySpanKind:     conf.ComputeStatsBySpanKind,
+		BucketInterval:             bsize,
+		PeerTags:                   conf.ConfiguredPeerTags(),
+		CustomTags:                 conf.StatsCustomTags,
+		CustomTagsCardinalityLimit: conf.StatsCustomTagsCardinalityLimit,
 	}, now)
 	c := Concentrator{
 		spanConcentrator: sc,
@@ -8,6 +8,7 @@ package stats
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -129,6 +130,15 @@ func TestNewConcentratorPeerTags(t *testing.T) {
 		c := NewConcentrator(&cfg, nil, time.Now(), statsd)
 		assert.Equal(cfg.ConfiguredPeerTags(), c.spanConcentrator.peerTagKeys)
 	})
+	t.Run("with custom tags", func(t *testing.T) {
+		assert := assert.New(t)
+		cfg := config.New()
+		cfg.BucketInterval = time.Duration(testBucketInterval)
+		cfg.StatsCustomTags = []string{"customer.tier"}
+		c := NewConcentrator(cfg, nil, time.Now(), statsd)
+		assert.Equal([]string{"customer.tier"}, c.spanConcentrator.customTagKeys)
+		assert.Equal(100, c.spanConcentrator.customTagsLimit)
+	})
 }
 
 // TestTracerHostname tests if `Concentrator` uses the tracer hostname rather than agent hostname, if there is one.
@@ -757,6 +767,59 @@ func TestPeerTags(t *testing.T) {
 	})
 }
 
+func TestCustomTags(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	newSpan := func(id uint64, tier string) *pb.Span {
+		return &pb.Span{
+			SpanID:   id,
+			Service:  "myservice",
+			Name:     "http.server.request",
+			Resource: "GET /users",
+			Duration: 100,
+			Meta:     map[string]string{"customer.tier": tier, "region": "us1"},
+			Metrics:  map[string]float64{"_top_level": 1},
+		}
+	}
+	spans := []*pb.Span{newSpan(1, "gold"), newSpan(2, "silver"), newSpan(3, "gold"), newSpan(4, "bronze"), newSpan(5, "")}
+	flush := func(c *Concentrator) map[string]uint64 {
+		for _, s := range spans {
+			c.addNow(toProcessedTrace([]*pb.Span{s}, "none", "", "", "", ""), "", nil)
+		}
+		stats := c.flushNow(now.UnixNano()+int64(c.spanConcentrator.bufferLen)*testBucketInterval, false)
+		hits := make(map[string]uint64)
+		for _, st := range stats.Stats[0].Stats[0].Stats {
+			hits[strings.Join(st.CustomTags, ",")] += st.Hits
+		}
+		return hits
+	}
+	t.Run("not configured", func(_ *testing.T) {
+		c := NewTestConcentrator(now)
+		assert.Equal(map[string]uint64{"": 5}, flush(c))
+	})
+	t.Run("configured", func(_ *testing.T) {
+		c := NewTestConcentrator(now)
+		c.spanConcentrator.customTagKeys = []string{"customer.tier", "region"}
+		assert.Equal(map[string]uint64{
+			"customer.tier:gold,region:us1":   2,
+			"customer.tier:silver,region:us1": 1,
+			"customer.tier:bronze,region:us1": 1,
+			"region:us1":                      1,
+		}, flush(c))
+	})
+	t.Run("cardinality limit", func(_ *testing.T) {
+		c := NewTestConcentrator(now)
+		c.spanConcentrator.customTagKeys = []string{"customer.tier"}
+		c.spanConcentrator.customTagsLimit = 2
+		assert.Equal(map[string]uint64{
+			"customer.tier:gold":   2,
+			"customer.tier:silver": 1,
+			"customer.tier:_other": 1,
+			"":                     1,
+		}, flush(c))
+	})
+}
+
 // TestComputeStatsThroughSpanKindCheck ensures that we generate stats for spans that have an eligible span.kind.
 func TestComputeStatsThroughSpanKindCheck(t *testing.T) {
 	assert := assert.New(t)
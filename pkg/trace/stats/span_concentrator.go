@@ -22,6 +22,11 @@ type SpanConcentratorConfig struct {
 	BucketInterval int64
 	// PeerTags additional tags to use for peer entity stats aggregation, nil if disabled
 	PeerTags []string
+	// CustomTags span tags to use as additional aggregation dimensions, nil if disabled
+	CustomTags []string
+	// CustomTagsCardinalityLimit the maximum number of distinct values of each custom tag in a bucket,
+	// unlimited if not positive
+	CustomTagsCardinalityLimit int
 }
 
 // SpanConcentrator produces time bucketed statistics from a stream of raw spans.
@@ -38,6 +43,10 @@ type SpanConcentrator struct {
 	// This only applies to past buckets. Stats buckets in the future are allowed with no restriction.
 	bufferLen   int
 	peerTagKeys []string // keys for supplementary tags that describe peer.service entities, nil if disabled
+	// customTagKeys are the keys of the span tags used as additional aggregation dimensions, nil if disabled
+	customTagKeys []string
+	// customTagsLimit is the maximum number of distinct values of each custom tag in a bucket
+	customTagsLimit int
 
 	// mu protects the buckets field
 	mu      sync.Mutex
@@ -54,6 +63,8 @@ func NewSpanConcentrator(cfg *SpanConcentratorConfig, now time.Time) *SpanConcen
 		mu:                     sync.Mutex{},
 		buckets:                make(map[int64]*RawBucket),
 		peerTagKeys:            cfg.PeerTags,
+		customTagKeys:          cfg.CustomTags,
+		customTagsLimit:        cfg.CustomTagsCardinalityLimit,
 	}
 	return sc
 }
@@ -83,6 +94,9 @@ func (sc *SpanConcentrator) addSpan(s *pb.Span, aggKey PayloadAggregationKey, co
 		if containerID != "" && len(containerTags) > 0 {
 			b.containerTagsByID[containerID] = containerTags
 		}
+		if len(sc.customTagKeys) > 0 {
+			b.customTags = newCustomTagsLimiter(sc.customTagKeys, sc.customTagsLimit)
+		}
 		sc.buckets[btime] = b
 	}
 	b.HandleSpan(s, weight, isTop, origin, aggKey, sc.peerTagKeys)
@@ -38,6 +38,7 @@ type groupedStats struct {
 	okDistribution  *ddsketch.DDSketch
 	errDistribution *ddsketch.DDSketch
 	peerTags        []string
+	customTags      []string
 }
 
 // round a float to an int, uniformly choosing
@@ -76,6 +77,7 @@ func (s *groupedStats) export(a Aggregation) (*pb.ClientGroupedStats, error) {
 		Synthetics:     a.Synthetics,
 		SpanKind:       a.SpanKind,
 		PeerTags:       s.peerTags,
+		CustomTags:     s.customTags,
 		IsTraceRoot:    a.IsTraceRoot,
 	}, nil
 }
@@ -108,6 +110,8 @@ type RawBucket struct {
 	data map[Aggregation]*groupedStats
 
 	containerTagsByID map[string][]string // a map from container ID to container tags
+
+	customTags *customTagsLimiter // extracts the custom aggregation tags of the spans, nil if disabled
 }
 
 // NewRawBucket opens a new calculation bucket for time ts and initializes it properly
@@ -159,16 +163,22 @@ func (sb *RawBucket) HandleSpan(s *pb.Span, weight float64, isTop bool, origin s
 		panic("env should never be empty")
 	}
 	aggr, peerTags := NewAggregationFromSpan(s, origin, aggKey, peerTagKeys)
-	sb.add(s, weight, isTop, aggr, peerTags)
+	var customTags []string
+	if sb.customTags != nil {
+		customTags = sb.customTags.tags(s)
+		aggr.CustomTagsHash = tagsHash(customTags)
+	}
+	sb.add(s, weight, isTop, aggr, peerTags, customTags)
 }
 
-func (sb *RawBucket) add(s *pb.Span, weight float64, isTop bool, aggr Aggregation, peerTags []string) {
+func (sb *RawBucket) add(s *pb.Span, weight float64, isTop bool, aggr Aggregation, peerTags, customTags []string) {
 	var gs *groupedStats
 	var ok bool
 
 	if gs, ok = sb.data[aggr]; !ok {
 		gs = newGroupedStats()
 		gs.peerTags = peerTags
+		gs.customTags = customTags
 		sb.data[aggr] = gs
 	}
 	if isTop {
@@ -217,6 +217,7 @@ DEFAULT_MODULES = {
     "pkg/gohai": GoModule("pkg/gohai", independent=True, importable=False),
     "pkg/logs/auditor": GoModule("pkg/logs/auditor", independent=True, used_by_otel=True),
     "pkg/logs/client": GoModule("pkg/logs/client", independent=True, used_by_otel=True),
+    "pkg/logs/client/otlp": GoModule("pkg/logs/client/otlp", independent=True),
     "pkg/logs/diagnostic": GoModule("pkg/logs/diagnostic", independent=True, used_by_otel=True),
     "pkg/logs/message": GoModule("pkg/logs/message", independent=True, used_by_otel=True),
     "pkg/logs/metrics": GoModule("pkg/logs/metrics", independent=True, used_by_otel=True),